@@ -0,0 +1,228 @@
+package timewheel
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// ack mode of a task, see Acked
+type ackPolicy struct {
+	timeout     time.Duration
+	maxAttempts int
+}
+
+// state of a delivery
+const (
+	deliveryPending int32 = iota
+	deliveryAcked
+	deliveryDone // redelivered, given up or dropped with its task
+)
+
+// a run of an acked task waiting for its Ack, it holds a reference to its task
+type delivery struct {
+	task      *task
+	data      TaskData // data of the run, the redeliveries get a copy
+	scheduled time.Time
+	attempt   int
+	deadline  time.Time
+	persist   func() // records the run in the store and the log once it is acknowledged or given up
+	state     int32  // accessed atomically
+}
+
+// context key of the delivery of the run
+type ackKey struct{}
+
+// Acked run the task at least once: every run must be acknowledged by calling Ack with the context it received,
+// so the job must take one, see AddTaskCtx, AddTaskErr, TaskBuilder.DoCtx and RestoreCtx,
+// a run not acknowledged within timeout is dispatched again with the same data and scheduled time, the
+// pending runs of a stored or logged task are only recorded once acknowledged so they are run again after a
+// restart. Once maxAttempts attempts of a run were not acknowledged the task goes to the dead letter handler
+// with ErrNotAcked, see WithDeadLetter, the run is dropped without one. The attempts are reported by Attempt
+// and Stats.Redelivered.
+func Acked(timeout time.Duration, maxAttempts int) TaskOption {
+	return func(t *task) {
+		if timeout > 0 && maxAttempts > 0 {
+			t.ack = &ackPolicy{timeout: timeout, maxAttempts: maxAttempts}
+		}
+	}
+}
+
+// Ack acknowledge the run whose job received ctx, see Acked. Report whether the attempt is acknowledged,
+// false if it was acknowledged already, redelivered meanwhile or the task is not acked.
+func Ack(ctx context.Context) bool {
+	d, ok := ctx.Value(ackKey{}).(*delivery)
+	return ok && d.acknowledge()
+}
+
+// Attempt get the attempt of the run whose job received ctx, 1 for the first dispatch, 0 if the task is not acked
+func Attempt(ctx context.Context) int {
+	if d, ok := ctx.Value(ackKey{}).(*delivery); ok {
+		return d.attempt
+	}
+	return 0
+}
+
+// mark the delivery acknowledged unless it is done already
+func (d *delivery) acknowledge() bool {
+	if !atomic.CompareAndSwapInt32(&d.state, deliveryPending, deliveryAcked) {
+		return false
+	}
+	if d.persist != nil {
+		d.persist()
+	}
+	return true
+}
+
+// track the run being dispatched and hand it the ack, only called on the wheel goroutine
+func (tw *TimeWheel) deliver(r *jobRun) {
+	task := r.task
+	d := &delivery{
+		task:      task,
+		data:      copyTaskData(task.taskData),
+		scheduled: r.exec.Scheduled,
+		attempt:   task.attempts + 1,
+		deadline:  r.exec.Fired.Add(task.ack.timeout),
+		persist:   r.persist,
+	}
+	task.attempts = 0
+	r.persist = nil
+	task.retain()
+	r.ack = d
+	tw.unacked = append(tw.unacked, d)
+}
+
+// drop the acknowledged runs, redeliver or give up the runs past their deadline, checked at every tick
+func (tw *TimeWheel) checkUnacked(now time.Time) {
+	pending := tw.unacked[:0]
+	for _, d := range tw.unacked {
+		if atomic.LoadInt32(&d.state) == deliveryPending && now.Before(d.deadline) {
+			pending = append(pending, d)
+			continue
+		}
+		// acknowledged meanwhile
+		if !atomic.CompareAndSwapInt32(&d.state, deliveryPending, deliveryDone) {
+			d.task.release()
+			continue
+		}
+		if d.attempt >= d.task.ack.maxAttempts {
+			tw.giveUp(d, now)
+			d.task.release()
+			continue
+		}
+		pending = append(pending, tw.redeliver(d, now))
+		d.task.release()
+	}
+	for i := len(pending); i < len(tw.unacked); i++ {
+		tw.unacked[i] = nil
+	}
+	tw.unacked = pending
+}
+
+// dispatch the run again, return its new delivery holding its own reference to the task
+func (tw *TimeWheel) redeliver(d *delivery, now time.Time) *delivery {
+	task := d.task
+	task.retain()
+	next := &delivery{
+		task:      task,
+		data:      d.data,
+		scheduled: d.scheduled,
+		attempt:   d.attempt + 1,
+		deadline:  now.Add(task.ack.timeout),
+		persist:   d.persist,
+	}
+	tw.logger.Printf("timewheel: run not acknowledged, redelivered, key: %v, attempt: %d", task.key, next.attempt)
+	atomic.AddInt64(&tw.firedNum, 1)
+	atomic.AddInt64(&task.stats.redelivered, 1)
+	task.stats.fired(now)
+	task.retain()
+	atomic.AddInt64(&tw.inflightNum, 1)
+	tw.dispatch(&jobRun{
+		task: task,
+		data: copyTaskData(d.data),
+		ack:  next,
+		exec: Execution{Key: task.key, Scheduled: d.scheduled, Fired: now},
+		info: task.info(),
+	})
+	return next
+}
+
+// hand the task to the dead letter handler once the last attempt of a run was not acknowledged
+func (tw *TimeWheel) giveUp(d *delivery, now time.Time) {
+	task := d.task
+	tw.logger.Printf("timewheel: run not acknowledged after %d attempts, key: %v", d.attempt, task.key)
+	if tw.deadHandler == nil {
+		if d.persist != nil {
+			go d.persist()
+		}
+		return
+	}
+	dead := DeadLetter{Spec: task.spec(now), Failures: int64(d.attempt), LastError: ErrNotAcked, job: task.job}
+	if t, ok := tw.taskRecord.Load(task.key); ok && t == task && !task.isHeld() {
+		tw.unregister(task)
+	} else {
+		// the last run of the task, only the unacknowledged run is left
+		dead.Spec.Times = 1
+	}
+	go func() {
+		if dead.Spec.JobName != "" {
+			tw.forgetNamed(dead.Spec.Key)
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				tw.logger.Printf("timewheel: dead letter handler panic recovered, key: %v, panic: %v", dead.Spec.Key, r)
+			}
+		}()
+		tw.deadHandler(dead)
+	}()
+}
+
+// drop the pending runs of the removed task, only called on the wheel goroutine
+func (tw *TimeWheel) dropUnacked(task *task) {
+	for _, d := range tw.unacked {
+		if d.task == task && atomic.CompareAndSwapInt32(&d.state, deliveryPending, deliveryDone) && d.persist != nil &&
+			tw.store != nil {
+			// the store update is skipped, the removal deletes the task
+			tw.storePending.done(task.key)
+		}
+	}
+}
+
+// describe the unacknowledged runs in the specs of their tasks, the runs of the finished tasks get their own
+func (tw *TimeWheel) unackedSpecs(specs []TaskSpec, now time.Time) []TaskSpec {
+	index := make(map[interface{}]int, len(specs))
+	for i, spec := range specs {
+		index[spec.Key] = i
+	}
+	for _, d := range tw.unacked {
+		if atomic.LoadInt32(&d.state) != deliveryPending {
+			continue
+		}
+		i, ok := index[d.task.key]
+		if !ok || d.task.times == 0 {
+			// the task ran its last time
+			spec := d.task.spec(now)
+			spec.Times, spec.Data = 0, copyTaskData(d.data)
+			specs = append(specs, spec)
+			i = len(specs) - 1
+		}
+		// the earliest run of the task
+		if spec := &specs[i]; spec.Redeliver.IsZero() || d.deadline.Before(spec.Redeliver) {
+			spec.Redeliver, spec.Attempt = d.deadline, d.attempt
+		}
+	}
+	return specs
+}
+
+// wait again for the Ack of the unacknowledged run of the restored task, only called on the wheel goroutine
+func (tw *TimeWheel) restoreDelivery(task *task) {
+	task.retain()
+	tw.unacked = append(tw.unacked, &delivery{
+		task:      task,
+		data:      copyTaskData(task.taskData),
+		scheduled: task.redeliver,
+		attempt:   task.attempts,
+		deadline:  task.redeliver,
+	})
+	task.redeliver, task.attempts = time.Time{}, 0
+}
@@ -0,0 +1,171 @@
+package timewheel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAckInTime(t *testing.T) {
+	c := newFakeClock()
+	tw := New(10*time.Millisecond, 8, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var runs int32
+	var acked int32
+	tw.AddTaskErr(10*time.Millisecond, 1, "k", TaskData{"a": 1}, func(ctx context.Context, d TaskData) error {
+		atomic.AddInt32(&runs, 1)
+		if Attempt(ctx) != 1 || d["a"] != 1 || len(d) != 1 {
+			t.Error("attempt", Attempt(ctx), d)
+		}
+		if Ack(ctx) {
+			atomic.AddInt32(&acked, 1)
+		}
+		if Ack(ctx) {
+			t.Error("acked twice")
+		}
+		return nil
+	}, Acked(50*time.Millisecond, 3))
+	settle(tw)
+	for i := 0; i < 20; i++ {
+		c.Tick(10 * time.Millisecond)
+		settle(tw)
+	}
+	if runs != 1 || acked != 1 {
+		t.Fatal(runs, acked)
+	}
+	var n int
+	tw.exec(func() { n = len(tw.unacked) })
+	if n != 0 {
+		t.Fatal(n)
+	}
+}
+
+func TestAckTooLate(t *testing.T) {
+	c := newFakeClock()
+	var mu sync.Mutex
+	var dead []DeadLetter
+	tw := New(10*time.Millisecond, 8, WithClock(c), WithDeadLetter(100, func(d DeadLetter) {
+		mu.Lock()
+		dead = append(dead, d)
+		mu.Unlock()
+	}))
+	tw.Start()
+	defer tw.Stop()
+	var attempts []int
+	var late context.Context
+	tw.AddTaskErr(10*time.Millisecond, 1, "k", nil, func(ctx context.Context, d TaskData) error {
+		mu.Lock()
+		attempts = append(attempts, Attempt(ctx))
+		if Attempt(ctx) == 1 {
+			late = ctx
+		}
+		mu.Unlock()
+		return nil
+	}, Acked(30*time.Millisecond, 3))
+	settle(tw)
+	for i := 0; i < 6; i++ {
+		c.Tick(10 * time.Millisecond)
+		settle(tw)
+	}
+	// acknowledged after its deadline, the redelivery is on
+	mu.Lock()
+	if Ack(late) {
+		t.Error("late ack accepted")
+	}
+	mu.Unlock()
+	for i := 0; i < 20; i++ {
+		c.Tick(10 * time.Millisecond)
+		settle(tw)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(attempts) != 3 || attempts[2] != 3 || len(dead) != 1 || !errors.Is(dead[0].LastError, ErrNotAcked) ||
+		dead[0].Spec.Times != 1 {
+		t.Fatal(attempts, dead)
+	}
+}
+
+func TestAckSnapshotRestore(t *testing.T) {
+	c := newFakeClock()
+	tw := New(10*time.Millisecond, 8, WithClock(c))
+	tw.Start()
+	job := func(d TaskData) {}
+	tw.AddTaskWith(10*time.Millisecond, 1, "once", TaskData{"x": 1}, job, Acked(40*time.Millisecond, 5))
+	tw.AddTaskWith(100*time.Millisecond, -1, "rec", TaskData{"x": 1}, job, Acked(40*time.Millisecond, 5))
+	settle(tw)
+	for i := 0; i < 11; i++ {
+		c.Tick(10 * time.Millisecond)
+	}
+	settle(tw)
+	specs, err := tw.Snapshot()
+	if err != nil || len(specs) != 2 {
+		t.Fatal(specs, err)
+	}
+	tw.Stop()
+
+	// crash, the unacknowledged run comes back
+	c2 := newFakeClock()
+	tw2 := New(10*time.Millisecond, 8, WithClock(c2))
+	tw2.Start()
+	defer tw2.Stop()
+	var mu sync.Mutex
+	got := map[interface{}][]int{}
+	err = tw2.RestoreCtx(specs, func(spec TaskSpec) (JobCtx, error) {
+		return func(ctx context.Context, d TaskData) {
+			mu.Lock()
+			got[spec.Key] = append(got[spec.Key], Attempt(ctx))
+			mu.Unlock()
+			if d["x"] != 1 || len(d) != 1 {
+				t.Error(d)
+			}
+			Ack(ctx)
+		}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	settle(tw2)
+	for i := 0; i < 15; i++ {
+		c2.Tick(10 * time.Millisecond)
+		settle(tw2)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(got) != "map[once:[4] rec:[2]]" {
+		t.Fatal(got)
+	}
+}
+
+// always held by another instance
+type lostLocker struct{}
+
+func (lostLocker) TryLock(string, time.Duration) (bool, error) { return false, nil }
+func (lostLocker) Unlock(string) error                         { return nil }
+
+// the run skipped for the lock is acknowledged, the instance holding the lock runs it
+func TestAckLockLost(t *testing.T) {
+	c := newFakeClock()
+	tw := New(10*time.Millisecond, 8, WithClock(c), WithLocker(lostLocker{}, time.Second))
+	tw.Start()
+	defer tw.Stop()
+	var runs int32
+	tw.AddTaskErr(10*time.Millisecond, 1, "k", nil, func(context.Context, TaskData) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	}, Acked(30*time.Millisecond, 3))
+	settle(tw)
+	for i := 0; i < 10; i++ {
+		c.Tick(10 * time.Millisecond)
+		settle(tw)
+	}
+	var n int
+	tw.exec(func() { n = len(tw.unacked) })
+	if runs != 0 || n != 0 || tw.LockLost() != 1 {
+		t.Fatal(runs, n, tw.LockLost())
+	}
+}
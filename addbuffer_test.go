@@ -0,0 +1,112 @@
+package timewheel
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// block the wheel goroutine until the returned func is called
+func stall(tw *TimeWheel) func() {
+	stalled, release := make(chan struct{}), make(chan struct{})
+	go tw.exec(func() {
+		close(stalled)
+		<-release
+	})
+	<-stalled
+	return func() { close(release) }
+}
+
+func TestTryAddTaskFull(t *testing.T) {
+	tw := New(time.Second, 4, WithAddBuffer(10))
+	tw.Start()
+	defer tw.Stop()
+	release := stall(tw)
+	job := func(TaskData) {}
+	begin := time.Now()
+	full := 0
+	for i := 0; i < 1000; i++ {
+		switch err := tw.TryAddTask(time.Second, 1, i, nil, job); err {
+		case nil:
+		case ErrQueueFull:
+			full++
+		default:
+			t.Fatal(err)
+		}
+	}
+	if d := time.Since(begin); d > time.Second {
+		t.Fatal("TryAddTask blocked", d)
+	}
+	if full != 990 || tw.Backlog() != 10 {
+		t.Fatal(full, tw.Backlog())
+	}
+
+	// AddTask waits for room and succeeds once the wheel goroutine goes on
+	done := make(chan error)
+	go func() {
+		done <- tw.AddTask(time.Second, 1, "blocked", nil, job)
+	}()
+	select {
+	case err := <-done:
+		t.Fatal("AddTask did not wait", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	release()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	settle(tw)
+	if tw.Backlog() != 0 || tw.Len() != 11 || !tw.HasTask("blocked") {
+		t.Fatal(tw.Backlog(), tw.Len())
+	}
+}
+
+// a buffered add is registered before a later RemoveTask of the same goroutine
+func TestAddBufferOrder(t *testing.T) {
+	tw := New(10*time.Millisecond, 4, WithAddBuffer(10))
+	tw.Start()
+	defer tw.Stop()
+	release := stall(tw)
+	ran := make(chan struct{}, 1)
+	if err := tw.AddTask(20*time.Millisecond, 1, "a", nil, func(TaskData) { ran <- struct{}{} }); err != nil {
+		t.Fatal(err)
+	}
+	if tw.Backlog() != 1 {
+		t.Fatal(tw.Backlog())
+	}
+	removed := make(chan error)
+	go func() {
+		removed <- tw.RemoveTask("a")
+	}()
+	time.Sleep(10 * time.Millisecond)
+	release()
+	if err := <-removed; err != nil {
+		t.Fatal(err)
+	}
+	if tw.HasTask("a") {
+		t.Fatal("task still registered")
+	}
+	select {
+	case <-ran:
+		t.Fatal("removed task ran")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// TryAddTask against a stalled wheel goroutine, the adds past the buffer fail fast
+func BenchmarkTryAddTaskFull(b *testing.B) {
+	tw := New(time.Second, 4, WithAddBuffer(64))
+	tw.Start()
+	defer tw.Stop()
+	release := stall(tw)
+	defer release()
+	keys := make([]string, b.N)
+	for i := range keys {
+		keys[i] = fmt.Sprint(i)
+	}
+	job := func(TaskData) {}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tw.TryAddTask(time.Second, 1, keys[i], nil, job)
+	}
+}
@@ -0,0 +1,45 @@
+package timewheel
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAddTaskContextTimeout(t *testing.T) {
+	tw := New(time.Second, 4)
+	tw.Start()
+	defer tw.Stop()
+	release := stall(tw)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	begin := time.Now()
+	if err := tw.AddTaskContext(ctx, time.Second, 1, "ghost", nil, func(TaskData) {}); err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+	if d := time.Since(begin); d > 500*time.Millisecond {
+		t.Fatal("not bounded", d)
+	}
+	if tw.HasTask("ghost") {
+		t.Fatal("ghost task while stalled")
+	}
+	release()
+	settle(tw)
+	if tw.HasTask("ghost") || tw.Len() != 0 {
+		t.Fatal("ghost task")
+	}
+	// the key is free
+	if err := tw.AddTaskContext(context.Background(), time.Second, 1, "ghost", nil, func(TaskData) {}); err != nil {
+		t.Fatal(err)
+	}
+	settle(tw)
+	if !tw.HasTask("ghost") {
+		t.Fatal("not added")
+	}
+
+	canceled, cancel2 := context.WithCancel(context.Background())
+	cancel2()
+	if err := tw.AddTaskContext(canceled, time.Second, 1, "canceled", nil, func(TaskData) {}); err != context.Canceled {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,241 @@
+package timewheel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync/atomic"
+)
+
+const (
+	adminDefaultLimit = 100
+	adminMaxLimit     = 1000
+)
+
+// AdminHandler get a http handler exposing the tasks as json, mount it with http.StripPrefix:
+//
+//	GET    /tasks?offset=0&limit=100  list the tasks ordered by key
+//	GET    /tasks/{key}               info and stats of a task
+//	DELETE /tasks/{key}               remove the task
+//	POST   /tasks/{key}/pause         pause the task
+//	POST   /tasks/{key}/resume        resume the task
+//	GET    /status                    counters and slot occupancy
+//	GET    /invariants                violations found by ValidateInvariants, 500 if any
+//	GET    /export                    every task, see MarshalTasksJSON
+//
+// Tasks are addressed by the string form of their key. The mutating endpoints answer 403
+// unless guard is set and accepts the request, a nil guard makes the handler read only.
+func (tw *TimeWheel) AdminHandler(guard func(r *http.Request) bool) http.Handler {
+	a := &admin{tw: tw, guard: guard}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tasks", a.list)
+	mux.HandleFunc("GET /tasks/{key}", a.get)
+	mux.HandleFunc("DELETE /tasks/{key}", a.mutate(tw.RemoveTask))
+	mux.HandleFunc("POST /tasks/{key}/pause", a.mutate(tw.PauseTask))
+	mux.HandleFunc("POST /tasks/{key}/resume", a.mutate(tw.ResumeTask))
+	mux.HandleFunc("GET /status", a.status)
+	mux.HandleFunc("GET /invariants", a.invariants)
+	mux.HandleFunc("GET /export", a.export)
+	return mux
+}
+
+type admin struct {
+	tw    *TimeWheel
+	guard func(r *http.Request) bool
+}
+
+// json form of a task
+type adminTask struct {
+	Key      string      `json:"key"`
+	Interval string      `json:"interval"`
+	Times    int         `json:"times"`
+	Paused   bool        `json:"paused"`
+	Stats    *adminStats `json:"stats,omitempty"`
+}
+
+type adminStats struct {
+	Runs         int64  `json:"runs"`
+	LastFire     string `json:"last_fire,omitempty"`
+	LastDuration string `json:"last_duration"`
+	LastError    string `json:"last_error,omitempty"`
+}
+
+func newAdminTask(info TaskInfo) adminTask {
+	return adminTask{
+		Key:      fmt.Sprint(info.Key),
+		Interval: info.Interval.String(),
+		Times:    info.Times,
+		Paused:   info.Paused,
+	}
+}
+
+func (a *admin) list(w http.ResponseWriter, r *http.Request) {
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+	limit, err := queryInt(r, "limit", adminDefaultLimit)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+	if limit > adminMaxLimit {
+		limit = adminMaxLimit
+	}
+
+	tasks := make([]adminTask, 0, a.tw.Len())
+	a.tw.Range(func(key interface{}, info TaskInfo) bool {
+		tasks = append(tasks, newAdminTask(info))
+		return true
+	})
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Key < tasks[j].Key
+	})
+	total := len(tasks)
+	if offset > total {
+		offset = total
+	}
+	tasks = tasks[offset:]
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+		"total":  total,
+		"offset": offset,
+		"tasks":  tasks,
+	})
+}
+
+func (a *admin) get(w http.ResponseWriter, r *http.Request) {
+	key, info, ok := a.find(r.PathValue("key"))
+	if !ok {
+		writeAdminError(w, http.StatusNotFound, errors.New("task not exists"))
+		return
+	}
+	task := newAdminTask(info)
+	if st, err := a.tw.TaskStats(key); err == nil {
+		task.Stats = newAdminStats(st)
+	}
+	writeAdminJSON(w, http.StatusOK, task)
+}
+
+func newAdminStats(st Stats) *adminStats {
+	s := &adminStats{Runs: st.Runs, LastDuration: st.LastDuration.String()}
+	if !st.LastFire.IsZero() {
+		s.LastFire = st.LastFire.Format("2006-01-02T15:04:05.000Z07:00")
+	}
+	if st.LastError != nil {
+		s.LastError = st.LastError.Error()
+	}
+	return s
+}
+
+func (a *admin) export(w http.ResponseWriter, r *http.Request) {
+	b, err := a.tw.MarshalTasksJSON()
+	if err != nil {
+		writeAdminError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+// wrap a call taking the task key, guarded
+func (a *admin) mutate(fn func(key interface{}) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.guard == nil || !a.guard(r) {
+			writeAdminError(w, http.StatusForbidden, errors.New("forbidden"))
+			return
+		}
+		key, _, ok := a.find(r.PathValue("key"))
+		if !ok {
+			writeAdminError(w, http.StatusNotFound, errors.New("task not exists"))
+			return
+		}
+		if err := fn(key); err != nil {
+			code := http.StatusInternalServerError
+			if errors.Is(err, ErrWheelStopped) {
+				code = http.StatusServiceUnavailable
+			}
+			writeAdminError(w, code, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (a *admin) status(w http.ResponseWriter, r *http.Request) {
+	snap, err := a.tw.slotSnapshot()
+	if err != nil {
+		writeAdminError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+		"tasks":    a.tw.Len(),
+		"backlog":  a.tw.Backlog(),
+		"position": snap.pos,
+		"heap":     snap.heap,
+		"slots":    snap.counts,
+		"fired":    atomic.LoadInt64(&a.tw.firedNum),
+		"removed":  atomic.LoadInt64(&a.tw.removedNum),
+		"ticks":    atomic.LoadInt64(&a.tw.tickNum),
+	})
+}
+
+func (a *admin) invariants(w http.ResponseWriter, r *http.Request) {
+	violations, zombies, err := a.tw.checkInvariants()
+	if err != nil {
+		writeAdminError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	list := make([]string, len(violations))
+	for i, v := range violations {
+		list[i] = v.String()
+	}
+	code := http.StatusOK
+	if len(violations) > 0 {
+		code = http.StatusInternalServerError
+	}
+	writeAdminJSON(w, code, map[string]interface{}{
+		"violations": list,
+		"zombies":    zombies,
+	})
+}
+
+// find the task whose key prints as s
+func (a *admin) find(s string) (key interface{}, info TaskInfo, ok bool) {
+	a.tw.Range(func(k interface{}, i TaskInfo) bool {
+		if fmt.Sprint(k) == s {
+			key, info, ok = k, i, true
+			return false
+		}
+		return true
+	})
+	return
+}
+
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("illegal %s %q", name, v)
+	}
+	return n, nil
+}
+
+func writeAdminJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeAdminError(w http.ResponseWriter, code int, err error) {
+	writeAdminJSON(w, code, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,121 @@
+package timewheel
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type adminList struct {
+	Total  int         `json:"total"`
+	Offset int         `json:"offset"`
+	Tasks  []adminTask `json:"tasks"`
+}
+
+func adminDo(t *testing.T, method, url string, v interface{}) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if v != nil && resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestAdminHandler(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	for _, k := range []string{"a", "b", "c"} {
+		tw.AddTask(2*time.Second, -1, k, nil, func(TaskData) {})
+	}
+	settle(tw)
+	srv := httptest.NewServer(tw.AdminHandler(func(*http.Request) bool { return true }))
+	defer srv.Close()
+
+	var list adminList
+	if code := adminDo(t, "GET", srv.URL+"/tasks?limit=2&offset=1", &list); code != http.StatusOK {
+		t.Fatalf("list: %d", code)
+	}
+	if list.Total != 3 || list.Offset != 1 || len(list.Tasks) != 2 || list.Tasks[0].Key != "b" || list.Tasks[1].Key != "c" {
+		t.Fatalf("list: %+v", list)
+	}
+	var task adminTask
+	if code := adminDo(t, "GET", srv.URL+"/tasks/b", &task); code != http.StatusOK || task.Key != "b" || task.Interval != "2s" || task.Times != -1 {
+		t.Fatalf("get: %d %+v", code, task)
+	}
+
+	if code := adminDo(t, "DELETE", srv.URL+"/tasks/b", nil); code != http.StatusNoContent {
+		t.Fatalf("delete: %d", code)
+	}
+	if tw.HasTask("b") {
+		t.Fatal("b still registered")
+	}
+	if code := adminDo(t, "POST", srv.URL+"/tasks/a/pause", nil); code != http.StatusNoContent {
+		t.Fatalf("pause: %d", code)
+	}
+	list = adminList{}
+	adminDo(t, "GET", srv.URL+"/tasks", &list)
+	if list.Total != 2 || list.Tasks[0].Key != "a" || !list.Tasks[0].Paused || list.Tasks[1].Key != "c" {
+		t.Fatalf("list after delete: %+v", list)
+	}
+	if code := adminDo(t, "POST", srv.URL+"/tasks/a/resume", nil); code != http.StatusNoContent {
+		t.Fatalf("resume: %d", code)
+	}
+
+	var status map[string]interface{}
+	if code := adminDo(t, "GET", srv.URL+"/status", &status); code != http.StatusOK || status["tasks"] != float64(2) {
+		t.Fatalf("status: %d %v", code, status)
+	}
+	if slots, ok := status["slots"].([]interface{}); !ok || len(slots) != 10 {
+		t.Fatalf("slots: %v", status["slots"])
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		code         int
+	}{
+		{"GET", "/tasks/zz", http.StatusNotFound},
+		{"DELETE", "/tasks/zz", http.StatusNotFound},
+		{"GET", "/tasks?limit=x", http.StatusBadRequest},
+	} {
+		if code := adminDo(t, tc.method, srv.URL+tc.path, nil); code != tc.code {
+			t.Fatalf("%s %s: %d, want %d", tc.method, tc.path, code, tc.code)
+		}
+	}
+}
+
+func TestAdminHandlerReadOnly(t *testing.T) {
+	tw := New(time.Second, 10)
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(time.Minute, -1, "a", nil, func(TaskData) {})
+	srv := httptest.NewServer(tw.AdminHandler(nil))
+	defer srv.Close()
+
+	if code := adminDo(t, "DELETE", srv.URL+"/tasks/a", nil); code != http.StatusForbidden {
+		t.Fatalf("delete: %d", code)
+	}
+	if code := adminDo(t, "POST", srv.URL+"/tasks/a/pause", nil); code != http.StatusForbidden {
+		t.Fatalf("pause: %d", code)
+	}
+	if !tw.HasTask("a") {
+		t.Fatal("a removed through a read only handler")
+	}
+	var task adminTask
+	if code := adminDo(t, "GET", srv.URL+"/tasks/a", &task); code != http.StatusOK || task.Key != "a" {
+		t.Fatalf("get: %d %+v", code, task)
+	}
+}
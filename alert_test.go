@@ -0,0 +1,61 @@
+package timewheel
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFailureAlert(t *testing.T) {
+	c := newFakeClock()
+	var alerts int64
+	tw := New(10*time.Millisecond, 8, WithClock(c), WithFailureAlert(2, func(key interface{}, n int64, err error, info TaskInfo) {
+		if key != "k" || n != 2 || err == nil {
+			t.Error(key, n, err)
+		}
+		atomic.AddInt64(&alerts, 1)
+	}))
+	tw.Start()
+	defer tw.Stop()
+	script := []bool{false, false, true, false}
+	var i int64
+	tw.AddTaskErr(10*time.Millisecond, -1, "k", nil, func(ctx context.Context, d TaskData) error {
+		n := atomic.AddInt64(&i, 1) - 1
+		if n < int64(len(script)) && script[n] {
+			return nil
+		}
+		return errors.New("boom")
+	})
+	settle(tw)
+	c.Tick(10 * time.Millisecond)
+	var st Stats
+	for k, want := range []int64{1, 2, 0, 1} {
+		c.Tick(10 * time.Millisecond)
+		settle(tw)
+		// the run may still be finishing
+		for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+			st, _ = tw.TaskStats("k")
+			if st.Failures == want || time.Now().After(deadline) {
+				break
+			}
+		}
+		if st.Failures != want {
+			t.Fatalf("run %d: %d failures, want %d", k, st.Failures, want)
+		}
+		if st.LastFailure == nil || st.LastFailedAt.IsZero() {
+			t.Fatalf("run %d: %+v", k, st)
+		}
+	}
+	if n := atomic.LoadInt64(&alerts); n != 1 {
+		t.Fatalf("%d alerts", n)
+	}
+	// a new definition starts afresh
+	if err := tw.UpdateTask("k", time.Minute, nil); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := tw.TaskStats("k"); st.Failures != 0 {
+		t.Fatal(st.Failures)
+	}
+}
@@ -0,0 +1,21 @@
+package timewheel
+
+import "time"
+
+// AlignToPeriod run the task on the multiples of its interval counted from the Unix epoch,
+// the first run is the next multiple after the task is added and every run stays on them
+func AlignToPeriod(on bool) TaskOption {
+	return func(t *task) {
+		t.alignPeriod = on
+	}
+}
+
+// first multiple of d since the Unix epoch after t
+func alignedAfter(t time.Time, d time.Duration) time.Time {
+	n := t.UnixNano()
+	next := (n/int64(d) + 1) * int64(d)
+	if n < 0 && n%int64(d) != 0 {
+		next -= int64(d)
+	}
+	return time.Unix(0, next).In(t.Location())
+}
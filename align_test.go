@@ -0,0 +1,161 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// clock whose ticker fires at fixed multiples of its period from its creation, like time.Ticker
+type wallClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	next    time.Time
+	period  time.Duration
+	periods []time.Duration
+	ch      chan time.Time
+}
+
+func (c *wallClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *wallClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.period, c.next = d, c.now.Add(d)
+	c.periods = append(c.periods, d)
+	return c
+}
+
+func (c *wallClock) C() <-chan time.Time { return c.ch }
+func (c *wallClock) Stop()               {}
+
+// fire the ticker, skip ticks are lost like on a busy host
+func (c *wallClock) Tick(skip int) {
+	c.mu.Lock()
+	c.next = c.next.Add(time.Duration(skip) * c.period)
+	c.now = c.next
+	c.next = c.next.Add(c.period)
+	now := c.now
+	c.mu.Unlock()
+	c.ch <- now
+}
+
+func TestAlignToWallClock(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 17, 300000000, time.UTC)
+	c := &wallClock{now: start, ch: make(chan time.Time)}
+	tw := New(time.Minute, 60, WithClock(c), WithAlignToWallClock())
+	tw.Start()
+	defer tw.Stop()
+	lastTick := func() time.Time {
+		var at time.Time
+		tw.exec(func() { at = tw.lastTick })
+		return at
+	}
+	var runs []time.Time
+	var mu sync.Mutex
+	tw.AddTask(time.Minute, -1, "k", nil, func(TaskData) {
+		mu.Lock()
+		runs = append(runs, c.Now())
+		mu.Unlock()
+	})
+	settle(tw)
+	if len(c.periods) != 1 || c.periods[0] != 42700*time.Millisecond {
+		t.Fatalf("first ticker %v, want to wait for 12:01", c.periods)
+	}
+	c.Tick(0)
+	settle(tw)
+	if at := lastTick(); !at.Equal(time.Date(2024, 5, 1, 12, 1, 0, 0, time.UTC)) {
+		t.Fatalf("first tick at %v", at)
+	}
+	if len(c.periods) != 2 || c.periods[1] != time.Minute {
+		t.Fatalf("tickers %v", c.periods)
+	}
+	for i := 2; i <= 100; i++ {
+		skip := 0
+		if i == 50 {
+			// two ticks lost, caught up on the boundaries
+			skip = 2
+		}
+		c.Tick(skip)
+		settle(tw)
+		if i == 50 {
+			i += 2
+		}
+		if at := lastTick(); at.Truncate(time.Minute) != at {
+			t.Fatalf("tick %d at %v", i, at)
+		}
+	}
+	if at := lastTick(); !at.Equal(time.Date(2024, 5, 1, 13, 40, 0, 0, time.UTC)) {
+		t.Fatalf("hundredth tick at %v", at)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, at := range runs {
+		if at.Truncate(time.Minute) != at {
+			t.Fatalf("run at %v", at)
+		}
+	}
+
+	if New(time.Minute, 60, WithAlignToWallClock(), WithTimeScale(10)) != nil {
+		t.Fatal("aligned ticks on a scaled clock")
+	}
+}
+
+func TestAlignToPeriod(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 600, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var mu sync.Mutex
+	var fires []time.Time
+	// added at 22:13:20, the runs stay on the 5 minute boundaries
+	tw.AddTaskWith(5*time.Minute, 3, "a", nil, func(TaskData) {
+		mu.Lock()
+		fires = append(fires, c.Now())
+		mu.Unlock()
+	}, AlignToPeriod(true))
+	settle(tw)
+	for i := 0; i < 15*60; i++ {
+		c.Tick(time.Second)
+		tw.exec(func() {})
+		for tw.Stats().InFlight > 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	settle(tw)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(fires) != 3 {
+		t.Fatalf("fired at %v", fires)
+	}
+	for i, f := range fires {
+		want := time.Date(2023, 11, 14, 22, 15+5*i, 0, 0, time.UTC)
+		if f.Before(want) || f.Sub(want) > 2*time.Second {
+			t.Fatalf("run %d at %v, want %v", i, f, want)
+		}
+	}
+}
+
+func TestAlignedAfter(t *testing.T) {
+	at := func(h, m, s int) time.Time { return time.Date(2023, 11, 14, h, m, s, 0, time.UTC) }
+	for _, tc := range []struct {
+		t    time.Time
+		d    time.Duration
+		want time.Time
+	}{
+		{at(22, 13, 20), 5 * time.Minute, at(22, 15, 0)},
+		{at(22, 15, 0), 5 * time.Minute, at(22, 20, 0)},
+		{at(22, 13, 20), time.Hour, at(23, 0, 0)},
+		// 7 minutes does not divide the hour, the multiples are counted from the epoch
+		{at(22, 13, 20), 7 * time.Minute, time.Unix(4047620*420, 0).UTC()},
+		{time.Unix(-90, 0).UTC(), time.Minute, time.Unix(-60, 0).UTC()},
+	} {
+		if got := alignedAfter(tc.t, tc.d); !got.Equal(tc.want) {
+			t.Errorf("aligned after %v by %v: %v, want %v", tc.t, tc.d, got, tc.want)
+		}
+	}
+}
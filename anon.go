@@ -0,0 +1,37 @@
+package timewheel
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// last generated key, shared by the wheels so a task keeps a unique key when it moves
+var anonSeq uint64
+
+// key generated for the anonymous tasks, unexported so it never equals a key of the caller
+type anonKey uint64
+
+func (k anonKey) String() string {
+	return "anon-" + strconv.FormatUint(uint64(k), 10)
+}
+
+// AddAnonymousTask add new task under a generated key and return the key, which can be passed to RemoveTask.
+// The keys are unique in the process and print as anon-N.
+func (tw *TimeWheel) AddAnonymousTask(interval time.Duration, times int, data TaskData, job Job) (interface{}, error) {
+	key := anonKey(atomic.AddUint64(&anonSeq, 1))
+	if err := tw.AddTask(interval, times, key, data, job); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// AfterFunc run f once after d, the returned key can be passed to RemoveTask
+func (tw *TimeWheel) AfterFunc(d time.Duration, f func()) (interface{}, error) {
+	if f == nil {
+		return nil, ErrInvalidParams
+	}
+	return tw.AddAnonymousTask(d, 1, nil, func(TaskData) {
+		f()
+	})
+}
@@ -0,0 +1,48 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAnonymousTasks(t *testing.T) {
+	tw := New(10*time.Millisecond, 64, WithAddBuffer(1024))
+	tw.Start()
+	defer tw.Stop()
+	// a million tasks, 80k in short mode
+	perG := 125000
+	if testing.Short() {
+		perG = 10000
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errs := 0
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perG; i++ {
+				if _, err := tw.AddAnonymousTask(time.Hour, 1, nil, func(TaskData) {}); err != nil {
+					mu.Lock()
+					errs++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	settle(tw)
+	checkInvariants(t, tw)
+	if errs != 0 || tw.Len() != 8*perG {
+		t.Fatalf("%d errors, %d tasks", errs, tw.Len())
+	}
+	key, _ := tw.AfterFunc(time.Hour, func() {})
+	if s := fmt.Sprint(key); s[:5] != "anon-" {
+		t.Fatal(s)
+	}
+	if err := tw.RemoveTask(key); err != nil {
+		t.Fatal(err)
+	}
+}
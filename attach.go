@@ -0,0 +1,87 @@
+package timewheel
+
+import "sync/atomic"
+
+// a job attached to a task by AttachJob
+type attachedJob struct {
+	id  string
+	job Job
+}
+
+// AttachJob attach another job to the task, it runs on every run of the task next to the task job, on its
+// own goroutine or worker and with its own panic recovery. The times and the removal of the task stay
+// with the task, the attached jobs are dropped with it. Attaching again under the same id replaces the job.
+func (tw *TimeWheel) AttachJob(key interface{}, id string, job Job) error {
+	if job == nil || id == "" {
+		return ErrInvalidParams
+	}
+	return tw.attach(key, func(t *task) error {
+		jobs := make([]attachedJob, 0, len(t.attached)+1)
+		for _, a := range t.attached {
+			if a.id != id {
+				jobs = append(jobs, a)
+			}
+		}
+		t.attached = append(jobs, attachedJob{id: id, job: job})
+		return nil
+	})
+}
+
+// DetachJob detach the job attached under id, the task keeps running without it
+func (tw *TimeWheel) DetachJob(key interface{}, id string) error {
+	return tw.attach(key, func(t *task) error {
+		for i, a := range t.attached {
+			if a.id == id {
+				// a copy, the runs being dispatched may still range over the old list
+				jobs := append(t.attached[:i:i], t.attached[i+1:]...)
+				if len(jobs) == 0 {
+					jobs = nil
+				}
+				t.attached = jobs
+				return nil
+			}
+		}
+		return ErrJobNotAttached
+	})
+}
+
+// call fn with the task registered under the key on the wheel goroutine
+func (tw *TimeWheel) attach(key interface{}, fn func(t *task) error) error {
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	err := ErrTaskNotFound
+	if execErr := tw.exec(func() {
+		if t, ok := tw.taskRecord.Load(key); ok {
+			err = fn(t)
+		}
+	}); execErr != nil {
+		return execErr
+	}
+	return err
+}
+
+// dispatch a run of every job attached to the task, next to the run r of the task job
+func (tw *TimeWheel) fireAttached(t *task, r *jobRun) {
+	for i := range t.attached {
+		t.retain()
+		atomic.AddInt64(&tw.inflightNum, 1)
+		tw.dispatch(&jobRun{
+			task:  t,
+			data:  copyTaskData(t.taskData),
+			exec:  r.exec,
+			info:  r.info,
+			extra: &t.attached[i],
+		})
+	}
+}
+
+// run the attached job of the run, a panic is recovered
+func (tw *TimeWheel) runAttached(r *jobRun) {
+	defer func() {
+		if v := recover(); v != nil {
+			tw.logger.Printf("timewheel: attached job panic recovered, key: %v, id: %s, panic: %v", r.task.key, r.extra.id, v)
+		}
+	}()
+	r.extra.job(r.data)
+}
@@ -0,0 +1,52 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAttachJob(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 8, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	if err := tw.AttachJob("none", "a", func(TaskData) {}); err != ErrTaskNotFound {
+		t.Fatal(err)
+	}
+	var main, a, b int64
+	tw.AddTask(time.Second, 5, "k", nil, func(TaskData) { atomic.AddInt64(&main, 1) })
+	settle(tw)
+	if err := tw.AttachJob("k", "a", func(TaskData) { atomic.AddInt64(&a, 1) }); err != nil {
+		t.Fatal(err)
+	}
+	tw.AttachJob("k", "b", func(TaskData) { atomic.AddInt64(&b, 1); panic("x") })
+	step := func() {
+		c.Tick(time.Second)
+		settle(tw)
+		time.Sleep(10 * time.Millisecond)
+	}
+	step()
+	step()
+	step()
+	if atomic.LoadInt64(&main) != 2 || atomic.LoadInt64(&a) != 2 || atomic.LoadInt64(&b) != 2 {
+		t.Fatal(atomic.LoadInt64(&main), atomic.LoadInt64(&a), atomic.LoadInt64(&b))
+	}
+	if err := tw.DetachJob("k", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.DetachJob("k", "a"); err != ErrJobNotAttached {
+		t.Fatal(err)
+	}
+	tw.DetachJob("k", "b")
+	step()
+	if atomic.LoadInt64(&main) != 3 || atomic.LoadInt64(&a) != 2 || atomic.LoadInt64(&b) != 2 || !tw.HasTask("k") {
+		t.Fatal(atomic.LoadInt64(&main), atomic.LoadInt64(&a), atomic.LoadInt64(&b))
+	}
+	for i := 0; i < 3; i++ {
+		step()
+	}
+	if atomic.LoadInt64(&main) != 5 || tw.HasTask("k") {
+		t.Fatal(atomic.LoadInt64(&main))
+	}
+}
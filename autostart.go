@@ -0,0 +1,19 @@
+package timewheel
+
+import "sync/atomic"
+
+// WithAutoStart start the wheel on the first task added, AddTask and every other way to add a task
+// start it like Start. Start may still be called, the wheel is only started once.
+func WithAutoStart() Option {
+	return func(tw *TimeWheel) {
+		tw.autoStart = true
+	}
+}
+
+// start the wheel before the task is handed over if it is started on first use, see WithAutoStart
+func (tw *TimeWheel) startOnAdd() {
+	if tw.autoStart && atomic.LoadInt32(&tw.state) == stateNew {
+		// startWith lets a single caller start the wheel, whoever comes first
+		tw.Start()
+	}
+}
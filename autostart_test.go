@@ -0,0 +1,43 @@
+package timewheel
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAutoStart(t *testing.T) {
+	before := runtime.NumGoroutine()
+	tw := New(5*time.Millisecond, 16, WithAutoStart())
+	var fired int64
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%10 == 0 {
+				tw.Start()
+			}
+			if err := tw.AddTask(10*time.Millisecond, 1, i, nil, func(TaskData) { atomic.AddInt64(&fired, 1) }); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt64(&fired) < 100 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := atomic.LoadInt64(&fired); n != 100 {
+		t.Fatalf("fired %d", n)
+	}
+	checkInvariants(t, tw)
+	tw.Stop()
+	<-tw.loopDone
+	time.Sleep(20 * time.Millisecond)
+	if g := runtime.NumGoroutine(); g > before+2 {
+		t.Fatalf("goroutines %d before %d", g, before)
+	}
+}
@@ -0,0 +1,347 @@
+package timewheel
+
+import (
+	"sort"
+	"time"
+)
+
+// Backend the storage and ordering strategy of the scheduled tasks
+type Backend int
+
+const (
+	// Wheel slots scanned one per tick, the default
+	Wheel Backend = iota
+	// Heap min-heap ordered by due tick, saves memory when there are few tasks with diverse delays
+	Heap
+)
+
+// WithBackend set the backend storing the scheduled tasks, the precision is the tick interval for every backend
+func WithBackend(b Backend) Option {
+	return func(tw *TimeWheel) {
+		tw.backendKind = b
+	}
+}
+
+// storage of the scheduled tasks, only used by the wheel goroutine
+type backend interface {
+	// schedule the task to be due after ticks, 0 means the tick being processed
+	push(t *task, ticks int)
+	// unlink the task
+	remove(t *task)
+	// hand the tasks due at the current tick to due then move to the next tick. due is called
+	// at least once, again with the tasks it pushed for the current tick, and must not keep the batch
+	advance(due func(batch []*task))
+	// call fn for every scheduled task
+	each(fn func(t *task))
+	// number of scheduled tasks
+	len() int
+	// current position, the slot index for the wheel and the tick count for the heap
+	position() int
+	// number of ticks before the tick processing the task
+	ticksUntil(t *task) int
+	// drop every task, the backend is not used anymore
+	clear()
+	// abandon the scan in progress, the due tasks not handed over yet are dropped
+	abort()
+}
+
+func newBackend(kind Backend, slotNum int, newStore func() SlotStore) backend {
+	if kind == Heap {
+		return &heapBackend{}
+	}
+	if newStore == nil {
+		newStore = NewSliceSlotStore
+	}
+	b := &wheelBackend{}
+	b.makeSlots(slotNum, newStore)
+	return b
+}
+
+// replace the slots by slotNum empty ones
+func (b *wheelBackend) makeSlots(slotNum int, newStore func() SlotStore) {
+	b.slots = make([]SlotStore, slotNum)
+	for i := range b.slots {
+		b.slots[i] = newStore()
+	}
+	b.mask, b.shift = 0, 0
+	if slotNum&(slotNum-1) == 0 {
+		b.mask = slotNum - 1
+		for 1<<b.shift < slotNum {
+			b.shift++
+		}
+	}
+}
+
+// the slots of the backend, false for the heap
+func wheelOf(b backend) (*wheelBackend, bool) {
+	if p, ok := b.(*preciseBackend); ok {
+		b = p.backend
+	}
+	wb, ok := b.(*wheelBackend)
+	return wb, ok
+}
+
+// smallest power of two not less than n
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// slots scanned one per tick, a task further than a rotation waits circle rotations
+type wheelBackend struct {
+	slots      []SlotStore
+	currentPos int
+	due        []*task // due tasks of the slot being scanned
+	scanning   bool    // the due tasks of the current slot are handed over
+	rescan     []*task // tasks pushed to the current slot while scanning
+	mask       int     // slot count - 1 when it is a power of two, 0 otherwise
+	shift      uint    // log2 of the slot count when it is a power of two
+
+	// soft slot capacity, see WithSlotCapacity
+	slotCap        int
+	maxShift       int
+	interval       time.Duration
+	displacedNum   int64 // accessed atomically
+	displacedTicks int64 // accessed atomically
+
+	// slots left by Resize while their tasks are moved, see migrate
+	gen        int // generation of slots, the tasks of an older one are in moving
+	moving     []SlotStore
+	movingPos  int
+	movingNext int // first slot of moving not emptied yet
+
+	onStay func(t *task) // see WithTaskTrace
+	onFull func(t *task) // no slot within maxShift has room, see Errors
+	stable bool          // equal priorities are handed over in the order the tasks were created, see WithStableOrder
+}
+
+func (b *wheelBackend) push(t *task, ticks int) {
+	shift, ok := b.displace(ticks)
+	if !ok && b.onFull != nil {
+		b.onFull(t)
+	}
+	t.displaced = time.Duration(shift) * b.interval
+	pos, circle := b.getPositionAndCircle(ticks + shift)
+	t.circle = circle
+	t.slot = pos
+	t.slotGen = b.gen
+	t.entry.task = t
+	if b.scanning && pos == b.currentPos {
+		b.rescan = append(b.rescan, t)
+		return
+	}
+	b.slots[pos].Push(&t.entry)
+}
+
+func (b *wheelBackend) remove(t *task) {
+	if b.storeOf(t).Remove(&t.entry) || !b.scanning {
+		return
+	}
+	for i, v := range b.rescan {
+		if v == t {
+			copy(b.rescan[i:], b.rescan[i+1:])
+			b.rescan[len(b.rescan)-1] = nil
+			b.rescan = b.rescan[:len(b.rescan)-1]
+			return
+		}
+	}
+}
+
+func (b *wheelBackend) advance(due func(batch []*task)) {
+	if b.moving != nil {
+		// the due tasks of the old slots join the first batch
+		b.moving[b.movingPos].Scan(func(e *SlotEntry) bool {
+			if b.stays(e.task) {
+				return true
+			}
+			b.due = append(b.due, e.task)
+			return false
+		})
+		b.movingPos = (b.movingPos + 1) % len(b.moving)
+	}
+	b.scanAddRunTask(b.slots[b.currentPos], due)
+	if b.currentPos == len(b.slots)-1 {
+		b.currentPos = 0
+	} else {
+		b.currentPos++
+	}
+}
+
+func (b *wheelBackend) each(fn func(t *task)) {
+	for _, slots := range [][]SlotStore{b.moving, b.slots} {
+		for _, s := range slots {
+			s.Each(func(e *SlotEntry) {
+				fn(e.task)
+			})
+		}
+	}
+}
+
+func (b *wheelBackend) len() int {
+	n := 0
+	for _, slots := range [][]SlotStore{b.moving, b.slots} {
+		for _, s := range slots {
+			n += s.Len()
+		}
+	}
+	return n
+}
+
+func (b *wheelBackend) position() int {
+	return b.currentPos
+}
+
+func (b *wheelBackend) ticksUntil(t *task) int {
+	if b.isMoving(t) {
+		n := len(b.moving)
+		return (t.slot-b.movingPos+n)%n + t.circle*n
+	}
+	n := len(b.slots)
+	return (t.slot-b.currentPos+n)%n + t.circle*n
+}
+
+func (b *wheelBackend) abort() {
+	for i := range b.due {
+		b.due[i] = nil
+	}
+	for i := range b.rescan {
+		b.rescan[i] = nil
+	}
+	b.due, b.rescan = b.due[:0], b.rescan[:0]
+	b.scanning = false
+}
+
+func (b *wheelBackend) clear() {
+	b.slots, b.due, b.rescan, b.moving = nil, nil, nil, nil
+}
+
+// report whether the task is in a slot left by Resize
+func (b *wheelBackend) isMoving(t *task) bool {
+	return b.moving != nil && t.slotGen != b.gen
+}
+
+// slot holding the task
+func (b *wheelBackend) storeOf(t *task) SlotStore {
+	if b.isMoving(t) {
+		return b.moving[t.slot]
+	}
+	return b.slots[t.slot]
+}
+
+// scan the slot and hand over the due tasks by priority, the tasks of the next rotations stay in the slot
+func (b *wheelBackend) scanAddRunTask(s SlotStore, due func(batch []*task)) {
+	s.Scan(func(e *SlotEntry) bool {
+		if b.stays(e.task) {
+			return true
+		}
+		b.due = append(b.due, e.task)
+		return false
+	})
+	// tasks pushed to this slot while the due tasks are handed over are scanned as well
+	b.scanning = true
+	for first := true; first || len(b.due) > 0; first = false {
+		batch := b.due
+		if b.stable {
+			sortByAge(batch)
+		} else {
+			sortByPriority(batch)
+		}
+		due(batch)
+		for j := range batch {
+			batch[j] = nil
+		}
+		b.due = batch[:0]
+
+		for j, task := range b.rescan {
+			b.rescan[j] = nil
+			if b.stays(task) {
+				s.Push(&task.entry)
+			} else {
+				b.due = append(b.due, task)
+			}
+		}
+		b.rescan = b.rescan[:0]
+	}
+	b.scanning = false
+}
+
+// count down a rotation of the task, report whether it stays in the slot
+func (b *wheelBackend) stays(t *task) bool {
+	if t.circle > 0 && t.times != 0 {
+		t.circle--
+		if b.onStay != nil {
+			b.onStay(t)
+		}
+		return true
+	}
+	return false
+}
+
+// order the tasks by priority, highest first, keeping the order of equal priorities
+func sortByPriority(tasks []*task) {
+	if len(tasks) < 2 {
+		return
+	}
+	mixed := false
+	for _, t := range tasks[1:] {
+		if t.priority != tasks[0].priority {
+			mixed = true
+			break
+		}
+	}
+	if mixed {
+		sort.SliceStable(tasks, func(i, j int) bool {
+			return tasks[i].priority > tasks[j].priority
+		})
+	}
+}
+
+// order the tasks by priority, highest first, then by generation, oldest first
+func sortByAge(tasks []*task) {
+	if len(tasks) < 2 {
+		return
+	}
+	less := func(a, b *task) bool {
+		if a.priority != b.priority {
+			return a.priority > b.priority
+		}
+		return a.gen < b.gen
+	}
+	if sort.SliceIsSorted(tasks, func(i, j int) bool { return less(tasks[i], tasks[j]) }) {
+		return
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return less(tasks[i], tasks[j])
+	})
+}
+
+// hand the tasks of the batch one by one to due
+func eachDue(due func(t *task)) func(batch []*task) {
+	return func(batch []*task) {
+		for _, t := range batch {
+			due(t)
+		}
+	}
+}
+
+// get the task position
+func (b *wheelBackend) getPositionAndCircle(ticks int) (pos int, circle int) {
+	if ticks < 0 {
+		ticks = 0
+	}
+	// reduce ticks first so a huge count can not overflow the sum
+	if b.mask != 0 {
+		return (b.currentPos + ticks&b.mask) & b.mask, ticks >> b.shift
+	}
+	// a single division, the remainder and the sum stay below two rotations
+	slotNum := len(b.slots)
+	circle = ticks / slotNum
+	pos = b.currentPos + ticks - circle*slotNum
+	if pos >= slotNum {
+		pos -= slotNum
+	}
+	return
+}
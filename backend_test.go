@@ -0,0 +1,347 @@
+package timewheel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var backends = []struct {
+	name    string
+	backend Backend
+}{
+	{"wheel", Wheel},
+	{"heap", Heap},
+}
+
+// the same scenarios against every backend
+func TestBackendConformance(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			t.Run("basic", func(t *testing.T) { conformBasic(t, b.backend) })
+			t.Run("update", func(t *testing.T) { conformUpdate(t, b.backend) })
+			t.Run("order", func(t *testing.T) { conformOrder(t, b.backend) })
+		})
+	}
+}
+
+func conformBasic(t *testing.T, b Backend) {
+	clk := newFakeClock()
+	tw := New(time.Second, 8, WithClock(clk), WithBackend(b))
+	tw.Start()
+	defer tw.Stop()
+	var n, m int64
+	if err := tw.AddTask(time.Second, 3, "a", nil, func(TaskData) { atomic.AddInt64(&n, 1) }); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.AddTask(2*time.Second, -1, "b", nil, func(TaskData) { atomic.AddInt64(&m, 1) }); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 30; i++ {
+		clk.Tick(time.Second)
+	}
+	settle(tw)
+	if atomic.LoadInt64(&n) != 3 {
+		t.Fatalf("n=%d", n)
+	}
+	if tw.HasTask("a") {
+		t.Fatal("a still registered")
+	}
+	if err := tw.RemoveTask("b"); err != nil {
+		t.Fatal(err)
+	}
+	c := atomic.LoadInt64(&m)
+	if c < 14 || c > 15 {
+		t.Fatalf("m=%d", c)
+	}
+	for i := 0; i < 10; i++ {
+		clk.Tick(time.Second)
+	}
+	settle(tw)
+	if atomic.LoadInt64(&m) != c {
+		t.Fatal("b still firing")
+	}
+	// an interval of a whole rotation
+	var k int64
+	tw.AddTask(8*time.Second, 2, "c", nil, func(TaskData) { atomic.AddInt64(&k, 1) })
+	settle(tw)
+	for i := 0; i < 17; i++ {
+		clk.Tick(time.Second)
+	}
+	settle(tw)
+	if atomic.LoadInt64(&k) != 2 {
+		t.Fatalf("k=%d", k)
+	}
+}
+
+func conformUpdate(t *testing.T, b Backend) {
+	clk := newFakeClock()
+	tw := New(time.Second, 8, WithClock(clk), WithBackend(b))
+	tw.Start()
+	defer tw.Stop()
+	got := make(chan interface{}, 10)
+	tw.AddTask(time.Second, -1, "a", TaskData{"v": 1}, func(data TaskData) { got <- data["v"] })
+	clk.Tick(time.Second)
+	clk.Tick(time.Second)
+	if v := <-got; v != 1 {
+		t.Fatal(v)
+	}
+	// the run already scheduled keeps its time, the new interval applies from it
+	if err := tw.UpdateTask("a", 3*time.Second, TaskData{"v": 2}); err != nil {
+		t.Fatal(err)
+	}
+	clk.Tick(time.Second)
+	if v := <-got; v != 2 {
+		t.Fatal(v)
+	}
+	clk.Tick(time.Second)
+	clk.Tick(time.Second)
+	settle(tw)
+	if len(got) != 0 {
+		t.Fatal("ran before the new interval")
+	}
+	clk.Tick(time.Second)
+	if v := <-got; v != 2 {
+		t.Fatal(v)
+	}
+}
+
+func conformOrder(t *testing.T, b Backend) {
+	clk := newFakeClock()
+	tw := New(time.Second, 8, WithClock(clk), WithBackend(b), WithWorkers(1, 100, Block))
+	tw.Start()
+	defer tw.Stop()
+	got := make(chan int, 100)
+	for i := 10; i > 0; i-- {
+		i := i
+		tw.AddTask(time.Duration(i)*time.Second, 1, i, nil, func(TaskData) { got <- i })
+	}
+	for i := 0; i < 12; i++ {
+		clk.Tick(time.Second)
+	}
+	settle(tw)
+	for want := 1; want <= 10; want++ {
+		if v := <-got; v != want {
+			t.Fatal(v, want)
+		}
+	}
+}
+
+var backendSizes = []int{1000, 100000, 1000000}
+
+// add n tasks of diverse delays
+func BenchmarkBackendInsert(b *testing.B) {
+	for _, be := range backends {
+		for _, n := range backendSizes {
+			b.Run(fmt.Sprintf("%s/%d", be.name, n), func(b *testing.B) {
+				job := func(TaskData) {}
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					tw := New(time.Second, 512, WithClock(newFakeClock()), WithBackend(be.backend))
+					tw.Start()
+					for k := 0; k < n; k++ {
+						tw.AddTask(time.Duration(1+k%86400)*time.Second, 1, k, nil, job)
+					}
+					tw.exec(func() {})
+					b.StopTimer()
+					tw.Stop()
+					b.StartTimer()
+				}
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/task")
+			})
+		}
+	}
+}
+
+// remove n tasks of diverse delays
+func BenchmarkBackendCancel(b *testing.B) {
+	for _, be := range backends {
+		for _, n := range backendSizes {
+			b.Run(fmt.Sprintf("%s/%d", be.name, n), func(b *testing.B) {
+				job := func(TaskData) {}
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					tw := New(time.Second, 512, WithClock(newFakeClock()), WithBackend(be.backend))
+					tw.Start()
+					for k := 0; k < n; k++ {
+						tw.AddTask(time.Duration(1+k%86400)*time.Second, 1, k, nil, job)
+					}
+					tw.exec(func() {})
+					b.StartTimer()
+					for k := 0; k < n; k++ {
+						tw.RemoveTask(k)
+					}
+					b.StopTimer()
+					tw.Stop()
+					b.StartTimer()
+				}
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/task")
+			})
+		}
+	}
+}
+
+// fire n tasks due on the same tick, the jobs run on a worker per cpu
+func BenchmarkBackendFire(b *testing.B) {
+	for _, be := range backends {
+		for _, n := range backendSizes {
+			b.Run(fmt.Sprintf("%s/%d", be.name, n), func(b *testing.B) {
+				var ran int64
+				job := func(TaskData) { atomic.AddInt64(&ran, 1) }
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					clk := newFakeClock()
+					tw := New(time.Second, 512, WithClock(clk), WithBackend(be.backend), WithWorkers(runtime.NumCPU(), 1024, Block))
+					tw.Start()
+					atomic.StoreInt64(&ran, 0)
+					for k := 0; k < n; k++ {
+						tw.AddTask(time.Second, 1, k, nil, job)
+					}
+					tw.exec(func() {})
+					b.StartTimer()
+					clk.Tick(time.Second)
+					clk.Tick(time.Second)
+					for atomic.LoadInt64(&ran) < int64(n) {
+						runtime.Gosched()
+					}
+					b.StopTimer()
+					tw.Stop()
+					b.StartTimer()
+				}
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*n), "ns/task")
+			})
+		}
+	}
+}
+
+// the position formula of the float version, for whole second intervals
+func floatPositionAndCircle(d, interval time.Duration, currentPos, slotNum int) (int, int) {
+	delaySeconds := int(d.Seconds())
+	intervalSeconds := int(interval.Seconds())
+	return (currentPos + delaySeconds/intervalSeconds) % slotNum, delaySeconds / intervalSeconds / slotNum
+}
+
+func TestPositionAndCircle(t *testing.T) {
+	for _, slotNum := range []int{7, 8, 60, 64} {
+		for _, interval := range []time.Duration{time.Second, 3 * time.Second} {
+			tw := New(interval, slotNum)
+			b, _ := wheelOf(tw.backend)
+			for cur := 0; cur < slotNum; cur += 3 {
+				b.currentPos = cur
+				for d := time.Duration(0); d < time.Duration(3*slotNum)*interval; d += time.Second {
+					pos, circle := b.getPositionAndCircle(tw.delayTicks(d))
+					wantPos, wantCircle := floatPositionAndCircle(d, interval, cur, slotNum)
+					if pos != wantPos || circle != wantCircle {
+						t.Fatalf("slots %d interval %v pos %d delay %v: got %d/%d, want %d/%d",
+							slotNum, interval, cur, d, pos, circle, wantPos, wantCircle)
+					}
+				}
+			}
+		}
+	}
+	// an interval not dividing the delay rounds down
+	tw := New(300*time.Millisecond, 10)
+	b, _ := wheelOf(tw.backend)
+	if pos, circle := b.getPositionAndCircle(tw.delayTicks(3500 * time.Millisecond)); pos != 1 || circle != 1 {
+		t.Fatalf("got %d/%d, want 1/1", pos, circle)
+	}
+	tw = New(time.Second, 60, WithPowerOfTwoSlots())
+	if b, _ := wheelOf(tw.backend); len(b.slots) != 64 || b.mask != 63 {
+		t.Fatalf("%d slots, want 64", len(b.slots))
+	}
+}
+
+func BenchmarkPositionAndCircle(b *testing.B) {
+	for _, slotNum := range []int{60, 64} {
+		tw := New(time.Second, slotNum)
+		wb, _ := wheelOf(tw.backend)
+		b.Run(fmt.Sprint("float/", slotNum), func(b *testing.B) {
+			n := 0
+			for i := 0; i < b.N; i++ {
+				pos, circle := floatPositionAndCircle(time.Duration(i)*time.Second, time.Second, 5, slotNum)
+				n += pos + circle
+			}
+			runtime.KeepAlive(n)
+		})
+		b.Run(fmt.Sprint("int/", slotNum), func(b *testing.B) {
+			n := 0
+			wb.currentPos = 5
+			for i := 0; i < b.N; i++ {
+				pos, circle := wb.getPositionAndCircle(tw.delayTicks(time.Duration(i) * time.Second))
+				n += pos + circle
+			}
+			runtime.KeepAlive(n)
+		})
+	}
+}
+
+// a tick of a wheel holding 500k recurring tasks, each tick re-inserts the due ones
+func BenchmarkRecurringTick(b *testing.B) {
+	const tasks = 500000
+	for _, slotNum := range []int{500, 512} {
+		b.Run(fmt.Sprint(slotNum), func(b *testing.B) {
+			clk := newFakeClock()
+			tw := New(time.Second, slotNum, WithClock(clk), WithWorkers(runtime.NumCPU(), 4096, DropNewest))
+			tw.Start()
+			defer tw.Stop()
+			job := func(TaskData) {}
+			for k := 0; k < tasks; k++ {
+				tw.AddTask(time.Duration(1+k%1000)*time.Second, -1, k, nil, job)
+			}
+			tw.exec(func() {})
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				clk.Tick(time.Second)
+			}
+			tw.exec(func() {})
+		})
+	}
+}
+
+func TestDelayTooLarge(t *testing.T) {
+	// the circle of a delay above 8ms*MaxInt32 would not fit an int32
+	tw := New(time.Millisecond, 8)
+	tw.Start()
+	defer tw.Stop()
+	job := func(TaskData) {}
+	for _, d := range []time.Duration{tw.maxDelay() + time.Second, math.MaxInt64} {
+		if err := tw.AddTask(d, 1, d, nil, job); !errors.Is(err, ErrDelayTooLarge) {
+			t.Fatal(d, err)
+		}
+	}
+	if err := tw.AddTask(tw.maxDelay(), 1, "max", nil, job); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func FuzzPositionAndCircle(f *testing.F) {
+	f.Add(int64(time.Second), uint16(60), uint16(0), int64(math.MaxInt64))
+	f.Add(int64(1), uint16(64), uint16(63), int64(-5))
+	f.Add(int64(time.Millisecond), uint16(7), uint16(3), int64(365*24*time.Hour))
+	f.Fuzz(func(t *testing.T, interval int64, slots, cur uint16, d int64) {
+		if interval <= 0 || slots == 0 {
+			return
+		}
+		tw := New(time.Duration(interval), int(slots))
+		b, _ := wheelOf(tw.backend)
+		b.currentPos = int(cur) % len(b.slots)
+		pos, circle := b.getPositionAndCircle(tw.delayTicks(time.Duration(d)))
+		if pos < 0 || pos >= len(b.slots) || circle < 0 {
+			t.Fatal(pos, circle)
+		}
+		if time.Duration(d) <= tw.maxDelay() && circle > math.MaxInt32 {
+			t.Fatal("circle", circle)
+		}
+		_, err := tw.newTask(time.Duration(d), 1, "k", nil, func(context.Context, TaskData) {})
+		if time.Duration(d) > tw.maxDelay() && !errors.Is(err, ErrDelayTooLarge) {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// stretching of the interval of a failing task
+type backoff struct {
+	factor float64
+	max    time.Duration
+}
+
+// Backoff stretch the interval of the task by factor after every failed run, up to max, the first
+// successful run restores the interval. The stretched interval is reported by TaskInfo.Effective,
+// only the runs count towards times. A run fails if its job panics or, for the jobs
+// added with AddTaskErr, returns an error. Scheduled tasks are not stretched.
+func Backoff(factor float64, max time.Duration) TaskOption {
+	return func(t *task) {
+		if factor > 1 && max > 0 {
+			t.backoff = &backoff{factor: factor, max: max}
+		}
+	}
+}
+
+// interval in effect, stretched while the task fails or is shed
+func (t *task) effective() time.Duration {
+	if d := atomic.LoadInt64(&t.shed); d > 0 {
+		return time.Duration(d)
+	}
+	return t.unshed()
+}
+
+// interval in effect without the load shedding
+func (t *task) unshed() time.Duration {
+	if d := atomic.LoadInt64(&t.stretched); d > 0 {
+		return time.Duration(d)
+	}
+	return t.interval
+}
+
+// stretch or restore the interval with the outcome of a run, called on the job goroutine
+func (tw *TimeWheel) backoffDone(r *jobRun, err error) {
+	task := r.task
+	if task.backoff == nil || task.schedule != nil || (err == nil && atomic.LoadInt64(&task.stretched) == 0) {
+		return
+	}
+	apply := func() {
+		// the task may be removed, exhausted or run again meanwhile
+		if t, ok := tw.taskRecord.Load(task.key); !ok || t != task || task.times == 0 || task.isHeld() ||
+			atomic.LoadInt64(&task.stats.lastFire) != r.exec.Fired.UnixNano() {
+			return
+		}
+		old, d := task.effective(), time.Duration(0)
+		if err != nil {
+			d = time.Duration(float64(task.unshed()) * task.backoff.factor)
+			if d > task.backoff.max {
+				d = task.backoff.max
+			}
+			if d <= task.interval {
+				d = 0
+			}
+		}
+		atomic.StoreInt64(&task.stretched, int64(d))
+		diff := task.effective() - old
+		if diff == 0 {
+			return
+		}
+		// move the pending run by the change of the interval
+		task.next = task.next.Add(diff)
+		ticks := tw.delayTicks(diff)
+		if !tw.undefer(task) {
+			ticks = tw.backend.ticksUntil(task) + tw.delayTicks(task.effective()) - tw.delayTicks(old)
+			tw.backend.remove(task)
+		}
+		if ticks < 0 {
+			ticks = 0
+		}
+		tw.backend.push(task, ticks)
+	}
+	tw.execLater(task, apply)
+}
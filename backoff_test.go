@@ -0,0 +1,87 @@
+package timewheel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 16, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var mu sync.Mutex
+	var at []time.Time
+	err := tw.AddTaskErr(time.Second, 6, "k", nil, func(ctx context.Context, d TaskData) error {
+		mu.Lock()
+		defer mu.Unlock()
+		at = append(at, c.Now())
+		if len(at) <= 3 {
+			return errors.New("down")
+		}
+		return nil
+	}, Backoff(2, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	settle(tw)
+	var eff []time.Duration
+	for i := 0; i < 24; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+		for _, info := range tw.Upcoming(time.Hour, 0) {
+			if len(eff) == 0 || eff[len(eff)-1] != info.Effective {
+				eff = append(eff, info.Effective)
+			}
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	var gaps []time.Duration
+	for i := 1; i < len(at); i++ {
+		gaps = append(gaps, at[i].Sub(at[i-1]))
+	}
+	if got := fmt.Sprint(gaps); got != "[2s 4s 8s 1s 1s]" {
+		t.Fatal(got)
+	}
+	if got := fmt.Sprint(eff); got != "[1s 2s 4s 8s 1s]" {
+		t.Fatal(got)
+	}
+	if tw.Len() != 0 {
+		t.Fatal(tw.Len())
+	}
+}
+
+func TestBackoffBlockingWorkers(t *testing.T) {
+	tw := New(10*time.Millisecond, 16, WithWorkers(1, 0, Block))
+	tw.Start()
+	defer tw.Stop()
+	var runs int64
+	for i := 0; i < 4; i++ {
+		tw.AddTaskErr(10*time.Millisecond, -1, i, nil, func(ctx context.Context, d TaskData) error {
+			atomic.AddInt64(&runs, 1)
+			return errors.New("down")
+		}, Backoff(2, 40*time.Millisecond))
+	}
+	time.Sleep(300 * time.Millisecond)
+	if n := atomic.LoadInt64(&runs); n < 8 {
+		t.Fatal(n)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- tw.AddTask(time.Second, 1, "late", nil, func(TaskData) {})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("wheel blocked")
+	}
+}
@@ -0,0 +1,122 @@
+package timewheel
+
+import (
+	"context"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// TaskEntry a due run handed to a BatchJob
+type TaskEntry struct {
+	Key       interface{}
+	Data      TaskData
+	Scheduled time.Time // ideal time of the run
+}
+
+// BatchJob callback receiving the runs of its tasks due at the same tick
+type BatchJob func(entries []TaskEntry)
+
+// BatchHandler a BatchJob shared by the tasks added with AddBatchTask
+type BatchHandler struct {
+	job   BatchJob
+	runs  []*jobRun // due during the tick being handled, only used by the wheel goroutine
+	calls int64     // accessed atomically
+}
+
+// NewBatchHandler create a handler calling job once per tick with the due runs of its tasks
+func NewBatchHandler(job BatchJob) *BatchHandler {
+	return &BatchHandler{job: job}
+}
+
+// Calls get the number of times the job of the handler was called
+func (h *BatchHandler) Calls() int64 {
+	return atomic.LoadInt64(&h.calls)
+}
+
+// AddBatchTask add new task like AddTaskWith whose runs are handed to h. The runs of the tasks of h due
+// at the same tick are handed over in a single call dispatched like a run, through the worker pool and the
+// interceptor. Every run is accounted on its own: times, stats, hooks and events. A run dispatched out of
+// a tick, by RunNow for example, is handed over alone. A panic of the job fails every run of the call.
+func (tw *TimeWheel) AddBatchTask(interval time.Duration, times int, key interface{}, data TaskData, h *BatchHandler, opts ...TaskOption) error {
+	if h == nil || h.job == nil {
+		return ErrInvalidParams
+	}
+	opts = append(opts[:len(opts):len(opts)], func(t *task) {
+		t.batch = h
+	})
+	return tw.addTaskWith(interval, times, key, data, batchedJob, opts)
+}
+
+// job of a batched task, the handler ran it already
+func batchedJob(context.Context, TaskData) {}
+
+// hold the run until the end of the tick if its task is batched, report whether it is held
+func (tw *TimeWheel) holdForBatch(r *jobRun) bool {
+	h := r.task.batch
+	if h == nil || !tw.batching {
+		return false
+	}
+	if len(h.runs) == 0 {
+		tw.batches = append(tw.batches, h)
+	}
+	h.runs = append(h.runs, r)
+	return true
+}
+
+// dispatch a run per handler with the runs held during the tick
+func (tw *TimeWheel) flushBatches() {
+	for i, h := range tw.batches {
+		tw.batches[i] = nil
+		lead := h.runs[0]
+		lead.batch = append([]*jobRun(nil), h.runs[1:]...)
+		for j := range h.runs {
+			h.runs[j] = nil
+		}
+		h.runs = h.runs[:0]
+		for _, r := range lead.batch {
+			tw.activeRuns.track(r)
+		}
+		tw.dispatch(lead)
+	}
+	tw.batches = tw.batches[:0]
+}
+
+// call the handler of the run with the runs of its batch then account the runs following it,
+// the run itself is accounted by runJob
+func (tw *TimeWheel) runBatched(r *jobRun) {
+	runs := append([]*jobRun{r}, r.batch...)
+	entries := make([]TaskEntry, 0, len(runs))
+	for _, v := range runs {
+		v.inBatch = true
+		// the worker pool dropped the whole batch
+		v.dropped = v.dropped || r.dropped
+		if !v.dropped && v.ctx.Err() == nil {
+			entries = append(entries, TaskEntry{Key: v.task.key, Data: v.data, Scheduled: v.exec.Scheduled})
+		}
+	}
+	var err error
+	if len(entries) > 0 {
+		err = tw.callBatch(r.task.batch, entries)
+	}
+	for _, v := range runs {
+		v.batchErr = err
+	}
+	for _, v := range r.batch {
+		tw.runJob(v)
+	}
+	r.batch = nil
+}
+
+// call the job of the handler, the panic is recovered and returned
+func (tw *TimeWheel) callBatch(h *BatchHandler, entries []TaskEntry) (err error) {
+	atomic.AddInt64(&h.calls, 1)
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Key: entries[0].Key, Value: v, Stack: debug.Stack()}
+			tw.logger.Printf("timewheel: batch job panic recovered, entries: %d, first key: %v, panic: %v", len(entries), entries[0].Key, v)
+		}
+	}()
+	h.job(entries)
+	return nil
+}
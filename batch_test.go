@@ -0,0 +1,134 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatchTask(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 16, WithClock(c), WithAddBuffer(2048))
+	tw.Start()
+	defer tw.Stop()
+	var mu sync.Mutex
+	var calls [][]TaskEntry
+	h := NewBatchHandler(func(entries []TaskEntry) {
+		mu.Lock()
+		calls = append(calls, entries)
+		mu.Unlock()
+	})
+	for i := 0; i < 1000; i++ {
+		if err := tw.AddBatchTask(time.Second, 2, i, TaskData{"i": i}, h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	settle(tw)
+	for i := 0; i < 4; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+		time.Sleep(20 * time.Millisecond)
+	}
+	mu.Lock()
+	if len(calls) != 2 || h.Calls() != 2 {
+		t.Fatal(len(calls))
+	}
+	for _, entries := range calls {
+		seen := map[interface{}]bool{}
+		for _, e := range entries {
+			if e.Data["i"] != e.Key || e.Scheduled.IsZero() {
+				t.Fatal(e)
+			}
+			seen[e.Key] = true
+		}
+		if len(seen) != 1000 {
+			t.Fatal(len(seen))
+		}
+	}
+	mu.Unlock()
+	if tw.Len() != 0 || tw.Stats().InFlight != 0 {
+		t.Fatal(tw.Len(), tw.Stats().InFlight)
+	}
+
+	// a panic fails every run
+	p := NewBatchHandler(func([]TaskEntry) { panic("x") })
+	tw.AddBatchTask(time.Second, -1, "p1", nil, p)
+	tw.AddBatchTask(time.Second, -1, "p2", nil, p)
+	settle(tw)
+	c.Tick(time.Second)
+	settle(tw)
+	c.Tick(time.Second)
+	settle(tw)
+	time.Sleep(20 * time.Millisecond)
+	for _, k := range []string{"p1", "p2"} {
+		s, _ := tw.TaskStats(k)
+		if s.Failures == 0 {
+			t.Fatal(k, s)
+		}
+	}
+	if p.Calls() != 1 {
+		t.Fatal(p.Calls())
+	}
+}
+
+func TestBatchTaskMoved(t *testing.T) {
+	c := newFakeClock()
+	src := New(time.Second, 16, WithClock(c))
+	dst := New(time.Second, 16, WithClock(c))
+	src.Start()
+	dst.Start()
+	defer dst.Stop()
+	var mu sync.Mutex
+	var sizes []int
+	h := NewBatchHandler(func(entries []TaskEntry) {
+		mu.Lock()
+		sizes = append(sizes, len(entries))
+		mu.Unlock()
+	})
+	for i := 0; i < 10; i++ {
+		if err := src.AddBatchTask(time.Second, 1, i, nil, h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		if err := src.MoveTask(i, dst); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the ticks of the shared clock go to dst
+	src.Stop()
+	settle(dst)
+	c.Tick(time.Second)
+	settle(dst)
+	c.Tick(time.Second)
+	settle(dst)
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sizes) != 1 || sizes[0] != 10 {
+		t.Fatal(sizes)
+	}
+}
+
+func TestBatchTaskPool(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 16, WithClock(c), WithWorkers(1, 0, DropNewest))
+	tw.Start()
+	defer tw.Stop()
+	block := make(chan struct{})
+	tw.AddTask(time.Second, 1, "blocker", nil, func(TaskData) { <-block })
+	h := NewBatchHandler(func([]TaskEntry) {})
+	for i := 0; i < 10; i++ {
+		tw.AddBatchTask(time.Second, 1, i, nil, h)
+	}
+	settle(tw)
+	c.Tick(time.Second)
+	settle(tw)
+	c.Tick(time.Second)
+	settle(tw)
+	close(block)
+	time.Sleep(20 * time.Millisecond)
+	if tw.Stats().InFlight != 0 || tw.Len() != 0 {
+		t.Fatal(tw.Stats().InFlight, tw.Len(), h.Calls())
+	}
+}
@@ -0,0 +1,96 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Blackout daily window during which no task fires
+type Blackout struct {
+	Start    time.Duration  // start of the window as an offset from midnight
+	Duration time.Duration  // length of the window, it may run past midnight
+	Location *time.Location // time zone of Start, nil means time.Local
+}
+
+// report whether t falls in the window
+func (b Blackout) contains(t time.Time) bool {
+	loc := b.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc)
+	y, m, d := t.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	// the window of the day before may run past midnight
+	for _, day := range []time.Time{midnight, midnight.AddDate(0, 0, -1)} {
+		start := day.Add(b.Start)
+		if !t.Before(start) && t.Before(start.Add(b.Duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+// BlackoutPolicy decide what happens to the tasks due during a blackout window
+type BlackoutPolicy int
+
+const (
+	// BlackoutDefer run the due tasks at the first tick after the window in the order they became due
+	BlackoutDefer BlackoutPolicy = iota
+	// BlackoutSkip drop the runs due during the window, they still count towards times
+	BlackoutSkip
+)
+
+// WithBlackout set the daily windows during which no task fires, policy decides whether the due tasks
+// wait for the end of the window or are skipped. The windows are checked against the wall clock time
+// of every tick, the ticks replayed by the catch up included.
+func WithBlackout(policy BlackoutPolicy, windows ...Blackout) Option {
+	return func(tw *TimeWheel) {
+		for _, w := range windows {
+			if w.Duration > 0 {
+				tw.blackouts = append(tw.blackouts, w)
+			}
+		}
+		tw.blackoutPolicy = policy
+	}
+}
+
+// BlackedOut get the number of due tasks waiting for the end of a blackout window
+func (tw *TimeWheel) BlackedOut() int {
+	return int(atomic.LoadInt64(&tw.blackedOutNum))
+}
+
+// update the blackout state for the tick, the tasks deferred by a window run once it ended
+func (tw *TimeWheel) checkBlackout(at time.Time) {
+	tw.inBlackout = false
+	for _, w := range tw.blackouts {
+		if w.contains(at) {
+			tw.inBlackout = true
+			return
+		}
+	}
+	for len(tw.blackedOut) > 0 {
+		task := tw.blackedOut[0]
+		tw.blackedOut[0] = nil
+		tw.blackedOut = tw.blackedOut[1:]
+		atomic.AddInt64(&tw.blackedOutNum, -1)
+		// removed while deferred
+		if task.times != 0 {
+			tw.runDueTask(task)
+		}
+		task.release()
+	}
+}
+
+// hold the due task until the window ends, report whether it is held
+func (tw *TimeWheel) holdForBlackout(task *task) bool {
+	if !tw.inBlackout || tw.blackoutPolicy != BlackoutDefer {
+		return false
+	}
+	// the queue holds its own reference, the task may be removed while it waits
+	task.retain()
+	tw.blackedOut = append(tw.blackedOut, task)
+	atomic.AddInt64(&tw.blackedOutNum, 1)
+	tw.trace(task, TraceBlackedOut)
+	return true
+}
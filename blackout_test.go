@@ -0,0 +1,60 @@
+package timewheel
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBlackout(t *testing.T) {
+	for _, tc := range []struct {
+		policy BlackoutPolicy
+		held   int
+		want   []string
+	}{
+		{BlackoutDefer, 2, []string{"before@23", "in1@28", "in2@28", "after@29"}},
+		{BlackoutSkip, 0, []string{"before@23", "after@29"}},
+	} {
+		// the fake clock starts at 22:13:20 UTC, the window covers 22:13:25 to 22:13:28
+		c := newFakeClock()
+		w := Blackout{Start: 22*time.Hour + 13*time.Minute + 25*time.Second, Duration: 3 * time.Second, Location: time.UTC}
+		// a single worker runs the jobs in the order they were dispatched
+		tw := New(time.Second, 10, WithClock(c), WithBlackout(tc.policy, w), WithWorkers(1, 64, Block))
+		tw.Start()
+		var mu sync.Mutex
+		var log []string
+		var runs int64
+		add := func(k string, d time.Duration) {
+			tw.AddTask(d, 1, k, nil, func(TaskData) {
+				mu.Lock()
+				log = append(log, k+"@"+c.Now().Format("05"))
+				mu.Unlock()
+				atomic.AddInt64(&runs, 1)
+			})
+		}
+		add("before", 2*time.Second)
+		add("in1", 4*time.Second)
+		add("in2", 5*time.Second)
+		add("after", 8*time.Second)
+		settle(tw)
+		for i := 0; i < 10; i++ {
+			c.Tick(time.Second)
+			settle(tw)
+			if i == 6 && tw.BlackedOut() != tc.held {
+				t.Fatalf("%v: %d tasks held inside the window, want %d", tc.policy, tw.BlackedOut(), tc.held)
+			}
+		}
+		waitCount(t, &runs, int64(len(tc.want)))
+		mu.Lock()
+		if !reflect.DeepEqual(log, tc.want) {
+			t.Fatalf("%v: %v, want %v", tc.policy, log, tc.want)
+		}
+		mu.Unlock()
+		if tw.BlackedOut() != 0 {
+			t.Fatalf("%v: %d tasks still held", tc.policy, tw.BlackedOut())
+		}
+		tw.Stop()
+	}
+}
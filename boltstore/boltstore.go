@@ -0,0 +1,400 @@
+// Package boltstore store the named tasks of a time wheel in a local bbolt file.
+//
+//	store, err := boltstore.Open("tasks.db")
+//	if err != nil {
+//		return err
+//	}
+//	defer store.Close()
+//	tw := timewheel.New(time.Second, 60,
+//		timewheel.WithJobRegistry(registry),
+//		timewheel.WithStore(store, 10*time.Minute))
+//
+// The writes are kept in memory and committed in a single transaction every flush interval,
+// so there is one fsync per interval instead of one per task. The durability window is the
+// flush interval: a crash loses the changes made since the last flush, Close flushes them.
+// The reads see the pending changes.
+//
+// The tasks returned by Due are claimed until they are deleted or the store is opened again, the file
+// is meant for a single wheel.
+//
+// The task keys must be strings and the task data is stored as JSON, so the keys of
+// the restored TaskData are strings.
+package boltstore
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nosixtools/timewheel"
+	bolt "go.etcd.io/bbolt"
+)
+
+// DefaultFlushInterval interval between two commits of the pending writes, see WithFlushInterval
+const DefaultFlushInterval = 100 * time.Millisecond
+
+// bucket names, the due bucket indexes the keys by next run time
+var (
+	tasksBucket = []byte("tasks")
+	dueBucket   = []byte("due")
+)
+
+// Option configure the store when calling Open
+type Option func(*Store)
+
+// WithFlushInterval set the interval between two commits of the pending writes, default is DefaultFlushInterval
+func WithFlushInterval(d time.Duration) Option {
+	return func(s *Store) {
+		if d > 0 {
+			s.interval = d
+		}
+	}
+}
+
+// Store a timewheel.Store backed by a bbolt file
+type Store struct {
+	db       *bolt.DB
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending map[string][]byte   // writes not committed yet, nil means deleted
+	claimed map[string]struct{} // keys returned by Due
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+var _ timewheel.Store = (*Store)(nil)
+
+// Open open or create the store file at path and start flushing the writes periodically
+func Open(path string, opts ...Option) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{tasksBucket, dueBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	s := &Store{
+		db:       db,
+		interval: DefaultFlushInterval,
+		pending:  make(map[string][]byte),
+		claimed:  make(map[string]struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	go s.flushLoop()
+	return s, nil
+}
+
+// stored form of timewheel.TaskSpec
+type record struct {
+	Key      string                 `json:"key"`
+	Gen      uint64                 `json:"gen,omitempty"`
+	Interval time.Duration          `json:"interval"`
+	Times    int                    `json:"times"`
+	Next     time.Time              `json:"next"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+	JobName  string                 `json:"job_name"`
+	Tags     []string               `json:"tags,omitempty"`
+	Priority int                    `json:"priority,omitempty"`
+	Until    time.Time              `json:"until,omitempty"`
+	Expires  time.Time              `json:"expires,omitempty"`
+	Aligned  bool                   `json:"aligned,omitempty"`
+}
+
+func encode(spec timewheel.TaskSpec) (string, []byte, error) {
+	key, ok := spec.Key.(string)
+	if !ok {
+		return "", nil, fmt.Errorf("boltstore: key %v is not a string", spec.Key)
+	}
+	r := record{Key: key, Gen: spec.Gen, Interval: spec.Interval, Times: spec.Times, Next: spec.Next, JobName: spec.JobName, Tags: spec.Tags, Priority: spec.Priority, Until: spec.Until, Expires: spec.Expires,
+		Aligned: spec.Aligned}
+	if spec.Data != nil {
+		r.Data = make(map[string]interface{}, len(spec.Data))
+		for k, v := range spec.Data {
+			r.Data[fmt.Sprint(k)] = v
+		}
+	}
+	b, err := json.Marshal(r)
+	return key, b, err
+}
+
+func decode(b []byte, now time.Time) (timewheel.TaskSpec, error) {
+	var r record
+	if err := json.Unmarshal(b, &r); err != nil {
+		return timewheel.TaskSpec{}, err
+	}
+	spec := timewheel.TaskSpec{Key: r.Key, Gen: r.Gen, Interval: r.Interval, Times: r.Times, Next: r.Next, JobName: r.JobName, Tags: r.Tags, Priority: r.Priority, Until: r.Until, Expires: r.Expires,
+		Aligned: r.Aligned}
+	if d := r.Next.Sub(now); d > 0 {
+		spec.Delay = d
+	}
+	if r.Data != nil {
+		spec.Data = make(timewheel.TaskData, len(r.Data))
+		for k, v := range r.Data {
+			spec.Data[k] = v
+		}
+	}
+	return spec, nil
+}
+
+// next run time of the stored record
+func nextOf(b []byte) (time.Time, error) {
+	var r struct {
+		Next time.Time `json:"next"`
+	}
+	err := json.Unmarshal(b, &r)
+	return r.Next, err
+}
+
+// report whether the stored record is of the generation gen, 0 on either side matches any
+func sameGen(b []byte, gen uint64) (bool, error) {
+	var r struct {
+		Gen uint64 `json:"gen"`
+	}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return false, err
+	}
+	return gen == 0 || r.Gen == 0 || r.Gen == gen, nil
+}
+
+// key of the due index, the big endian time sorts in time order, the sign bit flipped for times before 1970
+func dueKey(next time.Time, key string) []byte {
+	b := make([]byte, 8, 8+len(key))
+	binary.BigEndian.PutUint64(b, uint64(next.UnixMilli())^(1<<63))
+	return append(b, key...)
+}
+
+// get the record of the key, the pending writes first, must hold mu
+func (s *Store) load(key string) ([]byte, error) {
+	if b, ok := s.pending[key]; ok {
+		return b, nil
+	}
+	var b []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(tasksBucket).Get([]byte(key)); v != nil {
+			b = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	return b, err
+}
+
+// Add implement timewheel.Store
+func (s *Store) Add(spec timewheel.TaskSpec) error {
+	key, b, err := encode(spec)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, err := s.load(key)
+	if err != nil {
+		return err
+	}
+	if old != nil {
+		return timewheel.ErrDuplicateKey
+	}
+	s.pending[key] = b
+	return nil
+}
+
+// Get implement timewheel.Store
+func (s *Store) Get(key interface{}) (timewheel.TaskSpec, bool, error) {
+	s.mu.Lock()
+	b, err := s.load(fmt.Sprint(key))
+	s.mu.Unlock()
+	if err != nil || b == nil {
+		return timewheel.TaskSpec{}, false, err
+	}
+	spec, err := decode(b, time.Now())
+	return spec, err == nil, err
+}
+
+// Update implement timewheel.Store
+func (s *Store) Update(spec timewheel.TaskSpec) error {
+	key, b, err := encode(spec)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, err := s.load(key)
+	if err != nil {
+		return err
+	}
+	if old == nil {
+		return timewheel.ErrTaskNotFound
+	}
+	if ok, err := sameGen(old, spec.Gen); err != nil || !ok {
+		if err != nil {
+			return fmt.Errorf("boltstore: task %s: %w", key, err)
+		}
+		return timewheel.ErrStaleGeneration
+	}
+	s.pending[key] = b
+	return nil
+}
+
+// Delete implement timewheel.Store
+func (s *Store) Delete(key interface{}, gen uint64) (bool, error) {
+	k := fmt.Sprint(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, err := s.load(k)
+	if err != nil || old == nil {
+		return false, err
+	}
+	if ok, err := sameGen(old, gen); err != nil || !ok {
+		if err != nil {
+			return false, fmt.Errorf("boltstore: task %s: %w", k, err)
+		}
+		return false, nil
+	}
+	s.pending[k] = nil
+	delete(s.claimed, k)
+	return true, nil
+}
+
+// Due implement timewheel.Store
+func (s *Store) Due(before time.Time) ([]timewheel.TaskSpec, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var specs []timewheel.TaskSpec
+	for key, b := range s.pending {
+		if _, ok := s.claimed[key]; b == nil || ok {
+			continue
+		}
+		next, err := nextOf(b)
+		if err != nil {
+			return nil, fmt.Errorf("boltstore: task %s: %w", key, err)
+		}
+		if !next.After(before) {
+			spec, err := decode(b, now)
+			if err != nil {
+				return nil, err
+			}
+			specs = append(specs, spec)
+		}
+	}
+	end := dueKey(before, "")
+	err := s.db.View(func(tx *bolt.Tx) error {
+		tasks := tx.Bucket(tasksBucket)
+		c := tx.Bucket(dueBucket).Cursor()
+		for k, _ := c.First(); k != nil && string(k[:8]) <= string(end); k, _ = c.Next() {
+			key := string(k[8:])
+			if _, ok := s.pending[key]; ok {
+				continue
+			}
+			if _, ok := s.claimed[key]; ok {
+				continue
+			}
+			spec, err := decode(tasks.Get(k[8:]), now)
+			if err != nil {
+				return fmt.Errorf("boltstore: task %s: %w", key, err)
+			}
+			specs = append(specs, spec)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, spec := range specs {
+		s.claimed[spec.Key.(string)] = struct{}{}
+	}
+	return specs, nil
+}
+
+// Flush commit the pending writes in a single transaction, the writes are kept pending if it fails
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) == 0 {
+		return nil
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		tasks, due := tx.Bucket(tasksBucket), tx.Bucket(dueBucket)
+		for key, b := range s.pending {
+			k := []byte(key)
+			if old := tasks.Get(k); old != nil {
+				next, err := nextOf(old)
+				if err != nil {
+					return fmt.Errorf("boltstore: task %s: %w", key, err)
+				}
+				if err := due.Delete(dueKey(next, key)); err != nil {
+					return err
+				}
+			}
+			if b == nil {
+				if err := tasks.Delete(k); err != nil {
+					return err
+				}
+				continue
+			}
+			next, err := nextOf(b)
+			if err != nil {
+				return fmt.Errorf("boltstore: task %s: %w", key, err)
+			}
+			if err := tasks.Put(k, b); err != nil {
+				return err
+			}
+			if err := due.Put(dueKey(next, key), nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.pending = make(map[string][]byte)
+	return nil
+}
+
+// flush the pending writes every interval until Close
+func (s *Store) flushLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.Flush()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Close flush the pending writes and close the file
+func (s *Store) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+		err := s.Flush()
+		if cerr := s.db.Close(); err == nil {
+			err = cerr
+		}
+		s.closeErr = err
+	})
+	return s.closeErr
+}
@@ -0,0 +1,180 @@
+package boltstore
+
+import (
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nosixtools/timewheel"
+)
+
+// close the file without flushing, as a crash would
+func kill(s *Store) {
+	close(s.stop)
+	<-s.done
+	s.db.Close()
+}
+
+func TestBoltStoreBasic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	s, err := Open(path, WithFlushInterval(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if err := s.Add(timewheel.TaskSpec{Key: "a", Interval: time.Second, Times: 3, Next: now.Add(time.Second), JobName: "j"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(timewheel.TaskSpec{Key: "a", Next: now}); err != timewheel.ErrDuplicateKey {
+		t.Fatal(err)
+	}
+	if err := s.Add(timewheel.TaskSpec{Key: 1}); err == nil {
+		t.Fatal("a key that is not a string is accepted")
+	}
+	s.Add(timewheel.TaskSpec{Key: "b", Interval: time.Hour, Times: -1, Next: now.Add(time.Hour), JobName: "j", Data: timewheel.TaskData{"x": 1.0}})
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	s.Update(timewheel.TaskSpec{Key: "a", Interval: time.Second, Times: 2, Next: now.Add(2 * time.Minute), JobName: "j"})
+	if ok, _ := s.Delete("b", 0); !ok {
+		t.Fatal("b not deleted")
+	}
+	if ok, _ := s.Delete("zz", 0); ok {
+		t.Fatal("absent key deleted")
+	}
+	due, _ := s.Due(now.Add(time.Minute))
+	if len(due) != 0 {
+		t.Fatalf("due before the pending update: %+v", due)
+	}
+	due, _ = s.Due(now.Add(3 * time.Minute))
+	if len(due) != 1 || due[0].Times != 2 {
+		t.Fatalf("due: %+v", due)
+	}
+	kill(s)
+	// the pending update and delete are lost
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	due, _ = s.Due(now.Add(2 * time.Hour))
+	sort.Slice(due, func(i, j int) bool { return due[i].Key.(string) < due[j].Key.(string) })
+	if len(due) != 2 || due[0].Times != 3 || due[1].Data["x"] != 1.0 {
+		t.Fatalf("reopened after a crash: %+v", due)
+	}
+	s.Update(timewheel.TaskSpec{Key: "a", Interval: time.Second, Times: 2, Next: now.Add(2 * time.Minute), JobName: "j"})
+	s.Delete("b", 0)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s, _ = Open(path)
+	defer s.Close()
+	due, _ = s.Due(now.Add(2 * time.Hour))
+	if len(due) != 1 || due[0].Times != 2 || due[0].Delay < time.Minute {
+		t.Fatalf("reopened after close: %+v", due)
+	}
+	if due, _ = s.Due(now.Add(time.Minute)); len(due) != 0 {
+		t.Fatalf("stale due index: %+v", due)
+	}
+}
+
+func TestBoltStoreWheel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	var runs int64
+	reg := timewheel.NewJobRegistry(false)
+	reg.Register("count", func(timewheel.TaskData) { atomic.AddInt64(&runs, 1) })
+	s, _ := Open(path, WithFlushInterval(10*time.Millisecond))
+	tw := timewheel.New(10*time.Millisecond, 100, timewheel.WithJobRegistry(reg), timewheel.WithStore(s, time.Hour))
+	tw.Start()
+	tw.AddNamedTask(50*time.Millisecond, 100, "fast", "count", nil)
+	tw.AddNamedTask(2*time.Second, 1, "slow", "count", nil)
+	time.Sleep(180 * time.Millisecond)
+	tw.Stop()
+	kill(s)
+	before := atomic.LoadInt64(&runs)
+	// downtime
+	time.Sleep(300 * time.Millisecond)
+
+	s, _ = Open(path)
+	defer s.Close()
+	fast, ok, err := s.Get("fast")
+	if !ok || err != nil {
+		t.Fatalf("fast not stored: %v", err)
+	}
+	slow, _, _ := s.Get("slow")
+	if fast.Times > 100-int(before)+1 || fast.Times < 90 {
+		t.Fatalf("%d times left after %d runs", fast.Times, before)
+	}
+	if slow.Delay > 2*time.Second-400*time.Millisecond || slow.Delay < time.Second {
+		t.Fatalf("delay %v not adjusted for the downtime", slow.Delay)
+	}
+	reg2 := timewheel.NewJobRegistry(false)
+	var runs2 int64
+	reg2.Register("count", func(timewheel.TaskData) { atomic.AddInt64(&runs2, 1) })
+	tw2 := timewheel.New(10*time.Millisecond, 100, timewheel.WithJobRegistry(reg2), timewheel.WithStore(s, time.Hour))
+	tw2.Start()
+	defer tw2.Stop()
+	time.Sleep(100 * time.Millisecond)
+	if !tw2.HasTask("fast") || !tw2.HasTask("slow") || atomic.LoadInt64(&runs2) == 0 {
+		t.Fatalf("not recovered, %d runs", atomic.LoadInt64(&runs2))
+	}
+}
+
+func TestBoltStoreGeneration(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	now := time.Now()
+	s.Add(timewheel.TaskSpec{Key: "a", Gen: 1, Interval: time.Second, Times: -1, Next: now, JobName: "j"})
+	if err := s.Update(timewheel.TaskSpec{Key: "a", Gen: 2, Next: now}); err != timewheel.ErrStaleGeneration {
+		t.Fatalf("update of a newer generation: %v", err)
+	}
+	if err := s.Update(timewheel.TaskSpec{Key: "b", Next: now}); err != timewheel.ErrTaskNotFound {
+		t.Fatalf("update of an absent key: %v", err)
+	}
+	if ok, _ := s.Delete("a", 2); ok {
+		t.Fatal("deleted by a newer generation")
+	}
+	if err := s.Update(timewheel.TaskSpec{Key: "a", Gen: 1, Times: 5, Next: now}); err != nil {
+		t.Fatal(err)
+	}
+	s.Flush()
+	if ok, _ := s.Delete("a", 1); !ok {
+		t.Fatal("a not deleted")
+	}
+	// a late run of the removed task does not touch the task added again
+	s.Add(timewheel.TaskSpec{Key: "a", Gen: 3, Times: 7, Next: now})
+	if err := s.Update(timewheel.TaskSpec{Key: "a", Gen: 1, Times: 6, Next: now}); err != timewheel.ErrStaleGeneration {
+		t.Fatalf("late update: %v", err)
+	}
+	if spec, _, _ := s.Get("a"); spec.Gen != 3 || spec.Times != 7 {
+		t.Fatalf("a: %+v", spec)
+	}
+}
+
+func TestBoltStoreClaims(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "tasks.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	now := time.Now()
+	s.Add(timewheel.TaskSpec{Key: "a", Times: -1, Interval: time.Second, Next: now, JobName: "j"})
+	s.Add(timewheel.TaskSpec{Key: "b", Times: -1, Interval: time.Second, Next: now, JobName: "j"})
+	s.Flush()
+	if due, _ := s.Due(now.Add(time.Second)); len(due) != 2 {
+		t.Fatalf("due: %+v", due)
+	}
+	s.Update(timewheel.TaskSpec{Key: "a", Times: -1, Interval: time.Second, Next: now, JobName: "j"})
+	if due, _ := s.Due(now.Add(time.Second)); len(due) != 0 {
+		t.Fatalf("claimed twice: %+v", due)
+	}
+	s.Delete("a", 0)
+	s.Add(timewheel.TaskSpec{Key: "a", Times: -1, Interval: time.Second, Next: now, JobName: "j"})
+	if due, _ := s.Due(now.Add(time.Second)); len(due) != 1 || due[0].Key != "a" {
+		t.Fatalf("added again: %+v", due)
+	}
+}
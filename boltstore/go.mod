@@ -0,0 +1,12 @@
+module github.com/nosixtools/timewheel/boltstore
+
+go 1.24
+
+require (
+	github.com/nosixtools/timewheel v0.0.0
+	go.etcd.io/bbolt v1.4.3
+)
+
+require golang.org/x/sys v0.29.0 // indirect
+
+replace github.com/nosixtools/timewheel => ../
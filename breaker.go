@@ -0,0 +1,113 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// BreakerState state of the circuit breaker of a task, see CircuitBreaker
+type BreakerState int32
+
+const (
+	// BreakerClosed the runs go on
+	BreakerClosed BreakerState = iota
+	// BreakerOpen the runs are skipped until the cool down elapsed
+	BreakerOpen
+	// BreakerHalfOpen a single probe run is let through, the other runs are skipped until it returned
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half open"
+	}
+	return "unknown"
+}
+
+// circuit breaker of a task, the state is accessed atomically
+type breaker struct {
+	threshold int64
+	coolDown  time.Duration
+	state     int32
+	openedAt  int64
+}
+
+// CircuitBreaker open the breaker of the task once threshold runs failed in a row, the runs are then skipped
+// like the runs dropped while catching up and still count towards times. Once coolDown elapsed a single probe
+// run is let through, the breaker closes if it succeeds and opens again if it fails. A run fails if its job
+// panics or, for the jobs added with AddTaskErr, returns an error. The transitions are reported by the
+// OnBreakerChange hook and the breaker events.
+func CircuitBreaker(threshold int, coolDown time.Duration) TaskOption {
+	return func(t *task) {
+		if threshold > 0 && coolDown > 0 {
+			t.breaker = &breaker{threshold: int64(threshold), coolDown: coolDown}
+		}
+	}
+}
+
+// current state, closed without a breaker
+func (b *breaker) load() BreakerState {
+	if b == nil {
+		return BreakerClosed
+	}
+	return BreakerState(atomic.LoadInt32(&b.state))
+}
+
+// report whether the breaker lets the due task run, only called on the wheel goroutine
+func (tw *TimeWheel) breakerAllows(task *task) bool {
+	b := task.breaker
+	switch b.load() {
+	case BreakerClosed:
+		return true
+	case BreakerOpen:
+		if tw.clock.Now().Sub(time.Unix(0, atomic.LoadInt64(&b.openedAt))) < b.coolDown {
+			return false
+		}
+		return tw.breakerMove(task, task.info(), BreakerOpen, BreakerHalfOpen)
+	}
+	// the probe has not returned
+	return false
+}
+
+// update the breaker with the outcome of a run, called on the job goroutine with the snapshot of the run
+func (tw *TimeWheel) breakerDone(task *task, info TaskInfo, failures int64, err error) {
+	b := task.breaker
+	if b == nil {
+		return
+	}
+	state := b.load()
+	switch {
+	case err == nil && state != BreakerClosed:
+		tw.breakerMove(task, info, state, BreakerClosed)
+	case err != nil && state == BreakerHalfOpen:
+		atomic.StoreInt64(&b.openedAt, tw.clock.Now().UnixNano())
+		tw.breakerMove(task, info, BreakerHalfOpen, BreakerOpen)
+	case err != nil && state == BreakerClosed && failures >= b.threshold:
+		atomic.StoreInt64(&b.openedAt, tw.clock.Now().UnixNano())
+		tw.breakerMove(task, info, BreakerClosed, BreakerOpen)
+	}
+}
+
+// move the breaker from one state to the other and report the transition, report whether it moved
+func (tw *TimeWheel) breakerMove(task *task, info TaskInfo, from, to BreakerState) bool {
+	if !atomic.CompareAndSwapInt32(&task.breaker.state, int32(from), int32(to)) {
+		return false
+	}
+	tw.logger.Printf("timewheel: circuit breaker %v, key: %v", to, task.key)
+	info.Breaker = to
+	tw.emitInfo(tw.hooks.OnBreakerChange, task.key, info)
+	switch to {
+	case BreakerOpen:
+		tw.publishInfo(EventBreakerOpen, task.key, info)
+	case BreakerHalfOpen:
+		tw.publishInfo(EventBreakerHalfOpen, task.key, info)
+	default:
+		tw.publishInfo(EventBreakerClosed, task.key, info)
+	}
+	return true
+}
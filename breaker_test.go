@@ -0,0 +1,64 @@
+package timewheel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 16, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	ch, cancel := tw.Subscribe(64)
+	defer cancel()
+	var runs int64
+	// runs 1-3 fail and open, probe 4 fails, probe 5 succeeds
+	err := tw.AddTaskErr(time.Second, -1, "k", nil, func(ctx context.Context, d TaskData) error {
+		n := atomic.AddInt64(&runs, 1)
+		if n <= 4 {
+			return errors.New("down")
+		}
+		return nil
+	}, CircuitBreaker(3, 5*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	settle(tw)
+	var trace []int64
+	var states []BreakerState
+	for i := 0; i < 20; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+		trace = append(trace, atomic.LoadInt64(&runs))
+		st, _ := tw.TaskStats("k")
+		states = append(states, st.Breaker)
+	}
+	var evs []EventType
+	for len(ch) > 0 {
+		if ev := <-ch; ev.Type >= EventBreakerOpen {
+			evs = append(evs, ev.Type)
+		}
+	}
+	want := "[0 1 2 3 3 3 3 3 4 4 4 4 4 5 6 7 8 9 10 11]"
+	if got := fmt.Sprint(trace); got != want {
+		t.Fatalf("runs after each tick %s, want %s", got, want)
+	}
+	// open after the third failure, open again after the failed probe, closed after the good one
+	if got := fmt.Sprint(states[3], states[8], states[13]); got != "open open closed" {
+		t.Fatalf("breaker states %s", got)
+	}
+	if got := fmt.Sprint(evs); got != "[breaker open breaker half open breaker open breaker half open breaker closed]" {
+		t.Fatalf("breaker events %s", got)
+	}
+	tw.Range(func(_ interface{}, info TaskInfo) bool {
+		if info.Breaker != BreakerClosed {
+			t.Fatalf("task info breaker %v", info.Breaker)
+		}
+		return true
+	})
+}
@@ -0,0 +1,185 @@
+package timewheel
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TaskBuilder build a task step by step, see TimeWheel.NewTask. A builder is single use.
+type TaskBuilder struct {
+	tw        *TimeWheel
+	key       interface{}
+	interval  time.Duration
+	times     int
+	data      TaskData
+	job       JobCtx
+	first     time.Duration
+	opts      []TaskOption
+	jitter    time.Duration
+	until     time.Time
+	submitted bool
+}
+
+// NewTask start building a task registered under key, the task runs without limit unless Times is set
+func (tw *TimeWheel) NewTask(key interface{}) *TaskBuilder {
+	return &TaskBuilder{tw: tw, key: key, times: -1}
+}
+
+// Every set the interval between the runs
+func (b *TaskBuilder) Every(d time.Duration) *TaskBuilder {
+	b.interval = d
+	return b
+}
+
+// Times set the number of runs, -1 means no limit
+func (b *TaskBuilder) Times(n int) *TaskBuilder {
+	b.times = n
+	return b
+}
+
+// After set the delay of the first run, default is the interval
+func (b *TaskBuilder) After(d time.Duration) *TaskBuilder {
+	b.first = d
+	return b
+}
+
+// Until stop the task at end, see Until
+func (b *TaskBuilder) Until(end time.Time) *TaskBuilder {
+	b.until = end
+	b.opts = append(b.opts, Until(end))
+	return b
+}
+
+// WithTTL remove the task once d elapsed, see TTL
+func (b *TaskBuilder) WithTTL(d time.Duration) *TaskBuilder {
+	b.opts = append(b.opts, TTL(d))
+	return b
+}
+
+// AlignToPeriod run the task on the multiples of its interval, see AlignToPeriod
+func (b *TaskBuilder) AlignToPeriod(on bool) *TaskBuilder {
+	b.opts = append(b.opts, AlignToPeriod(on))
+	return b
+}
+
+// DependsOn hold the task until the task under key completed, see DependsOn
+func (b *TaskBuilder) DependsOn(key interface{}, firstRun bool) *TaskBuilder {
+	b.opts = append(b.opts, DependsOn(key, firstRun))
+	return b
+}
+
+// WithData set the data passed to the job
+func (b *TaskBuilder) WithData(data TaskData) *TaskBuilder {
+	b.data = data
+	return b
+}
+
+// Do set the job
+func (b *TaskBuilder) Do(job Job) *TaskBuilder {
+	if job != nil {
+		b.job = wrapJob(job)
+	}
+	return b
+}
+
+// DoCtx set the job receiving a context
+func (b *TaskBuilder) DoCtx(job JobCtx) *TaskBuilder {
+	b.job = job
+	return b
+}
+
+// WithJitter delay every run by a random duration below d, see Jitter
+func (b *TaskBuilder) WithJitter(d time.Duration) *TaskBuilder {
+	b.jitter = d
+	b.opts = append(b.opts, Jitter(d))
+	return b
+}
+
+// WithMinGap keep at least d between the starts of two runs, see MinGap
+func (b *TaskBuilder) WithMinGap(d time.Duration) *TaskBuilder {
+	b.opts = append(b.opts, MinGap(d))
+	return b
+}
+
+// WithMetricLabels attach the labels to the measurements, see MetricLabels
+func (b *TaskBuilder) WithMetricLabels(labels map[string]string) *TaskBuilder {
+	b.opts = append(b.opts, MetricLabels(labels))
+	return b
+}
+
+// Precise run the task on time, see Precise
+func (b *TaskBuilder) Precise() *TaskBuilder {
+	b.opts = append(b.opts, Precise())
+	return b
+}
+
+// Acked run the task at least once, see Acked
+func (b *TaskBuilder) Acked(timeout time.Duration, maxAttempts int) *TaskBuilder {
+	b.opts = append(b.opts, Acked(timeout, maxAttempts))
+	return b
+}
+
+// WithPriority set the priority, see Priority
+func (b *TaskBuilder) WithPriority(p int) *TaskBuilder {
+	b.opts = append(b.opts, Priority(p))
+	return b
+}
+
+// WithTags tag the task, see Tags
+func (b *TaskBuilder) WithTags(tags ...string) *TaskBuilder {
+	b.opts = append(b.opts, Tags(tags...))
+	return b
+}
+
+// OnResume set the resume policy, see OnResume
+func (b *TaskBuilder) OnResume(p ResumePolicy) *TaskBuilder {
+	b.opts = append(b.opts, OnResume(p))
+	return b
+}
+
+// OnExhausted set the callback of the final run, see OnExhausted
+func (b *TaskBuilder) OnExhausted(fn func(key interface{}, data TaskData)) *TaskBuilder {
+	b.opts = append(b.opts, OnExhausted(fn))
+	return b
+}
+
+// Submit check the task and add it to the wheel
+func (b *TaskBuilder) Submit() error {
+	if b.submitted {
+		return ErrBuilderSubmitted
+	}
+	b.submitted = true
+	if b.job == nil {
+		return fmt.Errorf("%w, no job", ErrInvalidParams)
+	}
+	if b.jitter < 0 || (b.jitter > 0 && b.jitter >= b.interval) {
+		return fmt.Errorf("%w, jitter must be less than the interval", ErrInvalidParams)
+	}
+	if b.first < 0 {
+		return fmt.Errorf("%w, negative first delay", ErrInvalidParams)
+	}
+	if b.first > b.tw.maxDelay() {
+		return ErrDelayTooLarge
+	}
+	task, err := b.tw.newTask(b.interval, b.times, b.key, b.data, b.job)
+	if err != nil {
+		return err
+	}
+	for _, opt := range b.opts {
+		opt(task)
+	}
+	if err := b.tw.acceptOptions(task); err != nil {
+		b.tw.dropTask(task)
+		return err
+	}
+	if b.first > 0 {
+		task.next = b.tw.clock.Now().Add(b.first)
+		task.atNext = true
+	}
+	if !b.until.IsZero() && task.next.After(b.until) {
+		b.tw.dropTask(task)
+		return fmt.Errorf("%w, no run before the end time", ErrInvalidParams)
+	}
+	return b.tw.submit(context.Background(), task)
+}
@@ -0,0 +1,139 @@
+package timewheel
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// read the registered task on the wheel goroutine
+func inspectTask(t *testing.T, tw *TimeWheel, key interface{}, fn func(task *task)) {
+	t.Helper()
+	found := false
+	tw.exec(func() {
+		if task, ok := tw.taskRecord.Load(key); ok {
+			found = true
+			fn(task)
+		}
+	})
+	if !found {
+		t.Fatalf("task %v not registered", key)
+	}
+}
+
+func TestTaskBuilder(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	tick := func(n int) {
+		for i := 0; i < n; i++ {
+			c.Tick(time.Second)
+		}
+		settle(tw)
+	}
+
+	// every, times and data
+	var runs int64
+	err := tw.NewTask("a").Every(time.Second).Times(3).WithData(TaskData{"v": 1}).Do(func(data TaskData) {
+		if data["v"] == 1 {
+			atomic.AddInt64(&runs, 1)
+		}
+	}).Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// first delay then the interval
+	var late int64
+	if err := tw.NewTask("b").Every(5 * time.Second).After(time.Second).Do(func(TaskData) { atomic.AddInt64(&late, 1) }).Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// tags, priority and jitter
+	if err := tw.NewTask("c").Every(time.Minute).WithTags("x", "y").WithPriority(7).WithJitter(time.Second).Do(func(TaskData) {}).Submit(); err != nil {
+		t.Fatal(err)
+	}
+	inspectTask(t, tw, "c", func(task *task) {
+		if len(task.tags) != 2 || task.priority != 7 || task.jitter != time.Second {
+			t.Errorf("tags %v priority %d jitter %v", task.tags, task.priority, task.jitter)
+		}
+	})
+	if tw.CountByTag("y") != 1 {
+		t.Fatal("tag index")
+	}
+
+	// exhaustion callback and resume policy
+	done := make(chan interface{}, 1)
+	err = tw.NewTask("d").Every(time.Second).Times(1).OnResume(ResumeReplayAll).OnExhausted(func(key interface{}, _ TaskData) {
+		done <- key
+	}).Do(func(TaskData) {}).Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inspectTask(t, tw, "d", func(task *task) {
+		if task.resume != ResumeReplayAll || task.onDone == nil {
+			t.Error("resume policy or callback not set")
+		}
+	})
+
+	// until
+	var bounded int64
+	if err := tw.NewTask("e").Every(time.Second).Until(c.Now().Add(3 * time.Second)).Do(func(TaskData) { atomic.AddInt64(&bounded, 1) }).Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	tick(2)
+	waitCount(t, &late, 1)
+	tick(5)
+	waitCount(t, &runs, 3)
+	waitCount(t, &late, 2)
+	select {
+	case key := <-done:
+		if key != "d" {
+			t.Fatalf("callback of %v", key)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no exhaustion callback")
+	}
+	if tw.HasTask("e") || atomic.LoadInt64(&bounded) == 0 || atomic.LoadInt64(&bounded) > 3 {
+		t.Fatalf("until: %d runs", atomic.LoadInt64(&bounded))
+	}
+}
+
+func TestTaskBuilderInvalid(t *testing.T) {
+	tw := New(time.Second, 10)
+	tw.Start()
+	defer tw.Stop()
+	job := func(TaskData) {}
+	for name, b := range map[string]*TaskBuilder{
+		"no job":         tw.NewTask("k").Every(time.Second),
+		"jitter":         tw.NewTask("k").Every(time.Second).WithJitter(time.Second).Do(job),
+		"negative after": tw.NewTask("k").Every(time.Second).After(-time.Second).Do(job),
+		"until in past":  tw.NewTask("k").Every(time.Second).Until(time.Now().Add(-time.Hour)).Do(job),
+		"zero interval":  tw.NewTask("k").Do(job),
+		"invalid times":  tw.NewTask("k").Every(time.Second).Times(0).Do(job),
+		"non comparable": tw.NewTask([]int{1}).Every(time.Second).Do(job),
+	} {
+		err := b.Submit()
+		if err == nil {
+			t.Fatalf("%s: accepted", name)
+		}
+		if name != "non comparable" && !errors.Is(err, ErrInvalidParams) {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	if tw.Len() != 0 {
+		t.Fatalf("%d tasks registered", tw.Len())
+	}
+
+	// single use
+	b := tw.NewTask("k").Every(time.Second).Do(job)
+	if err := b.Submit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Submit(); err == nil {
+		t.Fatal("builder submitted twice")
+	}
+}
@@ -0,0 +1,72 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// number of keys handed to the busy slot handler
+const busySample = 8
+
+// BusySlotHandler receive the index and the task count of a slot holding threshold tasks or more,
+// with a sample of their keys
+type BusySlotHandler func(slot int, count int, sample []interface{})
+
+// WithBusySlotWarning call handler on its own goroutine when the slot about to be scanned holds threshold
+// tasks or more, the tasks of the next rotations included. A slot is reported at most once every interval.
+// Only the wheel backend has slots, see WithBackend.
+func WithBusySlotWarning(threshold int, every time.Duration, handler BusySlotHandler) Option {
+	return func(tw *TimeWheel) {
+		if threshold > 0 {
+			tw.busyThreshold = threshold
+			tw.busyEvery = every
+			tw.busyHandler = handler
+		}
+	}
+}
+
+// BusySlotWarnings get the number of busy slot warnings, see WithBusySlotWarning
+func (tw *TimeWheel) BusySlotWarnings() int64 {
+	return atomic.LoadInt64(&tw.busyNum)
+}
+
+// check the length of the slot about to be scanned, only called on the wheel goroutine
+func (tw *TimeWheel) checkBusySlot() {
+	wb, ok := wheelOf(tw.backend)
+	if !ok {
+		return
+	}
+	pos := wb.currentPos
+	slot := wb.slots[pos]
+	n := slot.Len()
+	if n < tw.busyThreshold {
+		return
+	}
+	now := tw.clock.Now()
+	if last, ok := tw.busyLast[pos]; ok && now.Sub(last) < tw.busyEvery {
+		return
+	}
+	if tw.busyLast == nil {
+		tw.busyLast = make(map[int]time.Time)
+	}
+	tw.busyLast[pos] = now
+	atomic.AddInt64(&tw.busyNum, 1)
+	tw.logger.Printf("timewheel: busy slot, slot: %d, tasks: %d", pos, n)
+	if tw.busyHandler == nil {
+		return
+	}
+	sample := make([]interface{}, 0, busySample)
+	slot.Each(func(e *SlotEntry) {
+		if len(sample) < busySample {
+			sample = append(sample, e.Key())
+		}
+	})
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				tw.logger.Printf("timewheel: busy slot handler panic recovered, slot: %d, panic: %v", pos, r)
+			}
+		}()
+		tw.busyHandler(pos, n, sample)
+	}()
+}
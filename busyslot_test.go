@@ -0,0 +1,45 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBusySlotWarning(t *testing.T) {
+	c := newFakeClock()
+	type warning struct {
+		slot, n int
+		sample  []interface{}
+	}
+	var mu sync.Mutex
+	var got []warning
+	tw := New(time.Second, 8, WithClock(c), WithBusySlotWarning(10, time.Minute, func(slot, n int, sample []interface{}) {
+		mu.Lock()
+		got = append(got, warning{slot, n, sample})
+		mu.Unlock()
+	}))
+	tw.Start()
+	defer tw.Stop()
+	// 30 tasks in slot 3, the next rotations keep them there
+	for i := 0; i < 30; i++ {
+		tw.AddTask(3*time.Second+8*time.Second*time.Duration(i%3), 1, i, nil, func(TaskData) {})
+	}
+	for i := 0; i < 5; i++ {
+		tw.AddTask(5*time.Second, 1, 100+i, nil, func(TaskData) {})
+	}
+	settle(tw)
+	// slot 3 is scanned twice, the second warning is rate limited
+	for i := 0; i < 12; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 || got[0].slot != 3 || got[0].n != 30 || len(got[0].sample) != busySample {
+		t.Fatalf("warnings %+v", got)
+	}
+	if tw.BusySlotWarnings() != 1 {
+		t.Fatalf("%d warnings counted", tw.BusySlotWarnings())
+	}
+}
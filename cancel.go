@@ -0,0 +1,68 @@
+package timewheel
+
+import (
+	"context"
+	"sync"
+)
+
+// RemoveTaskAndCancel remove the task like RemoveTask and cancel the context of its runs still in
+// flight, the final one included. A run dispatched but not started yet is skipped. Without a run in
+// flight it behaves like RemoveTask, a key with a run in flight but no task left is not an error.
+func (tw *TimeWheel) RemoveTaskAndCancel(key interface{}) error {
+	err := tw.RemoveTask(key)
+	if n := tw.activeRuns.cancel(key); n > 0 && err == ErrTaskNotFound {
+		return nil
+	}
+	return err
+}
+
+// runs dispatched and not returned yet by task key, see RemoveTaskAndCancel
+type runIndex struct {
+	mu   sync.Mutex
+	runs map[interface{}][]*jobRun
+}
+
+// give the run its context and track it until untrack
+func (x *runIndex) track(r *jobRun) {
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	key := r.task.key
+	x.mu.Lock()
+	if x.runs == nil {
+		x.runs = make(map[interface{}][]*jobRun)
+	}
+	x.runs[key] = append(x.runs[key], r)
+	x.mu.Unlock()
+}
+
+// stop tracking the run once its job returned
+func (x *runIndex) untrack(r *jobRun) {
+	key := r.task.key
+	x.mu.Lock()
+	runs := x.runs[key]
+	for i, v := range runs {
+		if v == r {
+			if len(runs) == 1 {
+				delete(x.runs, key)
+			} else {
+				x.runs[key] = append(runs[:i:i], runs[i+1:]...)
+			}
+			break
+		}
+	}
+	x.mu.Unlock()
+	r.cancel()
+}
+
+// cancel the context of the runs of the key, return their number
+func (x *runIndex) cancel(key interface{}) int {
+	if !keyComparable(key) {
+		return 0
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	runs := x.runs[key]
+	for _, r := range runs {
+		r.cancel()
+	}
+	return len(runs)
+}
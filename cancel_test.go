@@ -0,0 +1,49 @@
+package timewheel
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRemoveTaskAndCancel(t *testing.T) {
+	tw := New(5*time.Millisecond, 8)
+	tw.Start()
+	defer tw.Stop()
+	var starts, done int64
+	job := func(ctx context.Context, data TaskData) {
+		atomic.AddInt64(&starts, 1)
+		select {
+		case <-ctx.Done():
+			atomic.AddInt64(&done, 1)
+		case <-time.After(5 * time.Second):
+		}
+	}
+	for _, times := range []int{-1, 1} {
+		atomic.StoreInt64(&starts, 0)
+		atomic.StoreInt64(&done, 0)
+		tw.AddTaskCtx(5*time.Millisecond, times, "k", nil, job)
+		for atomic.LoadInt64(&starts) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		begin := time.Now()
+		if err := tw.RemoveTaskAndCancel("k"); err != nil {
+			t.Fatal(times, err)
+		}
+		for atomic.LoadInt64(&done) != atomic.LoadInt64(&starts) {
+			time.Sleep(time.Millisecond)
+		}
+		if d := time.Since(begin); d > 100*time.Millisecond {
+			t.Fatal(d)
+		}
+		n := atomic.LoadInt64(&starts)
+		time.Sleep(50 * time.Millisecond)
+		if atomic.LoadInt64(&starts) != n || tw.HasTask("k") {
+			t.Fatal("ran again")
+		}
+	}
+	if err := tw.RemoveTaskAndCancel("none"); err != ErrTaskNotFound {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,76 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCatchUpPolicy(t *testing.T) {
+	for _, c := range []struct {
+		policy CatchUpPolicy
+		runs   int64
+	}{
+		// one run for the gap and the one of the resume tick
+		{FireOnePerTask, 2},
+		// every occurrence of the 20s gap but the first which was not due yet
+		{FireAll, 19},
+		{Skip, 1},
+	} {
+		clk := newFakeClock()
+		// the gap is five rotations
+		tw := New(time.Second, 4, WithClock(clk), WithCatchUpPolicy(c.policy))
+		tw.Start()
+		var n int64
+		tw.AddTask(time.Second, -1, "a", nil, func(TaskData) { atomic.AddInt64(&n, 1) })
+		settle(tw)
+		clk.Tick(20 * time.Second)
+		settle(tw)
+		if got := atomic.LoadInt64(&n); got != c.runs {
+			t.Errorf("policy %d: %d runs, want %d", c.policy, got, c.runs)
+		}
+		tw.Stop()
+	}
+}
+
+func TestCatchUpLongInterval(t *testing.T) {
+	clk := newFakeClock()
+	tw := New(time.Second, 4, WithClock(clk))
+	tw.Start()
+	defer tw.Stop()
+	var n int64
+	// due in the middle of the gap, circles the wheel twice
+	tw.AddTask(10*time.Second, -1, "daily", nil, func(TaskData) { atomic.AddInt64(&n, 1) })
+	settle(tw)
+	clk.Tick(30 * time.Second)
+	settle(tw)
+	if got := atomic.LoadInt64(&n); got != 1 {
+		t.Fatal("missed run not caught up", got)
+	}
+	// the schedule goes on from the caught up runs, no rotation of delay
+	for i := 0; i < 10; i++ {
+		clk.Tick(time.Second)
+	}
+	settle(tw)
+	if got := atomic.LoadInt64(&n); got != 2 {
+		t.Fatal("next run", got)
+	}
+}
+
+func TestCatchUpNoGap(t *testing.T) {
+	clk := newFakeClock()
+	tw := New(time.Second, 4, WithClock(clk), WithCatchUpPolicy(FireAll))
+	tw.Start()
+	defer tw.Stop()
+	var n int64
+	tw.AddTask(time.Second, -1, "a", nil, func(TaskData) { atomic.AddInt64(&n, 1) })
+	settle(tw)
+	for i := 0; i < 21; i++ {
+		clk.Tick(time.Second)
+	}
+	settle(tw)
+	// the same runs as after the 20s gap
+	if got := atomic.LoadInt64(&n); got != 20 {
+		t.Fatal(got)
+	}
+}
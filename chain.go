@@ -0,0 +1,52 @@
+package timewheel
+
+import (
+	"context"
+	"time"
+)
+
+// ChainStep a task of a chain run once, Delay after the job of the previous step returned
+type ChainStep struct {
+	Key   interface{}
+	Delay time.Duration
+	Data  TaskData
+	Job   Job
+}
+
+// ChainTasks schedule the steps one after another, the first step runs Delay from now.
+// A step is added when the previous one finished, so removing a scheduled step cancels the rest of the chain.
+func (tw *TimeWheel) ChainTasks(steps ...ChainStep) error {
+	if len(steps) == 0 {
+		return ErrInvalidParams
+	}
+	for _, step := range steps {
+		if step.Key == nil || step.Job == nil || step.Delay <= 0 {
+			return ErrInvalidParams
+		}
+	}
+	return tw.addChainStep(steps)
+}
+
+// add the first step, the others ride on it
+func (tw *TimeWheel) addChainStep(steps []ChainStep) error {
+	step := steps[0]
+	task, err := tw.newTask(step.Delay, 1, step.Key, step.Data, wrapJob(step.Job))
+	if err != nil {
+		return err
+	}
+	task.then = steps[1:]
+	return tw.submit(context.Background(), task)
+}
+
+// schedule the rest of the chain after the final run of the task, called on the job goroutine.
+// The step is added without waiting on the wheel goroutine, the job may hold the worker it waits for.
+func (tw *TimeWheel) chainNext(then []ChainStep) {
+	if len(then) == 0 {
+		return
+	}
+	go func() {
+		if err := tw.addChainStep(then); err != nil {
+			tw.logger.Printf("timewheel: chain step add failed, key: %v, err: %v", then[0].Key, err)
+		}
+	}()
+}
@@ -0,0 +1,163 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// steps run on a fake clock, the tick of every run is recorded
+type chainRecorder struct {
+	mu   sync.Mutex
+	tick int
+	runs map[string]int
+}
+
+func (r *chainRecorder) job(name string) Job {
+	return func(TaskData) {
+		r.mu.Lock()
+		r.runs[name] = r.tick
+		r.mu.Unlock()
+	}
+}
+
+func (r *chainRecorder) advance(t *testing.T, c *fakeClock, tw *TimeWheel, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		r.mu.Lock()
+		r.tick++
+		r.mu.Unlock()
+		c.Tick(time.Second)
+		settle(tw)
+		// the next step is added once the job returned
+		settle(tw)
+	}
+}
+
+func TestChainTasks(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	r := &chainRecorder{runs: make(map[string]int)}
+	err := tw.ChainTasks(
+		ChainStep{Key: "remind", Delay: time.Second, Job: r.job("remind")},
+		ChainStep{Key: "escalate", Delay: 3 * time.Second, Job: r.job("escalate")},
+		ChainStep{Key: "close", Delay: time.Second, Job: r.job("close")},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settle(tw)
+	if tw.HasTask("escalate") {
+		t.Fatal("second step added with the first")
+	}
+	r.advance(t, c, tw, 10)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// a task fires one tick after its delay
+	want := map[string]int{"remind": 2, "escalate": 6, "close": 8}
+	for name, tick := range want {
+		if r.runs[name] != tick {
+			t.Fatalf("runs at ticks %v, want %v", r.runs, want)
+		}
+	}
+	if tw.Len() != 0 {
+		t.Fatalf("%d tasks left", tw.Len())
+	}
+}
+
+func TestChainTasksRemoveStep(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	r := &chainRecorder{runs: make(map[string]int)}
+	tw.ChainTasks(
+		ChainStep{Key: 1, Delay: time.Second, Job: r.job("1")},
+		ChainStep{Key: 2, Delay: 2 * time.Second, Job: r.job("2")},
+		ChainStep{Key: 3, Delay: time.Second, Job: r.job("3")},
+	)
+	settle(tw)
+	r.advance(t, c, tw, 2)
+	if !tw.HasTask(2) {
+		t.Fatal("second step not added")
+	}
+	if err := tw.RemoveTask(2); err != nil {
+		t.Fatal(err)
+	}
+	r.advance(t, c, tw, 8)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.runs) != 1 || r.runs["1"] != 2 || tw.HasTask(3) {
+		t.Fatalf("runs %v", r.runs)
+	}
+
+	if tw.ChainTasks() != ErrInvalidParams {
+		t.Fatal("empty chain")
+	}
+	if tw.ChainTasks(ChainStep{Key: "a", Delay: time.Second, Job: r.job("a")}, ChainStep{Key: "b", Job: r.job("b")}) != ErrInvalidParams {
+		t.Fatal("step without delay")
+	}
+}
+
+// the next step waits for the job of the previous one to return
+func TestChainTasksAfterReturn(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	release := make(chan struct{})
+	done := make(chan struct{})
+	tw.ChainTasks(
+		ChainStep{Key: "slow", Delay: time.Second, Job: func(TaskData) { <-release }},
+		ChainStep{Key: "next", Delay: time.Second, Job: func(TaskData) { close(done) }},
+	)
+	settle(tw)
+	for i := 0; i < 5; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	if tw.HasTask("next") {
+		t.Fatal("next step added while the job runs")
+	}
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for !tw.HasTask("next") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	settle(tw)
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("next step did not run")
+	}
+}
+
+// the job of a step holds the single worker, the next step is added while the wheel goroutine waits for it
+func TestChainTasksBlockedWorkers(t *testing.T) {
+	tw := New(5*time.Millisecond, 16, WithWorkers(1, 0, Block))
+	tw.Start()
+	defer tw.Stop()
+	if err := tw.AddTask(5*time.Millisecond, -1, "busy", nil, func(TaskData) {}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	err := tw.ChainTasks(
+		ChainStep{Key: "first", Delay: 5 * time.Millisecond, Job: func(TaskData) { time.Sleep(30 * time.Millisecond) }},
+		ChainStep{Key: "second", Delay: 5 * time.Millisecond, Job: func(TaskData) { close(done) }},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("second step did not run")
+	}
+	if tw.WorkerStats().Blocked == 0 {
+		t.Fatal("queue never full")
+	}
+}
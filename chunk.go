@@ -0,0 +1,74 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DefaultScanChunk number of due tasks handled per loop iteration, see WithScanChunk
+const DefaultScanChunk = 1024
+
+// closed channel selecting the carried tasks while some are waiting
+var carryReady = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
+// WithScanChunk handle at most n due tasks per loop iteration, the rest of a huge slot is carried over
+// and handled between the other requests of the wheel in the order they became due.
+// Default is DefaultScanChunk, the tick cap of WithTickCap takes precedence.
+func WithScanChunk(n int) Option {
+	return func(tw *TimeWheel) {
+		if n > 0 {
+			tw.scanChunk = n
+		}
+	}
+}
+
+// run the due task within the chunk, carry it over otherwise
+func (tw *TimeWheel) runDueTaskChunked(task *task) {
+	if tw.catchingUp || (tw.chunkLeft > 0 && len(tw.carry) == 0) {
+		tw.chunkLeft--
+		tw.runDueTask(task)
+		return
+	}
+	// the queue holds its own reference, the task may be removed while it waits
+	task.retain()
+	tw.carry = append(tw.carry, task)
+	atomic.AddInt64(&tw.carryNum, 1)
+	tw.trace(task, TraceDeferred)
+}
+
+// handle the next chunk of the carried tasks
+func (tw *TimeWheel) runCarry() {
+	begin := time.Now()
+	n := len(tw.carry)
+	if n > tw.scanChunk {
+		n = tw.scanChunk
+	}
+	chunk := tw.carry[:n]
+	tw.carry = tw.carry[n:]
+	atomic.AddInt64(&tw.carryNum, -int64(n))
+	for i, task := range chunk {
+		chunk[i] = nil
+		// removed while carried
+		if task.times != 0 {
+			tw.runDueTask(task)
+		}
+		task.release()
+	}
+	if len(tw.carry) == 0 {
+		tw.carry = nil
+	}
+	cost := int64(time.Since(begin))
+	atomic.AddInt64(&tw.lastTickCost, cost)
+	atomic.AddInt64(&tw.tickTime, cost)
+}
+
+// handle every carried task, the catch up does not interleave
+func (tw *TimeWheel) drainCarry() {
+	for len(tw.carry) > 0 {
+		tw.runCarry()
+	}
+}
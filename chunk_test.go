@@ -0,0 +1,46 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScanChunk(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c), WithAddBuffer(1024), WithWorkers(4, 1<<17, Block))
+	tw.Start()
+	defer tw.Stop()
+	var n int64
+	job := func(TaskData) { atomic.AddInt64(&n, 1) }
+	for i := 0; i < 100000; i++ {
+		tw.AddTask(time.Second, 1, i, nil, job)
+	}
+	tw.AddTask(3*time.Second, 1, "other", nil, job)
+	settle(tw)
+	c.Tick(time.Second)
+	settle(tw)
+	go c.Tick(time.Second)
+	for i := 0; tw.QueueStats().Carried == 0; i++ {
+		if i == 1000 {
+			t.Fatal("the huge slot is never carried over")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	begin := time.Now()
+	if err := tw.RemoveTask("other"); err != nil {
+		t.Fatal(err)
+	}
+	// the removal is serviced between two chunks, not after the whole slot
+	if took, carried := time.Since(begin), tw.QueueStats().Carried; carried == 0 || took > time.Second {
+		t.Fatalf("remove took %v, %d tasks carried", took, carried)
+	}
+	for i := 0; i < 1000 && atomic.LoadInt64(&n) < 100000; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Tick(time.Second)
+	settle(tw)
+	if atomic.LoadInt64(&n) != 100000 || tw.Len() != 0 || tw.QueueStats().Carried != 0 {
+		t.Fatalf("%d runs, %d tasks left", atomic.LoadInt64(&n), tw.Len())
+	}
+}
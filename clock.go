@@ -0,0 +1,138 @@
+package timewheel
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock the source of time used by the wheel, replace it to drive the wheel with a simulated clock
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker the ticker created by Clock
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// TimerClock a Clock also creating timers, the precise tasks are backed by its timers, see Precise
+type TimerClock interface {
+	Clock
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer the timer created by TimerClock
+type Timer interface {
+	Stop() bool
+}
+
+// timer of the clock running f after d, nil if the clock creates no timer
+func afterFunc(c Clock, d time.Duration, f func()) Timer {
+	if tc, ok := c.(TimerClock); ok {
+		return tc.AfterFunc(d, f)
+	}
+	return nil
+}
+
+// system clock
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return &realTicker{time.NewTicker(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (t *realTicker) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t *realTicker) Stop() {
+	t.t.Stop()
+}
+
+// clock running f times faster than base from origin on, see WithTimeScale
+type scaledClock struct {
+	base   Clock
+	origin time.Time
+	f      float64
+}
+
+func newScaledClock(base Clock, f float64) *scaledClock {
+	return &scaledClock{base: base, origin: base.Now(), f: f}
+}
+
+// map a time of the base clock to the scaled timeline
+func (c *scaledClock) scale(t time.Time) time.Time {
+	return c.origin.Add(time.Duration(float64(t.Sub(c.origin)) * c.f))
+}
+
+func (c *scaledClock) Now() time.Time {
+	return c.scale(c.base.Now())
+}
+
+// duration of d on the base clock
+func (c *scaledClock) real(d time.Duration) time.Duration {
+	real := time.Duration(float64(d) / c.f)
+	if real <= 0 {
+		real = 1
+	}
+	return real
+}
+
+func (c *scaledClock) NewTicker(d time.Duration) Ticker {
+	t := &scaledTicker{t: c.base.NewTicker(c.real(d)), c: make(chan time.Time, 1), stop: make(chan struct{})}
+	go t.forward(c)
+	return t
+}
+
+// the timer of the base clock, nil if the base clock creates no timer
+func (c *scaledClock) AfterFunc(d time.Duration, f func()) Timer {
+	return afterFunc(c.base, c.real(d), f)
+}
+
+// ticker of the scaled clock, the ticks of the base ticker are forwarded in the scaled timeline
+type scaledTicker struct {
+	t    Ticker
+	c    chan time.Time
+	stop chan struct{}
+	once sync.Once
+}
+
+func (t *scaledTicker) forward(c *scaledClock) {
+	for {
+		select {
+		case now := <-t.t.C():
+			// a slow receiver misses ticks like with time.Ticker
+			select {
+			case t.c <- c.scale(now):
+			default:
+			}
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+func (t *scaledTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *scaledTicker) Stop() {
+	t.once.Do(func() {
+		t.t.Stop()
+		close(t.stop)
+	})
+}
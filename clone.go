@@ -0,0 +1,80 @@
+package timewheel
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CloneInto register a copy of every task of the wheel on dst, the time left until the next run,
+// the remaining times, the data and the options are kept, so the copies run with the originals.
+// The job of a copy is the job of the original, or the one given by resolve when it is not nil.
+// The wheel keeps running untouched. dst is started if it is not, since the tasks are added
+// through its wheel goroutine. Every task is tried, the errors are joined.
+func (tw *TimeWheel) CloneInto(dst *TimeWheel, resolve JobResolver) error {
+	if dst == nil || dst == tw {
+		return ErrInvalidParams
+	}
+	type clone struct {
+		t    *task
+		left time.Duration
+	}
+	var clones []clone
+	var errs []error
+	execErr := tw.exec(func() {
+		now := tw.clock.Now()
+		each := func(t *task) {
+			if t.times == 0 {
+				return
+			}
+			job := t.job
+			if resolve != nil {
+				j, err := resolve(t.spec(now))
+				if err == nil && j == nil {
+					err = ErrInvalidParams
+				}
+				if err != nil {
+					errs = append(errs, fmt.Errorf("clone task %v: %w", t.key, err))
+					return
+				}
+				job = wrapJob(j)
+			}
+			c, err := dst.copyTask(t, job)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("clone task %v: %w", t.key, err))
+				return
+			}
+			left := t.next.Sub(now)
+			if t.dep != nil {
+				c.dep = &dependency{key: t.dep.key, firstRun: t.dep.firstRun}
+				left = t.dep.delay
+			}
+			clones = append(clones, clone{t: c, left: left})
+		}
+		tw.eachWaiting(each)
+		tw.eachDependent(each)
+		tw.backend.each(each)
+	})
+	if execErr != nil {
+		for _, c := range clones {
+			dst.dropTask(c.t)
+		}
+		return execErr
+	}
+	dst.Start()
+	for i, c := range clones {
+		c.t.next = dst.clock.Now().Add(c.left)
+		key := c.t.key
+		if err := dst.adopt(c.t); err != nil {
+			errs = append(errs, fmt.Errorf("clone task %v: %w", key, err))
+			dst.dropTask(c.t)
+			if errors.Is(err, ErrWheelStopped) {
+				for _, rest := range clones[i+1:] {
+					dst.dropTask(rest.t)
+				}
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,68 @@
+package timewheel
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCloneInto(t *testing.T) {
+	c1, c2 := newFakeClock(), newFakeClock()
+	src := New(time.Second, 20, WithClock(c1))
+	src.Start()
+	defer src.Stop()
+	var tick int64
+	var mu sync.Mutex
+	fires := make(map[string][]int64)
+	rec := func(name string) Job {
+		return func(TaskData) {
+			mu.Lock()
+			fires[name] = append(fires[name], atomic.LoadInt64(&tick))
+			mu.Unlock()
+		}
+	}
+	src.AddTask(3*time.Second, 3, "near", nil, rec("near"))
+	src.AddTask(25*time.Second, 1, "far", nil, rec("far"))
+	src.AddTask(time.Hour, 1, "later", nil, rec("later"))
+	settle(src)
+	for i := 0; i < 2; i++ {
+		atomic.AddInt64(&tick, 1)
+		c1.Tick(time.Second)
+		settle(src)
+	}
+
+	// the copies keep the time left, not the whole interval
+	dst := New(time.Second, 60, WithClock(c2))
+	defer dst.Stop()
+	err := src.CloneInto(dst, func(spec TaskSpec) (Job, error) {
+		return rec("copy " + spec.Key.(string)), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.Len() != 3 || dst.Len() != 3 {
+		t.Fatalf("%d tasks on the source, %d on the clone", src.Len(), dst.Len())
+	}
+	if err := src.CloneInto(dst, nil); err == nil {
+		t.Fatal("duplicate keys cloned")
+	}
+	for i := 0; i < 30; i++ {
+		atomic.AddInt64(&tick, 1)
+		c1.Tick(time.Second)
+		c2.Tick(time.Second)
+		settle(src)
+		settle(dst)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, name := range []string{"near", "far"} {
+		if a, b := fires[name], fires["copy "+name]; len(a) == 0 || !reflect.DeepEqual(a, b) {
+			t.Fatalf("%s fired on ticks %v, its copy on %v", name, a, b)
+		}
+	}
+	if len(fires["later"])+len(fires["copy later"]) != 0 || !dst.HasTask("later") {
+		t.Fatal("the far future task fired or is not cloned")
+	}
+}
@@ -0,0 +1,36 @@
+package timewheel
+
+import "io"
+
+var _ io.Closer = (*TimeWheel)(nil)
+
+// Close stop the wheel like Stop, wait for the wheel goroutine to exit and drop the tasks, the slots and
+// the hook goroutine so the memory can be reclaimed, the runs in flight go on. The later calls return
+// ErrWheelStopped, closing a closed wheel does nothing.
+func (tw *TimeWheel) Close() error {
+	tw.Stop()
+	<-tw.loopDone
+	tw.closeOnce.Do(tw.clear)
+	return nil
+}
+
+// drop every reference to the tasks, only called once the wheel goroutine exited
+func (tw *TimeWheel) clear() {
+	for {
+		select {
+		case <-tw.addTaskChannel:
+			continue
+		default:
+		}
+		break
+	}
+	tw.taskRecord.clear()
+	tw.tagIndex.clear()
+	tw.backend.clear()
+	tw.deferred, tw.blackedOut, tw.carry = nil, nil, nil
+	tw.dependents = nil
+	tw.expiring = nil
+	tw.unacked = nil
+	tw.gatedTasks = nil
+	tw.caughtUp = nil
+}
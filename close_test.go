@@ -0,0 +1,72 @@
+package timewheel
+
+import (
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// real clock counting the tickers not stopped yet
+type liveTickerClock struct {
+	live int64
+}
+
+type liveTicker struct {
+	*time.Ticker
+	c       *liveTickerClock
+	stopped int32
+}
+
+func (c *liveTickerClock) Now() time.Time { return time.Now() }
+
+func (c *liveTickerClock) NewTicker(d time.Duration) Ticker {
+	atomic.AddInt64(&c.live, 1)
+	return &liveTicker{Ticker: time.NewTicker(d), c: c}
+}
+
+func (t *liveTicker) C() <-chan time.Time { return t.Ticker.C }
+
+func (t *liveTicker) Stop() {
+	t.Ticker.Stop()
+	if atomic.CompareAndSwapInt32(&t.stopped, 0, 1) {
+		atomic.AddInt64(&t.c.live, -1)
+	}
+}
+
+func TestCloseNoLeak(t *testing.T) {
+	c := &liveTickerClock{}
+	before := runtime.NumGoroutine()
+	for i := 0; i < 1000; i++ {
+		tw := New(time.Millisecond, 16, WithClock(c), WithHooks(Hooks{OnTaskAdded: func(interface{}, TaskInfo) {}}), WithWorkers(2, 4, Block))
+		if i%2 == 0 {
+			tw.Start()
+			tw.AddTask(time.Hour, 1, i, nil, func(TaskData) {})
+			tw.AddTask(time.Millisecond, 1, "x", nil, func(TaskData) {})
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		// closing twice is safe
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := tw.AddTask(time.Second, 1, "k", nil, func(TaskData) {}); err != ErrWheelStopped {
+			t.Fatalf("add to a closed wheel: %v", err)
+		}
+		if tw.HasTask(i) || tw.RemoveTask(i) != ErrWheelStopped || tw.Len() != 0 {
+			t.Fatal("the closed wheel keeps its tasks")
+		}
+	}
+	if n := atomic.LoadInt64(&c.live); n != 0 {
+		t.Fatalf("%d tickers not stopped", n)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		buf := make([]byte, 1<<16)
+		t.Fatalf("%d goroutines before, %d after\n%s", before, n, buf[:runtime.Stack(buf, true)])
+	}
+}
@@ -0,0 +1,205 @@
+package timewheel
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CoalescedGroup one shot tasks sharing a delay and a handler, e.g. the idle timeouts of connections.
+// A group keeps the keys added during a tick in a single batch run by one task of the wheel, so a key
+// costs a map entry and a slice element instead of a task. RemoveTask and HasTask of the wheel find
+// the keys of the groups, the keys are not counted by Len nor visited by Range.
+type CoalescedGroup struct {
+	tw      *TimeWheel
+	delay   time.Duration
+	handler func(key interface{})
+	batch   func(keys []interface{})
+	open    *keyBatch // batch of the current tick
+}
+
+// keys added to a group during a tick, a removed key is set to nil
+type keyBatch struct {
+	group *CoalescedGroup
+	tick  int64
+	keys  []interface{}
+	live  int
+}
+
+// position of a key in its batch
+type keyRef struct {
+	b *keyBatch
+	i int
+}
+
+// keys of the coalesced groups of a wheel
+type coalescedIndex struct {
+	mu   sync.Mutex
+	refs map[interface{}]keyRef
+}
+
+// Coalesce create a group calling handler with every key once delay passed after its add
+func (tw *TimeWheel) Coalesce(delay time.Duration, handler func(key interface{})) *CoalescedGroup {
+	if handler == nil {
+		return nil
+	}
+	return tw.newGroup(delay, handler, nil)
+}
+
+// CoalesceBatch create a group calling handler once per batch with the keys of the batch, the keys
+// added during the same tick expire together
+func (tw *TimeWheel) CoalesceBatch(delay time.Duration, handler func(keys []interface{})) *CoalescedGroup {
+	if handler == nil {
+		return nil
+	}
+	return tw.newGroup(delay, nil, handler)
+}
+
+func (tw *TimeWheel) newGroup(delay time.Duration, handler func(key interface{}), batch func(keys []interface{})) *CoalescedGroup {
+	if delay <= 0 || delay > tw.maxDelay() {
+		return nil
+	}
+	tw.coalesced.mu.Lock()
+	if tw.coalesced.refs == nil {
+		tw.coalesced.refs = make(map[interface{}]keyRef)
+	}
+	tw.coalesced.mu.Unlock()
+	atomic.StoreInt32(&tw.coalescing, 1)
+	return &CoalescedGroup{tw: tw, delay: delay, handler: handler, batch: batch}
+}
+
+// Add add the key to the group, it fails with ErrDuplicateKey if the key is taken by a task or a group
+func (g *CoalescedGroup) Add(key interface{}) error {
+	if key == nil {
+		return ErrInvalidKey
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	tw := g.tw
+	if tw.isStopped() {
+		return ErrWheelStopped
+	}
+	if _, ok := tw.taskRecord.Load(key); ok {
+		return ErrDuplicateKey
+	}
+	tick := atomic.LoadInt64(&tw.tickNum)
+	x := &tw.coalesced
+	x.mu.Lock()
+	if _, ok := x.refs[key]; ok {
+		x.mu.Unlock()
+		return ErrDuplicateKey
+	}
+	b := g.open
+	fresh := b == nil || b.tick != tick
+	if fresh {
+		b = &keyBatch{group: g, tick: tick}
+		g.open = b
+	}
+	x.refs[key] = keyRef{b: b, i: len(b.keys)}
+	b.keys = append(b.keys, key)
+	b.live++
+	x.mu.Unlock()
+	if !fresh {
+		return nil
+	}
+	err := tw.AddTask(g.delay, 1, anonKey(atomic.AddUint64(&anonSeq, 1)), nil, func(TaskData) {
+		tw.runBatch(b)
+	})
+	if err != nil {
+		// the keys joining the batch meanwhile share its fate
+		x.mu.Lock()
+		if g.open == b {
+			g.open = nil
+		}
+		for _, k := range b.keys {
+			if k != nil {
+				delete(x.refs, k)
+			}
+		}
+		b.keys, b.live = nil, 0
+		x.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// hand the keys of the batch left to the handler of its group
+func (tw *TimeWheel) runBatch(b *keyBatch) {
+	g := b.group
+	x := &tw.coalesced
+	x.mu.Lock()
+	if g.open == b {
+		g.open = nil
+	}
+	keys := make([]interface{}, 0, b.live)
+	for _, k := range b.keys {
+		if k != nil {
+			delete(x.refs, k)
+			keys = append(keys, k)
+		}
+	}
+	b.keys, b.live = nil, 0
+	x.mu.Unlock()
+	if len(keys) == 0 {
+		return
+	}
+	if g.batch != nil {
+		g.batch(keys)
+		return
+	}
+	for _, k := range keys {
+		tw.callCoalesced(g, k)
+	}
+}
+
+// call the handler of the key, a panic is recovered so the other keys of the batch still run
+func (tw *TimeWheel) callCoalesced(g *CoalescedGroup, key interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			tw.logger.Printf("timewheel: coalesced handler panic recovered, key: %v, panic: %v", key, r)
+		}
+	}()
+	g.handler(key)
+}
+
+// report whether a coalesced group was created, the groups are looked up only then
+func (tw *TimeWheel) isCoalescing() bool {
+	return atomic.LoadInt32(&tw.coalescing) == 1
+}
+
+// drop the key from its batch, report whether a group held it
+func (x *coalescedIndex) remove(key interface{}) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	ref, ok := x.refs[key]
+	if !ok {
+		return false
+	}
+	delete(x.refs, key)
+	ref.b.keys[ref.i] = nil
+	ref.b.live--
+	return true
+}
+
+// report whether a group holds the key
+func (x *coalescedIndex) has(key interface{}) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	_, ok := x.refs[key]
+	return ok
+}
+
+// Len get the number of keys of the group waiting for their delay
+func (g *CoalescedGroup) Len() int {
+	n := 0
+	x := &g.tw.coalesced
+	x.mu.Lock()
+	for _, ref := range x.refs {
+		if ref.b.group == g {
+			n++
+		}
+	}
+	x.mu.Unlock()
+	return n
+}
@@ -0,0 +1,107 @@
+package timewheel
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCoalesced(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 8, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var mu sync.Mutex
+	var got []int
+	g := tw.Coalesce(2*time.Second, func(key interface{}) {
+		mu.Lock()
+		got = append(got, key.(int))
+		mu.Unlock()
+	})
+	var batches [][]interface{}
+	gb := tw.CoalesceBatch(2*time.Second, func(keys []interface{}) {
+		mu.Lock()
+		batches = append(batches, keys)
+		mu.Unlock()
+	})
+	for i := 0; i < 10; i++ {
+		if err := g.Add(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gb.Add("x")
+	gb.Add("y")
+	if g.Add(3) != ErrDuplicateKey || tw.AddTask(time.Second, 1, 4, nil, func(TaskData) {}) != ErrDuplicateKey {
+		t.Fatal("dup")
+	}
+	// removed from the middle of the batch
+	if tw.RemoveTask(5) != nil || tw.RemoveTask(0) != nil || tw.RemoveTask(5) != ErrTaskNotFound || tw.HasTask(5) || !tw.HasTask(6) {
+		t.Fatal("remove")
+	}
+	if g.Len() != 8 || gb.Len() != 2 {
+		t.Fatal(g.Len(), gb.Len())
+	}
+	if tw.Len() != 2 {
+		t.Fatal("tasks", tw.Len())
+	}
+	for i := 0; i < 4; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	// the handlers run on the job goroutines
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		mu.Lock()
+		n := len(got) + len(batches)
+		mu.Unlock()
+		if n >= 9 {
+			break
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	sort.Ints(got)
+	if fmt.Sprint(got) != "[1 2 3 4 6 7 8 9]" || len(batches) != 1 || len(batches[0]) != 2 {
+		t.Fatal(got, batches)
+	}
+	if tw.HasTask(6) || g.Len() != 0 {
+		t.Fatal("left")
+	}
+}
+
+func heapUse() uint64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+// heap per key of 1M and 3M idle timeouts stored as tasks or coalesced: go test -bench CoalescedMemory
+func BenchmarkCoalescedMemory(b *testing.B) {
+	for _, n := range []int{1000000, 3000000} {
+		for _, mode := range []string{"tasks", "coalesced"} {
+			b.Run(fmt.Sprint(mode, "/", n), func(b *testing.B) {
+				for it := 0; it < b.N; it++ {
+					tw := New(10*time.Millisecond, 1024, WithAddBuffer(4096))
+					tw.Start()
+					before := heapUse()
+					job := func(TaskData) {}
+					g := tw.Coalesce(time.Hour, func(interface{}) {})
+					for i := 0; i < n; i++ {
+						if mode == "tasks" {
+							tw.AddTask(time.Hour, 1, i, nil, job)
+						} else {
+							g.Add(i)
+						}
+					}
+					tw.exec(func() {})
+					after := heapUse()
+					b.ReportMetric(float64(after-before)/float64(n), "B/key")
+					tw.Stop()
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRemoveTaskUnlinks(t *testing.T) {
+	tw := New(time.Second, 4)
+	tw.Start()
+	defer tw.Stop()
+	for i := 0; i < 10; i++ {
+		tw.AddTask(time.Second, -1, i, nil, func(TaskData) {})
+	}
+	total := func() int {
+		n := 0
+		for _, c := range tw.SlotLengths() {
+			n += c
+		}
+		return n
+	}
+	if n := total(); n != 10 {
+		t.Fatal(n)
+	}
+	for i := 0; i < 5; i++ {
+		if err := tw.RemoveTask(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// removed from the slots right away, not when the slot is scanned
+	if n := total(); n != 5 || tw.Len() != 5 {
+		t.Fatal(n, tw.Len())
+	}
+	if err := tw.RemoveTask(0); err != ErrTaskNotFound {
+		t.Fatal(err)
+	}
+	if err := tw.UpdateTask(0, time.Second, nil); err != ErrTaskNotFound {
+		t.Fatal(err)
+	}
+}
+
+// the callers mutate the tasks while the wheel ticks, run with -race
+func TestConcurrentMutations(t *testing.T) {
+	tw := New(time.Millisecond, 8)
+	tw.Start()
+	defer tw.Stop()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				key := g*1000 + i%10
+				switch i % 4 {
+				case 0:
+					tw.AddTask(time.Duration(1+i%3)*time.Millisecond, -1, key, TaskData{"i": i}, func(TaskData) {})
+				case 1:
+					tw.UpdateTask(key, 2*time.Millisecond, TaskData{"i": i})
+				case 2:
+					tw.HasTask(key)
+				case 3:
+					tw.RemoveTask(key)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	settle(tw)
+	checkInvariants(t, tw)
+	n := 0
+	tw.Range(func(key interface{}, info TaskInfo) bool {
+		n++
+		return true
+	})
+	if n != tw.Len() {
+		t.Fatal(n, tw.Len())
+	}
+}
@@ -0,0 +1,65 @@
+package timewheel
+
+import "time"
+
+// GetTaskData get a copy of the current data of the task, taken on the wheel goroutine
+func (tw *TimeWheel) GetTaskData(key interface{}) (TaskData, error) {
+	if key == nil || !keyComparable(key) {
+		return nil, ErrTaskNotFound
+	}
+	var data TaskData
+	found := false
+	err := tw.exec(func() {
+		task, ok := tw.taskRecord.Load(key)
+		if !ok {
+			return
+		}
+		data, found = copyTaskData(task.taskData), true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrTaskNotFound
+	}
+	return data, nil
+}
+
+// SetTaskData replace the data of the task, the schedule is untouched. The data is copied like in AddTask.
+// It can be called from the job of the task, the runs dispatched after it returns see the new data.
+func (tw *TimeWheel) SetTaskData(key interface{}, data TaskData) error {
+	if key == nil || !keyComparable(key) {
+		return ErrTaskNotFound
+	}
+	data = tw.ownData(data)
+	var (
+		interval time.Duration
+		named    bool
+		found    bool
+	)
+	err := tw.exec(func() {
+		task, ok := tw.taskRecord.Load(key)
+		if !ok {
+			return
+		}
+		task.taskData = data
+		interval, named, found = task.interval, task.jobName != "", true
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		if tw.store != nil {
+			// the task may be stored but not loaded in the wheel yet
+			return tw.storeSetData(key, data)
+		}
+		return ErrTaskNotFound
+	}
+	if named {
+		tw.walAppend(walRecord{Op: walUpdate, Spec: TaskSpec{Key: key, Interval: interval, Data: copyTaskData(data)}})
+		if tw.store != nil {
+			return tw.storeSetData(key, data)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package timewheel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetTaskData(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(time.Second, 1, "k", TaskData{"text": "remind me"}, func(TaskData) {})
+	data, err := tw.GetTaskData("k")
+	if err != nil || data["text"] != "remind me" {
+		t.Fatalf("data %v: %v", data, err)
+	}
+	// the copy is detached from the task
+	data["text"] = "changed"
+	if data, _ := tw.GetTaskData("k"); data["text"] != "remind me" {
+		t.Fatalf("data %v", data)
+	}
+	if err := tw.UpdateTask("k", time.Second, TaskData{"text": "updated"}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := tw.GetTaskData("k"); data["text"] != "updated" {
+		t.Fatalf("data %v", data)
+	}
+	if _, err := tw.GetTaskData("unknown"); err != ErrTaskNotFound {
+		t.Fatalf("unknown key: %v", err)
+	}
+	// exhausted
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	if _, err := tw.GetTaskData("k"); err != ErrTaskNotFound {
+		t.Fatalf("exhausted task: %v", err)
+	}
+	if _, err := tw.GetTaskData([]int{1}); err != ErrTaskNotFound {
+		t.Fatalf("slice key: %v", err)
+	}
+}
+
+func TestSetTaskData(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	seen := make(chan interface{}, 4)
+	tw.AddTask(2*time.Second, -1, "k", TaskData{"v": 1}, func(data TaskData) {
+		seen <- data["v"]
+		if data["v"] == 2 {
+			// from inside the job
+			if err := tw.SetTaskData("k", TaskData{"v": 3}); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+	settle(tw)
+	next := func() interface{} {
+		t.Helper()
+		c.Tick(time.Second)
+		c.Tick(time.Second)
+		settle(tw)
+		select {
+		case v := <-seen:
+			return v
+		case <-time.After(5 * time.Second):
+			t.Fatal("no run")
+		}
+		return nil
+	}
+	c.Tick(time.Second)
+	if v := next(); v != 1 {
+		t.Fatalf("first run saw %v", v)
+	}
+	data := TaskData{"v": 2}
+	if err := tw.SetTaskData("k", data); err != nil {
+		t.Fatal(err)
+	}
+	data["v"] = "caller's write"
+	// the schedule is untouched, the next run comes 2s after the previous one
+	if v := next(); v != 2 {
+		t.Fatalf("second run saw %v", v)
+	}
+	if v := next(); v != 3 {
+		t.Fatalf("third run saw %v", v)
+	}
+	if len(seen) != 0 {
+		t.Fatal("extra runs")
+	}
+	if err := tw.SetTaskData("unknown", nil); err != ErrTaskNotFound {
+		t.Fatalf("unknown key: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package timewheel
+
+import "context"
+
+// DeadLetter a task taken off the wheel after failing too many times in a row, see WithDeadLetter
+type DeadLetter struct {
+	Spec      TaskSpec // the task when it was parked
+	Failures  int64    // consecutive failed runs
+	LastError error    // error of the last run, a *PanicError if the job panicked
+	job       JobCtx
+}
+
+// DeadLetterHandler receive the parked tasks, it is called on a goroutine of its own once the task is off the wheel
+type DeadLetterHandler func(d DeadLetter)
+
+// WithDeadLetter take a task off the wheel once threshold consecutive runs failed and hand it to handler,
+// the task can be added again with Requeue
+func WithDeadLetter(threshold int, handler DeadLetterHandler) Option {
+	return func(tw *TimeWheel) {
+		if threshold > 0 && handler != nil {
+			tw.deadThreshold = int64(threshold)
+			tw.deadHandler = handler
+		}
+	}
+}
+
+// Requeue add the parked task again with its job, interval, remaining times and data
+func (tw *TimeWheel) Requeue(d DeadLetter) error {
+	if d.job == nil {
+		return ErrInvalidParams
+	}
+	spec := d.Spec
+	task, err := tw.newTask(spec.Interval, spec.Times, spec.Key, spec.Data, d.job)
+	if err != nil {
+		return err
+	}
+	task.tags = append([]string(nil), spec.Tags...)
+	task.priority = spec.Priority
+	if spec.JobName != "" {
+		task.jobName = spec.JobName
+		return tw.submitNamed(task)
+	}
+	return tw.submit(context.Background(), task)
+}
+
+// park the task once its consecutive failures reach the threshold, called on the job goroutine
+func (tw *TimeWheel) checkDeadLetter(task *task, failures int64, err error) {
+	if tw.deadThreshold == 0 || failures != tw.deadThreshold {
+		return
+	}
+	tw.execLater(task, func() {
+		// the task may be removed or exhausted meanwhile
+		if t, ok := tw.taskRecord.Load(task.key); !ok || t != task {
+			return
+		}
+		d := DeadLetter{Spec: task.spec(tw.clock.Now()), Failures: failures, LastError: err, job: task.job}
+		tw.unregister(task)
+		go tw.deadLetter(d)
+	})
+}
+
+// hand the parked task to the dead letter handler
+func (tw *TimeWheel) deadLetter(d DeadLetter) {
+	tw.logger.Printf("timewheel: task parked after %d failures, key: %v, err: %v", d.Failures, d.Spec.Key, d.LastError)
+	if d.Spec.JobName != "" {
+		tw.forgetNamed(d.Spec.Key)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tw.logger.Printf("timewheel: dead letter handler panic recovered, key: %v, panic: %v", d.Spec.Key, r)
+		}
+	}()
+	tw.deadHandler(d)
+}
+
+// delete the named task from the store and the log
+func (tw *TimeWheel) forgetNamed(key interface{}) {
+	if tw.store != nil {
+		if _, err := tw.store.Delete(key, 0); err != nil {
+			tw.logger.Printf("timewheel: store delete failed, key: %v, err: %v", key, err)
+		}
+	}
+	tw.walAppend(walRecord{Op: walRemove, Spec: TaskSpec{Key: key}})
+}
@@ -0,0 +1,74 @@
+package timewheel
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDeadLetter(t *testing.T) {
+	c := newFakeClock()
+	var mu sync.Mutex
+	var got []DeadLetter
+	var tw *TimeWheel
+	registered := false
+	tw = New(time.Second, 10, WithClock(c), WithDeadLetter(3, func(d DeadLetter) {
+		mu.Lock()
+		got = append(got, d)
+		registered = tw.HasTask(d.Spec.Key)
+		mu.Unlock()
+	}))
+	tw.Start()
+	defer tw.Stop()
+	var runs int64
+	tw.AddTask(time.Second, -1, "k", TaskData{"a": 1}, func(TaskData) {
+		atomic.AddInt64(&runs, 1)
+		panic("boom")
+	})
+	settle(tw)
+	for i := 0; i < 8; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	if len(got) != 1 || registered || tw.HasTask("k") || got[0].Failures != 3 || got[0].Spec.Data["a"] != 1 {
+		t.Fatal(got, registered, tw.HasTask("k"))
+	}
+	if _, ok := got[0].LastError.(*PanicError); !ok {
+		t.Fatal(got[0].LastError)
+	}
+	d := got[0]
+	mu.Unlock()
+	if n := atomic.LoadInt64(&runs); n != 3 {
+		t.Fatal(n)
+	}
+	err := tw.Requeue(d)
+	settle(tw)
+	if err != nil || !tw.HasTask("k") {
+		t.Fatal(err)
+	}
+}
+
+func TestDeadLetterBlockingWorkers(t *testing.T) {
+	parked := make(chan DeadLetter, 4)
+	tw := New(10*time.Millisecond, 16, WithWorkers(1, 0, Block), WithDeadLetter(2, func(d DeadLetter) {
+		parked <- d
+	}))
+	tw.Start()
+	defer tw.Stop()
+	for i := 0; i < 4; i++ {
+		tw.AddTask(10*time.Millisecond, -1, i, nil, func(TaskData) { panic("boom") })
+	}
+	for i := 0; i < 4; i++ {
+		select {
+		case <-parked:
+		case <-time.After(time.Second):
+			t.Fatal("wheel blocked")
+		}
+	}
+	if tw.Len() != 0 {
+		t.Fatal(tw.Len())
+	}
+}
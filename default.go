@@ -0,0 +1,84 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// params of the default wheel
+const (
+	DefaultInterval = 100 * time.Millisecond
+	DefaultSlotNum  = 600
+)
+
+// default wheel, replaced by StopDefault
+type defaultState struct {
+	once    sync.Once
+	tw      *TimeWheel
+	started bool
+}
+
+var (
+	defaultMu  sync.Mutex
+	defaultCur = &defaultState{}
+)
+
+// Default get the default wheel, it is created with DefaultInterval and DefaultSlotNum
+// or taken from SetDefault, and started on first use
+func Default() *TimeWheel {
+	defaultMu.Lock()
+	st := defaultCur
+	defaultMu.Unlock()
+	st.once.Do(func() {
+		defaultMu.Lock()
+		if st.tw == nil {
+			st.tw = New(DefaultInterval, DefaultSlotNum)
+		}
+		st.started = true
+		defaultMu.Unlock()
+		st.tw.Start()
+	})
+	return st.tw
+}
+
+// SetDefault replace the default wheel before its first use, tw must not be started.
+// An error is returned once the default wheel is in use.
+func SetDefault(tw *TimeWheel) error {
+	if tw == nil {
+		return fmt.Errorf("%w, illegal time wheel", ErrInvalidParams)
+	}
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultCur.started {
+		return ErrDefaultInUse
+	}
+	defaultCur.tw = tw
+	return nil
+}
+
+// StopDefault stop the default wheel, the next use starts a new one. Meant for tests.
+func StopDefault() {
+	defaultMu.Lock()
+	st := defaultCur
+	defaultCur = &defaultState{}
+	defaultMu.Unlock()
+	if st.started {
+		st.tw.Stop()
+	}
+}
+
+// AddTask add new task to the default wheel
+func AddTask(interval time.Duration, times int, key interface{}, data TaskData, job Job) error {
+	return Default().AddTask(interval, times, key, data, job)
+}
+
+// AfterFunc run f once after d on the default wheel, the returned key can be passed to RemoveTask
+func AfterFunc(d time.Duration, f func()) (interface{}, error) {
+	return Default().AfterFunc(d, f)
+}
+
+// RemoveTask remove the task from the default wheel
+func RemoveTask(key interface{}) error {
+	return Default().RemoveTask(key)
+}
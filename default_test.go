@@ -0,0 +1,79 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDefaultConcurrentFirstUse(t *testing.T) {
+	StopDefault()
+	defer StopDefault()
+	var wg sync.WaitGroup
+	wheels := make([]*TimeWheel, 16)
+	for i := range wheels {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			wheels[i] = Default()
+			if err := AddTask(time.Hour, -1, i, nil, func(TaskData) {}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, tw := range wheels {
+		if tw != wheels[0] {
+			t.Fatal("several default wheels")
+		}
+	}
+	// AddTask returns once the loop took the task, wait for it to be registered
+	settle(Default())
+	if Default().Len() != len(wheels) {
+		t.Fatalf("len %d", Default().Len())
+	}
+	if err := RemoveTask(3); err != nil || Default().HasTask(3) {
+		t.Fatalf("remove: %v", err)
+	}
+
+	fired := make(chan struct{})
+	if _, err := AfterFunc(10*time.Millisecond, func() { close(fired) }); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-fired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("AfterFunc did not fire")
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	StopDefault()
+	defer StopDefault()
+	if SetDefault(nil) == nil {
+		t.Fatal("nil wheel accepted")
+	}
+	tw := New(time.Second, 10)
+	if err := SetDefault(tw); err != nil {
+		t.Fatal(err)
+	}
+	if Default() != tw {
+		t.Fatal("SetDefault ignored")
+	}
+	// the default wheel is in use, it cannot be replaced any more
+	if err := SetDefault(New(time.Second, 10)); err == nil {
+		t.Fatal("default replaced while in use")
+	}
+	if Default() != tw {
+		t.Fatal("default replaced while in use")
+	}
+
+	// StopDefault stops the wheel, the next use starts a new one
+	StopDefault()
+	if !tw.isStopped() {
+		t.Fatal("default wheel not stopped")
+	}
+	if next := Default(); next == tw || next.isStopped() {
+		t.Fatal("no new default wheel")
+	}
+}
@@ -0,0 +1,129 @@
+package timewheel
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Entry an expired entry of a DelayQueue
+type Entry struct {
+	Key  interface{}
+	Data TaskData
+	Due  time.Time // time the entry was offered for
+}
+
+// DelayQueue a wheel handing the expired entries to the consumers instead of running jobs. The wheel
+// goroutine pushes the entries due at a tick into a bounded buffer in the order they were offered,
+// the policy decides what happens when the consumers lag: Block stops the ticks until there is room,
+// DropNewest drops the expiring entry and DropOldest the one waiting longest in the buffer.
+type DelayQueue struct {
+	tw      *TimeWheel
+	out     chan Entry
+	policy  DropPolicy
+	dropped int64 // accessed atomically
+}
+
+// NewDelayQueue create a queue on a wheel of the given interval and slots, buffer is the room for the
+// expired entries not consumed yet. opts configure the wheel, the job related ones have no effect.
+func NewDelayQueue(interval time.Duration, slotNum int, buffer int, policy DropPolicy, opts ...Option) *DelayQueue {
+	if buffer < 0 || policy == RunInline {
+		return nil
+	}
+	opts = append(opts, WithStableOrder(), func(tw *TimeWheel) {
+		tw.pull = true
+	})
+	tw := New(interval, slotNum, opts...)
+	if tw == nil {
+		return nil
+	}
+	return &DelayQueue{tw: tw, out: make(chan Entry, buffer), policy: policy}
+}
+
+// Start start the wheel of the queue
+func (q *DelayQueue) Start() {
+	q.tw.Start()
+}
+
+// Stop stop the wheel of the queue, the entries in the buffer can still be consumed
+func (q *DelayQueue) Stop() {
+	q.tw.Stop()
+}
+
+// Offer add an entry expiring after delay, the key must not be pending already
+func (q *DelayQueue) Offer(key interface{}, data TaskData, delay time.Duration) error {
+	due := q.tw.clock.Now().Add(delay)
+	return q.tw.AddTask(delay, 1, key, data, func(data TaskData) {
+		q.push(Entry{Key: key, Data: data, Due: due})
+	})
+}
+
+// Remove drop the pending entry before it expires
+func (q *DelayQueue) Remove(key interface{}) error {
+	return q.tw.RemoveTask(key)
+}
+
+// Len get the number of pending entries, the expired ones excepted
+func (q *DelayQueue) Len() int {
+	return q.tw.Len()
+}
+
+// Expired get the channel of the expired entries
+func (q *DelayQueue) Expired() <-chan Entry {
+	return q.out
+}
+
+// Poll wait for an expired entry. It fails with the error of ctx once it is done and with
+// ErrWheelStopped once the queue is stopped and its buffer is empty.
+func (q *DelayQueue) Poll(ctx context.Context) (key interface{}, data TaskData, err error) {
+	select {
+	case e := <-q.out:
+		return e.Key, e.Data, nil
+	default:
+	}
+	select {
+	case e := <-q.out:
+		return e.Key, e.Data, nil
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	case <-q.tw.stopChannel:
+		return nil, nil, ErrWheelStopped
+	}
+}
+
+// Dropped get the number of expired entries dropped by the policy
+func (q *DelayQueue) Dropped() int64 {
+	return atomic.LoadInt64(&q.dropped)
+}
+
+// hand the expired entry to the consumers according to the policy, called on the wheel goroutine
+func (q *DelayQueue) push(e Entry) {
+	select {
+	case q.out <- e:
+		return
+	default:
+	}
+	switch q.policy {
+	case DropNewest:
+		atomic.AddInt64(&q.dropped, 1)
+	case DropOldest:
+		for {
+			select {
+			case q.out <- e:
+				return
+			default:
+			}
+			select {
+			case <-q.out:
+				atomic.AddInt64(&q.dropped, 1)
+			default:
+			}
+		}
+	default:
+		select {
+		case q.out <- e:
+		case <-q.tw.stopChannel:
+			atomic.AddInt64(&q.dropped, 1)
+		}
+	}
+}
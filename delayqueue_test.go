@@ -0,0 +1,85 @@
+package timewheel
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDelayQueueOrder(t *testing.T) {
+	c := newFakeClock()
+	q := NewDelayQueue(time.Second, 8, 16, Block, WithClock(c))
+	q.Start()
+	defer q.Stop()
+	for i := 0; i < 10; i++ {
+		if err := q.Offer(fmt.Sprint("k", i), TaskData{"i": i}, 2*time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 4; i++ {
+		c.Tick(time.Second)
+	}
+	settle(q.tw)
+	for i := 0; i < 10; i++ {
+		key, data, err := q.Poll(context.Background())
+		if err != nil || key != fmt.Sprint("k", i) || data["i"] != i {
+			t.Fatal(i, key, data, err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := q.Poll(ctx); err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+}
+
+func TestDelayQueueSlowConsumer(t *testing.T) {
+	for _, policy := range []DropPolicy{Block, DropOldest, DropNewest} {
+		c := newFakeClock()
+		q := NewDelayQueue(time.Second, 8, 2, policy, WithClock(c))
+		q.Start()
+		for i := 0; i < 5; i++ {
+			q.Offer(i, nil, time.Second)
+		}
+		done := make(chan struct{})
+		go func() {
+			c.Tick(time.Second)
+			c.Tick(time.Second)
+			close(done)
+		}()
+		var got []interface{}
+		if policy == Block {
+			for i := 0; i < 5; i++ {
+				e := <-q.Expired()
+				got = append(got, e.Key)
+				time.Sleep(5 * time.Millisecond)
+			}
+			<-done
+		} else {
+			<-done
+			settle(q.tw)
+			for len(q.Expired()) > 0 {
+				got = append(got, (<-q.Expired()).Key)
+			}
+		}
+		switch policy {
+		case Block:
+			if len(got) != 5 || q.Dropped() != 0 || got[4] != 4 {
+				t.Fatal(got)
+			}
+		case DropOldest:
+			if len(got) != 2 || got[0] != 3 || got[1] != 4 || q.Dropped() != 3 {
+				t.Fatal(got)
+			}
+		case DropNewest:
+			if len(got) != 2 || got[0] != 0 || got[1] != 1 || q.Dropped() != 3 {
+				t.Fatal(got)
+			}
+		}
+		q.Stop()
+		if _, _, err := q.Poll(context.Background()); err != ErrWheelStopped {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// PrerequisitePolicy decide what happens to the waiting dependents of a removed task, see DependsOn
+type PrerequisitePolicy int
+
+const (
+	// PrerequisiteRelease start the countdown of the dependents as if the prerequisite completed
+	PrerequisiteRelease PrerequisitePolicy = iota
+	// PrerequisiteCancel remove the dependents, and their own dependents in turn
+	PrerequisiteCancel
+)
+
+// WithPrerequisitePolicy set what happens to the waiting dependents of a task removed by RemoveTask
+// or its TTL, default is PrerequisiteRelease
+func WithPrerequisitePolicy(p PrerequisitePolicy) Option {
+	return func(tw *TimeWheel) {
+		tw.prereqPolicy = p
+	}
+}
+
+// DependsOn register the task in a waiting state until the task under key completed, its delay
+// counts from then. The final run of the prerequisite releases it, or the first run when firstRun is set,
+// a run completes when its job returned. The prerequisite may be added later, a dependency cycle is
+// rejected with ErrInvalidParams when the task is added.
+func DependsOn(key interface{}, firstRun bool) TaskOption {
+	return func(t *task) {
+		if key != nil {
+			t.dep = &dependency{key: key, firstRun: firstRun}
+		}
+	}
+}
+
+// prerequisite of a waiting task
+type dependency struct {
+	key      interface{}
+	firstRun bool
+	delay    time.Duration // delay of the first run once released
+}
+
+// Waiting get the number of tasks waiting for their prerequisite
+func (tw *TimeWheel) Waiting() int {
+	return int(atomic.LoadInt64(&tw.waitingNum))
+}
+
+// add a dependent task, the cycle is checked on the wheel goroutine so the result is consistent
+func (tw *TimeWheel) submitDependent(task *task) error {
+	if !keyComparable(task.dep.key) {
+		tw.dropTask(task)
+		return ErrKeyNotComparable
+	}
+	var err error
+	execErr := tw.exec(func() {
+		if tw.dependsOn(task.dep.key, task.key) {
+			err = fmt.Errorf("%w, dependency cycle through %v", ErrInvalidParams, task.dep.key)
+			return
+		}
+		tw.taskAccepted()
+		tw.addTask(task)
+	})
+	if execErr != nil {
+		tw.dropTask(task)
+		return execErr
+	}
+	if err != nil {
+		tw.dropTask(task)
+	}
+	return err
+}
+
+// report whether the task under key waits, directly or not, for the task under on
+func (tw *TimeWheel) dependsOn(key, on interface{}) bool {
+	for {
+		if key == on {
+			return true
+		}
+		t, ok := tw.taskRecord.Load(key)
+		if !ok || t.dep == nil {
+			return false
+		}
+		key = t.dep.key
+	}
+}
+
+// hold the registered task until its prerequisite completes, the waiting list keeps its own reference
+func (tw *TimeWheel) park(t *task, d time.Duration) {
+	if tw.dependents == nil {
+		tw.dependents = make(map[interface{}][]*task)
+	}
+	t.dep.delay = d
+	t.retain()
+	tw.dependents[t.dep.key] = append(tw.dependents[t.dep.key], t)
+	atomic.AddInt64(&tw.waitingNum, 1)
+}
+
+// take the task out of the waiting list, report whether it was waiting
+func (tw *TimeWheel) unpark(t *task) bool {
+	if t.dep == nil {
+		return false
+	}
+	key := t.dep.key
+	list := tw.dependents[key]
+	for i, w := range list {
+		if w == t {
+			copy(list[i:], list[i+1:])
+			list[len(list)-1] = nil
+			if list = list[:len(list)-1]; len(list) == 0 {
+				delete(tw.dependents, key)
+			} else {
+				tw.dependents[key] = list
+			}
+			t.dep = nil
+			atomic.AddInt64(&tw.waitingNum, -1)
+			t.release()
+			return true
+		}
+	}
+	return false
+}
+
+// take the dependents waiting for key out of the waiting list, keep the ones waiting for the final run
+func (tw *TimeWheel) takeDependents(key interface{}, final bool) []*task {
+	list := tw.dependents[key]
+	if len(list) == 0 {
+		return nil
+	}
+	var taken, kept []*task
+	for _, t := range list {
+		if final || t.dep.firstRun {
+			taken = append(taken, t)
+		} else {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == 0 {
+		delete(tw.dependents, key)
+	} else {
+		tw.dependents[key] = kept
+	}
+	atomic.AddInt64(&tw.waitingNum, -int64(len(taken)))
+	return taken
+}
+
+// a run of the task under key completed, start the countdown of its dependents
+func (tw *TimeWheel) prerequisiteDone(key interface{}, final bool) {
+	now := tw.clock.Now()
+	for _, t := range tw.takeDependents(key, final) {
+		delay := t.dep.delay
+		t.dep = nil
+		if t.times != 0 {
+			switch {
+			case t.schedule != nil:
+				t.next = t.schedule.Next(now)
+			case t.alignPeriod:
+				t.next = alignedAfter(now, t.interval)
+			default:
+				t.next = now.Add(delay)
+			}
+			tw.backend.push(t, tw.delayTicks(t.next.Sub(now)))
+		}
+		t.release()
+	}
+}
+
+// the task under key was removed, apply the prerequisite policy to its dependents
+func (tw *TimeWheel) prerequisiteGone(key interface{}) {
+	if tw.prereqPolicy == PrerequisiteRelease {
+		tw.prerequisiteDone(key, true)
+		return
+	}
+	for _, t := range tw.takeDependents(key, true) {
+		t.dep = nil
+		if cur, ok := tw.taskRecord.Load(t.key); ok && cur == t {
+			tw.emit(tw.hooks.OnTaskRemoved, t)
+			tw.publish(EventRemoved, t)
+			if t.jobName != "" {
+				go tw.forgetNamed(t.key)
+			}
+			tw.unregister(t)
+			atomic.AddInt64(&tw.removedNum, 1)
+			if tw.metrics != nil {
+				tw.metrics.TaskRemoved()
+			}
+			tw.prerequisiteGone(t.key)
+		}
+		t.release()
+	}
+}
+
+// call fn for every task waiting for its prerequisite
+func (tw *TimeWheel) eachDependent(fn func(t *task)) {
+	for _, list := range tw.dependents {
+		for _, t := range list {
+			fn(t)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package timewheel
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// tick n times, letting the released dependents get positioned between the ticks
+func depTicks(c *fakeClock, tw *TimeWheel, n int) {
+	for i := 0; i < n; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+		time.Sleep(2 * time.Millisecond)
+		settle(tw)
+	}
+}
+
+func TestDependsFirstRun(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var a, b int64
+	if err := tw.AddTaskWith(time.Second, 2, "b", nil, func(TaskData) { atomic.AddInt64(&b, 1) }, DependsOn("a", true)); err != nil {
+		t.Fatal(err)
+	}
+	if tw.Waiting() != 1 || !tw.HasTask("b") {
+		t.Fatal("b is not waiting for a")
+	}
+	depTicks(c, tw, 5)
+	if atomic.LoadInt64(&b) != 0 {
+		t.Fatal("b ran before a")
+	}
+	tw.AddTask(3*time.Second, 3, "a", nil, func(TaskData) { atomic.AddInt64(&a, 1) })
+	settle(tw)
+	depTicks(c, tw, 3)
+	if atomic.LoadInt64(&a) != 0 || atomic.LoadInt64(&b) != 0 {
+		t.Fatalf("early runs, a %d b %d", a, b)
+	}
+	depTicks(c, tw, 1)
+	if atomic.LoadInt64(&a) != 1 || tw.Waiting() != 0 {
+		t.Fatalf("a ran %d times, %d waiting", a, tw.Waiting())
+	}
+	depTicks(c, tw, 6)
+	if atomic.LoadInt64(&b) != 2 {
+		t.Fatalf("b ran %d times", b)
+	}
+}
+
+func TestDependsExhaustion(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var a, b int64
+	tw.AddTask(time.Second, 3, "a", nil, func(TaskData) { atomic.AddInt64(&a, 1) })
+	tw.NewTask("b").Every(time.Second).Times(1).DependsOn("a", false).Do(func(TaskData) {
+		if atomic.LoadInt64(&a) != 3 {
+			t.Errorf("b ran after %d runs of a", atomic.LoadInt64(&a))
+		}
+		atomic.AddInt64(&b, 1)
+	}).Submit()
+	specs, _ := tw.Snapshot()
+	found := false
+	for _, s := range specs {
+		if s.Key == "b" && s.DependsOn == "a" && s.Next.IsZero() && s.Delay == time.Second {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("snapshot %+v", specs)
+	}
+	depTicks(c, tw, 12)
+	if atomic.LoadInt64(&b) != 1 || tw.Waiting() != 0 || tw.HasTask("b") {
+		t.Fatalf("b ran %d times", b)
+	}
+}
+
+func TestDependsRemoval(t *testing.T) {
+	for _, p := range []PrerequisitePolicy{PrerequisiteRelease, PrerequisiteCancel} {
+		c := newFakeClock()
+		tw := New(time.Second, 10, WithClock(c), WithPrerequisitePolicy(p))
+		tw.Start()
+		var b, cc int64
+		tw.AddTask(time.Hour, 1, "a", nil, func(TaskData) {})
+		tw.AddTaskWith(time.Second, 1, "b", nil, func(TaskData) { atomic.AddInt64(&b, 1) }, DependsOn("a", false))
+		tw.AddTaskWith(time.Second, 1, "c", nil, func(TaskData) { atomic.AddInt64(&cc, 1) }, DependsOn("b", false))
+		if tw.Waiting() != 2 {
+			t.Fatalf("%d waiting", tw.Waiting())
+		}
+		tw.RemoveTask("a")
+		depTicks(c, tw, 6)
+		if p == PrerequisiteRelease && (atomic.LoadInt64(&b) != 1 || atomic.LoadInt64(&cc) != 1) {
+			t.Fatalf("released: b ran %d times, c %d", b, cc)
+		}
+		if p == PrerequisiteCancel && (atomic.LoadInt64(&b) != 0 || tw.HasTask("b") || tw.HasTask("c")) {
+			t.Fatalf("canceled: b ran %d times, c %d", b, cc)
+		}
+		if tw.Waiting() != 0 || tw.Len() != 0 {
+			t.Fatalf("%d waiting, %d tasks left", tw.Waiting(), tw.Len())
+		}
+		tw.Stop()
+	}
+}
+
+func TestDependsCycle(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	job := func(TaskData) {}
+	if err := tw.AddTaskWith(time.Second, 1, "a", nil, job, DependsOn("b", false)); err != nil {
+		t.Fatal(err)
+	}
+	tw.AddTaskWith(time.Second, 1, "b", nil, job, DependsOn("c", false))
+	err := tw.AddTaskWith(time.Second, 1, "c", nil, job, DependsOn("a", false))
+	if !errors.Is(err, ErrInvalidParams) || tw.HasTask("c") {
+		t.Fatalf("cycle: %v", err)
+	}
+	if err := tw.AddTaskWith(time.Second, 1, "d", nil, job, DependsOn("d", false)); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("self dependency: %v", err)
+	}
+	tw.RemoveTask("a")
+	// the replaced task no longer waits
+	if tw.Waiting() != 1 {
+		t.Fatalf("%d waiting", tw.Waiting())
+	}
+	if err := tw.ReplaceTask("b", time.Second, 1, nil, job); err != nil || tw.Waiting() != 0 {
+		t.Fatalf("replace: %v, %d waiting", err, tw.Waiting())
+	}
+}
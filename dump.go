@@ -0,0 +1,155 @@
+package timewheel
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// number of slots listed with their keys by Dump
+const dumpTopSlots = 5
+
+// consistent view of the slots
+type slotSnapshot struct {
+	pos      int
+	heap     bool
+	counts   []int
+	circles  map[int]int
+	topSlots []int
+	topKeys  [][]interface{}
+}
+
+// take a snapshot on the wheel goroutine
+func (tw *TimeWheel) slotSnapshot() (*slotSnapshot, error) {
+	var snap *slotSnapshot
+	err := tw.exec(func() {
+		snap = &slotSnapshot{
+			pos:     tw.backend.position(),
+			circles: make(map[int]int),
+		}
+		tw.backend.each(func(t *task) {
+			snap.circles[t.circle]++
+		})
+		// slot occupancy only makes sense for the wheel backend
+		wb, ok := wheelOf(tw.backend)
+		if !ok {
+			snap.heap = true
+			snap.counts = []int{tw.backend.len()}
+			return
+		}
+		snap.counts = make([]int, len(wb.slots))
+		for i := range wb.slots {
+			snap.counts[i] = wb.slots[i].Len()
+		}
+		snap.topSlots = topSlots(snap.counts, dumpTopSlots)
+		for _, i := range snap.topSlots {
+			keys := make([]interface{}, 0, snap.counts[i])
+			wb.slots[i].Each(func(e *SlotEntry) {
+				keys = append(keys, e.Key())
+			})
+			snap.topKeys = append(snap.topKeys, keys)
+		}
+	})
+	return snap, err
+}
+
+// indexes of the n most populated non empty slots
+func topSlots(counts []int, n int) []int {
+	idx := make([]int, 0, len(counts))
+	for i, c := range counts {
+		if c > 0 {
+			idx = append(idx, i)
+		}
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		return counts[idx[a]] > counts[idx[b]]
+	})
+	if len(idx) > n {
+		idx = idx[:n]
+	}
+	return idx
+}
+
+// SlotCount task count of a slot
+type SlotCount struct {
+	Slot  int
+	Count int
+}
+
+// SlotLengths get the task count of every slot, taken on the wheel goroutine. Removed tasks are
+// unlinked right away so every counted task is live. nil for the heap backend or a stopped wheel.
+func (tw *TimeWheel) SlotLengths() []int {
+	snap, err := tw.slotSnapshot()
+	if err != nil || snap.heap {
+		return nil
+	}
+	return snap.counts
+}
+
+// HottestSlots get the n most populated non empty slots, most populated first
+func (tw *TimeWheel) HottestSlots(n int) []SlotCount {
+	counts := tw.SlotLengths()
+	if counts == nil || n <= 0 {
+		return nil
+	}
+	top := topSlots(counts, n)
+	hot := make([]SlotCount, len(top))
+	for i, slot := range top {
+		hot[i] = SlotCount{Slot: slot, Count: counts[slot]}
+	}
+	return hot
+}
+
+// Dump write the state of the wheel: current position, task count of the non empty slots,
+// histogram of the circle values and the keys of the most populated slots.
+// The wheel must be started, ErrWheelStopped is returned once it is stopped.
+func (tw *TimeWheel) Dump(w io.Writer) error {
+	snap, err := tw.slotSnapshot()
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	total := 0
+	for _, c := range snap.counts {
+		total += c
+	}
+	if snap.heap {
+		fmt.Fprintf(&b, "position: %d (heap)\n", snap.pos)
+	} else {
+		fmt.Fprintf(&b, "position: %d/%d\n", snap.pos, len(snap.counts))
+	}
+	fmt.Fprintf(&b, "tasks: %d\n", total)
+	b.WriteString("slots:\n")
+	for i, c := range snap.counts {
+		if c > 0 {
+			fmt.Fprintf(&b, "  %d: %d\n", i, c)
+		}
+	}
+	b.WriteString("circles:\n")
+	circles := make([]int, 0, len(snap.circles))
+	for c := range snap.circles {
+		circles = append(circles, c)
+	}
+	sort.Ints(circles)
+	for _, c := range circles {
+		fmt.Fprintf(&b, "  %d: %d\n", c, snap.circles[c])
+	}
+	b.WriteString("top slots:\n")
+	for i, slot := range snap.topSlots {
+		fmt.Fprintf(&b, "  %d: %d %v\n", slot, snap.counts[slot], snap.topKeys[i])
+	}
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// String dump the wheel, meant for small wheels
+func (tw *TimeWheel) String() string {
+	var b strings.Builder
+	if err := tw.Dump(&b); err != nil {
+		return err.Error()
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package timewheel
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestDump(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 8, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	job := func(TaskData) {}
+	tw.AddTask(time.Second, -1, "a", nil, job)
+	tw.AddTask(3*time.Second, -1, "b", nil, job)
+	tw.AddTask(3*time.Second, -1, "c", nil, job)
+	// two circles to go
+	tw.AddTask(19*time.Second, -1, "d", nil, job)
+	c.Tick(time.Second)
+	var buf bytes.Buffer
+	if err := tw.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `position: 1/8
+tasks: 4
+slots:
+  1: 1
+  3: 3
+circles:
+  0: 3
+  2: 1
+top slots:
+  3: 3 [b c d]
+  1: 1 [a]
+`
+	if buf.String() != want {
+		t.Fatalf("dump:\n%s", buf.String())
+	}
+	if got := tw.SlotLengths(); len(got) != 8 || got[1] != 1 || got[3] != 3 {
+		t.Fatal(got)
+	}
+	if hot := tw.HottestSlots(1); len(hot) != 1 || hot[0] != (SlotCount{Slot: 3, Count: 3}) {
+		t.Fatal(hot)
+	}
+	if tw.String() == "" {
+		t.Fatal("string")
+	}
+	tw.Stop()
+	if err := tw.Dump(&buf); err != ErrWheelStopped {
+		t.Fatal(err)
+	}
+}
+
+func TestSlotLengths(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 6, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	job := func(TaskData) {}
+	// 1s: 1 task, 2s: 4 tasks, 4s and 10s share a slot
+	tw.AddTask(time.Second, -1, "a", nil, job)
+	for i := 0; i < 4; i++ {
+		tw.AddTask(2*time.Second, -1, i, nil, job)
+	}
+	tw.AddTask(4*time.Second, -1, "b", nil, job)
+	tw.AddTask(10*time.Second, -1, "c", nil, job)
+	settle(tw)
+	want := []int{0, 1, 4, 0, 2, 0}
+	got := tw.SlotLengths()
+	if len(got) != len(want) {
+		t.Fatalf("slot lengths %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("slot lengths %v, want %v", got, want)
+		}
+	}
+	hot := tw.HottestSlots(10)
+	if len(hot) != 3 || hot[0] != (SlotCount{2, 4}) || hot[1] != (SlotCount{4, 2}) || hot[2] != (SlotCount{1, 1}) {
+		t.Fatalf("hottest slots %v", hot)
+	}
+	if tw.HottestSlots(0) != nil {
+		t.Fatal("hottest slots without n")
+	}
+
+	// a removed task leaves its slot right away
+	tw.RemoveTask(0)
+	tw.RemoveTask("c")
+	if got := tw.SlotLengths(); got[2] != 3 || got[4] != 1 {
+		t.Fatalf("after removal %v", got)
+	}
+
+	heap := New(time.Second, 6, WithBackend(Heap))
+	heap.Start()
+	defer heap.Stop()
+	heap.AddTask(time.Second, -1, "a", nil, job)
+	if heap.SlotLengths() != nil || heap.HottestSlots(1) != nil {
+		t.Fatal("slot lengths of the heap backend")
+	}
+}
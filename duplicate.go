@@ -0,0 +1,30 @@
+package timewheel
+
+// DuplicatePolicy decide what adding a task under a registered key does
+type DuplicatePolicy int
+
+const (
+	// DuplicateError reject the task with the duplicate task key error, the default
+	DuplicateError DuplicatePolicy = iota
+	// DuplicateIgnore accept the call and keep the registered task and its schedule
+	DuplicateIgnore
+	// DuplicateExtend accept the call and move the next run of the registered task
+	// a full interval from now, see ResetTask
+	DuplicateExtend
+)
+
+// WithDuplicatePolicy set what adding a task under a registered key does, the policy is applied
+// on the wheel goroutine so concurrent adds of a key end with a single task. Default is DuplicateError.
+func WithDuplicatePolicy(p DuplicatePolicy) Option {
+	return func(tw *TimeWheel) {
+		tw.dupPolicy = p
+	}
+}
+
+// apply the duplicate policy to the registered task, the new task is dropped by the caller
+func (tw *TimeWheel) coalesce(registered *task) {
+	if tw.dupPolicy != DuplicateExtend || registered.isHeld() || registered.times == 0 {
+		return
+	}
+	tw.rescheduleTask(registered, registered.interval)
+}
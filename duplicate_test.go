@@ -0,0 +1,64 @@
+package timewheel
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDuplicatePolicy(t *testing.T) {
+	for _, tc := range []struct {
+		policy DuplicatePolicy
+		next   time.Duration // wait for the next run after the duplicates
+	}{
+		{DuplicateIgnore, 2 * time.Second},
+		{DuplicateExtend, 4 * time.Second},
+	} {
+		c := newFakeClock()
+		tw := New(time.Second, 10, WithClock(c), WithDuplicatePolicy(tc.policy))
+		tw.Start()
+		var first, dup int64
+		tw.AddTask(3*time.Second, 1, "x", nil, func(TaskData) { atomic.AddInt64(&first, 1) })
+		settle(tw)
+		c.Tick(time.Second)
+		c.Tick(time.Second)
+		settle(tw)
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := tw.AddTask(3*time.Second, 1, "x", nil, func(TaskData) { atomic.AddInt64(&dup, 1) }); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+		settle(tw)
+		checkInvariants(t, tw)
+		up := tw.Upcoming(time.Hour, 0)
+		if tw.Len() != 1 || len(up) != 1 || up[0].Next.Sub(c.Now()) != tc.next {
+			t.Fatalf("%v: %d tasks, upcoming %+v", tc.policy, tw.Len(), up)
+		}
+		for i := 0; i < int(tc.next/time.Second); i++ {
+			c.Tick(time.Second)
+			settle(tw)
+		}
+		waitCount(t, &first, 1)
+		if atomic.LoadInt64(&dup) != 0 || tw.HasTask("x") {
+			t.Fatalf("%v: a duplicate ran", tc.policy)
+		}
+		tw.Stop()
+	}
+}
+
+func TestDuplicatePolicyDefault(t *testing.T) {
+	tw := New(time.Second, 10)
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(time.Minute, 1, "x", nil, func(TaskData) {})
+	if err := tw.AddTask(time.Minute, 1, "x", nil, func(TaskData) {}); err != ErrDuplicateKey {
+		t.Fatalf("duplicate add: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package timewheel
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// BareIntegerPolicy decide how AddTaskString handles an interval without a unit such as "90"
+type BareIntegerPolicy int
+
+const (
+	// BareIntegerReject reject the interval
+	BareIntegerReject BareIntegerPolicy = iota
+	// BareIntegerSeconds read the number as seconds
+	BareIntegerSeconds
+)
+
+// WithBareIntegerPolicy set how AddTaskString handles an interval without a unit, default is BareIntegerReject
+func WithBareIntegerPolicy(p BareIntegerPolicy) Option {
+	return func(tw *TimeWheel) {
+		tw.bareIntegers = p
+	}
+}
+
+// AddTaskString add new task like AddTask with the interval given as a string such as "90s" or "2h30m",
+// see time.ParseDuration. The interval must be at least the tick of the wheel and at most its maximum
+// supported delay, the errors wrap ErrInvalidParams and name the offending input.
+func (tw *TimeWheel) AddTaskString(interval string, times int, key interface{}, data TaskData, job Job) error {
+	d, err := tw.ParseInterval(interval)
+	if err != nil {
+		return err
+	}
+	return tw.AddTask(d, times, key, data, job)
+}
+
+// ParseInterval parse and check an interval the way AddTaskString does
+func (tw *TimeWheel) ParseInterval(s string) (time.Duration, error) {
+	in := strings.TrimSpace(s)
+	var d time.Duration
+	if n, err := strconv.ParseInt(in, 10, 64); err == nil && n != 0 {
+		if tw.bareIntegers != BareIntegerSeconds {
+			return 0, fmt.Errorf("%w, interval %q has no unit, e.g. %ss", ErrInvalidParams, s, in)
+		}
+		if n > int64(math.MaxInt64/time.Second) || n < int64(math.MinInt64/time.Second) {
+			return 0, fmt.Errorf("%w, interval %q overflows", ErrInvalidParams, s)
+		}
+		d = time.Duration(n) * time.Second
+	} else if d, err = time.ParseDuration(in); err != nil {
+		return 0, fmt.Errorf("%w, interval %q: %w", ErrInvalidParams, s, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%w, interval %q is not positive", ErrInvalidParams, s)
+	}
+	if tick := tw.Interval(); d < tick {
+		return 0, fmt.Errorf("%w, interval %q is below the tick %v", ErrInvalidParams, s, tick)
+	}
+	if limit := tw.maxDelay(); d > limit {
+		return 0, fmt.Errorf("%w, interval %q is above the maximum delay %v: %w", ErrInvalidParams, s, limit, ErrDelayTooLarge)
+	}
+	return d, nil
+}
+
+// longest delay whose circle count fits an int32
+func (tw *TimeWheel) maxDelay() time.Duration {
+	round := tw.Interval() * time.Duration(atomic.LoadInt64(&tw.slotNum))
+	if round <= 0 || round > math.MaxInt64/math.MaxInt32 {
+		return math.MaxInt64
+	}
+	return round * math.MaxInt32
+}
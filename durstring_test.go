@@ -0,0 +1,65 @@
+package timewheel
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseInterval(t *testing.T) {
+	tw := New(time.Second, 10)
+	for _, tc := range []struct {
+		s   string
+		d   time.Duration
+		err string
+	}{
+		{"90s", 90 * time.Second, ""},
+		{"2h30m", 150 * time.Minute, ""},
+		{" 1s ", time.Second, ""},
+		{"garbage", 0, `"garbage": time: invalid duration`},
+		{"-5s", 0, `"-5s" is not positive`},
+		{"0", 0, `"0" is not positive`},
+		{"500ms", 0, `"500ms" is below the tick 1s`},
+		{"90", 0, `"90" has no unit, e.g. 90s`},
+		{"", 0, `"": time: invalid duration`},
+	} {
+		d, err := tw.ParseInterval(tc.s)
+		if tc.err == "" {
+			if err != nil || d != tc.d {
+				t.Errorf("%q: %v %v", tc.s, d, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrInvalidParams) || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%q: %v, want an error with %q", tc.s, err, tc.err)
+		}
+	}
+
+	secs := New(time.Second, 10, WithBareIntegerPolicy(BareIntegerSeconds))
+	if d, err := secs.ParseInterval("90"); err != nil || d != 90*time.Second {
+		t.Fatalf("bare integer: %v %v", d, err)
+	}
+	if _, err := secs.ParseInterval("99999999999999999"); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("overflow: %v", err)
+	}
+	if _, err := New(time.Hour, 1000).ParseInterval("2562047h"); err != nil {
+		t.Fatal(err)
+	}
+	small := New(time.Millisecond, 1)
+	if _, err := small.ParseInterval("1000h"); !errors.Is(err, ErrDelayTooLarge) || !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("above %v: %v", small.maxDelay(), err)
+	}
+}
+
+func TestAddTaskString(t *testing.T) {
+	tw := New(time.Second, 10, WithBareIntegerPolicy(BareIntegerSeconds))
+	tw.Start()
+	defer tw.Stop()
+	if err := tw.AddTaskString("2", 1, "k", nil, func(TaskData) {}); err != nil || !tw.HasTask("k") {
+		t.Fatalf("add: %v", err)
+	}
+	if err := tw.AddTaskString("soon", 1, "x", nil, func(TaskData) {}); !errors.Is(err, ErrInvalidParams) || tw.HasTask("x") {
+		t.Fatalf("add garbage: %v", err)
+	}
+}
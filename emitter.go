@@ -0,0 +1,229 @@
+package timewheel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultEmitterBuffer default size of the queue of events waiting for the emitter
+const DefaultEmitterBuffer = 1024
+
+// Emitter publish the lifecycle events to an external system such as a message broker, see WithEmitter
+type Emitter interface {
+	Emit(ctx context.Context, ev Event) error
+}
+
+// WithEmitter hand the events of the given types to e, default is EventFired only. The events are
+// queued and emitted one by one on a dedicated goroutine, so a slow emitter never delays the tasks.
+// When the queue of the given size is full the event is dropped. The drops and the failed emits are
+// counted, see EmitterStats, and reported on Errors.
+func WithEmitter(e Emitter, buffer int, types ...EventType) Option {
+	return func(tw *TimeWheel) {
+		if e == nil {
+			return
+		}
+		if buffer <= 0 {
+			buffer = DefaultEmitterBuffer
+		}
+		if len(types) == 0 {
+			types = []EventType{EventFired}
+		}
+		q := &emitQueue{e: e, ch: make(chan Event, buffer)}
+		for _, typ := range types {
+			q.types |= 1 << uint(typ)
+		}
+		tw.emitter = q
+	}
+}
+
+// EmitterStats counters of the emitter, see WithEmitter
+type EmitterStats struct {
+	Emitted int64 // events emitted without error
+	Failed  int64 // events the emitter returned an error for
+	Dropped int64 // events dropped because the queue was full
+}
+
+// EmitterStats get the counters of the emitter
+func (tw *TimeWheel) EmitterStats() EmitterStats {
+	q := tw.emitter
+	if q == nil {
+		return EmitterStats{}
+	}
+	return EmitterStats{
+		Emitted: atomic.LoadInt64(&q.emitted),
+		Failed:  atomic.LoadInt64(&q.failed),
+		Dropped: atomic.LoadInt64(&q.dropped),
+	}
+}
+
+// queue of the events waiting for the emitter
+type emitQueue struct {
+	e     Emitter
+	ch    chan Event
+	types uint32 // bit set of the emitted event types
+
+	// counters, accessed atomically
+	emitted int64
+	failed  int64
+	dropped int64
+}
+
+// queue the event for the emitter without blocking
+func (tw *TimeWheel) offerEvent(ev Event) {
+	q := tw.emitter
+	if q.types&(1<<uint(ev.Type)) == 0 {
+		return
+	}
+	select {
+	case q.ch <- ev:
+	default:
+		atomic.AddInt64(&q.dropped, 1)
+		tw.reportError(ErrorEmit, ev.Key, errors.New("emitter queue full, event dropped"))
+	}
+}
+
+// emit the queued events until the wheel stops, the events queued by then are emitted before returning
+func (tw *TimeWheel) emitLoop() {
+	q := tw.emitter
+	for {
+		select {
+		case ev := <-q.ch:
+			tw.emitEvent(ev)
+		case <-tw.stopChannel:
+			for {
+				select {
+				case ev := <-q.ch:
+					tw.emitEvent(ev)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (tw *TimeWheel) emitEvent(ev Event) {
+	q := tw.emitter
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("emitter panic: %v", r)
+			}
+		}()
+		return q.e.Emit(context.Background(), ev)
+	}()
+	if err != nil {
+		atomic.AddInt64(&q.failed, 1)
+		tw.logger.Printf("timewheel: emit failed, key: %v, event: %v, err: %v", ev.Key, ev.Type, err)
+		tw.reportError(ErrorEmit, ev.Key, err)
+		return
+	}
+	atomic.AddInt64(&q.emitted, 1)
+}
+
+// ChanEmitter an emitter sending the events to the channel, Emit waits for the receiver
+type ChanEmitter chan Event
+
+// Emit implement Emitter
+func (c ChanEmitter) Emit(ctx context.Context, ev Event) error {
+	select {
+	case c <- ev:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// BatchEmitter an emitter handing the events over in batches, a batch is flushed once it holds size
+// events or every period, whichever comes first. A failed batch is dropped. The error of a flush on
+// the period is returned by the next Emit, so the wheel counts it.
+type BatchEmitter struct {
+	mu    sync.Mutex
+	size  int
+	flush func(ctx context.Context, batch []Event) error
+	buf   []Event
+	err   error // failure of the last flush on the period
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewBatchEmitter create an emitter calling flush with batches of size events, the events left are
+// flushed every period if it is positive. Call Close to flush the last batch.
+func NewBatchEmitter(size int, period time.Duration, flush func(ctx context.Context, batch []Event) error) *BatchEmitter {
+	if size <= 0 {
+		size = 1
+	}
+	b := &BatchEmitter{size: size, flush: flush, stop: make(chan struct{}), done: make(chan struct{})}
+	if period > 0 {
+		go b.flushLoop(period)
+	} else {
+		close(b.done)
+	}
+	return b
+}
+
+// Emit implement Emitter
+func (b *BatchEmitter) Emit(ctx context.Context, ev Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, ev)
+	var err error
+	if len(b.buf) >= b.size {
+		err = b.flushLocked(ctx)
+	}
+	if b.err != nil {
+		err = errors.Join(b.err, err)
+		b.err = nil
+	}
+	return err
+}
+
+// Close stop the periodic flush and flush the events left
+func (b *BatchEmitter) Close() error {
+	b.stopOnce.Do(func() {
+		close(b.stop)
+	})
+	<-b.done
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.flushLocked(context.Background())
+	if b.err != nil {
+		err = errors.Join(b.err, err)
+		b.err = nil
+	}
+	return err
+}
+
+func (b *BatchEmitter) flushLoop(period time.Duration) {
+	defer close(b.done)
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.mu.Lock()
+			if err := b.flushLocked(context.Background()); err != nil {
+				b.err = err
+			}
+			b.mu.Unlock()
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+// hand the buffered events to flush, the batch is not kept whatever the result
+func (b *BatchEmitter) flushLocked(ctx context.Context) error {
+	if len(b.buf) == 0 {
+		return nil
+	}
+	batch := b.buf
+	b.buf = nil
+	return b.flush(ctx, batch)
+}
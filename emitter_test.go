@@ -0,0 +1,100 @@
+package timewheel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEmitterOrderAndBlocked(t *testing.T) {
+	ch := make(ChanEmitter)
+	tw := New(5*time.Millisecond, 8, WithEmitter(ch, 4, EventAdded, EventFired, EventRemoved))
+	errs := tw.Errors()
+	tw.Start()
+	defer tw.Stop()
+	var fired int64
+	// nobody reads ch: the emitter is blocked, jobs must still run
+	for i := 0; i < 5; i++ {
+		tw.AddTask(5*time.Millisecond, 3, i, nil, func(TaskData) { atomic.AddInt64(&fired, 1) })
+	}
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt64(&fired) < 15 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if atomic.LoadInt64(&fired) != 15 {
+		t.Fatal(fired)
+	}
+	if tw.EmitterStats().Dropped == 0 {
+		t.Fatal(tw.EmitterStats())
+	}
+	select {
+	case e := <-errs:
+		if e.Kind != ErrorEmit {
+			t.Fatal(e)
+		}
+	default:
+		t.Fatal("no error")
+	}
+	// ordering within a key
+	last := map[interface{}]EventType{}
+	timeout := time.After(200 * time.Millisecond)
+loop:
+	for {
+		select {
+		case ev := <-ch:
+			if p, ok := last[ev.Key]; ok && p == EventFired && ev.Type == EventAdded {
+				t.Fatal("out of order", ev)
+			}
+			last[ev.Key] = ev.Type
+		case <-timeout:
+			break loop
+		}
+	}
+}
+
+func TestBatchEmitter(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]Event
+	b := NewBatchEmitter(3, 30*time.Millisecond, func(ctx context.Context, batch []Event) error {
+		mu.Lock()
+		batches = append(batches, batch)
+		mu.Unlock()
+		return nil
+	})
+	for i := 0; i < 7; i++ {
+		b.Emit(context.Background(), Event{Key: i})
+	}
+	mu.Lock()
+	if len(batches) != 2 || len(batches[0]) != 3 {
+		t.Fatal(batches)
+	}
+	mu.Unlock()
+	// the age of the batch flushes the rest
+	time.Sleep(60 * time.Millisecond)
+	mu.Lock()
+	if len(batches) != 3 || len(batches[2]) != 1 || batches[2][0].Key != 6 {
+		t.Fatal(batches)
+	}
+	mu.Unlock()
+	b.Emit(context.Background(), Event{Key: 7})
+	// Close flushes the partial batch
+	b.Close()
+	mu.Lock()
+	if len(batches) != 4 {
+		t.Fatal(batches)
+	}
+	mu.Unlock()
+
+	fail := NewBatchEmitter(2, 0, func(ctx context.Context, batch []Event) error { return errors.New("broker") })
+	tw := New(5*time.Millisecond, 8, WithEmitter(fail, 0))
+	tw.Start()
+	tw.AddTask(5*time.Millisecond, 4, "k", nil, func(TaskData) {})
+	time.Sleep(100 * time.Millisecond)
+	tw.Stop()
+	if s := tw.EmitterStats(); s.Failed != 2 || s.Emitted != 2 {
+		t.Fatal(s)
+	}
+}
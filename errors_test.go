@@ -0,0 +1,120 @@
+package timewheel
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	c := newFakeClock()
+	r := NewJobRegistry(false)
+	tw := New(time.Second, 10, WithClock(c), WithJobRegistry(r), WithMaxTasks(3, nil), WithHoldFinalKey(), WithAddBuffer(1))
+	tw.Start()
+	job := func(TaskData) {}
+	tw.AddTask(time.Minute, 1, "k", nil, job)
+	running, release := make(chan struct{}), make(chan struct{})
+	tw.AddTask(time.Second, 1, "held", nil, func(TaskData) {
+		close(running)
+		<-release
+	})
+	settle(tw)
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	<-running
+	settle(tw)
+
+	for _, tc := range []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"zero interval", tw.AddTask(0, 1, "x", nil, job), ErrInvalidParams},
+		{"nil job", tw.AddTask(time.Second, 1, "x", nil, nil), ErrInvalidParams},
+		{"zero times", tw.AddTask(time.Second, 0, "x", nil, job), ErrInvalidParams},
+		{"builder", tw.NewTask("b").Every(time.Second).Do(job).WithJitter(2 * time.Second).Submit(), ErrInvalidParams},
+		{"nil key", tw.PauseTask(nil), ErrInvalidKey},
+		{"duplicate", tw.AddTask(time.Second, 1, "k", nil, job), ErrDuplicateKey},
+		{"unknown job", tw.AddNamedTask(time.Second, 1, "n", "nope", nil), ErrUnknownJob},
+		{"no registry", New(time.Second, 10).AddNamedTask(time.Second, 1, "n", "nope", nil), ErrNoJobRegistry},
+		{"remove", tw.RemoveTask("nope"), ErrTaskNotFound},
+		{"update", tw.UpdateTask("nope", time.Second, nil), ErrTaskNotFound},
+		{"replace", tw.ReplaceTask("nope", time.Second, 1, nil, job), ErrTaskNotFound},
+		{"pause", tw.PauseTask("nope"), ErrTaskNotFound},
+		{"held key", tw.AddTask(time.Second, 1, "held", nil, job), ErrTaskStillRunning},
+		{"key type", tw.AddTask(time.Second, 1, []int{1}, nil, job), ErrKeyNotComparable},
+		{"submitted", submitTwice(tw.NewTask("b2").Every(time.Second)), ErrBuilderSubmitted},
+		{"nil default", SetDefault(nil), ErrInvalidParams},
+		{"register", r.Register("", job), ErrInvalidParams},
+		{"wal params", tw.RecoverFromWAL("", r), ErrInvalidParams},
+	} {
+		if !errors.Is(tc.err, tc.want) {
+			t.Errorf("%s: %v is not %v", tc.name, tc.err, tc.want)
+		}
+	}
+	close(release)
+	for i := 0; tw.HasTask("held"); i++ {
+		if i == 1000 {
+			t.Fatal("the held key is never released")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	tw.AddTask(time.Second, 1, "k2", nil, job)
+	tw.AddTask(time.Second, 1, "k3", nil, job)
+	settle(tw)
+	if err := tw.AddTask(time.Second, 1, "k4", nil, job); !errors.Is(err, ErrTooManyTasks) {
+		t.Fatalf("add past the limit: %v", err)
+	}
+	tw.RemoveTask("k2")
+	tw.RemoveTask("k3")
+	unstall := stall(tw)
+	tw.TryAddTask(time.Second, 1, "q1", nil, job)
+	if err := tw.TryAddTask(time.Second, 1, "q2", nil, job); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("add to a full buffer: %v", err)
+	}
+	unstall()
+	tw.Stop()
+	if err := tw.AddTask(time.Second, 1, "z", nil, job); !errors.Is(err, ErrWheelStopped) {
+		t.Fatalf("add to a stopped wheel: %v", err)
+	}
+	// the old messages are kept for callers matching strings
+	if ErrDuplicateKey.Error() != "duplicate task key" || ErrInvalidParams.Error() != "illegal task params" {
+		t.Fatal("error messages changed")
+	}
+	if err := r.Register("", job); err.Error() != "illegal task params, illegal job params" {
+		t.Fatal(err)
+	}
+}
+
+func submitTwice(b *TaskBuilder) error {
+	b.Submit()
+	return b.Submit()
+}
+
+func TestSentinelErrorsInUse(t *testing.T) {
+	StopDefault()
+	defer StopDefault()
+	Default()
+	if err := SetDefault(New(time.Second, 10)); !errors.Is(err, ErrDefaultInUse) {
+		t.Fatalf("replace the default wheel in use: %v", err)
+	}
+
+	tw := New(time.Second, 10)
+	tw.Start()
+	defer tw.Stop()
+	path := filepath.Join(t.TempDir(), "wal")
+	r := NewJobRegistry(false)
+	if err := tw.RecoverFromWAL(path, r); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.RecoverFromWAL(path, r); !errors.Is(err, ErrWALOpen) {
+		t.Fatalf("open the log twice: %v", err)
+	}
+	// published once per process, whatever the test count
+	tw.PublishExpvar("timewheel_sentinel_test")
+	if err := tw.PublishExpvar("timewheel_sentinel_test"); !errors.Is(err, ErrExpvarPublished) {
+		t.Fatalf("publish twice: %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package timewheel
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType kind of a lifecycle event
+type EventType int
+
+const (
+	// EventAdded the task is registered
+	EventAdded EventType = iota
+	// EventFired the task is dispatched
+	EventFired
+	// EventCompleted the final run of the task returned
+	EventCompleted
+	// EventRemoved the task is removed by RemoveTask
+	EventRemoved
+	// EventDropped a run of the task is dropped while catching up missed ticks or by the worker pool
+	EventDropped
+	// EventExpired the task is removed by its TTL
+	EventExpired
+	// EventBreakerOpen the circuit breaker of the task opened, see CircuitBreaker
+	EventBreakerOpen
+	// EventBreakerHalfOpen the circuit breaker of the task lets a probe run through
+	EventBreakerHalfOpen
+	// EventBreakerClosed the circuit breaker of the task closed
+	EventBreakerClosed
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventAdded:
+		return "added"
+	case EventFired:
+		return "fired"
+	case EventCompleted:
+		return "completed"
+	case EventRemoved:
+		return "removed"
+	case EventDropped:
+		return "dropped"
+	case EventExpired:
+		return "expired"
+	case EventBreakerOpen:
+		return "breaker open"
+	case EventBreakerHalfOpen:
+		return "breaker half open"
+	case EventBreakerClosed:
+		return "breaker closed"
+	}
+	return "unknown"
+}
+
+// Event a lifecycle event of a task, see Subscribe
+type Event struct {
+	Type EventType
+	Key  interface{}
+	Time time.Time
+	Info TaskInfo
+}
+
+type subscriber struct {
+	ch      chan Event
+	dropped int64 // accessed atomically
+}
+
+// subscribers of the lifecycle events
+type eventBus struct {
+	mu   sync.RWMutex
+	subs []*subscriber
+	n    int32 // number of subscribers, accessed atomically so publishing without subscriber is cheap
+}
+
+// Subscribe receive the lifecycle events on a channel of the given buffer, call cancel to unsubscribe,
+// the channel is closed then. Events are never waited for: when the buffer is full the event is dropped,
+// see DroppedEvents.
+func (tw *TimeWheel) Subscribe(buffer int) (<-chan Event, func()) {
+	if buffer < 0 {
+		buffer = 0
+	}
+	s := &subscriber{ch: make(chan Event, buffer)}
+	b := &tw.events
+	b.mu.Lock()
+	b.subs = append(b.subs, s)
+	atomic.AddInt32(&b.n, 1)
+	b.mu.Unlock()
+
+	var once sync.Once
+	return s.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			for i, v := range b.subs {
+				if v == s {
+					b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+					break
+				}
+			}
+			atomic.AddInt32(&b.n, -1)
+			close(s.ch)
+			b.mu.Unlock()
+		})
+	}
+}
+
+// DroppedEvents get the number of events dropped for the subscription because its buffer was full
+func (tw *TimeWheel) DroppedEvents(ch <-chan Event) int64 {
+	b := &tw.events
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, s := range b.subs {
+		if (<-chan Event)(s.ch) == ch {
+			return atomic.LoadInt64(&s.dropped)
+		}
+	}
+	return 0
+}
+
+// send the event to every subscriber without blocking, only called on the wheel goroutine
+func (tw *TimeWheel) publish(typ EventType, t *task) {
+	if atomic.LoadInt32(&tw.events.n) == 0 && tw.emitter == nil {
+		return
+	}
+	tw.publishInfo(typ, t.key, t.info())
+}
+
+// send the event with the given snapshot
+func (tw *TimeWheel) publishInfo(typ EventType, key interface{}, info TaskInfo) {
+	b := &tw.events
+	if atomic.LoadInt32(&b.n) == 0 && tw.emitter == nil {
+		return
+	}
+	ev := Event{Type: typ, Key: key, Time: tw.clock.Now(), Info: info}
+	if tw.emitter != nil {
+		tw.offerEvent(ev)
+	}
+	b.mu.RLock()
+	for _, s := range b.subs {
+		select {
+		case s.ch <- ev:
+		default:
+			atomic.AddInt64(&s.dropped, 1)
+		}
+	}
+	b.mu.RUnlock()
+}
@@ -0,0 +1,69 @@
+package timewheel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	ch, cancel := tw.Subscribe(16)
+	// never read
+	stuck, cancelStuck := tw.Subscribe(0)
+	defer cancelStuck()
+
+	tw.AddTask(time.Second, 1, "a", TaskData{"v": 1}, func(TaskData) {})
+	tw.AddTask(time.Hour, -1, "b", nil, func(TaskData) {})
+	settle(tw)
+	tw.RemoveTask("b")
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+
+	type seen struct {
+		typ EventType
+		key interface{}
+	}
+	want := []seen{{EventAdded, "a"}, {EventAdded, "b"}, {EventRemoved, "b"}, {EventFired, "a"}, {EventCompleted, "a"}}
+	for i, w := range want {
+		select {
+		case ev := <-ch:
+			if ev.Type != w.typ || ev.Key != w.key {
+				t.Fatalf("event %d: %v %v, want %v %v", i, ev.Type, ev.Key, w.typ, w.key)
+			}
+			if ev.Time.IsZero() || ev.Info.Interval == 0 {
+				t.Fatalf("event %d: %+v", i, ev)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("event %d missing", i)
+		}
+	}
+	// the full subscriber lost every event without holding the wheel
+	deadline := time.Now().Add(5 * time.Second)
+	for tw.DroppedEvents(stuck) < int64(len(want)) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := tw.DroppedEvents(stuck); n != int64(len(want)) {
+		t.Fatalf("%d dropped events, want %d", n, len(want))
+	}
+	if n := tw.DroppedEvents(ch); n != 0 {
+		t.Fatalf("%d dropped events", n)
+	}
+
+	cancel()
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Fatal("channel open after cancel")
+	}
+	tw.AddTask(time.Hour, -1, "c", nil, func(TaskData) {})
+	settle(tw)
+	if n := tw.DroppedEvents(stuck); n != int64(len(want))+1 {
+		t.Fatalf("%d dropped events", n)
+	}
+	if EventDropped.String() != "dropped" {
+		t.Fatal(EventDropped.String())
+	}
+}
@@ -0,0 +1,78 @@
+package timewheel
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// json form of a task in MarshalTasksJSON
+type exportTask struct {
+	Key       string          `json:"key"`
+	Interval  string          `json:"interval"`
+	Times     int             `json:"times"`
+	Paused    bool            `json:"paused"`
+	Next      time.Time       `json:"next"`
+	Tags      []string        `json:"tags,omitempty"`
+	Priority  int             `json:"priority,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	DataError string          `json:"data_error,omitempty"`
+	Stats     *adminStats     `json:"stats"`
+}
+
+// MarshalTasksJSON describe every task as a json array ordered by key, taken on the wheel goroutine
+// so the list is consistent. Keys are in their string form and next is the estimated fire time.
+// The data of a task is left out with data_error set when it can not be encoded.
+func (tw *TimeWheel) MarshalTasksJSON() ([]byte, error) {
+	var tasks []exportTask
+	err := tw.exec(func() {
+		tasks = make([]exportTask, 0, tw.backend.len())
+		add := func(t *task, ticks int) {
+			if t.times == 0 {
+				return
+			}
+			e := exportTask{
+				Key:      fmt.Sprint(t.key),
+				Interval: t.interval.String(),
+				Times:    t.times,
+				Paused:   t.isPaused(),
+				Next:     tw.estimateFire(ticks),
+				Tags:     append([]string(nil), t.tags...),
+				Priority: t.priority,
+				Stats:    newAdminStats(t.stats.snapshot()),
+			}
+			e.Data, e.DataError = exportData(t.taskData)
+			tasks = append(tasks, e)
+		}
+		tw.eachWaiting(func(t *task) {
+			add(t, 0)
+		})
+		tw.backend.each(func(t *task) {
+			add(t, tw.backend.ticksUntil(t))
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Key < tasks[j].Key
+	})
+	return json.Marshal(tasks)
+}
+
+// encode the task data with the keys in their string form, a value json can not encode gives an error text
+func exportData(data TaskData) (json.RawMessage, string) {
+	if len(data) == 0 {
+		return nil, ""
+	}
+	m := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		m[fmt.Sprint(k)] = v
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err.Error()
+	}
+	return b, ""
+}
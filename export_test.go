@@ -0,0 +1,43 @@
+package timewheel
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMarshalTasksJSON(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTaskWith(2*time.Second, 3, "a", TaskData{"n": 1}, func(TaskData) {}, Tags("x"))
+	// one bad payload does not break the export
+	tw.AddTask(time.Second, -1, "b", TaskData{"ch": make(chan int)}, func(TaskData) {})
+	tw.AddTask(time.Minute, 1, 7, nil, func(TaskData) {})
+	tw.PauseTask(7)
+	settle(tw)
+	b, err := tw.MarshalTasksJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []exportTask
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 3 {
+		t.Fatalf("%d tasks exported", len(out))
+	}
+	now := c.Now()
+	a, bad, num := out[1], out[2], out[0]
+	if a.Key != "a" || a.Interval != "2s" || a.Times != 3 || a.Paused || !a.Next.Equal(now.Add(3*time.Second)) ||
+		len(a.Tags) != 1 || a.Tags[0] != "x" || string(a.Data) != `{"n":1}` || a.DataError != "" || a.Stats == nil {
+		t.Fatalf("a: %+v", a)
+	}
+	if bad.Key != "b" || bad.Times != -1 || bad.Data != nil || bad.DataError == "" || !bad.Next.Equal(now.Add(2*time.Second)) {
+		t.Fatalf("b: %+v", bad)
+	}
+	if num.Key != "7" || !num.Paused || num.Interval != "1m0s" {
+		t.Fatalf("7: %+v", num)
+	}
+}
@@ -0,0 +1,37 @@
+package timewheel
+
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// serialize the check and publish, expvar.Publish panics on duplicate names
+var expvarLock sync.Mutex
+
+// PublishExpvar publish the counters of the wheel as an expvar variable named prefix,
+// an error is returned if the name is already published
+func (tw *TimeWheel) PublishExpvar(prefix string) error {
+	expvarLock.Lock()
+	defer expvarLock.Unlock()
+	if expvar.Get(prefix) != nil {
+		return ErrExpvarPublished
+	}
+	expvar.Publish(prefix, expvar.Func(tw.expvarSnapshot))
+	return nil
+}
+
+// snapshot of the counters
+func (tw *TimeWheel) expvarSnapshot() interface{} {
+	return map[string]interface{}{
+		"tasks":          atomic.LoadInt64(&tw.taskNum),
+		"fired":          atomic.LoadInt64(&tw.firedNum),
+		"removed":        atomic.LoadInt64(&tw.removedNum),
+		"position":       atomic.LoadInt64(&tw.position),
+		"ticks":          atomic.LoadInt64(&tw.tickNum),
+		"slow_jobs":      atomic.LoadInt64(&tw.slowNum),
+		"deferred":       atomic.LoadInt64(&tw.deferredNum),
+		"last_tick_cost": time.Duration(atomic.LoadInt64(&tw.lastTickCost)).String(),
+	}
+}
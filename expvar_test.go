@@ -0,0 +1,57 @@
+package timewheel
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// get the variable published under name from the expvar handler
+func readExpvar(t *testing.T, name string) map[string]interface{} {
+	rec := httptest.NewRecorder()
+	expvar.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/vars", nil))
+	var vars map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &vars); err != nil {
+		t.Fatal(err)
+	}
+	var v map[string]interface{}
+	if err := json.Unmarshal(vars[name], &v); err != nil {
+		t.Fatal(name, err)
+	}
+	return v
+}
+
+func TestPublishExpvar(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 4, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	// the expvar names live as long as the process
+	name := fmt.Sprintf("test_wheel_%p", tw)
+	if err := tw.PublishExpvar(name); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.PublishExpvar(name); err == nil {
+		t.Fatal("published twice")
+	}
+	v := readExpvar(t, name)
+	if v["tasks"] != 0.0 || v["fired"] != 0.0 || v["ticks"] != 0.0 {
+		t.Fatal(v)
+	}
+	tw.AddTask(time.Second, 2, "a", nil, func(TaskData) {})
+	tw.AddTask(time.Hour, 1, "b", nil, func(TaskData) {})
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	tw.RemoveTask("b")
+	settle(tw)
+	v = readExpvar(t, name)
+	if v["tasks"] != 1.0 || v["fired"] != 1.0 || v["removed"] != 1.0 || v["ticks"] != 2.0 || v["position"] != 2.0 {
+		t.Fatal(v)
+	}
+	if _, err := time.ParseDuration(v["last_tick_cost"].(string)); err != nil {
+		t.Fatal(err)
+	}
+}
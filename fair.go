@@ -0,0 +1,92 @@
+package timewheel
+
+import "sync/atomic"
+
+// FairGroup map a task to its group for fair dispatch, see WithFairDispatch
+type FairGroup func(key interface{}, tags []string) string
+
+// FairByTag group the tasks by their first tag, the untagged tasks share a group
+func FairByTag(key interface{}, tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return tags[0]
+}
+
+// WithFairDispatch dispatch the due tasks of a tick round-robin across the groups given by group
+// instead of the slot order, so a crowded group does not hold back the others when the tick cap,
+// the scan chunk or the worker pool is saturated. The priority still orders the tasks of a group.
+// Under WithTickCap the deferred tasks compete with the tasks of the tick, each group keeps its order.
+func WithFairDispatch(group FairGroup) Option {
+	return func(tw *TimeWheel) {
+		tw.fairGroup = group
+	}
+}
+
+// hand the due tasks of the tick to run in fair order, the deferred tasks join the first batch
+func (tw *TimeWheel) advanceFair(run func(t *task)) {
+	merged := false
+	tw.backend.advance(func(due []*task) {
+		// the batch holds its own reference like the waiting queues
+		batch := tw.fairBatch[:0]
+		if !merged {
+			merged = true
+			for i, t := range tw.deferred {
+				tw.deferred[i] = nil
+				if t.times == 0 {
+					// removed while deferred
+					t.release()
+					continue
+				}
+				batch = append(batch, t)
+			}
+			atomic.AddInt64(&tw.deferredNum, -int64(len(tw.deferred)))
+			tw.deferred = tw.deferred[:0]
+		}
+		for _, t := range due {
+			t.retain()
+			batch = append(batch, t)
+		}
+		batch = tw.fairOrder(batch)
+		for i, t := range batch {
+			batch[i] = nil
+			run(t)
+			t.release()
+		}
+		tw.fairBatch = batch[:0]
+	})
+}
+
+// order the tasks round-robin across their groups, keeping the order within a group
+func (tw *TimeWheel) fairOrder(tasks []*task) []*task {
+	if len(tasks) < 2 {
+		return tasks
+	}
+	index := make(map[string]int)
+	var groups [][]*task
+	for _, t := range tasks {
+		g := tw.fairGroup(t.key, t.tags)
+		i, ok := index[g]
+		if !ok {
+			i = len(groups)
+			index[g] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], t)
+	}
+	if len(groups) == 1 {
+		return tasks
+	}
+	out := tasks[:0]
+	for len(groups) > 0 {
+		live := groups[:0]
+		for _, g := range groups {
+			out = append(out, g[0])
+			if len(g) > 1 {
+				live = append(live, g[1:])
+			}
+		}
+		groups = live
+	}
+	return out
+}
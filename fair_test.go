@@ -0,0 +1,73 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFairDispatchCap(t *testing.T) {
+	for _, tc := range []struct {
+		fair bool
+		b    int64 // runs of the singleton group
+	}{
+		{false, 0},
+		{true, 4},
+	} {
+		c := newFakeClock()
+		opts := []Option{WithClock(c), WithTickCap(10)}
+		if tc.fair {
+			opts = append(opts, WithFairDispatch(FairByTag))
+		}
+		tw := New(time.Second, 10, opts...)
+		tw.Start()
+		var a, b int64
+		for i := 0; i < 1000; i++ {
+			tw.AddTaskWith(time.Second, -1, fmt.Sprint("a", i), nil, func(TaskData) { atomic.AddInt64(&a, 1) }, Tags("a"))
+		}
+		tw.AddTaskWith(time.Second, -1, "b", nil, func(TaskData) { atomic.AddInt64(&b, 1) }, Tags("b"))
+		settle(tw)
+		for i := 0; i < 5; i++ {
+			c.Tick(time.Second)
+			settle(tw)
+		}
+		waitCount(t, &a, 40-tc.b)
+		if atomic.LoadInt64(&b) != tc.b {
+			t.Fatalf("fair %v: the singleton ran %d times, want %d", tc.fair, b, tc.b)
+		}
+		// the cap still holds
+		if atomic.LoadInt64(&a)+atomic.LoadInt64(&b) != 40 || tw.Len() != 1001 {
+			t.Fatalf("fair %v: %d runs, %d tasks", tc.fair, a+b, tw.Len())
+		}
+		tw.Stop()
+	}
+}
+
+func TestFairDispatchWorkers(t *testing.T) {
+	c := newFakeClock()
+	block := make(chan struct{})
+	// one worker and a queue of 4, the rest of the tick is dropped
+	tw := New(time.Second, 10, WithClock(c), WithWorkers(1, 4, DropNewest), WithFairDispatch(FairByTag))
+	tw.Start()
+	defer tw.Stop()
+	var a, b int64
+	for i := 0; i < 100; i++ {
+		tw.AddTaskWith(time.Second, 1, fmt.Sprint("a", i), nil, func(TaskData) {
+			<-block
+			atomic.AddInt64(&a, 1)
+		}, Tags("a"), Priority(1))
+	}
+	tw.AddTaskWith(time.Second, 1, "b", nil, func(TaskData) {
+		<-block
+		atomic.AddInt64(&b, 1)
+	}, Tags("b"))
+	settle(tw)
+	c.Tick(time.Second)
+	settle(tw)
+	c.Tick(time.Second)
+	settle(tw)
+	close(block)
+	waitCount(t, &a, 4)
+	waitCount(t, &b, 1)
+}
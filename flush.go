@@ -0,0 +1,45 @@
+package timewheel
+
+import "context"
+
+// FlushAndStop run every registered task once right away then stop the wheel. The runs go through the
+// worker pool, the sequential keys, the hooks and the interceptor like scheduled ones, whatever the delay
+// left: a recurring task gets a single final run and leaves the wheel with it. Paused tasks and the tasks
+// leaving the wheel are not run. The call waits for the runs to return until ctx is done, executed counts
+// the runs returned by then and err is the error of ctx if it expired first. The wheel is stopped either way.
+func (tw *TimeWheel) FlushAndStop(ctx context.Context) (executed int, err error) {
+	var pending []chan error
+	execErr := tw.exec(func() {
+		var tasks []*task
+		tw.taskRecord.Range(func(key interface{}, t *task) bool {
+			if t.times != 0 && !t.isHeld() && !t.isPaused() {
+				tasks = append(tasks, t)
+			}
+			return true
+		})
+		sortByAge(tasks)
+		now := tw.clock.Now()
+		for _, t := range tasks {
+			done := make(chan error, 1)
+			t.waiters = append(t.waiters, done)
+			pending = append(pending, done)
+			tw.fireRun(t, now, tw.persistRun(t), true)
+			tw.unregister(t)
+		}
+	})
+	if execErr != nil {
+		return 0, execErr
+	}
+	defer tw.Stop()
+	for _, done := range pending {
+		select {
+		case runErr := <-done:
+			if runErr == nil {
+				executed++
+			}
+		case <-ctx.Done():
+			return executed, ctx.Err()
+		}
+	}
+	return executed, nil
+}
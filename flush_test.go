@@ -0,0 +1,47 @@
+package timewheel
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlushAndStop(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 8, WithClock(c))
+	tw.Start()
+	var once, rec, removed int64
+	tw.AddTask(time.Hour, 1, "once", nil, func(TaskData) { atomic.AddInt64(&once, 1) })
+	tw.AddTask(30*time.Second, 5, "rec", nil, func(TaskData) { atomic.AddInt64(&rec, 1) })
+	tw.AddTask(time.Second, -1, "forever", nil, func(TaskData) { atomic.AddInt64(&rec, 1) })
+	tw.AddTask(time.Hour, 1, "gone", nil, func(TaskData) { atomic.AddInt64(&removed, 1) })
+	tw.RemoveTask("gone")
+	n, err := tw.FlushAndStop(context.Background())
+	if err != nil || n != 3 {
+		t.Fatal(n, err)
+	}
+	if once != 1 || rec != 2 || removed != 0 {
+		t.Fatal(once, rec, removed)
+	}
+	<-tw.loopDone
+	if tw.AddTask(time.Second, 1, "x", nil, func(TaskData) {}) != ErrWheelStopped {
+		t.Fatal("not stopped")
+	}
+}
+
+func TestFlushAndStopCtx(t *testing.T) {
+	tw := New(time.Second, 8)
+	tw.Start()
+	block := make(chan struct{})
+	defer close(block)
+	tw.AddTask(time.Hour, 1, "fast", nil, func(TaskData) {})
+	tw.AddTask(time.Hour, 1, "slow", nil, func(TaskData) { <-block })
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	n, err := tw.FlushAndStop(ctx)
+	if err != context.DeadlineExceeded || n != 1 {
+		t.Fatal(n, err)
+	}
+	<-tw.loopDone
+}
@@ -0,0 +1,93 @@
+package timewheel
+
+import (
+	"context"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// states of a future
+const (
+	futurePending int32 = iota
+	futureRunning
+	futureCanceled
+)
+
+// Future result of a function run once by the wheel, see Defer
+type Future[T any] struct {
+	tw    *TimeWheel
+	key   anonKey
+	state int32 // accessed atomically
+	done  chan struct{}
+	val   T
+	err   error
+}
+
+// Defer run f once after d on the wheel and get the future of its result. A panic of f is returned
+// by Get as a *PanicError.
+func Defer[T any](tw *TimeWheel, d time.Duration, f func() (T, error)) *Future[T] {
+	fut := &Future[T]{tw: tw, done: make(chan struct{})}
+	if tw == nil || f == nil {
+		fut.resolve(futureCanceled, ErrInvalidParams)
+		return fut
+	}
+	fut.key = anonKey(atomic.AddUint64(&anonSeq, 1))
+	err := tw.AddTask(d, 1, fut.key, nil, func(TaskData) {
+		if !atomic.CompareAndSwapInt32(&fut.state, futurePending, futureRunning) {
+			return
+		}
+		fut.run(f)
+	})
+	if err != nil {
+		fut.resolve(futureCanceled, err)
+	}
+	return fut
+}
+
+// call f and store its result, a panic is stored as the error
+func (fut *Future[T]) run(f func() (T, error)) {
+	defer func() {
+		if v := recover(); v != nil {
+			fut.err = &PanicError{Key: fut.key, Value: v, Stack: debug.Stack()}
+		}
+		close(fut.done)
+	}()
+	fut.val, fut.err = f()
+}
+
+// settle the future without running f, report whether it was still pending
+func (fut *Future[T]) resolve(state int32, err error) bool {
+	if !atomic.CompareAndSwapInt32(&fut.state, futurePending, state) {
+		return false
+	}
+	fut.err = err
+	close(fut.done)
+	return true
+}
+
+// Get wait for the result of the function, or the error of ctx once it is done
+func (fut *Future[T]) Get(ctx context.Context) (T, error) {
+	select {
+	case <-fut.done:
+		return fut.val, fut.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Done get a channel closed once the result is available
+func (fut *Future[T]) Done() <-chan struct{} {
+	return fut.done
+}
+
+// Cancel remove the task before the function runs, Get then returns ErrFutureCanceled. It reports false
+// if the function already runs or the future is settled.
+func (fut *Future[T]) Cancel() bool {
+	if !fut.resolve(futureCanceled, ErrFutureCanceled) {
+		return false
+	}
+	fut.tw.RemoveTask(fut.key)
+	return true
+}
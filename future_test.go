@@ -0,0 +1,51 @@
+package timewheel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFuture(t *testing.T) {
+	tw := New(5*time.Millisecond, 16)
+	tw.Start()
+	defer tw.Stop()
+	ctx := context.Background()
+
+	f := Defer(tw, 10*time.Millisecond, func() (int, error) { return 42, nil })
+	if v, err := f.Get(ctx); v != 42 || err != nil {
+		t.Fatal(v, err)
+	}
+	if f.Cancel() {
+		t.Fatal("cancel after done")
+	}
+
+	ran := false
+	c := Defer(tw, time.Hour, func() (string, error) { ran = true; return "x", nil })
+	if !c.Cancel() || c.Cancel() {
+		t.Fatal("cancel")
+	}
+	if _, err := c.Get(ctx); err != ErrFutureCanceled {
+		t.Fatal(err)
+	}
+	if tw.Len() != 0 || ran {
+		t.Fatal(tw.Len(), ran)
+	}
+
+	s := Defer(tw, time.Hour, func() (int, error) { return 1, nil })
+	tctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := s.Get(tctx); err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+	s.Cancel()
+
+	p := Defer(tw, 10*time.Millisecond, func() (int, error) { panic("boom") })
+	<-p.Done()
+	_, err := p.Get(ctx)
+	var pe *PanicError
+	if !errors.As(err, &pe) || pe.Value != "boom" {
+		t.Fatal(err)
+	}
+}
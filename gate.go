@@ -0,0 +1,101 @@
+package timewheel
+
+import "sync/atomic"
+
+// WithFiringGate check gate once per tick, the tasks are scheduled as usual but their jobs only run while it
+// returns true, so every replica of a service keeps the full wheel and only the leader fires. The runs due
+// while the gate is closed do not count towards times, policy decides what happens to them once it opens:
+// Skip drops them, FireOnePerTask runs every task that missed a run once and FireAll runs every missed run.
+// The final run of a task with an end is dropped. A panicking gate counts as closed.
+func WithFiringGate(gate func() bool, policy CatchUpPolicy) Option {
+	return func(tw *TimeWheel) {
+		tw.gate = gate
+		tw.gatePolicy = policy
+	}
+}
+
+// LeaderGate adapt a leadership signal to WithFiringGate, the gate is open after true was received on ch
+// and closed after false, it starts closed and closes for good once ch is closed
+func LeaderGate(ch <-chan bool) func() bool {
+	var leader int32
+	go func() {
+		for v := range ch {
+			if v {
+				atomic.StoreInt32(&leader, 1)
+			} else {
+				atomic.StoreInt32(&leader, 0)
+			}
+		}
+		atomic.StoreInt32(&leader, 0)
+	}()
+	return func() bool {
+		return atomic.LoadInt32(&leader) == 1
+	}
+}
+
+// GateOpen report whether the firing gate was open at the last tick, true without a gate
+func (tw *TimeWheel) GateOpen() bool {
+	return !tw.isGated()
+}
+
+// report whether the firing gate is closed
+func (tw *TimeWheel) isGated() bool {
+	return atomic.LoadInt32(&tw.gateClosed) == 1
+}
+
+// call the gate, a panic closes it
+func (tw *TimeWheel) callGate() (open bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			tw.logger.Printf("timewheel: firing gate panic recovered, panic: %v", r)
+			open = false
+		}
+	}()
+	return tw.gate()
+}
+
+// update the gate state for the tick, the runs held while it was closed are replayed once it opens
+func (tw *TimeWheel) checkGate() {
+	if !tw.callGate() {
+		atomic.StoreInt32(&tw.gateClosed, 1)
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&tw.gateClosed, 1, 0) {
+		return
+	}
+	policy := ResumeSkip
+	switch tw.gatePolicy {
+	case FireOnePerTask:
+		policy = ResumeReplayOne
+	case FireAll:
+		policy = ResumeReplayAll
+	}
+	held := tw.gatedTasks
+	tw.gatedTasks = nil
+	for _, task := range held {
+		missed := task.gated
+		task.gated = 0
+		// removed or paused while held
+		if task.times != 0 && !task.isPaused() {
+			tw.replay(task, missed, policy)
+		}
+		task.release()
+	}
+}
+
+// hold the due run while the gate is closed, the task moves on to its next run, report whether it is held
+func (tw *TimeWheel) holdForGate(task *task) bool {
+	if !tw.isGated() || task.lastRun() {
+		return false
+	}
+	if task.gated == 0 {
+		// the list holds its own reference, the task may be removed while it waits
+		task.retain()
+		tw.gatedTasks = append(tw.gatedTasks, task)
+	}
+	task.gated++
+	tw.trace(task, TraceDeferred)
+	task.next = task.following()
+	tw.addTask(task)
+	return true
+}
@@ -0,0 +1,62 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFiringGate(t *testing.T) {
+	for _, tc := range []struct {
+		policy CatchUpPolicy
+		want   int64
+	}{{Skip, 6}, {FireOnePerTask, 7}, {FireAll, 10}} {
+		var open int32 = 1
+		c := newFakeClock()
+		tw := New(10*time.Millisecond, 8, WithClock(c), WithFiringGate(func() bool { return atomic.LoadInt32(&open) == 1 }, tc.policy))
+		tw.Start()
+		var n int64
+		tw.AddTask(10*time.Millisecond, -1, "k", nil, func(TaskData) { atomic.AddInt64(&n, 1) })
+		settle(tw)
+		step := func(k int) {
+			for i := 0; i < k; i++ {
+				c.Tick(10 * time.Millisecond)
+				settle(tw)
+			}
+		}
+		step(4) // 3 runs
+		if atomic.LoadInt64(&n) != 3 {
+			t.Fatal(n)
+		}
+		atomic.StoreInt32(&open, 0)
+		step(4)
+		if atomic.LoadInt64(&n) != 3 || tw.GateOpen() {
+			t.Fatal("fired while closed", n)
+		}
+		atomic.StoreInt32(&open, 1)
+		step(3)
+		time.Sleep(20 * time.Millisecond)
+		if got := atomic.LoadInt64(&n); got != tc.want {
+			t.Fatal(tc.policy, got, tc.want)
+		}
+		tw.Stop()
+	}
+}
+
+func TestLeaderGate(t *testing.T) {
+	ch := make(chan bool)
+	g := LeaderGate(ch)
+	if g() {
+		t.Fatal()
+	}
+	ch <- true
+	ch <- true
+	if !g() {
+		t.Fatal()
+	}
+	close(ch)
+	time.Sleep(10 * time.Millisecond)
+	if g() {
+		t.Fatal()
+	}
+}
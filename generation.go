@@ -0,0 +1,75 @@
+package timewheel
+
+import "time"
+
+// last generation stamped on a task, shared by the wheels like the task pool. It starts from the clock
+// so the generations kept by a Store differ from one process to the next.
+var taskGen = uint64(time.Now().UnixNano())
+
+// AddTaskGen add new task like AddTaskWith and return its generation, every task gets a new one so a key
+// removed and added again is told apart, see RemoveTaskIf. A task dropped by the duplicate policy leaves
+// the registered task and its generation in place.
+func (tw *TimeWheel) AddTaskGen(interval time.Duration, times int, key interface{}, data TaskData, job Job, opts ...TaskOption) (uint64, error) {
+	if job == nil {
+		return 0, ErrInvalidParams
+	}
+	return tw.addTaskGen(interval, times, key, data, wrapJob(job), opts)
+}
+
+// RemoveTaskIf remove the task like RemoveTask if it is of the generation gen,
+// it fails with ErrStaleGeneration if the key was removed and added again meanwhile
+func (tw *TimeWheel) RemoveTaskIf(key interface{}, gen uint64) error {
+	if key == nil || gen == 0 {
+		return ErrInvalidParams
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	if tw.isStopped() {
+		return ErrWheelStopped
+	}
+
+	req := &removeRequest{key: key, gen: gen, reply: make(chan error, 1)}
+	if err := tw.sendRemove(req); err != nil {
+		return err
+	}
+	if req.named {
+		tw.walAppend(walRecord{Op: walRemove, Spec: TaskSpec{Key: key}})
+	}
+	if tw.store != nil {
+		if _, err := tw.store.Delete(key, gen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateTaskIf update the task like UpdateTask if it is of the generation gen,
+// it fails with ErrStaleGeneration if the key was removed and added again meanwhile
+func (tw *TimeWheel) UpdateTaskIf(key interface{}, gen uint64, interval time.Duration, taskData TaskData) error {
+	if key == nil || gen == 0 {
+		return ErrInvalidParams
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	if err := tw.checkInterval(interval); err != nil {
+		return err
+	}
+	if tw.isStopped() {
+		return ErrWheelStopped
+	}
+
+	taskData = tw.ownData(taskData)
+	req := &updateRequest{key: key, interval: interval, taskData: taskData, gen: gen, reply: make(chan error, 1)}
+	if err := tw.sendUpdate(req); err != nil {
+		return err
+	}
+	if req.named {
+		tw.walAppend(walRecord{Op: walUpdate, Spec: TaskSpec{Key: key, Interval: interval, Data: copyTaskData(taskData)}})
+		if tw.store != nil {
+			return tw.storeUpdate(key, interval, taskData)
+		}
+	}
+	return nil
+}
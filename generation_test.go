@@ -0,0 +1,43 @@
+package timewheel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGeneration(t *testing.T) {
+	tw := New(10*time.Millisecond, 8)
+	tw.Start()
+	defer tw.Stop()
+	job := func(TaskData) {}
+	g1, err := tw.AddTaskGen(time.Second, -1, "k", nil, job)
+	if err != nil || g1 == 0 {
+		t.Fatal(err)
+	}
+	tw.RemoveTask("k")
+	g2, _ := tw.AddTaskGen(time.Second, -1, "k", nil, job)
+	if g2 == g1 {
+		t.Fatal(g1)
+	}
+	if err := tw.RemoveTaskIf("k", g1); err != ErrStaleGeneration {
+		t.Fatal(err)
+	}
+	if err := tw.UpdateTaskIf("k", g1, time.Minute, nil); err != ErrStaleGeneration {
+		t.Fatal(err)
+	}
+	if !tw.HasTask("k") {
+		t.Fatal("new task removed")
+	}
+	tw.Range(func(k interface{}, info TaskInfo) bool {
+		if info.Generation != g2 || info.Interval != time.Second {
+			t.Fatal(info)
+		}
+		return true
+	})
+	if err := tw.UpdateTaskIf("k", g2, time.Minute, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.RemoveTaskIf("k", g2); err != nil || tw.HasTask("k") {
+		t.Fatal(err)
+	}
+}
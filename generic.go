@@ -0,0 +1,86 @@
+package timewheel
+
+import (
+	"context"
+	"time"
+)
+
+// TimeWheelOf time wheel whose task keys are of type K, the tasks are recorded in a map keyed by K.
+// The underlying wheel rejects the keys of another type with ErrKeyType.
+type TimeWheelOf[K comparable] struct {
+	tw     *TimeWheel
+	record *recordOf[K]
+}
+
+// NewOf create a empty time wheel with keys of type K
+func NewOf[K comparable](interval time.Duration, slotNum int, opts ...Option) *TimeWheelOf[K] {
+	record := newRecordOf[K]()
+	tw := New(interval, slotNum, append([]Option{withRecord(record)}, opts...)...)
+	if tw == nil {
+		return nil
+	}
+	return &TimeWheelOf[K]{tw: tw, record: record}
+}
+
+// record the tasks in r instead of a record keyed by interface{}
+func withRecord(r taskRecord) Option {
+	return func(tw *TimeWheel) {
+		tw.taskRecord = r
+	}
+}
+
+// Wheel get the underlying time wheel, used for the introspection apis
+func (w *TimeWheelOf[K]) Wheel() *TimeWheel {
+	return w.tw
+}
+
+// Start start the time wheel
+func (w *TimeWheelOf[K]) Start() {
+	w.tw.Start()
+}
+
+// Stop stop the time wheel
+func (w *TimeWheelOf[K]) Stop() {
+	w.tw.Stop()
+}
+
+// AddTask add new task to the time wheel
+func (w *TimeWheelOf[K]) AddTask(interval time.Duration, times int, key K, data TaskData, job Job) error {
+	return w.tw.AddTask(interval, times, key, data, job)
+}
+
+// AddTaskContext add new task, giving up when ctx is done
+func (w *TimeWheelOf[K]) AddTaskContext(ctx context.Context, interval time.Duration, times int, key K, data TaskData, job Job) error {
+	return w.tw.AddTaskContext(ctx, interval, times, key, data, job)
+}
+
+// RemoveTask remove the task from time wheel
+func (w *TimeWheelOf[K]) RemoveTask(key K) error {
+	return w.tw.RemoveTask(key)
+}
+
+// UpdateTask update task interval and data
+func (w *TimeWheelOf[K]) UpdateTask(key K, interval time.Duration, taskData TaskData) error {
+	return w.tw.UpdateTask(key, interval, taskData)
+}
+
+// HasTask report whether the task is registered
+func (w *TimeWheelOf[K]) HasTask(key K) bool {
+	_, ok := w.record.load(key)
+	return ok
+}
+
+// AddTaskT add new task carrying a payload of type T instead of TaskData,
+// the payload is passed to every run untouched, UpdateTask only replaces the TaskData
+func AddTaskT[K comparable, T any](w *TimeWheelOf[K], interval time.Duration, times int, key K, data T, job func(T)) error {
+	if job == nil {
+		return ErrInvalidParams
+	}
+	task, err := w.tw.newTask(interval, times, key, nil, func(context.Context, TaskData) {
+		job(data)
+	})
+	if err != nil {
+		return err
+	}
+	return w.tw.submit(context.Background(), task)
+}
@@ -0,0 +1,226 @@
+package timewheel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeWheelOfString(t *testing.T) {
+	c := newFakeClock()
+	tw := NewOf[string](time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	runs := make(chan string, 10)
+	if err := tw.AddTask(time.Second, 2, "a", TaskData{"n": 1}, func(data TaskData) { runs <- "a" }); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.AddTask(time.Second, -1, "b", nil, func(TaskData) { runs <- "b" }); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.AddTask(time.Second, 1, "a", nil, func(TaskData) {}); err != ErrDuplicateKey {
+		t.Fatal(err)
+	}
+	if !tw.HasTask("a") || tw.HasTask("c") {
+		t.Fatal("has task")
+	}
+	if err := tw.UpdateTask("b", 2*time.Second, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.RemoveTask("b"); err != nil {
+		t.Fatal(err)
+	}
+	if tw.HasTask("b") || tw.RemoveTask("b") != ErrTaskNotFound {
+		t.Fatal("removed task")
+	}
+	for i := 0; i < 4; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw.Wheel())
+	if len(runs) != 2 || <-runs != "a" || <-runs != "a" {
+		t.Fatal("runs", len(runs))
+	}
+	if tw.HasTask("a") || tw.Wheel().Len() != 0 {
+		t.Fatal("finished task", tw.Wheel().Len())
+	}
+}
+
+func TestTimeWheelOfUint64(t *testing.T) {
+	c := newFakeClock()
+	tw := NewOf[uint64](time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	runs := make(chan uint64, 10)
+	for id := uint64(1); id <= 3; id++ {
+		id := id
+		if err := tw.AddTask(time.Second, 1, id<<40, nil, func(TaskData) { runs <- id }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !tw.HasTask(2<<40) || tw.HasTask(2) {
+		t.Fatal("has task")
+	}
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	sum := <-runs + <-runs + <-runs
+	if sum != 6 {
+		t.Fatal(sum)
+	}
+}
+
+func TestTimeWheelOfKeyType(t *testing.T) {
+	tw := NewOf[uint64](time.Second, 10)
+	tw.Start()
+	defer tw.Stop()
+	// the untyped api of the underlying wheel only takes the keys of the type
+	if err := tw.Wheel().AddTask(time.Second, 1, "x", nil, func(TaskData) {}); err != ErrKeyType {
+		t.Fatal(err)
+	}
+	if err := tw.Wheel().AddTask(time.Second, 1, 1, nil, func(TaskData) {}); err != ErrKeyType {
+		t.Fatal("int key", err)
+	}
+	if err := tw.Wheel().AddTask(time.Second, 1, uint64(1), nil, func(TaskData) {}); err != nil {
+		t.Fatal(err)
+	}
+	if !tw.HasTask(1) || !tw.Wheel().HasTask(uint64(1)) || tw.Wheel().HasTask(1) {
+		t.Fatal("has task")
+	}
+	if err := tw.Wheel().RemoveTask("x"); err != ErrTaskNotFound {
+		t.Fatal(err)
+	}
+}
+
+// add, look up and remove n keys, the typed wheel records them in a map[uint64]*task
+func BenchmarkTypedKeys(b *testing.B) {
+	const n = 1000
+	job := func(TaskData) {}
+	b.Run("interface", func(b *testing.B) {
+		tw := New(time.Second, 64)
+		tw.Start()
+		defer tw.Stop()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for k := uint64(0); k < n; k++ {
+				tw.AddTask(time.Hour, 1, k<<20, nil, job)
+			}
+			for k := uint64(0); k < n; k++ {
+				tw.HasTask(k << 20)
+			}
+			for k := uint64(0); k < n; k++ {
+				tw.RemoveTask(k << 20)
+			}
+		}
+	})
+	b.Run("typed", func(b *testing.B) {
+		tw := NewOf[uint64](time.Second, 64)
+		tw.Start()
+		defer tw.Stop()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for k := uint64(0); k < n; k++ {
+				tw.AddTask(time.Hour, 1, k<<20, nil, job)
+			}
+			for k := uint64(0); k < n; k++ {
+				tw.HasTask(k << 20)
+			}
+			for k := uint64(0); k < n; k++ {
+				tw.RemoveTask(k << 20)
+			}
+		}
+	})
+}
+
+// look up n keys, the typed wheel does not box them
+func BenchmarkTypedHasTask(b *testing.B) {
+	const n = 1000
+	job := func(TaskData) {}
+	untyped, typed := New(time.Second, 64), NewOf[uint64](time.Second, 64)
+	untyped.Start()
+	defer untyped.Stop()
+	typed.Start()
+	defer typed.Stop()
+	for k := uint64(0); k < n; k++ {
+		untyped.AddTask(time.Hour, 1, k<<20, nil, job)
+		typed.AddTask(time.Hour, 1, k<<20, nil, job)
+	}
+	b.Run("interface", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			untyped.HasTask(uint64(i%n) << 20)
+		}
+	})
+	b.Run("typed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			typed.HasTask(uint64(i%n) << 20)
+		}
+	})
+}
+
+type payload struct {
+	ID    uint64
+	Email string
+}
+
+func TestAddTaskT(t *testing.T) {
+	c := newFakeClock()
+	tw := NewOf[string](time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	got := make(chan payload, 10)
+	p := payload{ID: 7, Email: "a@example.com"}
+	if err := AddTaskT(tw, time.Second, 3, "p", p, func(v payload) { got <- v }); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddTaskT[string, payload](tw, time.Second, 1, "nil", p, nil); err != ErrInvalidParams {
+		t.Fatal(err)
+	}
+	if err := AddTaskT(tw, time.Second, 1, "p", 1, func(int) {}); err != ErrDuplicateKey {
+		t.Fatal(err)
+	}
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	if v := <-got; v != p {
+		t.Fatal(v)
+	}
+	// the payload is kept by the re-insertions and the update
+	if err := tw.UpdateTask("p", 2*time.Second, TaskData{"x": 1}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 6; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw.Wheel())
+	if len(got) != 2 || <-got != p || <-got != p {
+		t.Fatal("runs", len(got))
+	}
+	if tw.HasTask("p") {
+		t.Fatal("finished task")
+	}
+}
+
+// allocations per task of a struct payload against a TaskData map, run with -benchtime 1000000x for a million tasks
+func BenchmarkTypedPayload(b *testing.B) {
+	b.Run("map", func(b *testing.B) {
+		tw := NewOf[uint64](time.Second, 64)
+		tw.Start()
+		defer tw.Stop()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			data := TaskData{"id": uint64(i), "email": "a@example.com"}
+			tw.AddTask(time.Hour, 1, uint64(i), data, func(data TaskData) {
+				_ = data["id"].(uint64)
+			})
+		}
+	})
+	b.Run("struct", func(b *testing.B) {
+		tw := NewOf[uint64](time.Second, 64)
+		tw.Start()
+		defer tw.Stop()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			AddTaskT(tw, time.Hour, 1, uint64(i), payload{ID: uint64(i), Email: "a@example.com"}, func(p payload) {
+				_ = p.ID
+			})
+		}
+	})
+}
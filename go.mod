@@ -0,0 +1,3 @@
+module github.com/nosixtools/timewheel
+
+go 1.24
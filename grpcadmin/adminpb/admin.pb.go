@@ -0,0 +1,875 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: admin.proto
+
+package adminpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type EventType int32
+
+const (
+	EventType_EVENT_TYPE_ADDED             EventType = 0
+	EventType_EVENT_TYPE_FIRED             EventType = 1
+	EventType_EVENT_TYPE_COMPLETED         EventType = 2
+	EventType_EVENT_TYPE_REMOVED           EventType = 3
+	EventType_EVENT_TYPE_DROPPED           EventType = 4
+	EventType_EVENT_TYPE_EXPIRED           EventType = 5
+	EventType_EVENT_TYPE_BREAKER_OPEN      EventType = 6
+	EventType_EVENT_TYPE_BREAKER_HALF_OPEN EventType = 7
+	EventType_EVENT_TYPE_BREAKER_CLOSED    EventType = 8
+)
+
+// Enum value maps for EventType.
+var (
+	EventType_name = map[int32]string{
+		0: "EVENT_TYPE_ADDED",
+		1: "EVENT_TYPE_FIRED",
+		2: "EVENT_TYPE_COMPLETED",
+		3: "EVENT_TYPE_REMOVED",
+		4: "EVENT_TYPE_DROPPED",
+		5: "EVENT_TYPE_EXPIRED",
+		6: "EVENT_TYPE_BREAKER_OPEN",
+		7: "EVENT_TYPE_BREAKER_HALF_OPEN",
+		8: "EVENT_TYPE_BREAKER_CLOSED",
+	}
+	EventType_value = map[string]int32{
+		"EVENT_TYPE_ADDED":             0,
+		"EVENT_TYPE_FIRED":             1,
+		"EVENT_TYPE_COMPLETED":         2,
+		"EVENT_TYPE_REMOVED":           3,
+		"EVENT_TYPE_DROPPED":           4,
+		"EVENT_TYPE_EXPIRED":           5,
+		"EVENT_TYPE_BREAKER_OPEN":      6,
+		"EVENT_TYPE_BREAKER_HALF_OPEN": 7,
+		"EVENT_TYPE_BREAKER_CLOSED":    8,
+	}
+)
+
+func (x EventType) Enum() *EventType {
+	p := new(EventType)
+	*p = x
+	return p
+}
+
+func (x EventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_admin_proto_enumTypes[0].Descriptor()
+}
+
+func (EventType) Type() protoreflect.EnumType {
+	return &file_admin_proto_enumTypes[0]
+}
+
+func (x EventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventType.Descriptor instead.
+func (EventType) EnumDescriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{0}
+}
+
+type ListTasksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// number of tasks per page, default 100, at most 1000
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token of the previous page, empty for the first page
+	PageToken     string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksRequest) Reset() {
+	*x = ListTasksRequest{}
+	mi := &file_admin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksRequest) ProtoMessage() {}
+
+func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListTasksRequest) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListTasksRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListTasksRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListTasksResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Tasks []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	// empty on the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	Total         int32  `protobuf:"varint,3,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTasksResponse) Reset() {
+	*x = ListTasksResponse{}
+	mi := &file_admin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksResponse) ProtoMessage() {}
+
+func (x *ListTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksResponse.ProtoReflect.Descriptor instead.
+func (*ListTasksResponse) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListTasksResponse) GetTasks() []*Task {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+func (x *ListTasksResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListTasksResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type TaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskRequest) Reset() {
+	*x = TaskRequest{}
+	mi := &file_admin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskRequest) ProtoMessage() {}
+
+func (x *TaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskRequest.ProtoReflect.Descriptor instead.
+func (*TaskRequest) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TaskRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type TaskReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskReply) Reset() {
+	*x = TaskReply{}
+	mi := &file_admin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskReply) ProtoMessage() {}
+
+func (x *TaskReply) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskReply.ProtoReflect.Descriptor instead.
+func (*TaskReply) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{3}
+}
+
+type Task struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Key      string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Interval *durationpb.Duration   `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	// remaining runs, -1 means no limit
+	Times  int32 `protobuf:"varint,3,opt,name=times,proto3" json:"times,omitempty"`
+	Paused bool  `protobuf:"varint,4,opt,name=paused,proto3" json:"paused,omitempty"`
+	// set by GetTask only
+	Stats         *TaskStats `protobuf:"bytes,5,opt,name=stats,proto3" json:"stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Task) Reset() {
+	*x = Task{}
+	mi := &file_admin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Task) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Task) ProtoMessage() {}
+
+func (x *Task) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Task.ProtoReflect.Descriptor instead.
+func (*Task) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Task) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Task) GetInterval() *durationpb.Duration {
+	if x != nil {
+		return x.Interval
+	}
+	return nil
+}
+
+func (x *Task) GetTimes() int32 {
+	if x != nil {
+		return x.Times
+	}
+	return 0
+}
+
+func (x *Task) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *Task) GetStats() *TaskStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+type TaskStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Runs          int64                  `protobuf:"varint,1,opt,name=runs,proto3" json:"runs,omitempty"`
+	LastFire      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=last_fire,json=lastFire,proto3" json:"last_fire,omitempty"`
+	LastDuration  *durationpb.Duration   `protobuf:"bytes,3,opt,name=last_duration,json=lastDuration,proto3" json:"last_duration,omitempty"`
+	LastError     string                 `protobuf:"bytes,4,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	Failures      int64                  `protobuf:"varint,5,opt,name=failures,proto3" json:"failures,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskStats) Reset() {
+	*x = TaskStats{}
+	mi := &file_admin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskStats) ProtoMessage() {}
+
+func (x *TaskStats) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskStats.ProtoReflect.Descriptor instead.
+func (*TaskStats) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TaskStats) GetRuns() int64 {
+	if x != nil {
+		return x.Runs
+	}
+	return 0
+}
+
+func (x *TaskStats) GetLastFire() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastFire
+	}
+	return nil
+}
+
+func (x *TaskStats) GetLastDuration() *durationpb.Duration {
+	if x != nil {
+		return x.LastDuration
+	}
+	return nil
+}
+
+func (x *TaskStats) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *TaskStats) GetFailures() int64 {
+	if x != nil {
+		return x.Failures
+	}
+	return 0
+}
+
+type WheelStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WheelStatusRequest) Reset() {
+	*x = WheelStatusRequest{}
+	mi := &file_admin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WheelStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WheelStatusRequest) ProtoMessage() {}
+
+func (x *WheelStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WheelStatusRequest.ProtoReflect.Descriptor instead.
+func (*WheelStatusRequest) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{6}
+}
+
+type WheelStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         int64                  `protobuf:"varint,1,opt,name=tasks,proto3" json:"tasks,omitempty"`
+	Added         int64                  `protobuf:"varint,2,opt,name=added,proto3" json:"added,omitempty"`
+	Fired         int64                  `protobuf:"varint,3,opt,name=fired,proto3" json:"fired,omitempty"`
+	Removed       int64                  `protobuf:"varint,4,opt,name=removed,proto3" json:"removed,omitempty"`
+	Expired       int64                  `protobuf:"varint,5,opt,name=expired,proto3" json:"expired,omitempty"`
+	Ticks         int64                  `protobuf:"varint,6,opt,name=ticks,proto3" json:"ticks,omitempty"`
+	Position      int64                  `protobuf:"varint,7,opt,name=position,proto3" json:"position,omitempty"`
+	InFlight      int64                  `protobuf:"varint,8,opt,name=in_flight,json=inFlight,proto3" json:"in_flight,omitempty"`
+	Backlog       int64                  `protobuf:"varint,9,opt,name=backlog,proto3" json:"backlog,omitempty"`
+	Interval      *durationpb.Duration   `protobuf:"bytes,10,opt,name=interval,proto3" json:"interval,omitempty"`
+	LastTick      *durationpb.Duration   `protobuf:"bytes,11,opt,name=last_tick,json=lastTick,proto3" json:"last_tick,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WheelStatusResponse) Reset() {
+	*x = WheelStatusResponse{}
+	mi := &file_admin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WheelStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WheelStatusResponse) ProtoMessage() {}
+
+func (x *WheelStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WheelStatusResponse.ProtoReflect.Descriptor instead.
+func (*WheelStatusResponse) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *WheelStatusResponse) GetTasks() int64 {
+	if x != nil {
+		return x.Tasks
+	}
+	return 0
+}
+
+func (x *WheelStatusResponse) GetAdded() int64 {
+	if x != nil {
+		return x.Added
+	}
+	return 0
+}
+
+func (x *WheelStatusResponse) GetFired() int64 {
+	if x != nil {
+		return x.Fired
+	}
+	return 0
+}
+
+func (x *WheelStatusResponse) GetRemoved() int64 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+func (x *WheelStatusResponse) GetExpired() int64 {
+	if x != nil {
+		return x.Expired
+	}
+	return 0
+}
+
+func (x *WheelStatusResponse) GetTicks() int64 {
+	if x != nil {
+		return x.Ticks
+	}
+	return 0
+}
+
+func (x *WheelStatusResponse) GetPosition() int64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *WheelStatusResponse) GetInFlight() int64 {
+	if x != nil {
+		return x.InFlight
+	}
+	return 0
+}
+
+func (x *WheelStatusResponse) GetBacklog() int64 {
+	if x != nil {
+		return x.Backlog
+	}
+	return 0
+}
+
+func (x *WheelStatusResponse) GetInterval() *durationpb.Duration {
+	if x != nil {
+		return x.Interval
+	}
+	return nil
+}
+
+func (x *WheelStatusResponse) GetLastTick() *durationpb.Duration {
+	if x != nil {
+		return x.LastTick
+	}
+	return nil
+}
+
+type WatchEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// buffer of the subscription, the events are dropped while it is full
+	Buffer int32 `protobuf:"varint,1,opt,name=buffer,proto3" json:"buffer,omitempty"`
+	// only stream the events of these types, empty means every type
+	Types         []EventType `protobuf:"varint,2,rep,packed,name=types,proto3,enum=timewheel.admin.v1.EventType" json:"types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_admin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WatchEventsRequest) GetBuffer() int32 {
+	if x != nil {
+		return x.Buffer
+	}
+	return 0
+}
+
+func (x *WatchEventsRequest) GetTypes() []EventType {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+type TaskEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          EventType              `protobuf:"varint,1,opt,name=type,proto3,enum=timewheel.admin.v1.EventType" json:"type,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	Task          *Task                  `protobuf:"bytes,3,opt,name=task,proto3" json:"task,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskEvent) Reset() {
+	*x = TaskEvent{}
+	mi := &file_admin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskEvent) ProtoMessage() {}
+
+func (x *TaskEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_admin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskEvent.ProtoReflect.Descriptor instead.
+func (*TaskEvent) Descriptor() ([]byte, []int) {
+	return file_admin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *TaskEvent) GetType() EventType {
+	if x != nil {
+		return x.Type
+	}
+	return EventType_EVENT_TYPE_ADDED
+}
+
+func (x *TaskEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *TaskEvent) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+var File_admin_proto protoreflect.FileDescriptor
+
+const file_admin_proto_rawDesc = "" +
+	"\n" +
+	"\vadmin.proto\x12\x12timewheel.admin.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"N\n" +
+	"\x10ListTasksRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\"\x81\x01\n" +
+	"\x11ListTasksResponse\x12.\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x18.timewheel.admin.v1.TaskR\x05tasks\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x14\n" +
+	"\x05total\x18\x03 \x01(\x05R\x05total\"\x1f\n" +
+	"\vTaskRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"\v\n" +
+	"\tTaskReply\"\xb2\x01\n" +
+	"\x04Task\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x125\n" +
+	"\binterval\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\binterval\x12\x14\n" +
+	"\x05times\x18\x03 \x01(\x05R\x05times\x12\x16\n" +
+	"\x06paused\x18\x04 \x01(\bR\x06paused\x123\n" +
+	"\x05stats\x18\x05 \x01(\v2\x1d.timewheel.admin.v1.TaskStatsR\x05stats\"\xd3\x01\n" +
+	"\tTaskStats\x12\x12\n" +
+	"\x04runs\x18\x01 \x01(\x03R\x04runs\x127\n" +
+	"\tlast_fire\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\blastFire\x12>\n" +
+	"\rlast_duration\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\flastDuration\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x04 \x01(\tR\tlastError\x12\x1a\n" +
+	"\bfailures\x18\x05 \x01(\x03R\bfailures\"\x14\n" +
+	"\x12WheelStatusRequest\"\xe3\x02\n" +
+	"\x13WheelStatusResponse\x12\x14\n" +
+	"\x05tasks\x18\x01 \x01(\x03R\x05tasks\x12\x14\n" +
+	"\x05added\x18\x02 \x01(\x03R\x05added\x12\x14\n" +
+	"\x05fired\x18\x03 \x01(\x03R\x05fired\x12\x18\n" +
+	"\aremoved\x18\x04 \x01(\x03R\aremoved\x12\x18\n" +
+	"\aexpired\x18\x05 \x01(\x03R\aexpired\x12\x14\n" +
+	"\x05ticks\x18\x06 \x01(\x03R\x05ticks\x12\x1a\n" +
+	"\bposition\x18\a \x01(\x03R\bposition\x12\x1b\n" +
+	"\tin_flight\x18\b \x01(\x03R\binFlight\x12\x18\n" +
+	"\abacklog\x18\t \x01(\x03R\abacklog\x125\n" +
+	"\binterval\x18\n" +
+	" \x01(\v2\x19.google.protobuf.DurationR\binterval\x126\n" +
+	"\tlast_tick\x18\v \x01(\v2\x19.google.protobuf.DurationR\blastTick\"a\n" +
+	"\x12WatchEventsRequest\x12\x16\n" +
+	"\x06buffer\x18\x01 \x01(\x05R\x06buffer\x123\n" +
+	"\x05types\x18\x02 \x03(\x0e2\x1d.timewheel.admin.v1.EventTypeR\x05types\"\x9c\x01\n" +
+	"\tTaskEvent\x121\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x1d.timewheel.admin.v1.EventTypeR\x04type\x12.\n" +
+	"\x04time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12,\n" +
+	"\x04task\x18\x03 \x01(\v2\x18.timewheel.admin.v1.TaskR\x04task*\xf7\x01\n" +
+	"\tEventType\x12\x14\n" +
+	"\x10EVENT_TYPE_ADDED\x10\x00\x12\x14\n" +
+	"\x10EVENT_TYPE_FIRED\x10\x01\x12\x18\n" +
+	"\x14EVENT_TYPE_COMPLETED\x10\x02\x12\x16\n" +
+	"\x12EVENT_TYPE_REMOVED\x10\x03\x12\x16\n" +
+	"\x12EVENT_TYPE_DROPPED\x10\x04\x12\x16\n" +
+	"\x12EVENT_TYPE_EXPIRED\x10\x05\x12\x1b\n" +
+	"\x17EVENT_TYPE_BREAKER_OPEN\x10\x06\x12 \n" +
+	"\x1cEVENT_TYPE_BREAKER_HALF_OPEN\x10\a\x12\x1d\n" +
+	"\x19EVENT_TYPE_BREAKER_CLOSED\x10\b2\x9b\x05\n" +
+	"\x0eTimeWheelAdmin\x12X\n" +
+	"\tListTasks\x12$.timewheel.admin.v1.ListTasksRequest\x1a%.timewheel.admin.v1.ListTasksResponse\x12D\n" +
+	"\aGetTask\x12\x1f.timewheel.admin.v1.TaskRequest\x1a\x18.timewheel.admin.v1.Task\x12L\n" +
+	"\n" +
+	"RemoveTask\x12\x1f.timewheel.admin.v1.TaskRequest\x1a\x1d.timewheel.admin.v1.TaskReply\x12K\n" +
+	"\tPauseTask\x12\x1f.timewheel.admin.v1.TaskRequest\x1a\x1d.timewheel.admin.v1.TaskReply\x12L\n" +
+	"\n" +
+	"ResumeTask\x12\x1f.timewheel.admin.v1.TaskRequest\x1a\x1d.timewheel.admin.v1.TaskReply\x12H\n" +
+	"\x06RunNow\x12\x1f.timewheel.admin.v1.TaskRequest\x1a\x1d.timewheel.admin.v1.TaskReply\x12^\n" +
+	"\vWheelStatus\x12&.timewheel.admin.v1.WheelStatusRequest\x1a'.timewheel.admin.v1.WheelStatusResponse\x12V\n" +
+	"\vWatchEvents\x12&.timewheel.admin.v1.WatchEventsRequest\x1a\x1d.timewheel.admin.v1.TaskEvent0\x01B3Z1github.com/nosixtools/timewheel/grpcadmin/adminpbb\x06proto3"
+
+var (
+	file_admin_proto_rawDescOnce sync.Once
+	file_admin_proto_rawDescData []byte
+)
+
+func file_admin_proto_rawDescGZIP() []byte {
+	file_admin_proto_rawDescOnce.Do(func() {
+		file_admin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_admin_proto_rawDesc), len(file_admin_proto_rawDesc)))
+	})
+	return file_admin_proto_rawDescData
+}
+
+var file_admin_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_admin_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_admin_proto_goTypes = []any{
+	(EventType)(0),                // 0: timewheel.admin.v1.EventType
+	(*ListTasksRequest)(nil),      // 1: timewheel.admin.v1.ListTasksRequest
+	(*ListTasksResponse)(nil),     // 2: timewheel.admin.v1.ListTasksResponse
+	(*TaskRequest)(nil),           // 3: timewheel.admin.v1.TaskRequest
+	(*TaskReply)(nil),             // 4: timewheel.admin.v1.TaskReply
+	(*Task)(nil),                  // 5: timewheel.admin.v1.Task
+	(*TaskStats)(nil),             // 6: timewheel.admin.v1.TaskStats
+	(*WheelStatusRequest)(nil),    // 7: timewheel.admin.v1.WheelStatusRequest
+	(*WheelStatusResponse)(nil),   // 8: timewheel.admin.v1.WheelStatusResponse
+	(*WatchEventsRequest)(nil),    // 9: timewheel.admin.v1.WatchEventsRequest
+	(*TaskEvent)(nil),             // 10: timewheel.admin.v1.TaskEvent
+	(*durationpb.Duration)(nil),   // 11: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+}
+var file_admin_proto_depIdxs = []int32{
+	5,  // 0: timewheel.admin.v1.ListTasksResponse.tasks:type_name -> timewheel.admin.v1.Task
+	11, // 1: timewheel.admin.v1.Task.interval:type_name -> google.protobuf.Duration
+	6,  // 2: timewheel.admin.v1.Task.stats:type_name -> timewheel.admin.v1.TaskStats
+	12, // 3: timewheel.admin.v1.TaskStats.last_fire:type_name -> google.protobuf.Timestamp
+	11, // 4: timewheel.admin.v1.TaskStats.last_duration:type_name -> google.protobuf.Duration
+	11, // 5: timewheel.admin.v1.WheelStatusResponse.interval:type_name -> google.protobuf.Duration
+	11, // 6: timewheel.admin.v1.WheelStatusResponse.last_tick:type_name -> google.protobuf.Duration
+	0,  // 7: timewheel.admin.v1.WatchEventsRequest.types:type_name -> timewheel.admin.v1.EventType
+	0,  // 8: timewheel.admin.v1.TaskEvent.type:type_name -> timewheel.admin.v1.EventType
+	12, // 9: timewheel.admin.v1.TaskEvent.time:type_name -> google.protobuf.Timestamp
+	5,  // 10: timewheel.admin.v1.TaskEvent.task:type_name -> timewheel.admin.v1.Task
+	1,  // 11: timewheel.admin.v1.TimeWheelAdmin.ListTasks:input_type -> timewheel.admin.v1.ListTasksRequest
+	3,  // 12: timewheel.admin.v1.TimeWheelAdmin.GetTask:input_type -> timewheel.admin.v1.TaskRequest
+	3,  // 13: timewheel.admin.v1.TimeWheelAdmin.RemoveTask:input_type -> timewheel.admin.v1.TaskRequest
+	3,  // 14: timewheel.admin.v1.TimeWheelAdmin.PauseTask:input_type -> timewheel.admin.v1.TaskRequest
+	3,  // 15: timewheel.admin.v1.TimeWheelAdmin.ResumeTask:input_type -> timewheel.admin.v1.TaskRequest
+	3,  // 16: timewheel.admin.v1.TimeWheelAdmin.RunNow:input_type -> timewheel.admin.v1.TaskRequest
+	7,  // 17: timewheel.admin.v1.TimeWheelAdmin.WheelStatus:input_type -> timewheel.admin.v1.WheelStatusRequest
+	9,  // 18: timewheel.admin.v1.TimeWheelAdmin.WatchEvents:input_type -> timewheel.admin.v1.WatchEventsRequest
+	2,  // 19: timewheel.admin.v1.TimeWheelAdmin.ListTasks:output_type -> timewheel.admin.v1.ListTasksResponse
+	5,  // 20: timewheel.admin.v1.TimeWheelAdmin.GetTask:output_type -> timewheel.admin.v1.Task
+	4,  // 21: timewheel.admin.v1.TimeWheelAdmin.RemoveTask:output_type -> timewheel.admin.v1.TaskReply
+	4,  // 22: timewheel.admin.v1.TimeWheelAdmin.PauseTask:output_type -> timewheel.admin.v1.TaskReply
+	4,  // 23: timewheel.admin.v1.TimeWheelAdmin.ResumeTask:output_type -> timewheel.admin.v1.TaskReply
+	4,  // 24: timewheel.admin.v1.TimeWheelAdmin.RunNow:output_type -> timewheel.admin.v1.TaskReply
+	8,  // 25: timewheel.admin.v1.TimeWheelAdmin.WheelStatus:output_type -> timewheel.admin.v1.WheelStatusResponse
+	10, // 26: timewheel.admin.v1.TimeWheelAdmin.WatchEvents:output_type -> timewheel.admin.v1.TaskEvent
+	19, // [19:27] is the sub-list for method output_type
+	11, // [11:19] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_admin_proto_init() }
+func file_admin_proto_init() {
+	if File_admin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_admin_proto_rawDesc), len(file_admin_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_admin_proto_goTypes,
+		DependencyIndexes: file_admin_proto_depIdxs,
+		EnumInfos:         file_admin_proto_enumTypes,
+		MessageInfos:      file_admin_proto_msgTypes,
+	}.Build()
+	File_admin_proto = out.File
+	file_admin_proto_goTypes = nil
+	file_admin_proto_depIdxs = nil
+}
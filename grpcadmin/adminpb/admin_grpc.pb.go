@@ -0,0 +1,411 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: admin.proto
+
+package adminpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TimeWheelAdmin_ListTasks_FullMethodName   = "/timewheel.admin.v1.TimeWheelAdmin/ListTasks"
+	TimeWheelAdmin_GetTask_FullMethodName     = "/timewheel.admin.v1.TimeWheelAdmin/GetTask"
+	TimeWheelAdmin_RemoveTask_FullMethodName  = "/timewheel.admin.v1.TimeWheelAdmin/RemoveTask"
+	TimeWheelAdmin_PauseTask_FullMethodName   = "/timewheel.admin.v1.TimeWheelAdmin/PauseTask"
+	TimeWheelAdmin_ResumeTask_FullMethodName  = "/timewheel.admin.v1.TimeWheelAdmin/ResumeTask"
+	TimeWheelAdmin_RunNow_FullMethodName      = "/timewheel.admin.v1.TimeWheelAdmin/RunNow"
+	TimeWheelAdmin_WheelStatus_FullMethodName = "/timewheel.admin.v1.TimeWheelAdmin/WheelStatus"
+	TimeWheelAdmin_WatchEvents_FullMethodName = "/timewheel.admin.v1.TimeWheelAdmin/WatchEvents"
+)
+
+// TimeWheelAdminClient is the client API for TimeWheelAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TimeWheelAdmin manage the tasks of a running time wheel, tasks are addressed by the string form of their key
+type TimeWheelAdminClient interface {
+	// ListTasks list the tasks ordered by key
+	ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*ListTasksResponse, error)
+	// GetTask info and stats of a task
+	GetTask(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*Task, error)
+	// RemoveTask remove the task
+	RemoveTask(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*TaskReply, error)
+	// PauseTask pause the task
+	PauseTask(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*TaskReply, error)
+	// ResumeTask resume the task
+	ResumeTask(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*TaskReply, error)
+	// RunNow dispatch an extra run of the task right away
+	RunNow(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*TaskReply, error)
+	// WheelStatus counters of the wheel
+	WheelStatus(ctx context.Context, in *WheelStatusRequest, opts ...grpc.CallOption) (*WheelStatusResponse, error)
+	// WatchEvents stream the lifecycle events of the tasks until the call is canceled
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskEvent], error)
+}
+
+type timeWheelAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTimeWheelAdminClient(cc grpc.ClientConnInterface) TimeWheelAdminClient {
+	return &timeWheelAdminClient{cc}
+}
+
+func (c *timeWheelAdminClient) ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*ListTasksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTasksResponse)
+	err := c.cc.Invoke(ctx, TimeWheelAdmin_ListTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *timeWheelAdminClient) GetTask(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*Task, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Task)
+	err := c.cc.Invoke(ctx, TimeWheelAdmin_GetTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *timeWheelAdminClient) RemoveTask(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*TaskReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskReply)
+	err := c.cc.Invoke(ctx, TimeWheelAdmin_RemoveTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *timeWheelAdminClient) PauseTask(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*TaskReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskReply)
+	err := c.cc.Invoke(ctx, TimeWheelAdmin_PauseTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *timeWheelAdminClient) ResumeTask(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*TaskReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskReply)
+	err := c.cc.Invoke(ctx, TimeWheelAdmin_ResumeTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *timeWheelAdminClient) RunNow(ctx context.Context, in *TaskRequest, opts ...grpc.CallOption) (*TaskReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskReply)
+	err := c.cc.Invoke(ctx, TimeWheelAdmin_RunNow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *timeWheelAdminClient) WheelStatus(ctx context.Context, in *WheelStatusRequest, opts ...grpc.CallOption) (*WheelStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WheelStatusResponse)
+	err := c.cc.Invoke(ctx, TimeWheelAdmin_WheelStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *timeWheelAdminClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TimeWheelAdmin_ServiceDesc.Streams[0], TimeWheelAdmin_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, TaskEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TimeWheelAdmin_WatchEventsClient = grpc.ServerStreamingClient[TaskEvent]
+
+// TimeWheelAdminServer is the server API for TimeWheelAdmin service.
+// All implementations must embed UnimplementedTimeWheelAdminServer
+// for forward compatibility.
+//
+// TimeWheelAdmin manage the tasks of a running time wheel, tasks are addressed by the string form of their key
+type TimeWheelAdminServer interface {
+	// ListTasks list the tasks ordered by key
+	ListTasks(context.Context, *ListTasksRequest) (*ListTasksResponse, error)
+	// GetTask info and stats of a task
+	GetTask(context.Context, *TaskRequest) (*Task, error)
+	// RemoveTask remove the task
+	RemoveTask(context.Context, *TaskRequest) (*TaskReply, error)
+	// PauseTask pause the task
+	PauseTask(context.Context, *TaskRequest) (*TaskReply, error)
+	// ResumeTask resume the task
+	ResumeTask(context.Context, *TaskRequest) (*TaskReply, error)
+	// RunNow dispatch an extra run of the task right away
+	RunNow(context.Context, *TaskRequest) (*TaskReply, error)
+	// WheelStatus counters of the wheel
+	WheelStatus(context.Context, *WheelStatusRequest) (*WheelStatusResponse, error)
+	// WatchEvents stream the lifecycle events of the tasks until the call is canceled
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[TaskEvent]) error
+	mustEmbedUnimplementedTimeWheelAdminServer()
+}
+
+// UnimplementedTimeWheelAdminServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTimeWheelAdminServer struct{}
+
+func (UnimplementedTimeWheelAdminServer) ListTasks(context.Context, *ListTasksRequest) (*ListTasksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTasks not implemented")
+}
+func (UnimplementedTimeWheelAdminServer) GetTask(context.Context, *TaskRequest) (*Task, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTask not implemented")
+}
+func (UnimplementedTimeWheelAdminServer) RemoveTask(context.Context, *TaskRequest) (*TaskReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveTask not implemented")
+}
+func (UnimplementedTimeWheelAdminServer) PauseTask(context.Context, *TaskRequest) (*TaskReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseTask not implemented")
+}
+func (UnimplementedTimeWheelAdminServer) ResumeTask(context.Context, *TaskRequest) (*TaskReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeTask not implemented")
+}
+func (UnimplementedTimeWheelAdminServer) RunNow(context.Context, *TaskRequest) (*TaskReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunNow not implemented")
+}
+func (UnimplementedTimeWheelAdminServer) WheelStatus(context.Context, *WheelStatusRequest) (*WheelStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WheelStatus not implemented")
+}
+func (UnimplementedTimeWheelAdminServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[TaskEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedTimeWheelAdminServer) mustEmbedUnimplementedTimeWheelAdminServer() {}
+func (UnimplementedTimeWheelAdminServer) testEmbeddedByValue()                        {}
+
+// UnsafeTimeWheelAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TimeWheelAdminServer will
+// result in compilation errors.
+type UnsafeTimeWheelAdminServer interface {
+	mustEmbedUnimplementedTimeWheelAdminServer()
+}
+
+func RegisterTimeWheelAdminServer(s grpc.ServiceRegistrar, srv TimeWheelAdminServer) {
+	// If the following call pancis, it indicates UnimplementedTimeWheelAdminServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TimeWheelAdmin_ServiceDesc, srv)
+}
+
+func _TimeWheelAdmin_ListTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TimeWheelAdminServer).ListTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TimeWheelAdmin_ListTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TimeWheelAdminServer).ListTasks(ctx, req.(*ListTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TimeWheelAdmin_GetTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TimeWheelAdminServer).GetTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TimeWheelAdmin_GetTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TimeWheelAdminServer).GetTask(ctx, req.(*TaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TimeWheelAdmin_RemoveTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TimeWheelAdminServer).RemoveTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TimeWheelAdmin_RemoveTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TimeWheelAdminServer).RemoveTask(ctx, req.(*TaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TimeWheelAdmin_PauseTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TimeWheelAdminServer).PauseTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TimeWheelAdmin_PauseTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TimeWheelAdminServer).PauseTask(ctx, req.(*TaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TimeWheelAdmin_ResumeTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TimeWheelAdminServer).ResumeTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TimeWheelAdmin_ResumeTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TimeWheelAdminServer).ResumeTask(ctx, req.(*TaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TimeWheelAdmin_RunNow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TimeWheelAdminServer).RunNow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TimeWheelAdmin_RunNow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TimeWheelAdminServer).RunNow(ctx, req.(*TaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TimeWheelAdmin_WheelStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WheelStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TimeWheelAdminServer).WheelStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TimeWheelAdmin_WheelStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TimeWheelAdminServer).WheelStatus(ctx, req.(*WheelStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TimeWheelAdmin_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TimeWheelAdminServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, TaskEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TimeWheelAdmin_WatchEventsServer = grpc.ServerStreamingServer[TaskEvent]
+
+// TimeWheelAdmin_ServiceDesc is the grpc.ServiceDesc for TimeWheelAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TimeWheelAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "timewheel.admin.v1.TimeWheelAdmin",
+	HandlerType: (*TimeWheelAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListTasks",
+			Handler:    _TimeWheelAdmin_ListTasks_Handler,
+		},
+		{
+			MethodName: "GetTask",
+			Handler:    _TimeWheelAdmin_GetTask_Handler,
+		},
+		{
+			MethodName: "RemoveTask",
+			Handler:    _TimeWheelAdmin_RemoveTask_Handler,
+		},
+		{
+			MethodName: "PauseTask",
+			Handler:    _TimeWheelAdmin_PauseTask_Handler,
+		},
+		{
+			MethodName: "ResumeTask",
+			Handler:    _TimeWheelAdmin_ResumeTask_Handler,
+		},
+		{
+			MethodName: "RunNow",
+			Handler:    _TimeWheelAdmin_RunNow_Handler,
+		},
+		{
+			MethodName: "WheelStatus",
+			Handler:    _TimeWheelAdmin_WheelStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _TimeWheelAdmin_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "admin.proto",
+}
@@ -0,0 +1,18 @@
+module github.com/nosixtools/timewheel/grpcadmin
+
+go 1.24
+
+require (
+	github.com/nosixtools/timewheel v0.0.0
+	google.golang.org/grpc v1.65.0
+	google.golang.org/protobuf v1.36.6
+)
+
+require (
+	golang.org/x/net v0.25.0 // indirect
+	golang.org/x/sys v0.20.0 // indirect
+	golang.org/x/text v0.15.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240528184218-531527333157 // indirect
+)
+
+replace github.com/nosixtools/timewheel => ../
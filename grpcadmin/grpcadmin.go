@@ -0,0 +1,238 @@
+// Package grpcadmin expose the tasks of a time wheel over grpc, the counterpart of TimeWheel.AdminHandler.
+//
+//	s := grpc.NewServer()
+//	adminpb.RegisterTimeWheelAdminServer(s, grpcadmin.New(tw, func(ctx context.Context) bool {
+//		return isOperator(ctx)
+//	}))
+//	s.Serve(lis)
+//
+// Tasks are addressed by the string form of their key. The mutating calls fail with PermissionDenied
+// unless guard is set and accepts the call, a nil guard makes the service read only.
+package grpcadmin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/nosixtools/timewheel"
+	"github.com/nosixtools/timewheel/grpcadmin/adminpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+	defaultBuffer   = 256
+)
+
+// Server the admin service of a time wheel
+type Server struct {
+	adminpb.UnimplementedTimeWheelAdminServer
+
+	tw    *timewheel.TimeWheel
+	guard func(ctx context.Context) bool
+}
+
+var _ adminpb.TimeWheelAdminServer = (*Server)(nil)
+
+// New create the service of the wheel, guard decides whether a mutating call is allowed
+func New(tw *timewheel.TimeWheel, guard func(ctx context.Context) bool) *Server {
+	return &Server{tw: tw, guard: guard}
+}
+
+func newTask(info timewheel.TaskInfo) *adminpb.Task {
+	return &adminpb.Task{
+		Key:      fmt.Sprint(info.Key),
+		Interval: durationpb.New(info.Interval),
+		Times:    int32(info.Times),
+		Paused:   info.Paused,
+	}
+}
+
+func newStats(st timewheel.Stats) *adminpb.TaskStats {
+	s := &adminpb.TaskStats{
+		Runs:         st.Runs,
+		LastDuration: durationpb.New(st.LastDuration),
+		Failures:     st.Failures,
+	}
+	if !st.LastFire.IsZero() {
+		s.LastFire = timestamppb.New(st.LastFire)
+	}
+	if st.LastError != nil {
+		s.LastError = st.LastError.Error()
+	}
+	return s
+}
+
+// ListTasks implement adminpb.TimeWheelAdminServer, the page token is the offset of the page
+func (s *Server) ListTasks(ctx context.Context, req *adminpb.ListTasksRequest) (*adminpb.ListTasksResponse, error) {
+	size := int(req.GetPageSize())
+	if size < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "illegal page size %d", size)
+	}
+	if size == 0 {
+		size = defaultPageSize
+	}
+	if size > maxPageSize {
+		size = maxPageSize
+	}
+	offset := 0
+	if token := req.GetPageToken(); token != "" {
+		n, err := strconv.Atoi(token)
+		if err != nil || n < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "illegal page token %q", token)
+		}
+		offset = n
+	}
+
+	tasks := make([]*adminpb.Task, 0, s.tw.Len())
+	s.tw.Range(func(key interface{}, info timewheel.TaskInfo) bool {
+		tasks = append(tasks, newTask(info))
+		return true
+	})
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Key < tasks[j].Key
+	})
+	resp := &adminpb.ListTasksResponse{Total: int32(len(tasks))}
+	if offset > len(tasks) {
+		offset = len(tasks)
+	}
+	tasks = tasks[offset:]
+	if len(tasks) > size {
+		tasks = tasks[:size]
+		resp.NextPageToken = strconv.Itoa(offset + size)
+	}
+	resp.Tasks = tasks
+	return resp, nil
+}
+
+// GetTask implement adminpb.TimeWheelAdminServer
+func (s *Server) GetTask(ctx context.Context, req *adminpb.TaskRequest) (*adminpb.Task, error) {
+	key, info, ok := s.find(req.GetKey())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %q not exists", req.GetKey())
+	}
+	task := newTask(info)
+	if st, err := s.tw.TaskStats(key); err == nil {
+		task.Stats = newStats(st)
+	}
+	return task, nil
+}
+
+// RemoveTask implement adminpb.TimeWheelAdminServer
+func (s *Server) RemoveTask(ctx context.Context, req *adminpb.TaskRequest) (*adminpb.TaskReply, error) {
+	return s.mutate(ctx, req.GetKey(), s.tw.RemoveTask)
+}
+
+// PauseTask implement adminpb.TimeWheelAdminServer
+func (s *Server) PauseTask(ctx context.Context, req *adminpb.TaskRequest) (*adminpb.TaskReply, error) {
+	return s.mutate(ctx, req.GetKey(), s.tw.PauseTask)
+}
+
+// ResumeTask implement adminpb.TimeWheelAdminServer
+func (s *Server) ResumeTask(ctx context.Context, req *adminpb.TaskRequest) (*adminpb.TaskReply, error) {
+	return s.mutate(ctx, req.GetKey(), s.tw.ResumeTask)
+}
+
+// RunNow implement adminpb.TimeWheelAdminServer
+func (s *Server) RunNow(ctx context.Context, req *adminpb.TaskRequest) (*adminpb.TaskReply, error) {
+	return s.mutate(ctx, req.GetKey(), s.tw.RunNow)
+}
+
+// WheelStatus implement adminpb.TimeWheelAdminServer
+func (s *Server) WheelStatus(ctx context.Context, req *adminpb.WheelStatusRequest) (*adminpb.WheelStatusResponse, error) {
+	st := s.tw.Stats()
+	return &adminpb.WheelStatusResponse{
+		Tasks:    st.Tasks,
+		Added:    st.Added,
+		Fired:    st.Fired,
+		Removed:  st.Removed,
+		Expired:  st.Expired,
+		Ticks:    st.Ticks,
+		Position: st.Position,
+		InFlight: st.InFlight,
+		Backlog:  int64(s.tw.Backlog()),
+		Interval: durationpb.New(s.tw.Interval()),
+		LastTick: durationpb.New(st.LastTick),
+	}, nil
+}
+
+// WatchEvents implement adminpb.TimeWheelAdminServer, the events dropped while the buffer is full are lost
+func (s *Server) WatchEvents(req *adminpb.WatchEventsRequest, stream adminpb.TimeWheelAdmin_WatchEventsServer) error {
+	buffer := int(req.GetBuffer())
+	if buffer <= 0 {
+		buffer = defaultBuffer
+	}
+	var types map[timewheel.EventType]bool
+	if len(req.GetTypes()) > 0 {
+		types = make(map[timewheel.EventType]bool)
+		for _, t := range req.GetTypes() {
+			types[timewheel.EventType(t)] = true
+		}
+	}
+	events, cancel := s.tw.Subscribe(buffer)
+	defer cancel()
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-events:
+			if types != nil && !types[ev.Type] {
+				continue
+			}
+			err := stream.Send(&adminpb.TaskEvent{
+				Type: adminpb.EventType(ev.Type),
+				Time: timestamppb.New(ev.Time),
+				Task: newTask(ev.Info),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// call fn with the key of the task, guarded
+func (s *Server) mutate(ctx context.Context, name string, fn func(key interface{}) error) (*adminpb.TaskReply, error) {
+	if s.guard == nil || !s.guard(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "forbidden")
+	}
+	key, _, ok := s.find(name)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %q not exists", name)
+	}
+	if err := fn(key); err != nil {
+		return nil, statusOf(err)
+	}
+	return &adminpb.TaskReply{}, nil
+}
+
+// find the task whose key prints as name
+func (s *Server) find(name string) (key interface{}, info timewheel.TaskInfo, ok bool) {
+	s.tw.Range(func(k interface{}, i timewheel.TaskInfo) bool {
+		if fmt.Sprint(k) == name {
+			key, info, ok = k, i, true
+			return false
+		}
+		return true
+	})
+	return
+}
+
+// map the errors of the wheel to grpc codes
+func statusOf(err error) error {
+	switch {
+	case errors.Is(err, timewheel.ErrTaskNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, timewheel.ErrWheelStopped):
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
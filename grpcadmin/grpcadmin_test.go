@@ -0,0 +1,206 @@
+package grpcadmin
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nosixtools/timewheel"
+	"github.com/nosixtools/timewheel/grpcadmin/adminpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// allow the calls carrying the operator role
+func operator(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	roles := md.Get("role")
+	return len(roles) > 0 && roles[0] == "operator"
+}
+
+func asOperator(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "role", "operator")
+}
+
+// serve the wheel on an in memory listener and dial it
+func dial(t *testing.T, tw *timewheel.TimeWheel, guard func(ctx context.Context) bool) adminpb.TimeWheelAdminClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	adminpb.RegisterTimeWheelAdminServer(s, New(tw, guard))
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return adminpb.NewTimeWheelAdminClient(conn)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func code(err error) codes.Code {
+	return status.Code(err)
+}
+
+func TestServer(t *testing.T) {
+	tw := timewheel.New(10*time.Millisecond, 10)
+	tw.Start()
+	defer tw.Stop()
+	var runs int32
+	for _, k := range []string{"a", "b", "c"} {
+		if err := tw.AddTask(time.Hour, -1, k, nil, func(timewheel.TaskData) {
+			atomic.AddInt32(&runs, 1)
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, "tasks", func() bool { return tw.Len() == 3 })
+	client := dial(t, tw, operator)
+	ctx := context.Background()
+
+	page, err := client.ListTasks(ctx, &adminpb.ListTasksRequest{PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 3 || len(page.Tasks) != 2 || page.Tasks[0].Key != "a" || page.Tasks[1].Key != "b" || page.NextPageToken != "2" {
+		t.Fatalf("first page: %v", page)
+	}
+	page, err = client.ListTasks(ctx, &adminpb.ListTasksRequest{PageSize: 2, PageToken: page.NextPageToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Tasks) != 1 || page.Tasks[0].Key != "c" || page.NextPageToken != "" {
+		t.Fatalf("last page: %v", page)
+	}
+	if _, err := client.ListTasks(ctx, &adminpb.ListTasksRequest{PageToken: "x"}); code(err) != codes.InvalidArgument {
+		t.Fatalf("bad token: %v", err)
+	}
+
+	task, err := client.GetTask(ctx, &adminpb.TaskRequest{Key: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Key != "b" || task.Interval.AsDuration() != time.Hour || task.Times != -1 || task.Paused || task.Stats == nil {
+		t.Fatalf("get: %v", task)
+	}
+	if _, err := client.GetTask(ctx, &adminpb.TaskRequest{Key: "x"}); code(err) != codes.NotFound {
+		t.Fatalf("get missing: %v", err)
+	}
+
+	// the mutating calls are refused without the role
+	if _, err := client.RemoveTask(ctx, &adminpb.TaskRequest{Key: "a"}); code(err) != codes.PermissionDenied {
+		t.Fatalf("unguarded remove: %v", err)
+	}
+	if _, err := client.RunNow(ctx, &adminpb.TaskRequest{Key: "a"}); code(err) != codes.PermissionDenied {
+		t.Fatalf("unguarded run: %v", err)
+	}
+	if tw.Len() != 3 {
+		t.Fatalf("len after refused remove: %d", tw.Len())
+	}
+
+	op := asOperator(ctx)
+	if _, err := client.PauseTask(op, &adminpb.TaskRequest{Key: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if task, err := client.GetTask(ctx, &adminpb.TaskRequest{Key: "a"}); err != nil || !task.Paused {
+		t.Fatalf("paused: %v %v", task, err)
+	}
+	if _, err := client.ResumeTask(op, &adminpb.TaskRequest{Key: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if task, err := client.GetTask(ctx, &adminpb.TaskRequest{Key: "a"}); err != nil || task.Paused {
+		t.Fatalf("resumed: %v %v", task, err)
+	}
+	if _, err := client.RunNow(op, &adminpb.TaskRequest{Key: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "run", func() bool { return atomic.LoadInt32(&runs) == 1 })
+
+	if _, err := client.RemoveTask(op, &adminpb.TaskRequest{Key: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if tw.Len() != 2 {
+		t.Fatalf("len after remove: %d", tw.Len())
+	}
+	if _, err := client.RemoveTask(op, &adminpb.TaskRequest{Key: "b"}); code(err) != codes.NotFound {
+		t.Fatalf("remove missing: %v", err)
+	}
+
+	st, err := client.WheelStatus(ctx, &adminpb.WheelStatusRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Tasks != 2 || st.Added != 3 || st.Removed != 1 || st.Interval.AsDuration() != 10*time.Millisecond {
+		t.Fatalf("status: %v", st)
+	}
+}
+
+func TestServerReadOnly(t *testing.T) {
+	tw := timewheel.New(10*time.Millisecond, 10)
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(time.Hour, -1, "a", nil, func(timewheel.TaskData) {})
+	waitFor(t, "task", func() bool { return tw.Len() == 1 })
+	client := dial(t, tw, nil)
+
+	op := asOperator(context.Background())
+	if _, err := client.PauseTask(op, &adminpb.TaskRequest{Key: "a"}); code(err) != codes.PermissionDenied {
+		t.Fatalf("pause: %v", err)
+	}
+	if _, err := client.GetTask(op, &adminpb.TaskRequest{Key: "a"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatchEvents(t *testing.T) {
+	tw := timewheel.New(10*time.Millisecond, 10)
+	tw.Start()
+	defer tw.Stop()
+	client := dial(t, tw, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.WatchEvents(ctx, &adminpb.WatchEventsRequest{
+		Types: []adminpb.EventType{adminpb.EventType_EVENT_TYPE_FIRED},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the task keeps firing, the first runs may pass before the server subscribed
+	tw.AddTask(20*time.Millisecond, -1, "tick", nil, func(timewheel.TaskData) {})
+	for i := 0; i < 2; i++ {
+		ev, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.Type != adminpb.EventType_EVENT_TYPE_FIRED || ev.Task.Key != "tick" || ev.Time == nil {
+			t.Fatalf("event: %v", ev)
+		}
+	}
+	cancel()
+	if _, err := stream.Recv(); code(err) != codes.Canceled {
+		t.Fatalf("after cancel: %v", err)
+	}
+}
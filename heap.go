@@ -0,0 +1,139 @@
+package timewheel
+
+// 4-ary min-heap of tasks ordered by due tick, priority then insertion order
+type heapBackend struct {
+	tasks   []*task
+	current int64  // tick being processed or next to process
+	seq     uint64 // insertion counter keeping equal due ticks in fifo order
+	due     []*task
+	stable  bool // order equal due ticks by the generation of the tasks instead, see WithStableOrder
+}
+
+func (h *heapBackend) push(t *task, ticks int) {
+	t.due = h.current + int64(ticks)
+	h.seq++
+	t.seq = h.seq
+	if h.stable {
+		t.seq = t.gen
+	}
+	t.heapIndex = len(h.tasks)
+	h.tasks = append(h.tasks, t)
+	h.up(t.heapIndex)
+}
+
+func (h *heapBackend) remove(t *task) {
+	i := t.heapIndex
+	if i < 0 || i >= len(h.tasks) || h.tasks[i] != t {
+		return
+	}
+	last := len(h.tasks) - 1
+	if i != last {
+		h.swap(i, last)
+	}
+	h.tasks[last] = nil
+	h.tasks = h.tasks[:last]
+	t.heapIndex = -1
+	if i != last {
+		h.down(i)
+		h.up(i)
+	}
+}
+
+func (h *heapBackend) advance(due func(batch []*task)) {
+	for first := true; first || h.dueNow(); first = false {
+		batch := h.due
+		for h.dueNow() {
+			t := h.tasks[0]
+			h.remove(t)
+			batch = append(batch, t)
+		}
+		due(batch)
+		for i := range batch {
+			batch[i] = nil
+		}
+		h.due = batch[:0]
+	}
+	h.current++
+}
+
+// report whether the first task is due at the current tick
+func (h *heapBackend) dueNow() bool {
+	return len(h.tasks) > 0 && h.tasks[0].due <= h.current
+}
+
+func (h *heapBackend) each(fn func(t *task)) {
+	for _, t := range h.tasks {
+		fn(t)
+	}
+}
+
+func (h *heapBackend) len() int {
+	return len(h.tasks)
+}
+
+func (h *heapBackend) position() int {
+	return int(h.current)
+}
+
+func (h *heapBackend) ticksUntil(t *task) int {
+	if t.due < h.current {
+		return 0
+	}
+	return int(t.due - h.current)
+}
+
+func (h *heapBackend) abort() {
+	for i := range h.due {
+		h.due[i] = nil
+	}
+	h.due = h.due[:0]
+}
+
+func (h *heapBackend) clear() {
+	h.tasks, h.due = nil, nil
+}
+
+func (h *heapBackend) less(i, j int) bool {
+	a, b := h.tasks[i], h.tasks[j]
+	if a.due != b.due {
+		return a.due < b.due
+	}
+	if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	return a.seq < b.seq
+}
+
+func (h *heapBackend) swap(i, j int) {
+	h.tasks[i], h.tasks[j] = h.tasks[j], h.tasks[i]
+	h.tasks[i].heapIndex = i
+	h.tasks[j].heapIndex = j
+}
+
+func (h *heapBackend) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 4
+		if !h.less(i, parent) {
+			break
+		}
+		h.swap(i, parent)
+		i = parent
+	}
+}
+
+func (h *heapBackend) down(i int) {
+	n := len(h.tasks)
+	for {
+		smallest := i
+		for c := 4*i + 1; c <= 4*i+4 && c < n; c++ {
+			if h.less(c, smallest) {
+				smallest = c
+			}
+		}
+		if smallest == i {
+			return
+		}
+		h.swap(i, smallest)
+		i = smallest
+	}
+}
@@ -0,0 +1,51 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// clock moved by the test, see Tick
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+	ch  chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1700000000, 0), ch: make(chan time.Time)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) Ticker { return c }
+func (c *fakeClock) C() <-chan time.Time              { return c.ch }
+func (c *fakeClock) Stop()                            {}
+
+// advance the time by d and deliver a tick
+func (c *fakeClock) Tick(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	now := c.now
+	c.mu.Unlock()
+	c.ch <- now
+}
+
+// wait for the wheel goroutine to handle the pending requests and the jobs to start
+func settle(tw *TimeWheel) {
+	tw.exec(func() {})
+	time.Sleep(5 * time.Millisecond)
+}
+
+// fail the test if the record and the slots of the running wheel disagree
+func checkInvariants(t *testing.T, tw *TimeWheel) {
+	t.Helper()
+	if err := tw.ValidateInvariants(); err != nil {
+		t.Fatal(err)
+	}
+}
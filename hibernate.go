@@ -0,0 +1,51 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// WithIdleHibernation stop the ticker while the wheel holds no task and start it again when a task is added,
+// the wheel goroutine then sleeps instead of waking every tick. The wheel is anchored again at the add, so
+// the delays count from there. No tick is handled while idle, the tick hook and the blackout windows included.
+func WithIdleHibernation() Option {
+	return func(tw *TimeWheel) {
+		tw.hibernate = true
+	}
+}
+
+// stop the ticker if the wheel holds no task, only called on the wheel goroutine
+func (tw *TimeWheel) checkIdle() {
+	if atomic.LoadInt64(&tw.taskNum) != 0 || len(tw.deferred) > 0 || len(tw.blackedOut) > 0 || len(tw.carry) > 0 ||
+		len(tw.unacked) > 0 || tw.backend.len() > 0 {
+		return
+	}
+	tw.ticker.Stop()
+	tw.idle = true
+	atomic.StoreInt32(&tw.idleNow, 1)
+}
+
+// start the ticker again, the next tick comes an interval from now, only called on the wheel goroutine
+func (tw *TimeWheel) wake() {
+	now := tw.clock.Now().Round(0)
+	tw.lastTick = now
+	tw.markTick(now)
+	tw.beat()
+	tw.ticker = tw.clock.NewTicker(tw.interval)
+	tw.phased = false
+	tw.idle = false
+	atomic.StoreInt32(&tw.idleNow, 0)
+}
+
+// Idle report whether the wheel is hibernating, see WithIdleHibernation
+func (tw *TimeWheel) Idle() bool {
+	return atomic.LoadInt32(&tw.idleNow) == 1
+}
+
+// the channel of the ticker, nil while idle so the select never picks it
+func (tw *TimeWheel) tickChannel() <-chan time.Time {
+	if tw.idle {
+		return nil
+	}
+	return tw.ticker.C()
+}
@@ -0,0 +1,56 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// wait for the wheel to hibernate
+func waitIdle(t *testing.T, tw *TimeWheel) {
+	t.Helper()
+	for i := 0; !tw.Idle(); i++ {
+		if i == 1000 {
+			t.Fatal("the wheel never goes idle")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestIdleHibernation(t *testing.T) {
+	tw := New(time.Millisecond, 16, WithIdleHibernation())
+	tw.Start()
+	defer tw.Stop()
+	waitIdle(t, tw)
+	done := make(chan struct{})
+	tw.AddTask(3*time.Millisecond, 2, "a", nil, func(TaskData) { done <- struct{}{} })
+	<-done
+	<-done
+	waitIdle(t, tw)
+
+	// no tick while idle
+	ticks := atomic.LoadInt64(&tw.tickNum)
+	time.Sleep(50 * time.Millisecond)
+	if !tw.Idle() || atomic.LoadInt64(&tw.tickNum) != ticks {
+		t.Fatalf("%d ticks while idle", atomic.LoadInt64(&tw.tickNum)-ticks)
+	}
+
+	// the delay counts from the add that wakes the wheel
+	fired := make(chan time.Time, 1)
+	begin := time.Now()
+	tw.AddTask(20*time.Millisecond, 1, "b", nil, func(TaskData) { fired <- time.Now() })
+	if d := (<-fired).Sub(begin); d < 20*time.Millisecond || d > 100*time.Millisecond {
+		t.Fatalf("fired %v after the add", d)
+	}
+
+	// adds racing the hibernation
+	for i := 0; i < 200; i++ {
+		ch := make(chan struct{})
+		tw.AddTask(time.Millisecond, 1, i, nil, func(TaskData) { close(ch) })
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			t.Fatalf("task %d lost", i)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package timewheel
+
+import "sync/atomic"
+
+// WithHoldFinalKey keep the key of a task registered until its final run returns,
+// adding a task under the key meanwhile fails with ErrTaskStillRunning
+func WithHoldFinalKey() Option {
+	return func(tw *TimeWheel) {
+		tw.holdKeys = true
+	}
+}
+
+// report whether the final run of the task holds its key
+func (t *task) isHeld() bool {
+	return atomic.LoadInt32(&t.held) == 1
+}
+
+// release the key held by the final run, called on the job goroutine once it returned. The wheel goroutine
+// drops the task, it marks the task held after dispatching the run so the job may return before.
+func (tw *TimeWheel) releaseHeld(task *task) {
+	if !tw.holdKeys {
+		return
+	}
+	tw.execLater(task, func() {
+		// RemoveTask may have released it already
+		if task.isHeld() && tw.taskRecord.CompareAndDelete(task.key, task) {
+			atomic.AddInt64(&tw.taskNum, -1)
+			tw.tagIndex.remove(task)
+			tw.dropTask(task)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHoldFinalKey(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c), WithHoldFinalKey())
+	tw.Start()
+	defer tw.Stop()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	tw.AddTask(time.Second, 1, "k", nil, func(TaskData) {
+		close(started)
+		<-release
+	})
+	settle(tw)
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	<-started
+	errs := make(chan error)
+	go func() {
+		errs <- tw.AddTask(time.Second, 1, "k", nil, func(TaskData) {})
+	}()
+	if err := <-errs; err != ErrTaskStillRunning {
+		t.Fatal(err)
+	}
+	if !tw.HasTask("k") {
+		t.Fatal("key released")
+	}
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for tw.HasTask("k") {
+		if time.Now().After(deadline) {
+			t.Fatal("key still held")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := tw.AddTask(time.Second, 1, "k", nil, func(TaskData) {}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHoldFinalKeyFastJob(t *testing.T) {
+	tw := New(time.Millisecond, 16, WithHoldFinalKey())
+	tw.Start()
+	defer tw.Stop()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := fmt.Sprint(g, "-", i%10)
+				tw.AddTask(time.Millisecond, 1, key, nil, func(TaskData) {})
+				tw.HasTask(key)
+			}
+		}(g)
+	}
+	wg.Wait()
+	// every final run returns at once, the keys must all be released
+	deadline := time.Now().Add(time.Second)
+	for tw.Len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal(tw.Len())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	checkInvariants(t, tw)
+}
+
+func TestReAddOneShotKeys(t *testing.T) {
+	tw := New(time.Millisecond, 16)
+	tw.Start()
+	defer tw.Stop()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				key := fmt.Sprint(g, "-", i%10)
+				tw.AddTask(time.Millisecond, 1, key, nil, func(TaskData) {})
+			}
+		}(g)
+	}
+	wg.Wait()
+	checkInvariants(t, tw)
+}
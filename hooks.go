@@ -0,0 +1,136 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TaskInfo read only snapshot of a task
+type TaskInfo struct {
+	Key        interface{}
+	Generation uint64 // generation of the task, see RemoveTaskIf
+	Interval   time.Duration
+	Effective  time.Duration // interval in effect, longer than Interval while Backoff stretches it
+	Times      int           // remaining run times, -1 means no limit
+	Paused     bool          // the runs are skipped, see PauseTask
+	Skip       int           // due runs left to skip, see SkipNext
+	Next       time.Time     // ideal time of the next run, Upcoming reports the estimated fire time
+	Displaced  time.Duration // delay added to the next run by the slot capacity, see WithSlotCapacity
+	Breaker    BreakerState  // state of the circuit breaker, see CircuitBreaker
+	Shed       bool          // the interval is stretched by the load shedding, see WithLoadShedding
+}
+
+// Hook callback receiving the task key and a snapshot of the task
+type Hook func(key interface{}, info TaskInfo)
+
+// Hooks lifecycle callbacks of the wheel, nil hooks are ignored.
+// Hooks are called in order on a dedicated goroutine, a panicking hook is recovered.
+type Hooks struct {
+	OnTaskAdded     Hook // the task is registered
+	OnTaskFired     Hook // the task is due, called before the job is dispatched
+	OnTaskCompleted Hook // the final run of the task returned
+	OnTaskRemoved   Hook // the task is removed by RemoveTask
+	OnTaskExpired   Hook // the task is removed by its TTL, see TTL
+	OnBreakerChange Hook // the circuit breaker of the task changed state, see CircuitBreaker
+}
+
+// WithHooks set the lifecycle hooks of the wheel. The hooks run on a goroutine of their own, it exits
+// once the wheel is stopped and the queued calls are done, the hooks raised later by the runs still in
+// flight are dropped.
+func WithHooks(h Hooks) Option {
+	return func(tw *TimeWheel) {
+		tw.hooks = h
+		tw.hookQueue = newHookQueue()
+	}
+}
+
+// snapshot the task
+func (t *task) info() TaskInfo {
+	return TaskInfo{Key: t.key, Generation: t.gen, Interval: t.interval, Effective: t.effective(), Times: t.times, Paused: t.isPaused(), Next: t.next,
+		Skip: int(atomic.LoadInt32(&t.skip)), Displaced: t.displaced, Breaker: t.breaker.load(),
+		Shed: atomic.LoadInt64(&t.shed) > 0}
+}
+
+// queue the hook call if the hook is set, only called on the wheel goroutine
+func (tw *TimeWheel) emit(h Hook, t *task) {
+	if h == nil {
+		return
+	}
+	tw.emitInfo(h, t.key, t.info())
+}
+
+// queue the hook call with the given snapshot
+func (tw *TimeWheel) emitInfo(h Hook, key interface{}, info TaskInfo) {
+	if h == nil {
+		return
+	}
+	tw.hookQueue.push(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				tw.logger.Printf("timewheel: hook panic recovered, key: %v, panic: %v", key, r)
+				tw.reportError(ErrorCallbackPanic, key, fmt.Errorf("hook panic: %v", r))
+			}
+		}()
+		h(key, info)
+	})
+}
+
+// stop the hook goroutine, called once the wheel goroutine exited or Stop is called before Start
+func (tw *TimeWheel) closeHooks() {
+	if tw.hookQueue != nil {
+		tw.hookQueue.close()
+	}
+}
+
+// unbounded fifo of hook calls, so slow hooks never block the wheel goroutine
+type hookQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	calls  []func()
+	closed bool
+}
+
+func newHookQueue() *hookQueue {
+	q := &hookQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+func (q *hookQueue) push(call func()) {
+	q.mu.Lock()
+	if !q.closed {
+		q.calls = append(q.calls, call)
+		q.cond.Signal()
+	}
+	q.mu.Unlock()
+}
+
+// stop the goroutine once the queued calls are done, later calls are dropped
+func (q *hookQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Signal()
+	q.mu.Unlock()
+}
+
+func (q *hookQueue) run() {
+	for {
+		q.mu.Lock()
+		for len(q.calls) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if len(q.calls) == 0 {
+			q.mu.Unlock()
+			return
+		}
+		call := q.calls[0]
+		q.calls[0] = nil
+		q.calls = q.calls[1:]
+		q.mu.Unlock()
+
+		call()
+	}
+}
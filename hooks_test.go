@@ -0,0 +1,124 @@
+package timewheel
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// hooks recording their calls in order
+type hookLog struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (l *hookLog) hook(name string) Hook {
+	return func(key interface{}, info TaskInfo) {
+		l.mu.Lock()
+		l.calls = append(l.calls, fmt.Sprintf("%s %v %d", name, key, info.Times))
+		l.mu.Unlock()
+	}
+}
+
+func (l *hookLog) hooks() Hooks {
+	return Hooks{
+		OnTaskAdded:     l.hook("added"),
+		OnTaskFired:     l.hook("fired"),
+		OnTaskCompleted: l.hook("completed"),
+		OnTaskRemoved:   l.hook("removed"),
+	}
+}
+
+// wait for the hook goroutine to catch up then get the calls
+func (l *hookLog) get(n int) []string {
+	deadline := time.Now().Add(time.Second)
+	for {
+		l.mu.Lock()
+		calls := append([]string(nil), l.calls...)
+		l.mu.Unlock()
+		if len(calls) >= n || time.Now().After(deadline) {
+			return calls
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHooksSequence(t *testing.T) {
+	c := newFakeClock()
+	var log hookLog
+	tw := New(time.Second, 8, WithClock(c), WithHooks(log.hooks()))
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(time.Second, 3, "k", nil, func(TaskData) {})
+	settle(tw)
+	for i := 0; i < 5; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+	}
+	want := "[added k 3 fired k 3 fired k 2 fired k 1 completed k 0]"
+	if got := fmt.Sprint(log.get(5)); got != want {
+		t.Fatal(got)
+	}
+}
+
+func TestHooksRemovedMidway(t *testing.T) {
+	c := newFakeClock()
+	var log hookLog
+	tw := New(time.Second, 8, WithClock(c), WithHooks(log.hooks()))
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(time.Second, 3, "k", nil, func(TaskData) {})
+	settle(tw)
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	if err := tw.RemoveTask("k"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+	}
+	want := "[added k 3 fired k 3 removed k 2]"
+	if got := fmt.Sprint(log.get(3)); got != want {
+		t.Fatal(got)
+	}
+}
+
+func TestHookPanic(t *testing.T) {
+	c := newFakeClock()
+	var log hookLog
+	hooks := log.hooks()
+	hooks.OnTaskAdded = func(interface{}, TaskInfo) { panic("boom") }
+	tw := New(time.Second, 8, WithClock(c), WithHooks(hooks))
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(time.Second, 1, "k", nil, func(TaskData) {})
+	settle(tw)
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	if got := fmt.Sprint(log.get(2)); got != "[fired k 1 completed k 0]" {
+		t.Fatal(got)
+	}
+}
+
+func TestHooksStoppedWithStop(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for i := 0; i < 20; i++ {
+		tw := New(time.Millisecond, 8, WithHooks(Hooks{OnTaskAdded: func(interface{}, TaskInfo) {}}))
+		if i%2 == 0 {
+			tw.Start()
+		}
+		tw.Stop()
+	}
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatal(before, runtime.NumGoroutine())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,64 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Interval get the tick interval of the wheel, see SetInterval
+func (tw *TimeWheel) Interval() time.Duration {
+	return time.Duration(atomic.LoadInt64((*int64)(&tw.interval)))
+}
+
+// SetInterval change the tick interval while the wheel runs, e.g. to coarsen the tick during load shedding.
+// The ticker is restarted with the new interval and every scheduled task is placed again by the time left
+// until its next run, it does not run early and runs at most a new tick late. Like the tasks added with an
+// interval below the tick, the tasks whose interval is below the new tick run on every tick. Precise tasks
+// keep their timer.
+func (tw *TimeWheel) SetInterval(d time.Duration) error {
+	if d <= 0 {
+		return ErrInvalidParams
+	}
+	return tw.exec(func() {
+		old := tw.interval
+		if d == old {
+			return
+		}
+		now := tw.clock.Now().Round(0)
+		var tasks []*task
+		var runAt []time.Time
+		tw.backend.each(func(t *task) {
+			if !t.precise {
+				tasks = append(tasks, t)
+				runAt = append(runAt, tw.estimateFire(tw.backend.ticksUntil(t)))
+			}
+		})
+
+		atomic.StoreInt64((*int64)(&tw.interval), int64(d))
+		if wb, ok := wheelOf(tw.backend); ok {
+			wb.interval = d
+		}
+		first := d
+		if tw.alignTicks {
+			first = now.Truncate(d).Add(d).Sub(now)
+		}
+		tw.lastTick = now.Add(first - d)
+		tw.markTick(tw.lastTick)
+		if !tw.idle {
+			tw.ticker.Stop()
+			tw.ticker = tw.clock.NewTicker(first)
+			tw.phased = first != d
+		}
+		tw.logger.Printf("timewheel: tick interval %v -> %v, tasks: %d", old, d, len(tasks))
+
+		for i, t := range tasks {
+			// the first tick not before the run, the next tick processes the current position
+			ticks := 0
+			if left := runAt[i].Sub(tw.lastTick.Add(d)); left > 0 {
+				ticks = int((left + d - 1) / d)
+			}
+			tw.backend.remove(t)
+			tw.backend.push(t, ticks)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSetInterval(t *testing.T) {
+	for _, tc := range []struct{ from, to time.Duration }{
+		{100 * time.Millisecond, time.Second},
+		{time.Second, 100 * time.Millisecond},
+	} {
+		c := newFakeClock()
+		tw := New(tc.from, 16, WithClock(c))
+		tw.Start()
+		start := c.Now()
+		var mu sync.Mutex
+		fired := map[time.Duration]time.Time{}
+		delays := []time.Duration{3 * time.Second, 4500 * time.Millisecond, 7 * time.Second, 20 * time.Second}
+		for _, d := range delays {
+			d := d
+			tw.AddTask(d, 1, d, nil, func(TaskData) {
+				mu.Lock()
+				fired[d] = c.Now()
+				mu.Unlock()
+			})
+		}
+		settle(tw)
+		for c.Now().Sub(start) < 2*time.Second {
+			c.Tick(tc.from)
+			settle(tw)
+		}
+		if err := tw.SetInterval(tc.to); err != nil || tw.Interval() != tc.to {
+			t.Fatalf("set interval %v: %v", tc.to, err)
+		}
+		for c.Now().Sub(start) < 25*time.Second {
+			c.Tick(tc.to)
+			settle(tw)
+		}
+		tw.Stop()
+		mu.Lock()
+		// never early, at most a tick of each interval late
+		for _, d := range delays {
+			late := fired[d].Sub(start.Add(d))
+			if late < 0 || late > tc.to+tc.from {
+				t.Errorf("%v -> %v: task %v fired %v late", tc.from, tc.to, d, late)
+			}
+		}
+		mu.Unlock()
+	}
+}
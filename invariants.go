@@ -0,0 +1,189 @@
+package timewheel
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+)
+
+// number of violations listed by InvariantError.Error
+const invariantErrorList = 5
+
+// InvariantViolation a broken invariant found by ValidateInvariants
+type InvariantViolation struct {
+	Rule     string
+	Key      interface{} // nil for a counter
+	Slot     int         // slot of the task, its index for the heap backend, -1 if it has none
+	Expected int64
+	Actual   int64
+}
+
+func (v InvariantViolation) String() string {
+	return fmt.Sprintf("%s: key %v, slot %d, expected %d, actual %d", v.Rule, v.Key, v.Slot, v.Expected, v.Actual)
+}
+
+// InvariantError the violations found by ValidateInvariants
+type InvariantError struct {
+	Violations []InvariantViolation
+	Zombies    int // removed tasks still held by a due queue, they are not violations, see MemStats
+}
+
+func (e *InvariantError) Error() string {
+	list := make([]string, 0, invariantErrorList)
+	for i, v := range e.Violations {
+		if i == invariantErrorList {
+			list = append(list, "...")
+			break
+		}
+		list = append(list, v.String())
+	}
+	return fmt.Sprintf("timewheel: %d invariant violations: %s", len(e.Violations), strings.Join(list, "; "))
+}
+
+// ValidateInvariants check the consistency of the wheel on a snapshot taken on the wheel goroutine:
+// every registered task is in exactly one slot, timer or waiting queue, every task found there is
+// registered, the circles are within bounds and the cached counts match. It returns an *InvariantError
+// listing the violations, it must not be called from a job running inline.
+func (tw *TimeWheel) ValidateInvariants() error {
+	violations, zombies, err := tw.checkInvariants()
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &InvariantError{Violations: violations, Zombies: zombies}
+	}
+	return nil
+}
+
+// collect the broken invariants and count the zombies on the wheel goroutine
+func (tw *TimeWheel) checkInvariants() (violations []InvariantViolation, zombies int, err error) {
+	err = tw.exec(func() {
+		violate := func(rule string, key interface{}, slot int, expected, actual int64) {
+			violations = append(violations, InvariantViolation{Rule: rule, Key: key, Slot: slot, Expected: expected, Actual: actual})
+		}
+		placed := make(map[*task]int)
+		slotOf := make(map[*task]int)
+		place := func(t *task, slot int) {
+			if t.times == 0 {
+				violate("removed task scheduled", t.key, slot, 0, 1)
+				return
+			}
+			placed[t]++
+			slotOf[t] = slot
+		}
+
+		b := tw.backend
+		if p, ok := b.(*preciseBackend); ok {
+			for t := range p.timers {
+				place(t, -1)
+			}
+			b = p.backend
+		}
+		switch b := b.(type) {
+		case *wheelBackend:
+			b.checkSlots(b.slots, true, place, violate)
+			b.checkSlots(b.moving, false, place, violate)
+		case *heapBackend:
+			for i, t := range b.tasks {
+				if t.heapIndex != i {
+					violate("heap index", t.key, i, int64(i), int64(t.heapIndex))
+				}
+				place(t, i)
+			}
+		}
+
+		queues := []struct {
+			name  string
+			tasks []*task
+			num   *int64
+		}{
+			{"deferred count", tw.deferred, &tw.deferredNum},
+			{"blacked out count", tw.blackedOut, &tw.blackedOutNum},
+			{"carried count", tw.carry, &tw.carryNum},
+			{"gated count", tw.gatedTasks, nil},
+		}
+		for _, q := range queues {
+			for _, t := range q.tasks {
+				if t.times == 0 {
+					zombies++
+					continue
+				}
+				place(t, -1)
+			}
+			if q.num != nil && atomic.LoadInt64(q.num) != int64(len(q.tasks)) {
+				violate(q.name, nil, -1, int64(len(q.tasks)), atomic.LoadInt64(q.num))
+			}
+		}
+		waiting := 0
+		for key, list := range tw.dependents {
+			for _, t := range list {
+				if t.dep == nil || t.dep.key != key {
+					violate("waiting task without prerequisite", t.key, -1, 1, 0)
+				}
+				place(t, -1)
+				waiting++
+			}
+		}
+		if n := atomic.LoadInt64(&tw.waitingNum); n != int64(waiting) {
+			violate("waiting count", nil, -1, int64(waiting), n)
+		}
+
+		registered := 0
+		tw.taskRecord.Range(func(key interface{}, t *task) bool {
+			registered++
+			if t.times == 0 || t.isHeld() {
+				// done, the key leaves the record once the loop iteration or the final run is over
+				return true
+			}
+			if n := placed[t]; n != 1 {
+				slot, ok := slotOf[t]
+				if !ok {
+					slot = -1
+				}
+				violate("registered task placements", key, slot, 1, int64(n))
+			}
+			return true
+		})
+		for t, slot := range slotOf {
+			if r, ok := tw.taskRecord.Load(t.key); !ok || r != t {
+				violate("scheduled task not registered", t.key, slot, 1, 0)
+			}
+		}
+		// the final run releases a held key on its job goroutine, the count follows the record
+		if !tw.holdKeys {
+			if n := atomic.LoadInt64(&tw.taskNum); n != int64(registered) {
+				violate("task count", nil, -1, int64(registered), n)
+			}
+		}
+	})
+	return
+}
+
+// check the entries of the slots against the position and the length of their slot, current tells
+// the slots of the wheel from the ones left by Resize
+func (b *wheelBackend) checkSlots(slots []SlotStore, current bool,
+	place func(t *task, slot int), violate func(rule string, key interface{}, slot int, expected, actual int64)) {
+	for i, s := range slots {
+		n := 0
+		s.Each(func(e *SlotEntry) {
+			t := e.task
+			n++
+			if t.slot != i {
+				violate("task slot", t.key, i, int64(i), int64(t.slot))
+			}
+			if (t.slotGen == b.gen) != current {
+				violate("slot generation", t.key, i, int64(b.gen), int64(t.slotGen))
+			}
+			if t.circle < 0 {
+				violate("negative circle", t.key, i, 0, int64(t.circle))
+			} else if int64(t.circle) > math.MaxInt32 {
+				violate("circle out of bounds", t.key, i, math.MaxInt32, int64(t.circle))
+			}
+			place(t, i)
+		})
+		if s.Len() != n {
+			violate("slot length", nil, i, int64(n), int64(s.Len()))
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package timewheel
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInvariantsStress(t *testing.T) {
+	for _, opts := range [][]Option{
+		nil,
+		{WithBackend(Heap), WithTickCap(100)},
+		{WithPreciseTasks(10), WithScanChunk(5), WithTickCap(7)},
+		{WithHoldFinalKey(), WithTickCap(100), WithScanChunk(100), WithTickCap(100)},
+	} {
+		opts := opts
+		t.Run(fmt.Sprint(len(opts)), func(t *testing.T) {
+			tw := New(time.Millisecond, 16, opts...)
+			tw.Start()
+			var wg sync.WaitGroup
+			for g := 0; g < 8; g++ {
+				wg.Add(1)
+				go func(g int) {
+					defer wg.Done()
+					r := rand.New(rand.NewSource(int64(g)))
+					for i := 0; i < 2000; i++ {
+						key := fmt.Sprint(g, "-", r.Intn(50))
+						switch r.Intn(4) {
+						case 0, 1:
+							var o []TaskOption
+							if r.Intn(10) == 0 {
+								o = append(o, Precise())
+							}
+							tw.AddTaskWith(time.Duration(1+r.Intn(40))*time.Millisecond, r.Intn(5)-1, key, nil, func(TaskData) {}, o...)
+						case 2:
+							tw.RemoveTask(key)
+						case 3:
+							tw.UpdateTask(key, time.Duration(1+r.Intn(40))*time.Millisecond, nil)
+						}
+						if i%500 == 0 {
+							if err := tw.ValidateInvariants(); err != nil {
+								t.Error(err)
+							}
+						}
+					}
+				}(g)
+			}
+			wg.Wait()
+			if err := tw.ValidateInvariants(); err != nil {
+				t.Fatal(err)
+			}
+			tw.Stop()
+		})
+	}
+}
+
+func TestInvariantsBroken(t *testing.T) {
+	tw := New(time.Second, 8)
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(time.Minute, -1, "a", nil, func(TaskData) {})
+	tw.AddTask(time.Minute, -1, "b", nil, func(TaskData) {})
+	settle(tw)
+	if err := tw.ValidateInvariants(); err != nil {
+		t.Fatal(err)
+	}
+	tw.exec(func() {
+		a, _ := tw.taskRecord.Load("a")
+		tw.backend.remove(a)
+		b, _ := tw.taskRecord.Load("b")
+		tw.taskRecord.CompareAndDelete("b", b)
+		b.circle = -3
+	})
+	// a task missing from its slot, a record entry missing and a negative circle
+	err := tw.ValidateInvariants()
+	var ie *InvariantError
+	if !errors.As(err, &ie) || len(ie.Violations) != 4 {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	tw.AdminHandler(nil).ServeHTTP(rec, httptest.NewRequest("GET", "/invariants", nil))
+	if rec.Code != 500 || !strings.Contains(rec.Body.String(), "negative circle") {
+		t.Fatal(rec.Code, rec.Body.String())
+	}
+}
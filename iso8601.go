@@ -0,0 +1,132 @@
+package timewheel
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AddISO8601Task add new task from an ISO 8601 repeating interval such as R5/PT10M or R/P1D,
+// R without a count means no limit. See ParseISO8601Repeat for the supported forms.
+func (tw *TimeWheel) AddISO8601Task(spec string, key interface{}, data TaskData, job Job) error {
+	times, interval, err := ParseISO8601Repeat(spec)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return ErrInvalidParams
+	}
+	task, err := tw.newTask(interval, times, key, data, wrapJob(job))
+	if err != nil {
+		return err
+	}
+	return tw.submit(context.Background(), task)
+}
+
+// ParseISO8601Repeat parse a repeating interval R[n]/P[nW][nD][T[nH][nM][nS]] into the run times,
+// -1 when the count is left out, and the interval. The seconds may have a fraction.
+// Years and months have no fixed length and are rejected, so are start and end times.
+// The errors wrap ErrInvalidParams and name the offending component.
+func ParseISO8601Repeat(spec string) (times int, interval time.Duration, err error) {
+	parts := strings.Split(spec, "/")
+	if len(parts) != 2 {
+		return 0, 0, isoError(spec, "want R[n]/duration, start and end times are not supported")
+	}
+	rep := parts[0]
+	if !strings.HasPrefix(rep, "R") {
+		return 0, 0, isoError(spec, "repetition %q does not start with R", rep)
+	}
+	times = -1
+	if n := rep[1:]; n != "" {
+		if times, err = strconv.Atoi(n); err != nil || times <= 0 {
+			return 0, 0, isoError(spec, "repetition count %q is not a positive number", n)
+		}
+	}
+	if interval, err = parseISO8601Duration(parts[1]); err != nil {
+		return 0, 0, isoError(spec, "%v", err)
+	}
+	return times, interval, nil
+}
+
+func isoError(spec string, format string, args ...interface{}) error {
+	return fmt.Errorf("%w, iso 8601 spec %q: %s", ErrInvalidParams, spec, fmt.Sprintf(format, args...))
+}
+
+// parse P[nW][nD][T[nH][nM][nS]]
+func parseISO8601Duration(s string) (time.Duration, error) {
+	if !strings.HasPrefix(s, "P") {
+		return 0, fmt.Errorf("duration %q does not start with P", s)
+	}
+	var total time.Duration
+	inTime, units, last := false, 0, -1
+	rest := s[1:]
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime {
+				return 0, fmt.Errorf("duration %q has more than one T", s)
+			}
+			inTime, rest = true, rest[1:]
+			if rest == "" {
+				return 0, fmt.Errorf("duration %q has no component after T", s)
+			}
+			continue
+		}
+		i := strings.IndexFunc(rest, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.' && r != ','
+		})
+		if i <= 0 {
+			return 0, fmt.Errorf("duration %q: missing number before %q", s, rest)
+		}
+		num, designator := strings.Replace(rest[:i], ",", ".", 1), rest[i]
+		rest = rest[i+1:]
+		// rank orders the designators, each may appear once
+		var unit time.Duration
+		var rank int
+		switch {
+		case !inTime && (designator == 'Y' || designator == 'M'):
+			return 0, fmt.Errorf("duration %q: years and months are not supported", s)
+		case !inTime && designator == 'W':
+			unit, rank = 7*24*time.Hour, 0
+		case !inTime && designator == 'D':
+			unit, rank = 24*time.Hour, 1
+		case inTime && designator == 'H':
+			unit, rank = time.Hour, 2
+		case inTime && designator == 'M':
+			unit, rank = time.Minute, 3
+		case inTime && designator == 'S':
+			unit, rank = time.Second, 4
+		default:
+			return 0, fmt.Errorf("duration %q: unknown designator %q", s, designator)
+		}
+		if rank <= last {
+			return 0, fmt.Errorf("duration %q: designator %q repeated or out of order", s, designator)
+		}
+		last = rank
+		if strings.Contains(num, ".") {
+			if unit != time.Second {
+				return 0, fmt.Errorf("duration %q: fraction only allowed on seconds", s)
+			}
+			f, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				return 0, fmt.Errorf("duration %q: bad number %q", s, num)
+			}
+			total += time.Duration(f * float64(time.Second))
+		} else {
+			n, err := strconv.ParseInt(num, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("duration %q: bad number %q", s, num)
+			}
+			total += time.Duration(n) * unit
+		}
+		units++
+	}
+	if units == 0 {
+		return 0, fmt.Errorf("duration %q has no component", s)
+	}
+	if total <= 0 {
+		return 0, fmt.Errorf("duration %q is not positive", s)
+	}
+	return total, nil
+}
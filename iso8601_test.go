@@ -0,0 +1,73 @@
+package timewheel
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseISO8601Repeat(t *testing.T) {
+	for _, tc := range []struct {
+		spec  string
+		times int
+		d     time.Duration
+		err   string // part of the error naming the offending component
+	}{
+		{"R5/PT10M", 5, 10 * time.Minute, ""},
+		{"R/P1D", -1, 24 * time.Hour, ""},
+		{"R1/P1W", 1, 7 * 24 * time.Hour, ""},
+		{"R3/P1DT2H30M", 3, 26*time.Hour + 30*time.Minute, ""},
+		{"R/PT0.5S", -1, 500 * time.Millisecond, ""},
+		{"R2/PT1H1M1S", 2, time.Hour + time.Minute + time.Second, ""},
+		{"R/PT1,5S", -1, 1500 * time.Millisecond, ""},
+		{"R5/P1M", 0, 0, "years and months"},
+		{"R5/P1Y", 0, 0, "years and months"},
+		{"5/PT1M", 0, 0, `repetition "5"`},
+		{"R0/PT1M", 0, 0, `count "0"`},
+		{"R-1/PT1M", 0, 0, `count "-1"`},
+		{"R/PT", 0, 0, "no component after T"},
+		{"R/P", 0, 0, "has no component"},
+		{"R/PT1X", 0, 0, `designator 'X'`},
+		{"R/PT1M1H", 0, 0, "out of order"},
+		{"R/PT0S", 0, 0, "not positive"},
+		{"R/2020-01-01T00:00:00Z/PT1M", 0, 0, "start and end times"},
+		{"R/P1.5D", 0, 0, "fraction only allowed on seconds"},
+		{"RPT1M", 0, 0, "want R[n]/duration"},
+	} {
+		times, d, err := ParseISO8601Repeat(tc.spec)
+		if tc.err == "" {
+			if err != nil || times != tc.times || d != tc.d {
+				t.Errorf("%s: %d %v %v", tc.spec, times, d, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrInvalidParams) || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: %v, want an error with %q", tc.spec, err, tc.err)
+		}
+	}
+}
+
+func TestAddISO8601Task(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var n int64
+	if err := tw.AddISO8601Task("R2/PT2S", "i", nil, func(TaskData) { atomic.AddInt64(&n, 1) }); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.AddISO8601Task("R2/P1M", "m", nil, func(TaskData) {}); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("months: %v", err)
+	}
+	settle(tw)
+	for i := 0; i < 8; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+	}
+	waitCount(t, &n, 2)
+	if tw.HasTask("i") {
+		t.Fatal("task left after its runs")
+	}
+}
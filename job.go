@@ -0,0 +1,220 @@
+package timewheel
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// JobCtx callback function receiving a context
+type JobCtx func(ctx context.Context, data TaskData)
+
+// JobErr callback function reporting a failed run by its error, a failed run counts like a panic,
+// see Stats.Failures, WithDeadLetter and CircuitBreaker
+type JobErr func(ctx context.Context, data TaskData) error
+
+// context key of the run, its error is set by the jobs added with AddTaskErr
+type jobErrKey struct{}
+
+// value of the run in the context of the job
+type runValue struct {
+	key       interface{}
+	scheduled time.Time
+	err       error
+
+	// result of a job added with AddTaskNext
+	next   time.Duration
+	stop   bool
+	chosen bool
+}
+
+// adapt the job, its error is handed to callJob through the context
+func wrapJobErr(job JobErr) JobCtx {
+	return func(ctx context.Context, data TaskData) {
+		if err := job(ctx, data); err != nil {
+			if v, ok := ctx.Value(jobErrKey{}).(*runValue); ok {
+				v.err = err
+			}
+		}
+	}
+}
+
+// AddTaskErr add new task like AddTaskWith, a run of the job returning an error counts as failed
+func (tw *TimeWheel) AddTaskErr(interval time.Duration, times int, key interface{}, data TaskData, job JobErr, opts ...TaskOption) error {
+	if job == nil {
+		return ErrInvalidParams
+	}
+	return tw.addTaskWith(interval, times, key, data, wrapJobErr(job), opts)
+}
+
+// Execution describe a single run of a task
+type Execution struct {
+	Key       interface{}
+	Scheduled time.Time // ideal time of the run
+	Fired     time.Time // time the task was dispatched
+}
+
+// Interceptor wrap every job execution, it must call run to execute the job.
+// run returns a *PanicError if the job panicked.
+type Interceptor func(ctx context.Context, exec Execution, run func(ctx context.Context) error) error
+
+// WithInterceptor set the interceptor wrapping the job executions
+func WithInterceptor(i Interceptor) Option {
+	return func(tw *TimeWheel) {
+		tw.interceptor = i
+	}
+}
+
+// PanicError a recovered job panic
+type PanicError struct {
+	Key   interface{}
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("timewheel: job of task %v panicked: %v", e.Key, e.Value)
+}
+
+// a dispatched run, the fields are taken on the wheel goroutine
+type jobRun struct {
+	task    *task
+	data    TaskData
+	exec    Execution
+	info    TaskInfo // the task when the run was dispatched
+	final   bool
+	persist func()       // records the run in the store before the job runs, so a restarted wheel does not run it again
+	dropped bool         // the worker pool dropped the run, the job is skipped
+	inline  bool         // the run is on the wheel goroutine, see RunInline
+	prereq  bool         // tasks wait for the run to complete, see DependsOn
+	lock    string       // name of the lock held by the run, see WithLocker
+	waiters []chan error // released once the job returned, see WaitForNextRun
+	ack     *delivery    // the run waits for its Ack, see Acked
+
+	late time.Duration // lateness of the run over the threshold, see WithLatenessAlert
+
+	extra *attachedJob // the run is of a job attached to the task, see AttachJob
+	group *mutexGroup  // group whose slot the run holds, see InGroup
+
+	// runs handed to the batch handler with this one, see AddBatchTask
+	batch    []*jobRun
+	inBatch  bool  // the batch handler got the run
+	batchErr error // failure of the batch handler, counted by every run of the batch
+
+	// context of the job, canceled by RemoveTaskAndCancel or once the job returned
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// run the job through the interceptor, a panicking job must not crash the process
+func (tw *TimeWheel) runJob(r *jobRun) {
+	task := r.task
+	ran := false
+	defer func() {
+		if v := recover(); v != nil {
+			tw.logger.Printf("timewheel: interceptor panic recovered, key: %v, panic: %v", task.key, v)
+		}
+		if len(r.waiters) > 0 {
+			tw.runDone(r, ran)
+		}
+		if r.final {
+			tw.releaseHeld(task)
+			info := r.info
+			info.Times = 0
+			tw.emitInfo(tw.hooks.OnTaskCompleted, task.key, info)
+			tw.publishInfo(EventCompleted, task.key, info)
+			tw.exhausted(task, r.data)
+			tw.chainNext(task.then)
+		}
+		if r.prereq && (r.final || !r.dropped) {
+			key, final := task.key, r.final
+			go tw.exec(func() {
+				tw.prerequisiteDone(key, final)
+			})
+		}
+		tw.activeRuns.untrack(r)
+		if r.group != nil {
+			tw.releaseGroup(r)
+		}
+		task.release()
+		atomic.AddInt64(&tw.inflightNum, -1)
+	}()
+	if r.persist != nil {
+		r.persist()
+	}
+	if r.late > 0 {
+		tw.checkLateness(task.key, r.info, r.late)
+	}
+	if r.task.batch != nil && !r.inBatch {
+		tw.runBatched(r)
+	}
+	if r.dropped || r.ctx.Err() != nil {
+		return
+	}
+	if r.extra != nil {
+		tw.runAttached(r)
+		return
+	}
+	if tw.locker != nil && !r.inBatch && !tw.lockRun(r) {
+		return
+	}
+	ran = true
+	run := func(ctx context.Context) error {
+		return tw.callJob(ctx, r)
+	}
+	ctx := r.ctx
+	if r.ack != nil {
+		ctx = context.WithValue(ctx, ackKey{}, r.ack)
+	}
+	tw.withKeyLabel(ctx, task.key, func(ctx context.Context) {
+		if tw.interceptor == nil {
+			run(ctx)
+			return
+		}
+		tw.interceptor(ctx, r.exec, run)
+	})
+}
+
+// call the job, the panic is recovered and returned
+func (tw *TimeWheel) callJob(ctx context.Context, r *jobRun) (err error) {
+	task := r.task
+	begin := time.Now()
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Key: task.key, Value: v, Stack: debug.Stack()}
+			tw.logger.Printf("timewheel: job panic recovered, key: %v, panic: %v", task.key, v)
+		}
+		cost := time.Since(begin)
+		failures := task.stats.done(tw.clock.Now(), cost, err)
+		if tw.metrics != nil {
+			tw.metricJobDone(task, cost, err != nil)
+		}
+		tw.checkSlowJob(task.key, r.info, cost)
+		tw.checkFailureAlert(task.key, r.info, failures, err)
+		tw.breakerDone(task, r.info, failures, err)
+		tw.backoffDone(r, err)
+		tw.checkDeadLetter(task, failures, err)
+		if err != nil && r.lock != "" {
+			tw.unlockRun(r)
+		}
+	}()
+	v := &runValue{key: task.key, scheduled: r.exec.Scheduled, err: r.batchErr}
+	task.run(context.WithValue(ctx, jobErrKey{}, v), r.data)
+	tw.nextDone(r, v)
+	return v.err
+}
+
+// call the exhausted callback of the task
+func (tw *TimeWheel) exhausted(task *task, data TaskData) {
+	if task.onDone == nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tw.logger.Printf("timewheel: exhausted callback panic recovered, key: %v, panic: %v", task.key, r)
+		}
+	}()
+	task.onDone(task.key, data)
+}
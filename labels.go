@@ -0,0 +1,78 @@
+package timewheel
+
+import (
+	"fmt"
+	"time"
+)
+
+// LabeledMetrics optional interface of the Metrics receiving the metric labels of the task with its measurements,
+// the methods are called instead of TaskFired and JobDone, labels is nil for a task without labels
+type LabeledMetrics interface {
+	TaskFiredLabeled(labels map[string]string)
+	JobDoneLabeled(labels map[string]string, d time.Duration, panicked bool)
+}
+
+// WithMetricLabels allow the metric labels of the given names on the tasks, see MetricLabels.
+// Default is no label, so the series can not grow with the keys.
+func WithMetricLabels(names ...string) Option {
+	return func(tw *TimeWheel) {
+		if tw.labelNames == nil {
+			tw.labelNames = make(map[string]struct{})
+		}
+		for _, name := range names {
+			tw.labelNames[name] = struct{}{}
+		}
+	}
+}
+
+// MetricLabels attach the labels to the measurements of the task, e.g. the job type or the tenant, the label
+// names must be allowed by WithMetricLabels
+func MetricLabels(labels map[string]string) TaskOption {
+	return func(t *task) {
+		t.labels = make(map[string]string, len(labels))
+		for k, v := range labels {
+			t.labels[k] = v
+		}
+	}
+}
+
+// check the labels of the task against the allowed names
+func (tw *TimeWheel) checkLabels(t *task) error {
+	for name := range t.labels {
+		if _, ok := tw.labelNames[name]; !ok {
+			return fmt.Errorf("%w, metric label %q is not allowed", ErrInvalidParams, name)
+		}
+	}
+	return nil
+}
+
+// check the options of a new task and count it against the precise tasks
+func (tw *TimeWheel) acceptOptions(t *task) error {
+	if err := tw.checkLabels(t); err != nil {
+		t.precise = false
+		return err
+	}
+	if err := tw.checkGroup(t); err != nil {
+		t.precise = false
+		return err
+	}
+	return tw.admitPrecise(t)
+}
+
+// report the dispatch of the task to the metrics
+func (tw *TimeWheel) metricFired(t *task) {
+	if m, ok := tw.metrics.(LabeledMetrics); ok {
+		m.TaskFiredLabeled(t.labels)
+		return
+	}
+	tw.metrics.TaskFired()
+}
+
+// report the end of a run of the task to the metrics
+func (tw *TimeWheel) metricJobDone(t *task, d time.Duration, failed bool) {
+	if m, ok := tw.metrics.(LabeledMetrics); ok {
+		m.JobDoneLabeled(t.labels, d, failed)
+		return
+	}
+	tw.metrics.JobDone(d, failed)
+}
@@ -0,0 +1,60 @@
+package timewheel
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// metrics recording the measurements by label set, the unlabeled methods must not be called
+type labelRecorder struct {
+	mu    sync.Mutex
+	fired map[string]int
+	done  map[string]int
+}
+
+func (r *labelRecorder) TaskAdded()                                   {}
+func (r *labelRecorder) TaskRemoved()                                 {}
+func (r *labelRecorder) TaskFired()                                   { panic("unlabeled") }
+func (r *labelRecorder) JobDone(d time.Duration, panicked bool)       { panic("unlabeled") }
+func (r *labelRecorder) TickDone(d time.Duration, tasks, backlog int) {}
+func (r *labelRecorder) TaskFiredLabeled(labels map[string]string) {
+	r.mu.Lock()
+	r.fired[fmt.Sprint(labels)]++
+	r.mu.Unlock()
+}
+func (r *labelRecorder) JobDoneLabeled(labels map[string]string, d time.Duration, panicked bool) {
+	r.mu.Lock()
+	r.done[fmt.Sprint(labels)]++
+	r.mu.Unlock()
+}
+
+func TestMetricLabels(t *testing.T) {
+	r := &labelRecorder{fired: map[string]int{}, done: map[string]int{}}
+	c := newFakeClock()
+	tw := New(time.Second, 16, WithClock(c), WithMetrics(r), WithMetricLabels("tenant", "job"))
+	tw.Start()
+	defer tw.Stop()
+	job := func(TaskData) {}
+	if err := tw.AddTaskWith(time.Second, 3, "a", nil, job, MetricLabels(map[string]string{"tenant": "x"})); err != nil {
+		t.Fatal(err)
+	}
+	tw.AddTaskWith(time.Second, 2, "b", nil, job, MetricLabels(map[string]string{"tenant": "y", "job": "sync"}))
+	tw.AddTask(time.Second, 1, "c", nil, job)
+	if err := tw.AddTaskWith(time.Second, 1, "d", nil, job, MetricLabels(map[string]string{"key": "d"})); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("label not allowed: %v", err)
+	}
+	settle(tw)
+	for i := 0; i < 5; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	want := "map[map[]:1 map[job:sync tenant:y]:2 map[tenant:x]:3]"
+	if fmt.Sprint(r.fired) != want || fmt.Sprint(r.done) != want {
+		t.Fatalf("fired %v, done %v", r.fired, r.done)
+	}
+}
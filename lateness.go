@@ -0,0 +1,89 @@
+package timewheel
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLatenessBuckets upper bounds of the buckets of the lateness histogram
+var DefaultLatenessBuckets = []time.Duration{
+	time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 5 * time.Second, 10 * time.Second,
+}
+
+// LatenessHandler receive the runs dispatched later than the lateness threshold
+type LatenessHandler func(key interface{}, lateness time.Duration, info TaskInfo)
+
+// WithLatenessAlert call handler on the job goroutine before every run dispatched more than d after its
+// ideal time, e.g. 2 ticks for an SLO of two ticks. The runs over d are counted in the Overruns of the
+// lateness histogram, see LatenessHistogram.
+func WithLatenessAlert(d time.Duration, handler LatenessHandler) Option {
+	return func(tw *TimeWheel) {
+		if d > 0 {
+			tw.lateThreshold = d
+			tw.lateHandler = handler
+		}
+	}
+}
+
+// WithLatenessBuckets set the upper bounds of the buckets of the lateness histogram, default is
+// DefaultLatenessBuckets
+func WithLatenessBuckets(bounds ...time.Duration) Option {
+	return func(tw *TimeWheel) {
+		if len(bounds) > 0 {
+			tw.lateHist = newTickHistogram(bounds)
+		}
+	}
+}
+
+// LatenessHistogram get the distribution of the lateness of the runs, the time from the ideal time of a run
+// to its dispatch. Overruns counts the runs later than the threshold of WithLatenessAlert.
+func (tw *TimeWheel) LatenessHistogram() TickHistogram {
+	return tw.lateHist.snapshot()
+}
+
+// measure the lateness of the run dispatched at fired, only called on the wheel goroutine
+func (tw *TimeWheel) observeLateness(task *task, scheduled, fired time.Time) time.Duration {
+	late := fired.Sub(scheduled)
+	if scheduled.IsZero() || late < 0 {
+		late = 0
+	}
+	task.stats.late(late)
+	if tw.shed != nil {
+		tw.shed.observe(late)
+	}
+	threshold := tw.lateThreshold
+	if threshold <= 0 {
+		threshold = math.MaxInt64
+	}
+	if !tw.lateHist.observe(late, threshold) {
+		return 0
+	}
+	return late
+}
+
+// call the lateness handler, late is the lateness of a run over the threshold
+func (tw *TimeWheel) checkLateness(key interface{}, info TaskInfo, late time.Duration) {
+	if tw.lateHandler == nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tw.logger.Printf("timewheel: lateness handler panic recovered, key: %v, panic: %v", key, r)
+		}
+	}()
+	tw.lateHandler(key, late, info)
+}
+
+// record the lateness of a dispatch
+func (s *taskStats) late(d time.Duration) {
+	atomic.StoreInt64(&s.lastLate, int64(d))
+	for {
+		max := atomic.LoadInt64(&s.maxLate)
+		if int64(d) <= max || atomic.CompareAndSwapInt64(&s.maxLate, max, int64(d)) {
+			return
+		}
+	}
+}
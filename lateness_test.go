@@ -0,0 +1,46 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLateness(t *testing.T) {
+	c := newFakeClock()
+	var mu sync.Mutex
+	got := map[interface{}][]time.Duration{}
+	tw := New(time.Second, 8, WithClock(c), WithLatenessAlert(1500*time.Millisecond, func(key interface{}, d time.Duration, info TaskInfo) {
+		mu.Lock()
+		got[key] = append(got[key], d)
+		mu.Unlock()
+	}))
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(2*time.Second, -1, "a", nil, func(TaskData) {})
+	settle(tw)
+	for i := 0; i < 3; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	st, _ := tw.TaskStats("a")
+	base := st.LastLateness
+	// stall: the tick comes 4s late, the catch up dispatches the run scheduled before
+	c.Tick(4 * time.Second)
+	settle(tw)
+	st, _ = tw.TaskStats("a")
+	mu.Lock()
+	// only the stalled run crossed the threshold
+	if len(got["a"]) != 1 || got["a"][0] != 3*time.Second {
+		t.Fatal(got)
+	}
+	mu.Unlock()
+	// a run fires on the tick after its due time, a second late at best
+	if base != time.Second || st.MaxLateness != 3*time.Second || st.LastLateness != time.Second {
+		t.Fatal(st)
+	}
+	h := tw.LatenessHistogram()
+	if h.Count != 3 || h.Overruns != 1 {
+		t.Fatal(h)
+	}
+}
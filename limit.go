@@ -0,0 +1,55 @@
+package timewheel
+
+import "sync/atomic"
+
+// WithMaxTasks limit the number of tasks held by the wheel, the tasks waiting in the add buffer included.
+// The adds beyond the limit return ErrTooManyTasks, onLimit is called when the limit is reached
+// after being below it, it may be nil.
+func WithMaxTasks(n int, onLimit func(n int)) Option {
+	return func(tw *TimeWheel) {
+		if n > 0 {
+			tw.maxTasks = int64(n)
+			tw.onLimit = onLimit
+		}
+	}
+}
+
+// count a new task against the limit and the quota of its namespace
+func (tw *TimeWheel) admit(key interface{}) error {
+	if tw.maxTasks > 0 {
+		if atomic.AddInt64(&tw.admitted, 1) > tw.maxTasks {
+			atomic.AddInt64(&tw.admitted, -1)
+			if atomic.CompareAndSwapInt32(&tw.limitHit, 0, 1) && tw.onLimit != nil {
+				tw.onLimit(int(tw.maxTasks))
+			}
+			return ErrTooManyTasks
+		}
+	}
+	if !tw.admitNS(key) {
+		tw.unadmit()
+		return ErrNamespaceQuota
+	}
+	return nil
+}
+
+// undo the count of a task against the limit
+func (tw *TimeWheel) unadmit() {
+	if tw.maxTasks > 0 {
+		atomic.AddInt64(&tw.admitted, -1)
+		atomic.StoreInt32(&tw.limitHit, 0)
+	}
+}
+
+// drop the reference of the wheel to the task, the task no longer counts against the limit
+func (tw *TimeWheel) dropTask(task *task) {
+	tw.unadmit()
+	tw.unadmitNS(task.key)
+	if task.precise {
+		atomic.AddInt64(&tw.preciseNum, -1)
+	}
+	if len(task.waiters) > 0 {
+		releaseWaiters(task.waiters, ErrTaskRemoved)
+		task.waiters = nil
+	}
+	task.release()
+}
@@ -0,0 +1,67 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMaxTasks(t *testing.T) {
+	c := newFakeClock()
+	var hits int64
+	tw := New(time.Second, 10, WithClock(c), WithMaxTasks(5, func(n int) {
+		if n != 5 {
+			t.Errorf("limit %d", n)
+		}
+		atomic.AddInt64(&hits, 1)
+	}))
+	tw.Start()
+	defer tw.Stop()
+	job := func(TaskData) {}
+	for i := 0; i < 5; i++ {
+		if err := tw.AddTask(time.Second, 1, i, nil, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.AddTask(time.Second, 1, 9, nil, job); err != ErrTooManyTasks {
+		t.Fatalf("add over the limit: %v", err)
+	}
+	if err := tw.AddTaskWith(time.Second, 1, 9, nil, job, Tags("x")); err != ErrTooManyTasks {
+		t.Fatalf("add with options over the limit: %v", err)
+	}
+	if _, err := tw.AddAnonymousTask(time.Second, 1, nil, job); err != ErrTooManyTasks {
+		t.Fatalf("anonymous add over the limit: %v", err)
+	}
+	// a rejected duplicate does not count
+	if err := tw.AddTask(time.Second, 1, 0, nil, job); err == nil {
+		t.Fatal("duplicate accepted")
+	}
+
+	// removed
+	tw.RemoveTask(0)
+	if err := tw.AddTask(time.Second, 1, 9, nil, job); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.AddTask(time.Second, 1, 10, nil, job); err != ErrTooManyTasks {
+		t.Fatalf("add over the limit: %v", err)
+	}
+	// exhausted
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	if tw.Len() != 0 {
+		t.Fatalf("%d tasks left", tw.Len())
+	}
+	for i := 0; i < 5; i++ {
+		if err := tw.AddTask(time.Second, 1, i, nil, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.AddTask(time.Second, 1, 9, nil, job); err != ErrTooManyTasks {
+		t.Fatalf("add over the limit: %v", err)
+	}
+	// called when the limit is reached after being below it
+	if n := atomic.LoadInt64(&hits); n != 3 {
+		t.Fatalf("%d limit callbacks, want 3", n)
+	}
+}
@@ -0,0 +1,119 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Locker lock shared by the instances of a service loading the same tasks, see WithLocker and the
+// redislock package
+type Locker interface {
+	// TryLock take the lock of the name for ttl, report whether it was free
+	TryLock(name string, ttl time.Duration) (bool, error)
+	// Unlock release the lock of the name taken by this instance
+	Unlock(name string) error
+}
+
+// WithLocker run an occurrence of a task on a single instance among the wheels sharing l. Before the job
+// runs the lock of the occurrence is taken for ttl, the task interval if ttl is not positive, the instances
+// failing to take it skip the run, see WheelStats.LockLost. The occurrences are named by the task key and
+// the scheduled time truncated to the interval, so the instances should place the tasks alike, see
+// AlignToPeriod. The lock is kept until it expires so an instance dispatching the occurrence a little
+// later skips it as well, a failed run releases it so such an instance may run it again. The lock is
+// taken on the job goroutine, a slow locker does not stall the ticks, a lock error skips the run.
+func WithLocker(l Locker, ttl time.Duration) Option {
+	return func(tw *TimeWheel) {
+		tw.locker = l
+		tw.lockTTL = ttl
+	}
+}
+
+// LockLost get the number of runs skipped for the lock held by another instance, see WithLocker
+func (tw *TimeWheel) LockLost() int64 {
+	return atomic.LoadInt64(&tw.lockLost)
+}
+
+// name of the occurrence of the run
+func lockName(r *jobRun) string {
+	scheduled := r.exec.Scheduled
+	if d := r.task.interval; d > 0 {
+		scheduled = scheduled.Truncate(d)
+	}
+	return fmt.Sprintf("%v@%d", r.exec.Key, scheduled.UnixNano())
+}
+
+// take the lock of the run, report whether the job runs, called on the job goroutine
+func (tw *TimeWheel) lockRun(r *jobRun) bool {
+	ttl := tw.lockTTL
+	if ttl <= 0 {
+		ttl = r.task.interval
+	}
+	name := lockName(r)
+	ok, err := tw.locker.TryLock(name, ttl)
+	if err != nil {
+		tw.logger.Printf("timewheel: lock failed, run skipped, key: %v, err: %v", r.exec.Key, err)
+	}
+	if err != nil || !ok {
+		atomic.AddInt64(&tw.lockLost, 1)
+		// the instance holding the lock runs it
+		if r.ack != nil {
+			r.ack.acknowledge()
+		}
+		return false
+	}
+	r.lock = name
+	return true
+}
+
+// release the lock of the failed run
+func (tw *TimeWheel) unlockRun(r *jobRun) {
+	if err := tw.locker.Unlock(r.lock); err != nil {
+		tw.logger.Printf("timewheel: unlock failed, key: %v, err: %v", r.exec.Key, err)
+	}
+}
+
+// MemoryLocker Locker shared by the wheels of a process
+type MemoryLocker struct {
+	mu    sync.Mutex
+	locks map[string]time.Time // expiry by name
+	calls int
+}
+
+var _ Locker = (*MemoryLocker)(nil)
+
+// NewMemoryLocker create a empty locker
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{locks: make(map[string]time.Time)}
+}
+
+// number of TryLock calls between the sweeps of the expired locks
+const lockSweepEvery = 1024
+
+// TryLock implement Locker
+func (l *MemoryLocker) TryLock(name string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.calls++; l.calls%lockSweepEvery == 0 {
+		for n, expiry := range l.locks {
+			if !now.Before(expiry) {
+				delete(l.locks, n)
+			}
+		}
+	}
+	if expiry, ok := l.locks[name]; ok && now.Before(expiry) {
+		return false, nil
+	}
+	l.locks[name] = now.Add(ttl)
+	return true, nil
+}
+
+// Unlock implement Locker
+func (l *MemoryLocker) Unlock(name string) error {
+	l.mu.Lock()
+	delete(l.locks, name)
+	l.mu.Unlock()
+	return nil
+}
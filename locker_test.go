@@ -0,0 +1,73 @@
+package timewheel
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLocker(t *testing.T) {
+	l := NewMemoryLocker()
+	var mu sync.Mutex
+	runs := map[time.Time]int{}
+	record := func(ctx context.Context, exec Execution, run func(ctx context.Context) error) error {
+		mu.Lock()
+		runs[exec.Scheduled]++
+		mu.Unlock()
+		return run(ctx)
+	}
+	var wheels []*TimeWheel
+	var clocks []*fakeClock
+	for i := 0; i < 2; i++ {
+		c := newFakeClock()
+		tw := New(10*time.Millisecond, 8, WithClock(c), WithLocker(l, time.Minute), WithInterceptor(record))
+		tw.Start()
+		defer tw.Stop()
+		tw.AddTask(10*time.Millisecond, 10, "k", nil, func(TaskData) {})
+		settle(tw)
+		wheels, clocks = append(wheels, tw), append(clocks, c)
+	}
+	for i := 0; i < 15; i++ {
+		for j := range wheels {
+			clocks[j].Tick(10 * time.Millisecond)
+		}
+		for j := range wheels {
+			settle(wheels[j])
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(runs) != 10 {
+		t.Fatal(runs)
+	}
+	for s, n := range runs {
+		if n != 1 {
+			t.Fatal(s, n)
+		}
+	}
+	if lost := wheels[0].LockLost() + wheels[1].Stats().LockLost; lost != 10 {
+		t.Fatal(lost)
+	}
+}
+
+func TestMemoryLocker(t *testing.T) {
+	l := NewMemoryLocker()
+	if ok, err := l.TryLock("a", 20*time.Millisecond); !ok || err != nil {
+		t.Fatal(ok, err)
+	}
+	if ok, _ := l.TryLock("a", time.Minute); ok {
+		t.Fatal("held lock taken")
+	}
+	if ok, _ := l.TryLock("b", time.Minute); !ok {
+		t.Fatal("other name")
+	}
+	l.Unlock("b")
+	if ok, _ := l.TryLock("b", time.Minute); !ok {
+		t.Fatal("unlocked")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if ok, _ := l.TryLock("a", time.Minute); !ok {
+		t.Fatal("expired lock held")
+	}
+}
@@ -0,0 +1,43 @@
+package timewheel
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Logger receive the internal events of the wheel, *log.Logger satisfies it
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// WithLogger set the logger of the wheel, default discards everything
+func WithLogger(l Logger) Option {
+	return func(tw *TimeWheel) {
+		if l != nil {
+			tw.logger = l
+		}
+	}
+}
+
+// discard everything
+type nopLogger struct{}
+
+func (nopLogger) Printf(format string, args ...interface{}) {}
+
+// SlogLogger adapt a *slog.Logger to Logger, the events are logged at the given level
+func SlogLogger(l *slog.Logger, level slog.Level) Logger {
+	return &slogLogger{l: l, level: level}
+}
+
+type slogLogger struct {
+	l     *slog.Logger
+	level slog.Level
+}
+
+func (s *slogLogger) Printf(format string, args ...interface{}) {
+	if !s.l.Enabled(context.Background(), s.level) {
+		return
+	}
+	s.l.Log(context.Background(), s.level, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,87 @@
+package timewheel
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Logger keeping the lines
+type captureLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *captureLogger) Printf(format string, args ...interface{}) {
+	l.mu.Lock()
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+	l.mu.Unlock()
+}
+
+// report whether a line contains s
+func (l *captureLogger) has(s string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, line := range l.lines {
+		if strings.Contains(line, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLoggerEvents(t *testing.T) {
+	l := &captureLogger{}
+	c := newFakeClock()
+	tw := New(time.Second, 4, WithClock(c), WithLogger(l), WithAddBuffer(2))
+	// the buffer holds both adds, the wheel goroutine rejects the second one
+	tw.AddTask(time.Second, 1, "dup", nil, func(TaskData) {})
+	tw.AddTask(time.Second, 1, "dup", nil, func(TaskData) {})
+	if err := tw.TryAddTask(time.Second, 1, "full", nil, func(TaskData) {}); err != ErrQueueFull {
+		t.Fatal(err)
+	}
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(time.Second, 1, "panic", nil, func(TaskData) { panic("boom") })
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	c.Tick(10 * time.Second)
+	settle(tw)
+	for _, s := range []string{
+		"add queue is full, task rejected, key: full",
+		"duplicate task key rejected, key: dup",
+		"job panic recovered, key: panic, panic: boom",
+		"9 ticks missed",
+	} {
+		if !l.has(s) {
+			t.Errorf("%q not logged in %q", s, l.lines)
+		}
+	}
+}
+
+func TestLoggerAdapters(t *testing.T) {
+	var buf bytes.Buffer
+	var std Logger = log.New(&buf, "", 0)
+	std.Printf("timewheel: %d ticks missed", 3)
+	if buf.String() != "timewheel: 3 ticks missed\n" {
+		t.Fatal(buf.String())
+	}
+
+	buf.Reset()
+	l := SlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})), slog.LevelWarn)
+	l.Printf("timewheel: key: %v", "a")
+	if !strings.Contains(buf.String(), `level=WARN msg="timewheel: key: a"`) {
+		t.Fatal(buf.String())
+	}
+	buf.Reset()
+	SlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})), slog.LevelInfo).Printf("dropped")
+	if buf.Len() != 0 {
+		t.Fatal("below the handler level", buf.String())
+	}
+}
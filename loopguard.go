@@ -0,0 +1,168 @@
+package timewheel
+
+import (
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// limit of the panics of the wheel goroutine within the window before the wheel stops, see WithLoopPanicLimit
+const (
+	defaultLoopPanics      = 3
+	defaultLoopPanicWindow = time.Minute
+)
+
+// Status state of the wheel
+type Status int
+
+const (
+	// StatusNew the wheel is not started
+	StatusNew Status = iota
+	// StatusRunning the wheel is started
+	StatusRunning
+	// StatusStopped the wheel is stopped
+	StatusStopped
+	// StatusFatal the wheel stopped itself after repeated panics of its goroutine, see WithLoopPanicLimit
+	StatusFatal
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusNew:
+		return "new"
+	case StatusRunning:
+		return "running"
+	case StatusStopped:
+		return "stopped"
+	case StatusFatal:
+		return "fatal"
+	}
+	return "unknown"
+}
+
+// Status get the state of the wheel
+func (tw *TimeWheel) Status() Status {
+	if atomic.LoadInt32(&tw.fatal) == 1 {
+		return StatusFatal
+	}
+	switch atomic.LoadInt32(&tw.state) {
+	case stateNew:
+		return StatusNew
+	case stateStarted:
+		return StatusRunning
+	}
+	return StatusStopped
+}
+
+// LoopPanicHandler receive a panic recovered on the wheel goroutine with its stack
+type LoopPanicHandler func(v interface{}, stack []byte)
+
+// WithPanicHandler call h on the wheel goroutine with every panic recovered there, e.g. raised by a hook
+// or a job run inline. The event being handled is abandoned, a call waiting for it gets ErrLoopPanic,
+// and the tasks it left out of the wheel are placed back to be due at the next tick.
+func WithPanicHandler(h LoopPanicHandler) Option {
+	return func(tw *TimeWheel) {
+		tw.panicHandler = h
+	}
+}
+
+// WithLoopPanicLimit stop the wheel once its goroutine panicked n times within window, Status then reports
+// StatusFatal. Default is 3 panics within a minute.
+func WithLoopPanicLimit(n int, window time.Duration) Option {
+	return func(tw *TimeWheel) {
+		if n > 0 && window > 0 {
+			tw.panicLimit, tw.panicWindow = n, window
+		}
+	}
+}
+
+// LoopPanics get the number of panics recovered on the wheel goroutine
+func (tw *TimeWheel) LoopPanics() int64 {
+	return atomic.LoadInt64(&tw.loopPanics)
+}
+
+// handle an event recovering its panic, report false once the wheel is stopped
+func (tw *TimeWheel) guardedStep() (running bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			tw.loopPanic(v, debug.Stack())
+			running = true
+		}
+	}()
+	return tw.step()
+}
+
+// send the error of fn to the caller waiting on ch, ErrLoopPanic if fn panics
+func (tw *TimeWheel) reply(ch chan error, fn func() error) {
+	err := ErrLoopPanic
+	defer func() {
+		ch <- err
+	}()
+	err = fn()
+}
+
+// report the panic, restore a consistent state and stop the wheel once the panics exceed the limit
+func (tw *TimeWheel) loopPanic(v interface{}, stack []byte) {
+	atomic.AddInt64(&tw.loopPanics, 1)
+	tw.logger.Printf("timewheel: wheel goroutine panic recovered, panic: %v\n%s", v, stack)
+	if tw.panicHandler != nil {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					tw.logger.Printf("timewheel: panic handler panic recovered, panic: %v", r)
+				}
+			}()
+			tw.panicHandler(v, stack)
+		}()
+	}
+	tw.restoreLoop()
+
+	limit, window := tw.panicLimit, tw.panicWindow
+	if limit == 0 {
+		limit, window = defaultLoopPanics, defaultLoopPanicWindow
+	}
+	now := time.Now()
+	tw.panicTimes = append(tw.panicTimes, now)
+	for len(tw.panicTimes) > 0 && now.Sub(tw.panicTimes[0]) > window {
+		tw.panicTimes = tw.panicTimes[1:]
+	}
+	if len(tw.panicTimes) >= limit {
+		tw.logger.Printf("timewheel: %d panics within %v, stopping the wheel", len(tw.panicTimes), window)
+		atomic.StoreInt32(&tw.fatal, 1)
+		tw.Stop()
+	}
+}
+
+// abandon the event being handled: the scan in progress is dropped and the registered tasks left out of
+// the backend and of the waiting queues are placed back to be due at the next tick
+func (tw *TimeWheel) restoreLoop() {
+	tw.catchingUp = false
+	tw.caughtUp = nil
+	tw.backend.abort()
+	// the runs held for the batch handlers are dispatched already
+	tw.batching = false
+	tw.flushBatches()
+
+	placed := make(map[*task]struct{})
+	tw.backend.each(func(t *task) {
+		placed[t] = struct{}{}
+	})
+	for _, queue := range [][]*task{tw.deferred, tw.blackedOut, tw.carry, tw.gatedTasks} {
+		for _, t := range queue {
+			placed[t] = struct{}{}
+		}
+	}
+	var lost []*task
+	tw.taskRecord.Range(func(key interface{}, t *task) bool {
+		if _, ok := placed[t]; !ok && t.times != 0 && t.dep == nil && !t.isHeld() {
+			lost = append(lost, t)
+		}
+		return true
+	})
+	for _, t := range lost {
+		tw.backend.push(t, 0)
+	}
+	if len(lost) > 0 {
+		tw.logger.Printf("timewheel: %d tasks placed back after the panic", len(lost))
+	}
+}
@@ -0,0 +1,70 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slot store panicking on the scans while armed
+type poisonStore struct {
+	SlotStore
+	armed *int32
+}
+
+func (s poisonStore) Scan(keep func(e *SlotEntry) bool) {
+	s.SlotStore.Scan(func(e *SlotEntry) bool {
+		if atomic.LoadInt32(s.armed) == 1 && s.SlotStore.Len() > 0 {
+			atomic.StoreInt32(s.armed, 0)
+			panic("poisoned")
+		}
+		return keep(e)
+	})
+}
+
+func TestLoopPanicRecovered(t *testing.T) {
+	c := newFakeClock()
+	var armed int32
+	var handled int64
+	tw := New(time.Second, 4, WithClock(c), WithSlotStore(func() SlotStore {
+		return poisonStore{NewSliceSlotStore(), &armed}
+	}), WithPanicHandler(func(v interface{}, stack []byte) {
+		atomic.AddInt64(&handled, 1)
+	}))
+	tw.Start()
+	defer tw.Stop()
+	var n int64
+	tw.AddTask(time.Second, -1, "a", nil, func(TaskData) { atomic.AddInt64(&n, 1) })
+	tw.SetTickHook(func(int, int) { panic("hook") })
+	if err := tw.exec(func() { panic("exec") }); err != nil {
+		t.Fatal(err)
+	}
+	atomic.StoreInt32(&armed, 1)
+	for i := 0; i < 6; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	// the exec panic and the poisoned scan, the hook panics are recovered by the hook call
+	if tw.LoopPanics() != 2 || atomic.LoadInt64(&handled) != 2 || tw.Status() != StatusRunning {
+		t.Fatal(tw.LoopPanics(), handled, tw.Status())
+	}
+	// the task is still scheduled after the abandoned scan
+	waitCount(t, &n, 4)
+	if !tw.HasTask("a") {
+		t.Fatal("task lost")
+	}
+}
+
+func TestLoopPanicFatal(t *testing.T) {
+	tw := New(time.Second, 4, WithLoopPanicLimit(2, time.Minute))
+	tw.Start()
+	tw.exec(func() { panic(1) })
+	if tw.Status() != StatusRunning {
+		t.Fatal(tw.Status())
+	}
+	tw.exec(func() { panic(2) })
+	<-tw.loopDone
+	if tw.Status() != StatusFatal || tw.Healthy(time.Second) != ErrLoopPanic {
+		t.Fatal(tw.Status())
+	}
+}
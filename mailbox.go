@@ -0,0 +1,120 @@
+package timewheel
+
+import "time"
+
+// DefaultMailboxSize default number of runs a mailbox holds, see HoldDeliveries
+const DefaultMailboxSize = 64
+
+// ReleasePolicy decide what happens to the runs held in the mailbox of a task, see ReleaseDeliveries
+type ReleasePolicy int
+
+const (
+	// ReleaseEach run every held run right away, the runs count towards times
+	ReleaseEach ReleasePolicy = iota
+	// ReleaseCollapse run once right away for the held runs, scheduled at the latest one
+	ReleaseCollapse
+	// ReleaseDiscard drop the held runs
+	ReleaseDiscard
+)
+
+// WithMailboxSize set the number of runs held by the mailbox of a task, once it is full the oldest run
+// is dropped. Default is DefaultMailboxSize.
+func WithMailboxSize(n int) Option {
+	return func(tw *TimeWheel) {
+		if n > 0 {
+			tw.mailboxSize = n
+		}
+	}
+}
+
+// runs of a task held by HoldDeliveries
+type mailbox struct {
+	runs    []time.Time // ideal times of the held runs, oldest first
+	dropped int64       // runs dropped because the mailbox was full
+}
+
+// HoldDeliveries hold the runs of the task in its mailbox until ReleaseDeliveries, the task keeps its
+// schedule but the jobs do not run. Like under the firing gate, the held runs do not count towards
+// times until they are released and the final run of a task with an end is not held.
+// See WithMailboxSize for the overflow.
+func (tw *TimeWheel) HoldDeliveries(key interface{}) error {
+	return tw.withMailbox(key, func(t *task) error {
+		if t.mailbox == nil {
+			t.mailbox = &mailbox{}
+		}
+		return nil
+	})
+}
+
+// ReleaseDeliveries stop holding the runs of the task and apply policy to the runs held so far.
+// The job gets the ideal time of each run, see ScheduledTime.
+func (tw *TimeWheel) ReleaseDeliveries(key interface{}, policy ReleasePolicy) error {
+	return tw.withMailbox(key, func(t *task) error {
+		m := t.mailbox
+		if m == nil {
+			return nil
+		}
+		t.mailbox = nil
+		switch {
+		case len(m.runs) == 0 || policy == ReleaseDiscard:
+		case policy == ReleaseCollapse:
+			tw.replayAt(t, m.runs[len(m.runs)-1:])
+		default:
+			tw.replayAt(t, m.runs)
+		}
+		return nil
+	})
+}
+
+// Mailbox get the number of runs held for the task and of the runs dropped because its mailbox was full,
+// both are 0 if the runs of the task are not held
+func (tw *TimeWheel) Mailbox(key interface{}) (held int, dropped int64, err error) {
+	err = tw.withMailbox(key, func(t *task) error {
+		if t.mailbox != nil {
+			held, dropped = len(t.mailbox.runs), t.mailbox.dropped
+		}
+		return nil
+	})
+	return
+}
+
+// call fn with the task registered under the key on the wheel goroutine
+func (tw *TimeWheel) withMailbox(key interface{}, fn func(t *task) error) error {
+	if key == nil {
+		return ErrInvalidKey
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	err := ErrTaskNotFound
+	if execErr := tw.exec(func() {
+		if t, ok := tw.taskRecord.Load(key); ok && t.times != 0 {
+			err = fn(t)
+		}
+	}); execErr != nil {
+		return execErr
+	}
+	return err
+}
+
+// put the due run in the mailbox of the task, the task moves on to its next run, report whether it is held
+func (tw *TimeWheel) holdForMailbox(task *task) bool {
+	m := task.mailbox
+	if m == nil || task.lastRun() {
+		return false
+	}
+	size := tw.mailboxSize
+	if size == 0 {
+		size = DefaultMailboxSize
+	}
+	if len(m.runs) == size {
+		copy(m.runs, m.runs[1:])
+		m.runs = m.runs[:size-1]
+		m.dropped++
+	}
+	m.runs = append(m.runs, task.next)
+	tw.trace(task, TraceDeferred)
+	task.next = task.following()
+	tw.addTask(task)
+	return true
+}
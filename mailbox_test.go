@@ -0,0 +1,67 @@
+package timewheel
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMailbox(t *testing.T) {
+	for _, policy := range []ReleasePolicy{ReleaseEach, ReleaseCollapse, ReleaseDiscard} {
+		c := newFakeClock()
+		start := c.Now()
+		tw := New(100*time.Millisecond, 16, WithClock(c), WithMailboxSize(3))
+		tw.Start()
+		var mu sync.Mutex
+		var got []time.Time
+		tw.AddTaskCtx(100*time.Millisecond, -1, "k", nil, func(ctx context.Context, _ TaskData) {
+			at, _ := ScheduledTime(ctx)
+			mu.Lock()
+			got = append(got, at)
+			mu.Unlock()
+		})
+		settle(tw)
+		if err := tw.HoldDeliveries("k"); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 5; i++ {
+			c.Tick(100 * time.Millisecond)
+			settle(tw)
+		}
+		held, dropped, _ := tw.Mailbox("k")
+		if held != 3 || dropped != 1 {
+			t.Fatal(held, dropped)
+		}
+		if err := tw.ReleaseDeliveries("k", policy); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		sort.Slice(got, func(i, j int) bool { return got[i].Before(got[j]) })
+		// the run due at 100ms was dropped, a collapsed release runs the latest
+		want := map[ReleasePolicy][]time.Duration{
+			ReleaseEach:     {200 * time.Millisecond, 300 * time.Millisecond, 400 * time.Millisecond},
+			ReleaseCollapse: {400 * time.Millisecond},
+		}[policy]
+		if len(got) != len(want) {
+			t.Fatal(policy, got)
+		}
+		for i, w := range want {
+			if d := got[i].Sub(start); d != w {
+				t.Fatalf("%v: run %d scheduled at %v, want %v", policy, i, d, w)
+			}
+		}
+		mu.Unlock()
+		c.Tick(100 * time.Millisecond)
+		settle(tw)
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		if len(got) != len(want)+1 {
+			t.Fatal("not resumed", got)
+		}
+		mu.Unlock()
+		tw.Stop()
+	}
+}
@@ -0,0 +1,58 @@
+package timewheel
+
+import (
+	"container/list"
+	"sync/atomic"
+	"unsafe"
+)
+
+// rough size of a record map entry: the key, the task pointer and the bucket overhead
+const recordEntryBytes = int64(unsafe.Sizeof(interface{}(nil))+unsafe.Sizeof((*task)(nil))) + 8
+
+// MemStats estimated memory held by the wheel, the bytes are computed from the counts, not measured
+type MemStats struct {
+	Tasks       int64 // tasks registered now
+	Zombies     int64 // removed tasks still held by a due queue until the queue reaches them
+	SlotEntries int64 // entries of the slots, or of the heap for the heap backend
+	TaskBytes   int64 // tasks, the zombies included
+	EntryBytes  int64 // slot entries
+	RecordBytes int64 // key record
+	Bytes       int64 // total of the estimates
+	PeakTasks   int64 // most tasks registered at once since the wheel was created
+	PeakBytes   int64 // estimated total at PeakTasks
+}
+
+// MemoryStats estimate the memory held by the tasks, the slots and the key record for capacity planning.
+// The slot entries and the zombies are counted on the wheel goroutine, they are 0 once the wheel is stopped.
+// A removed task leaves its slot at once, only the tasks removed while waiting in the queues of the tick cap,
+// the blackout windows or the scan chunk linger as zombies.
+func (tw *TimeWheel) MemoryStats() MemStats {
+	var entries, zombies int64
+	entryBytes := int64(unsafe.Sizeof((*SlotEntry)(nil)))
+	tw.exec(func() {
+		entries = int64(tw.backend.len())
+		tw.eachWaiting(func(t *task) {
+			if t.times == 0 {
+				zombies++
+			}
+		})
+		if wb, ok := wheelOf(tw.backend); ok && len(wb.slots) > 0 {
+			if _, ok := wb.slots[0].(*listSlot); ok {
+				entryBytes = int64(unsafe.Sizeof(list.Element{}))
+			}
+		}
+	})
+	taskBytes := int64(unsafe.Sizeof(task{}))
+	s := MemStats{
+		Tasks:       atomic.LoadInt64(&tw.taskNum),
+		Zombies:     zombies,
+		SlotEntries: entries,
+		PeakTasks:   atomic.LoadInt64(&tw.peakTasks),
+	}
+	s.TaskBytes = (s.Tasks + s.Zombies) * taskBytes
+	s.EntryBytes = s.SlotEntries * entryBytes
+	s.RecordBytes = s.Tasks * recordEntryBytes
+	s.Bytes = s.TaskBytes + s.EntryBytes + s.RecordBytes
+	s.PeakBytes = s.PeakTasks * (taskBytes + entryBytes + recordEntryBytes)
+	return s
+}
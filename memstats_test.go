@@ -0,0 +1,50 @@
+package timewheel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStats(t *testing.T) {
+	c := newFakeClock()
+	tw := New(10*time.Millisecond, 8, WithClock(c), WithTickCap(1))
+	tw.Start()
+	defer tw.Stop()
+	for i := 0; i < 10; i++ {
+		tw.AddTask(time.Hour, 1, i, nil, func(TaskData) {})
+	}
+	for i := 10; i < 14; i++ {
+		tw.AddTask(10*time.Millisecond, 1, i, nil, func(TaskData) {})
+	}
+	for i := 0; i < 3; i++ {
+		tw.RemoveTask(i)
+	}
+	s := tw.MemoryStats()
+	if s.Tasks != 11 || s.SlotEntries != 11 || s.PeakTasks != 14 || s.Zombies != 0 || s.Bytes == 0 {
+		t.Fatalf("%+v", s)
+	}
+	c.Tick(10 * time.Millisecond)
+	c.Tick(10 * time.Millisecond)
+	settle(tw)
+	// one ran, the others wait under the cap
+	tw.RemoveTask(12)
+	tw.RemoveTask(13)
+	s = tw.MemoryStats()
+	if s.Zombies != 2 || s.Tasks != 8 || s.SlotEntries != 7 {
+		t.Fatalf("%+v", s)
+	}
+	// the zombies are counted in the task bytes, not in the record
+	if s.TaskBytes%10 != 0 || s.RecordBytes%8 != 0 || s.EntryBytes%7 != 0 ||
+		s.Bytes != s.TaskBytes+s.EntryBytes+s.RecordBytes {
+		t.Fatalf("%+v", s)
+	}
+	// a full rotation sweeps the zombies
+	for i := 0; i < 8; i++ {
+		c.Tick(10 * time.Millisecond)
+	}
+	settle(tw)
+	s = tw.MemoryStats()
+	if s.Zombies != 0 || s.Tasks != 7 {
+		t.Fatalf("%+v", s)
+	}
+}
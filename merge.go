@@ -0,0 +1,178 @@
+package timewheel
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ConflictPolicy decide what Merge does with an incoming task whose key is registered on the wheel
+type ConflictPolicy int
+
+const (
+	// KeepExisting keep the registered task and drop the incoming one
+	KeepExisting ConflictPolicy = iota
+	// TakeIncoming remove the registered task and add the incoming one
+	TakeIncoming
+	// ConflictError merge nothing and return ErrDuplicateKey
+	ConflictError
+)
+
+func (p ConflictPolicy) String() string {
+	switch p {
+	case KeepExisting:
+		return "keep existing"
+	case TakeIncoming:
+		return "take incoming"
+	case ConflictError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// task taken from the source of a merge
+type incoming struct {
+	t    *task
+	left time.Duration
+	spec TaskSpec
+	gen  uint64 // generation of t, read before t is registered
+}
+
+// Merge move every task of src to the wheel, the time left until the next run, the remaining times,
+// the data, the job and the options are kept. src keeps running with no tasks left but the final runs
+// in flight. The tasks are unlinked on the src goroutine before the wheel adds them, so no task runs on
+// both wheels, the tasks the wheel can not take are put back on src. A key registered on both wheels
+// is resolved by onConflict. Merge returns the number of tasks added to the wheel.
+func (tw *TimeWheel) Merge(src *TimeWheel, onConflict ConflictPolicy) (int, error) {
+	if src == nil || src == tw {
+		return 0, ErrInvalidParams
+	}
+	if tw.isStopped() {
+		return 0, ErrWheelStopped
+	}
+	var in []incoming
+	var errs []error
+	execErr := src.exec(func() {
+		var tasks []*task
+		each := func(t *task) {
+			if t.times != 0 && !t.isHeld() {
+				tasks = append(tasks, t)
+			}
+		}
+		src.eachWaiting(each)
+		src.eachDependent(each)
+		src.backend.each(each)
+		if onConflict == ConflictError {
+			for _, t := range tasks {
+				if _, ok := tw.taskRecord.Load(t.key); ok {
+					errs = append(errs, fmt.Errorf("%w: %v", ErrDuplicateKey, t.key))
+					return
+				}
+			}
+		}
+		now := src.clock.Now()
+		for _, t := range tasks {
+			m, err := tw.copyTask(t, t.job)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("merge task %v: %w", t.key, err))
+				continue
+			}
+			left := t.next.Sub(now)
+			if t.dep != nil {
+				m.dep = &dependency{key: t.dep.key, firstRun: t.dep.firstRun}
+				left = t.dep.delay
+			}
+			in = append(in, incoming{t: m, left: left, spec: t.spec(now), gen: m.gen})
+
+			src.emit(src.hooks.OnTaskRemoved, t)
+			src.publish(EventRemoved, t)
+			src.undefer(t)
+			src.unregister(t)
+			atomic.AddInt64(&src.removedNum, 1)
+			if src.metrics != nil {
+				src.metrics.TaskRemoved()
+			}
+		}
+	})
+	if execErr != nil {
+		return 0, execErr
+	}
+	if len(in) == 0 {
+		return 0, errors.Join(errs...)
+	}
+
+	var merged, dropped, replaced, back []incoming
+	execErr = tw.exec(func() {
+		now := tw.clock.Now()
+		for _, m := range in {
+			if old, ok := tw.taskRecord.Load(m.t.key); ok {
+				switch onConflict {
+				case KeepExisting:
+					dropped = append(dropped, m)
+					continue
+				case TakeIncoming:
+					if old.jobName != "" {
+						replaced = append(replaced, m)
+					}
+					tw.removeTask(&removeRequest{key: m.t.key})
+				default:
+					// the key was added since the check
+					back = append(back, m)
+					continue
+				}
+			}
+			m.t.next = now.Add(m.left)
+			m.t.atNext = true
+			tw.taskAccepted()
+			tw.addTask(m.t)
+			merged = append(merged, m)
+		}
+	})
+	if execErr != nil {
+		back, merged, dropped, replaced = in, nil, nil, nil
+		errs = append(errs, execErr)
+	}
+
+	for _, m := range dropped {
+		tw.dropTask(m.t)
+		if m.spec.JobName != "" {
+			src.forgetNamed(m.spec.Key)
+		}
+	}
+	for _, m := range replaced {
+		tw.forgetNamed(m.spec.Key)
+	}
+	for _, m := range merged {
+		if m.spec.JobName != "" {
+			src.forgetNamed(m.spec.Key)
+			tw.rememberNamed(m.spec, m.gen)
+		}
+	}
+	for _, m := range back {
+		if err := src.putBack(m); err != nil {
+			src.logger.Printf("timewheel: task lost by a failed merge, key: %v, err: %v", m.spec.Key, err)
+		}
+		tw.dropTask(m.t)
+		if onConflict == ConflictError && execErr == nil {
+			errs = append(errs, fmt.Errorf("%w: %v", ErrDuplicateKey, m.spec.Key))
+		}
+	}
+	return len(merged), errors.Join(errs...)
+}
+
+// register again the task taken by a merge
+func (tw *TimeWheel) putBack(m incoming) error {
+	t, err := tw.copyTask(m.t, m.t.job)
+	if err != nil {
+		return err
+	}
+	if m.t.dep != nil {
+		t.dep = &dependency{key: m.t.dep.key, firstRun: m.t.dep.firstRun}
+	}
+	t.next = tw.clock.Now().Add(m.left)
+	if err = tw.adopt(t); err != nil {
+		tw.dropTask(t)
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+package timewheel
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// time left until the next run of the task under key, -1 if it is not upcoming
+func nextIn(tw *TimeWheel, c *fakeClock, key interface{}) time.Duration {
+	for _, info := range tw.Upcoming(time.Hour, 0) {
+		if info.Key == key {
+			return info.Next.Sub(c.Now())
+		}
+	}
+	return -1
+}
+
+func TestMerge(t *testing.T) {
+	for _, tc := range []struct {
+		policy  ConflictPolicy
+		merged  int
+		from    string // data of the shared key after the merge
+		srcRuns int64
+	}{
+		{KeepExisting, 2, "dst", 1},
+		{TakeIncoming, 3, "src", 2},
+		{ConflictError, 0, "dst", 0},
+	} {
+		t.Run(tc.policy.String(), func(t *testing.T) {
+			var srcRuns, dstRuns int64
+			c1, c2 := newFakeClock(), newFakeClock()
+			src := New(time.Second, 16, WithClock(c1))
+			dst := New(time.Second, 64, WithClock(c2))
+			src.Start()
+			dst.Start()
+			defer src.Stop()
+			defer dst.Stop()
+			sj := func(TaskData) { atomic.AddInt64(&srcRuns, 1) }
+			dj := func(TaskData) { atomic.AddInt64(&dstRuns, 1) }
+			src.AddTask(8*time.Second, 1, "a", nil, sj)
+			src.AddTask(8*time.Second, 1, "shared", TaskData{"from": "src"}, sj)
+			src.AddTask(time.Hour, 1, "far", nil, sj)
+			dst.AddTask(time.Hour, 1, "shared", TaskData{"from": "dst"}, dj)
+			dst.AddTask(time.Hour, 1, "b", nil, dj)
+			settle(src)
+			settle(dst)
+			for i := 0; i < 2; i++ {
+				c1.Tick(time.Second)
+				c2.Tick(time.Second)
+			}
+			settle(src)
+			settle(dst)
+			left := nextIn(src, c1, "a")
+
+			n, err := dst.Merge(src, tc.policy)
+			if tc.policy == ConflictError {
+				if !errors.Is(err, ErrDuplicateKey) || n != 0 || src.Len() != 3 || dst.Len() != 2 {
+					t.Fatalf("merged %d: %v, %d tasks left on the source, %d on the receiver", n, err, src.Len(), dst.Len())
+				}
+				return
+			}
+			if err != nil || n != tc.merged || src.Len() != 0 || dst.Len() != 4 {
+				t.Fatalf("merged %d: %v, %d tasks left on the source, %d on the receiver", n, err, src.Len(), dst.Len())
+			}
+			if got := nextIn(dst, c2, "a"); left <= 0 || got != left {
+				t.Fatalf("a runs in %v after the merge, %v before", got, left)
+			}
+			if data, _ := dst.GetTaskData("shared"); data["from"] != tc.from {
+				t.Fatalf("shared is the task from %v", data["from"])
+			}
+			for i := 0; i < 10; i++ {
+				c1.Tick(time.Second)
+				c2.Tick(time.Second)
+				settle(src)
+				settle(dst)
+			}
+			waitCount(t, &srcRuns, tc.srcRuns)
+			if atomic.LoadInt64(&dstRuns) != 0 || dst.Len() != 4-int(tc.srcRuns) {
+				t.Fatalf("%d runs of the receiver tasks, %d tasks left", dstRuns, dst.Len())
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package timewheel
+
+import "time"
+
+// Metrics receive the measurements of the wheel, see the prommetrics package for a prometheus implementation.
+// The methods are called from the wheel goroutine and the job goroutines, they must be cheap and safe for concurrent use.
+type Metrics interface {
+	// TaskAdded a task is registered
+	TaskAdded()
+	// TaskRemoved a task is removed by RemoveTask
+	TaskRemoved()
+	// TaskFired a task is dispatched
+	TaskFired()
+	// JobDone a job returned, panicked reports whether it panicked
+	JobDone(d time.Duration, panicked bool)
+	// TickDone a tick is processed, tasks is the number of registered tasks
+	// and backlog the number of tasks waiting in the add channel
+	TickDone(d time.Duration, tasks int, backlog int)
+}
+
+// WithMetrics set the metrics receiver of the wheel
+func WithMetrics(m Metrics) Option {
+	return func(tw *TimeWheel) {
+		tw.metrics = m
+	}
+}
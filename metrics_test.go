@@ -0,0 +1,60 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Metrics counting the calls
+type countMetrics struct {
+	added, removed, fired, jobs, panics, ticks int64
+	tasks                                      int64
+}
+
+func (m *countMetrics) TaskAdded()   { atomic.AddInt64(&m.added, 1) }
+func (m *countMetrics) TaskRemoved() { atomic.AddInt64(&m.removed, 1) }
+func (m *countMetrics) TaskFired()   { atomic.AddInt64(&m.fired, 1) }
+
+func (m *countMetrics) JobDone(d time.Duration, panicked bool) {
+	atomic.AddInt64(&m.jobs, 1)
+	if panicked {
+		atomic.AddInt64(&m.panics, 1)
+	}
+}
+
+func (m *countMetrics) TickDone(d time.Duration, tasks int, backlog int) {
+	atomic.AddInt64(&m.ticks, 1)
+	atomic.StoreInt64(&m.tasks, int64(tasks))
+}
+
+func TestMetrics(t *testing.T) {
+	m := &countMetrics{}
+	c := newFakeClock()
+	tw := New(time.Second, 4, WithClock(c), WithMetrics(m))
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(time.Second, -1, "a", nil, func(TaskData) {})
+	tw.AddTask(time.Second, 1, "b", nil, func(TaskData) { panic("boom") })
+	tw.AddTask(time.Hour, 1, "c", nil, func(TaskData) {})
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	if atomic.LoadInt64(&m.added) != 3 || atomic.LoadInt64(&m.fired) != 2 {
+		t.Fatal("added", m.added, "fired", m.fired)
+	}
+	if atomic.LoadInt64(&m.jobs) != 2 || atomic.LoadInt64(&m.panics) != 1 {
+		t.Fatal("jobs", m.jobs, "panics", m.panics)
+	}
+	if err := tw.RemoveTask("c"); err != nil {
+		t.Fatal(err)
+	}
+	c.Tick(time.Second)
+	settle(tw)
+	if atomic.LoadInt64(&m.removed) != 1 || atomic.LoadInt64(&m.fired) != 3 {
+		t.Fatal("removed", m.removed, "fired", m.fired)
+	}
+	if atomic.LoadInt64(&m.ticks) != 3 || atomic.LoadInt64(&m.tasks) != 1 {
+		t.Fatal("ticks", m.ticks, "tasks", m.tasks)
+	}
+}
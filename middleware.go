@@ -0,0 +1,59 @@
+package timewheel
+
+import (
+	"context"
+	"time"
+)
+
+// Middleware wrap the job of a task, it may skip the job by not calling next. TaskKey gets the key
+// of the task from the context.
+type Middleware func(next JobCtx) JobCtx
+
+// Use add middlewares around the jobs, applied in the order they are added, the first one outermost.
+// The chain is composed once when a task is added, so the middlewares added after Start apply to the
+// tasks added afterwards only. Every run goes through the chain, the runs of RunNow, the retries and
+// the runs of FlushAndStop included.
+func (tw *TimeWheel) Use(mw ...Middleware) {
+	tw.mwMu.Lock()
+	defer tw.mwMu.Unlock()
+	var chain []Middleware
+	if cur := tw.middleware.Load(); cur != nil {
+		chain = append(chain, *cur...)
+	}
+	for _, m := range mw {
+		if m != nil {
+			chain = append(chain, m)
+		}
+	}
+	tw.middleware.Store(&chain)
+}
+
+// wrap the job in the middlewares added so far
+func (tw *TimeWheel) wrapMiddleware(job JobCtx) JobCtx {
+	chain := tw.middleware.Load()
+	if chain == nil {
+		return job
+	}
+	for i := len(*chain) - 1; i >= 0; i-- {
+		job = (*chain)[i](job)
+	}
+	return job
+}
+
+// TaskKey get the key of the task whose job runs with ctx
+func TaskKey(ctx context.Context) (interface{}, bool) {
+	v, ok := ctx.Value(jobErrKey{}).(*runValue)
+	if !ok {
+		return nil, false
+	}
+	return v.key, true
+}
+
+// ScheduledTime get the ideal time of the run whose job runs with ctx, like Execution.Scheduled
+func ScheduledTime(ctx context.Context) (time.Time, bool) {
+	v, ok := ctx.Value(jobErrKey{}).(*runValue)
+	if !ok {
+		return time.Time{}, false
+	}
+	return v.scheduled, true
+}
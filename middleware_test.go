@@ -0,0 +1,60 @@
+package timewheel
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	tw := New(5*time.Millisecond, 16)
+	var mu sync.Mutex
+	var order []string
+	rec := func(s string) {
+		mu.Lock()
+		order = append(order, s)
+		mu.Unlock()
+	}
+	mk := func(name string) Middleware {
+		return func(next JobCtx) JobCtx {
+			return func(ctx context.Context, d TaskData) {
+				k, _ := TaskKey(ctx)
+				rec(name + ":" + k.(string))
+				next(ctx, d)
+			}
+		}
+	}
+	tw.Use(mk("a"), mk("b"))
+	tw.Use(mk("c"))
+	tw.Start()
+	defer tw.Stop()
+	done := make(chan struct{})
+	tw.AddTask(10*time.Millisecond, 1, "k", nil, func(TaskData) { rec("job"); close(done) })
+	<-done
+	mu.Lock()
+	if len(order) != 4 || order[0] != "a:k" || order[1] != "b:k" || order[2] != "c:k" || order[3] != "job" {
+		t.Fatal(order)
+	}
+	order = nil
+	mu.Unlock()
+
+	// the chain of a task is composed when it is added
+	early := make(chan struct{})
+	tw.AddTask(30*time.Millisecond, 1, "early", nil, func(TaskData) { close(early) })
+	settle(tw)
+	var skipped int64
+	tw.Use(func(next JobCtx) JobCtx {
+		return func(ctx context.Context, d TaskData) {
+			atomic.AddInt64(&skipped, 1)
+		}
+	})
+	var ran int64
+	tw.AddTask(10*time.Millisecond, 1, "late", nil, func(TaskData) { atomic.AddInt64(&ran, 1) })
+	waitCount(t, &skipped, 1)
+	<-early
+	if atomic.LoadInt64(&ran) != 0 || atomic.LoadInt64(&skipped) != 1 {
+		t.Fatal("short-circuited job ran")
+	}
+}
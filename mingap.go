@@ -0,0 +1,59 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// MinGap keep at least d between the starts of two runs of the task, whatever moves its runs closer:
+// ResetTaskTo, RunNow, a resumed task or the catch up of missed ticks. A run due sooner is deferred to the
+// earliest allowed time rather than dropped, the following runs count from there, see Stats.Deferred
+func MinGap(d time.Duration) TaskOption {
+	return func(t *task) {
+		if d > 0 {
+			t.minGap = d
+		}
+	}
+}
+
+// time left before the next run of the task may start, zero or less if it may start now
+func (tw *TimeWheel) gapLeft(task *task) time.Duration {
+	if task.minGap <= 0 {
+		return 0
+	}
+	last := atomic.LoadInt64(&task.stats.lastFire)
+	if last == 0 {
+		return 0
+	}
+	return time.Unix(0, last).Add(task.minGap).Sub(tw.clock.Now())
+}
+
+// defer the due task if its last run started less than its minimum gap ago, report whether it was deferred
+func (tw *TimeWheel) holdForGap(task *task) bool {
+	wait := tw.gapLeft(task)
+	if wait <= 0 {
+		return false
+	}
+	atomic.AddInt64(&task.stats.deferred, 1)
+	task.next = tw.clock.Now().Add(wait)
+	tw.backend.push(task, tw.delayTicks(wait))
+	tw.trace(task, TraceDeferred)
+	return true
+}
+
+// defer the run asked by RunNowWith if the last run started less than the minimum gap ago: the scheduled run
+// is taken out and deferred by holdForGap, report whether it was deferred
+func (tw *TimeWheel) holdRunNow(task *task) bool {
+	if tw.gapLeft(task) <= 0 {
+		return false
+	}
+	if task.dep != nil {
+		// still waiting, the gap is checked again once released
+		atomic.AddInt64(&task.stats.deferred, 1)
+		return true
+	}
+	if !tw.undefer(task) {
+		tw.backend.remove(task)
+	}
+	return tw.holdForGap(task)
+}
@@ -0,0 +1,51 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMinGap(t *testing.T) {
+	c := newFakeClock()
+	tw := New(100*time.Millisecond, 20, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var mu sync.Mutex
+	var starts []time.Time
+	err := tw.AddTaskWith(300*time.Millisecond, -1, "k", nil, func(TaskData) {
+		mu.Lock()
+		starts = append(starts, c.Now())
+		mu.Unlock()
+	}, MinGap(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	settle(tw)
+	// RunNow within the gap and the scheduled runs every 300ms are deferred
+	for i := 0; i < 50; i++ {
+		if err := tw.RunNow("k"); err != nil {
+			t.Fatal(err)
+		}
+		settle(tw)
+		c.Tick(100 * time.Millisecond)
+		settle(tw)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	// 5 seconds of runs at most a second apart
+	if len(starts) < 4 || len(starts) > 6 {
+		t.Fatalf("%d runs", len(starts))
+	}
+	for i := 1; i < len(starts); i++ {
+		if gap := starts[i].Sub(starts[i-1]); gap < time.Second {
+			t.Fatalf("runs %d and %d %v apart", i-1, i, gap)
+		}
+	}
+	st, _ := tw.TaskStats("k")
+	// 50 RunNow and the scheduled runs, at most a run a second
+	if st.Deferred < 40 {
+		t.Fatalf("%d runs deferred", st.Deferred)
+	}
+	checkInvariants(t, tw)
+}
@@ -0,0 +1,168 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// MoveTask move the task registered under key to dst, the time left until its next run, its remaining
+// times, data, job and options are kept. The task is unlinked on the wheel goroutine before dst adds it,
+// so it never runs on both wheels, the runs in flight go on. ErrDuplicateKey is returned and the task
+// stays if dst has the key. A task waiting for its prerequisite can not be moved.
+func (tw *TimeWheel) MoveTask(key interface{}, dst *TimeWheel) error {
+	if key == nil {
+		return ErrInvalidKey
+	}
+	if dst == nil || dst == tw {
+		return ErrInvalidParams
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	if dst.isStopped() {
+		return ErrWheelStopped
+	}
+	var moved *task
+	var left time.Duration
+	var err error
+	execErr := tw.exec(func() {
+		t, ok := tw.taskRecord.Load(key)
+		if !ok {
+			err = ErrTaskNotFound
+			return
+		}
+		if t.isHeld() {
+			err = ErrTaskStillRunning
+			return
+		}
+		if t.dep != nil {
+			err = fmt.Errorf("%w, the task waits for its prerequisite", ErrInvalidParams)
+			return
+		}
+		if _, ok := dst.taskRecord.Load(key); ok {
+			err = ErrDuplicateKey
+			return
+		}
+		if moved, err = dst.copyTask(t, t.job); err != nil {
+			return
+		}
+		left = t.next.Sub(tw.clock.Now())
+
+		tw.emit(tw.hooks.OnTaskRemoved, t)
+		tw.publish(EventRemoved, t)
+		tw.undefer(t)
+		tw.unregister(t)
+		atomic.AddInt64(&tw.removedNum, 1)
+		if tw.metrics != nil {
+			tw.metrics.TaskRemoved()
+		}
+		tw.prerequisiteGone(key)
+	})
+	if execErr != nil {
+		return execErr
+	}
+	if err != nil {
+		return err
+	}
+	moved.next = dst.clock.Now().Add(left)
+	spec, gen := moved.spec(dst.clock.Now()), moved.gen
+	if err = dst.adopt(moved); err != nil {
+		// dst stopped or got the key meanwhile, put the task back
+		back, aerr := tw.copyTask(moved, moved.job)
+		if aerr == nil {
+			back.next = tw.clock.Now().Add(left)
+			aerr = tw.adopt(back)
+		}
+		dst.dropTask(moved)
+		if aerr != nil {
+			tw.logger.Printf("timewheel: task lost by a failed move, key: %v, err: %v", key, aerr)
+		}
+		return err
+	}
+	if spec.JobName != "" {
+		tw.forgetNamed(key)
+		dst.rememberNamed(spec, gen)
+	}
+	return nil
+}
+
+// register the task at its next run time, fail if the key is registered
+func (tw *TimeWheel) adopt(t *task) error {
+	var err error
+	execErr := tw.exec(func() {
+		if _, ok := tw.taskRecord.Load(t.key); ok {
+			err = ErrDuplicateKey
+			return
+		}
+		t.atNext = true
+		tw.taskAccepted()
+		tw.addTask(t)
+	})
+	if execErr != nil {
+		return execErr
+	}
+	return err
+}
+
+// persist the named task of generation gen added by a move
+func (tw *TimeWheel) rememberNamed(spec TaskSpec, gen uint64) {
+	if tw.store != nil {
+		if err := tw.store.Add(spec); err != nil {
+			tw.logger.Printf("timewheel: store add failed, key: %v, err: %v", spec.Key, err)
+		}
+	}
+	tw.walAppend(walRecord{Op: walAdd, Spec: spec, Gen: gen})
+}
+
+// allocate on tw a copy of the task running job, see copyDefinition, the options are checked against tw
+func (tw *TimeWheel) copyTask(from *task, job JobCtx) (*task, error) {
+	t, err := tw.allocTask(from.interval, from.times, from.key, from.taskData, job)
+	if err != nil {
+		return nil, err
+	}
+	t.copyDefinition(from)
+	if err = tw.acceptOptions(t); err != nil {
+		tw.dropTask(t)
+		return nil, err
+	}
+	return t, nil
+}
+
+// copy the options and the state of from, the params given to allocTask excepted. The state bound to the
+// wheel of from is not copied: its position, the runs in flight, the prerequisite, the scope and the trace.
+func (t *task) copyDefinition(from *task) {
+	t.jobName = from.jobName
+	t.paused = atomic.LoadInt32(&from.paused)
+	t.skip = atomic.LoadInt32(&from.skip)
+	t.anchor = from.anchor
+	t.tags = append([]string(nil), from.tags...)
+	t.priority = from.priority
+	t.jitter = from.jitter
+	t.minGap = from.minGap
+	if b := from.breaker; b != nil {
+		t.breaker = &breaker{threshold: b.threshold, coolDown: b.coolDown, state: atomic.LoadInt32(&b.state),
+			openedAt: atomic.LoadInt64(&b.openedAt)}
+	}
+	t.precise = from.precise
+	t.backoff = from.backoff
+	t.stretched = atomic.LoadInt64(&from.stretched)
+	t.critical = from.critical
+	t.ack = from.ack
+	t.resume = from.resume
+	t.missed = from.missed
+	t.then = from.then
+	t.attached = append([]attachedJob(nil), from.attached...)
+	if m := from.mailbox; m != nil {
+		t.mailbox = &mailbox{runs: append([]time.Time(nil), m.runs...), dropped: m.dropped}
+	}
+	t.group = from.group
+	t.batch = from.batch
+	t.onDone = from.onDone
+	t.until = from.until
+	t.alignPeriod = from.alignPeriod
+	t.schedule = from.schedule
+	t.labels = from.labels
+	t.ttl = from.ttl
+	t.expires = from.expires
+}
@@ -0,0 +1,233 @@
+package timewheel
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// fields of task copied by copyDefinition
+var definitionFields = map[string]bool{
+	"jobName": true, "paused": true, "skip": true, "anchor": true, "tags": true, "priority": true,
+	"jitter": true, "minGap": true, "breaker": true, "precise": true, "backoff": true, "stretched": true,
+	"critical": true, "ack": true, "resume": true, "missed": true, "then": true, "attached": true,
+	"mailbox": true, "group": true, "batch": true, "onDone": true, "until": true, "alignPeriod": true,
+	"schedule": true, "labels": true, "ttl": true, "expires": true,
+}
+
+// fields of task set by allocTask or bound to the wheel holding the task
+var wheelFields = map[string]bool{
+	"gen": true, "interval": true, "times": true, "key": true, "job": true, "run": true, "taskData": true,
+	"next": true, "refs": true, "circle": true, "slot": true, "slotGen": true, "displaced": true,
+	"heapIndex": true, "due": true, "seq": true, "entry": true, "atNext": true, "timerSeq": true,
+	"shed": true, "redeliver": true, "attempts": true, "gated": true, "waiters": true, "dep": true,
+	"held": true, "trace": true, "stats": true, "scope": true,
+}
+
+// schedule running every second
+type secondly struct{}
+
+func (secondly) Next(t time.Time) time.Time {
+	return t.Add(time.Second)
+}
+
+// addressable view of the field i of v, unexported fields included
+func field(v reflect.Value, i int) reflect.Value {
+	f := v.Field(i)
+	return reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+}
+
+func TestCopyDefinition(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	from := &task{
+		jobName:     "job",
+		paused:      1,
+		skip:        2,
+		anchor:      at,
+		tags:        []string{"a", "b"},
+		priority:    3,
+		jitter:      time.Millisecond,
+		minGap:      2 * time.Millisecond,
+		breaker:     &breaker{threshold: 3, coolDown: time.Second, state: 1, openedAt: 42},
+		precise:     true,
+		backoff:     &backoff{factor: 2, max: time.Minute},
+		stretched:   int64(time.Second),
+		critical:    true,
+		ack:         &ackPolicy{timeout: time.Second, maxAttempts: 3},
+		resume:      ResumeReplayAll,
+		missed:      4,
+		then:        []ChainStep{{Key: "next"}},
+		attached:    []attachedJob{{id: "x", job: func(TaskData) {}}},
+		mailbox:     &mailbox{runs: []time.Time{at}, dropped: 1},
+		group:       "g",
+		batch:       NewBatchHandler(func([]TaskEntry) {}),
+		onDone:      func(interface{}, TaskData) {},
+		until:       at.Add(time.Hour),
+		alignPeriod: true,
+		schedule:    secondly{},
+		labels:      map[string]string{"l": "v"},
+		ttl:         time.Hour,
+		expires:     at.Add(2 * time.Hour),
+	}
+	to := &task{}
+	to.copyDefinition(from)
+
+	fv, tv := reflect.ValueOf(from).Elem(), reflect.ValueOf(to).Elem()
+	typ := fv.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		name := typ.Field(i).Name
+		if wheelFields[name] {
+			continue
+		}
+		if !definitionFields[name] {
+			t.Errorf("field %s of task is neither copied nor bound to the wheel, classify it", name)
+			continue
+		}
+		f, c := field(fv, i), field(tv, i)
+		if f.IsZero() {
+			t.Errorf("field %s is not set by the test", name)
+			continue
+		}
+		switch f.Kind() {
+		case reflect.Func:
+			if f.Pointer() != c.Pointer() {
+				t.Errorf("field %s not copied", name)
+			}
+		case reflect.Slice:
+			if c.Len() != f.Len() {
+				t.Errorf("field %s not copied", name)
+			} else if name == "attached" {
+				// the jobs are funcs, compare the ids
+				if to.attached[0].id != from.attached[0].id {
+					t.Errorf("field %s not copied", name)
+				}
+			} else if !reflect.DeepEqual(f.Interface(), c.Interface()) {
+				t.Errorf("field %s not copied: %v, want %v", name, c, f)
+			}
+		default:
+			if !reflect.DeepEqual(f.Interface(), c.Interface()) {
+				t.Errorf("field %s not copied: %v, want %v", name, c, f)
+			}
+		}
+	}
+	// the mutable state is not shared
+	if to.breaker == from.breaker || to.mailbox == from.mailbox {
+		t.Fatal("state shared with the source task")
+	}
+	to.tags[0] = "z"
+	to.mailbox.runs[0] = time.Time{}
+	if from.tags[0] != "a" || from.mailbox.runs[0] != at {
+		t.Fatal("state shared with the source task")
+	}
+}
+
+func TestMoveTaskKeepsDefinition(t *testing.T) {
+	src := New(time.Second, 8, WithPreciseTasks(1), WithMutexGroup("g", 1, GroupQueue))
+	dst := New(time.Second, 8, WithPreciseTasks(1), WithMutexGroup("g", 1, GroupQueue))
+	src.Start()
+	dst.Start()
+	defer src.Stop()
+	defer dst.Stop()
+	job := func(TaskData) {}
+	if err := src.AddTaskWith(time.Hour, -1, "k", nil, job, Precise(), Critical(), InGroup("g")); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.SkipNextN("k", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.MoveTask("k", dst); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := dst.PreciseTasks(); n != 1 {
+		t.Fatal("precise not admitted by dst", n)
+	}
+	if n, _ := src.PreciseTasks(); n != 0 {
+		t.Fatal("precise still counted by src", n)
+	}
+	var moved task
+	dst.exec(func() {
+		if v, ok := dst.taskRecord.Load("k"); ok {
+			moved.precise, moved.critical, moved.group, moved.skip = v.precise, v.critical, v.group, v.skip
+		}
+	})
+	if !moved.precise || !moved.critical || moved.group != "g" || moved.skip != 2 {
+		t.Fatal(moved.precise, moved.critical, moved.group, moved.skip)
+	}
+
+	// dst does not know the group
+	other := New(time.Second, 8)
+	other.Start()
+	defer other.Stop()
+	if err := dst.MoveTask("k", other); err == nil || other.HasTask("k") || !dst.HasTask("k") {
+		t.Fatal(err)
+	}
+}
+
+func TestMoveTask(t *testing.T) {
+	c1, c2 := newFakeClock(), newFakeClock()
+	src := New(time.Second, 10, WithClock(c1))
+	dst := New(time.Second, 60, WithClock(c2))
+	src.Start()
+	dst.Start()
+	defer src.Stop()
+	defer dst.Stop()
+	var onSrc, onDst int64
+	var which int64 // 0 src, 1 dst
+	tick := func(n int) {
+		for i := 0; i < n; i++ {
+			c1.Tick(time.Second)
+			c2.Tick(time.Second)
+			settle(src)
+			settle(dst)
+		}
+	}
+	src.AddTaskWith(5*time.Second, -1, "k", TaskData{"v": 1}, func(d TaskData) {
+		if atomic.LoadInt64(&which) == 0 {
+			atomic.AddInt64(&onSrc, 1)
+		} else {
+			atomic.AddInt64(&onDst, 1)
+		}
+		if d["v"] != 1 {
+			t.Errorf("data %v", d)
+		}
+	}, Tags("x"))
+	settle(src)
+	tick(2)
+	atomic.StoreInt64(&which, 1)
+	if err := src.MoveTask("k", dst); err != nil {
+		t.Fatal(err)
+	}
+	if src.HasTask("k") || !dst.HasTask("k") || src.Len() != 0 {
+		t.Fatal("task not moved")
+	}
+	if dst.CountByTag("x") != 1 {
+		t.Fatal("tags not moved")
+	}
+	// 3s left, fires one tick late
+	tick(3)
+	if atomic.LoadInt64(&onDst)+atomic.LoadInt64(&onSrc) != 0 {
+		t.Fatalf("%d early runs", onDst)
+	}
+	tick(1)
+	if atomic.LoadInt64(&onDst) != 1 || atomic.LoadInt64(&onSrc) != 0 {
+		t.Fatalf("%d runs on the source, %d on the destination", onSrc, onDst)
+	}
+	tick(5)
+	if atomic.LoadInt64(&onDst) != 2 {
+		t.Fatalf("%d runs on the destination", onDst)
+	}
+
+	// the key exists on the destination, the task stays
+	src.AddTask(time.Second, -1, "k", nil, func(TaskData) {})
+	if err := src.MoveTask("k", dst); err != ErrDuplicateKey || !src.HasTask("k") {
+		t.Fatal(err)
+	}
+	if err := src.MoveTask("none", dst); err != ErrTaskNotFound {
+		t.Fatal(err)
+	}
+	if err := src.MoveTask("k", src); err != ErrInvalidParams {
+		t.Fatal(err)
+	}
+}
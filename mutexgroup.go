@@ -0,0 +1,127 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// GroupPolicy decide what happens to a run of a mutex group whose slots are all taken
+type GroupPolicy int
+
+const (
+	// GroupQueue run it once a slot is free, in firing order
+	GroupQueue GroupPolicy = iota
+	// GroupSkip drop it, the run still counts towards times
+	GroupSkip
+)
+
+// WithMutexGroup declare a mutex group, at most width jobs of the tasks of the group run at a time
+// whatever their keys, see InGroup. The slot is taken when a run is dispatched and released once its
+// job returned, panicked or was skipped. A queued run starts on its own goroutine.
+func WithMutexGroup(name string, width int, policy GroupPolicy) Option {
+	return func(tw *TimeWheel) {
+		if name == "" || width <= 0 {
+			return
+		}
+		if tw.groups == nil {
+			tw.groups = make(map[string]*mutexGroup)
+		}
+		tw.groups[name] = &mutexGroup{width: width, policy: policy}
+	}
+}
+
+// InGroup add the task to the mutex group declared by WithMutexGroup, adding the task fails if the group
+// is unknown
+func InGroup(name string) TaskOption {
+	return func(t *task) {
+		t.group = name
+	}
+}
+
+// GroupState state of a mutex group
+type GroupState struct {
+	Width   int
+	Holders []interface{} // keys of the tasks whose runs hold a slot
+	Queued  int           // runs waiting for a slot
+	Skipped int64         // runs dropped under GroupSkip
+}
+
+// GroupState get the state of the mutex group, false if the group is unknown
+func (tw *TimeWheel) GroupState(name string) (GroupState, bool) {
+	g, ok := tw.groups[name]
+	if !ok {
+		return GroupState{}, false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	st := GroupState{Width: g.width, Queued: len(g.queue), Skipped: atomic.LoadInt64(&g.skipped)}
+	for _, r := range g.holders {
+		st.Holders = append(st.Holders, r.task.key)
+	}
+	return st, true
+}
+
+// semaphore shared by the tasks of a group
+type mutexGroup struct {
+	width   int
+	policy  GroupPolicy
+	mu      sync.Mutex
+	holders []*jobRun
+	queue   []*jobRun
+	skipped int64 // accessed atomically
+}
+
+// reject a task of an unknown group
+func (tw *TimeWheel) checkGroup(t *task) error {
+	if t.group != "" && tw.groups[t.group] == nil {
+		return fmt.Errorf("%w, unknown mutex group %q", ErrInvalidParams, t.group)
+	}
+	return nil
+}
+
+// take a slot of the group of the run, report whether the run goes on now. A skipped run goes on dropped.
+func (g *mutexGroup) acquire(r *jobRun) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.holders) < g.width {
+		r.group = g
+		g.holders = append(g.holders, r)
+		return true
+	}
+	if g.policy == GroupSkip {
+		atomic.AddInt64(&g.skipped, 1)
+		r.dropped = true
+		return true
+	}
+	g.queue = append(g.queue, r)
+	return false
+}
+
+// release the slot of the run, the first queued run takes it and is returned
+func (g *mutexGroup) release(r *jobRun) *jobRun {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i, v := range g.holders {
+		if v == r {
+			g.holders = append(g.holders[:i], g.holders[i+1:]...)
+			break
+		}
+	}
+	if len(g.queue) == 0 {
+		return nil
+	}
+	next := g.queue[0]
+	g.queue[0] = nil
+	g.queue = g.queue[1:]
+	next.group = g
+	g.holders = append(g.holders, next)
+	return next
+}
+
+// release the slot of the group held by the run, called once its job returned
+func (tw *TimeWheel) releaseGroup(r *jobRun) {
+	if next := r.group.release(r); next != nil {
+		go tw.runJob(next)
+	}
+}
@@ -0,0 +1,80 @@
+package timewheel
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMutexGroup(t *testing.T) {
+	tw := New(5*time.Millisecond, 16, WithMutexGroup("db", 1, GroupQueue), WithMutexGroup("skip", 1, GroupSkip))
+	tw.Start()
+	defer tw.Stop()
+	if err := tw.AddTaskWith(time.Second, 1, "x", nil, func(TaskData) {}, InGroup("nope")); err == nil {
+		t.Fatal("unknown group")
+	}
+	var mu sync.Mutex
+	type span struct {
+		k    string
+		b, e time.Time
+	}
+	var spans []span
+	var cur, maxCur, outsideOverlap int64
+	slow := func(k string) Job {
+		return func(TaskData) {
+			if n := atomic.AddInt64(&cur, 1); n > atomic.LoadInt64(&maxCur) {
+				atomic.StoreInt64(&maxCur, n)
+			}
+			b := time.Now()
+			time.Sleep(30 * time.Millisecond)
+			atomic.AddInt64(&cur, -1)
+			mu.Lock()
+			spans = append(spans, span{k, b, time.Now()})
+			mu.Unlock()
+		}
+	}
+	var outside int64
+	tw.AddTaskWith(10*time.Millisecond, 4, "a", nil, slow("a"), InGroup("db"))
+	tw.AddTaskWith(10*time.Millisecond, 4, "b", nil, slow("b"), InGroup("db"))
+	tw.AddTask(10*time.Millisecond, -1, "out", nil, func(TaskData) {
+		if atomic.LoadInt64(&cur) > 0 {
+			atomic.AddInt64(&outsideOverlap, 1)
+		}
+		atomic.AddInt64(&outside, 1)
+	})
+	time.Sleep(60 * time.Millisecond)
+	// one of them runs, the other waits for the group
+	st, _ := tw.GroupState("db")
+	if len(st.Holders) != 1 || st.Queued == 0 {
+		t.Fatal(st)
+	}
+	time.Sleep(400 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(spans) != 8 || atomic.LoadInt64(&maxCur) != 1 {
+		t.Fatal(len(spans), maxCur)
+	}
+	// strictly serialized
+	for i := 1; i < len(spans); i++ {
+		if spans[i].b.Before(spans[i-1].e) {
+			t.Fatal("overlap", i)
+		}
+	}
+	if atomic.LoadInt64(&outsideOverlap) == 0 {
+		t.Fatal("outside task serialized")
+	}
+	st, _ = tw.GroupState("db")
+	if len(st.Holders) != 0 || st.Queued != 0 {
+		t.Fatal(st)
+	}
+
+	// skip policy and a panicking holder
+	var ran int64
+	tw.AddTaskWith(10*time.Millisecond, 3, "p", nil, func(TaskData) { time.Sleep(25 * time.Millisecond); atomic.AddInt64(&ran, 1); panic("x") }, InGroup("skip"))
+	time.Sleep(200 * time.Millisecond)
+	st, _ = tw.GroupState("skip")
+	if st.Skipped == 0 || len(st.Holders) != 0 || atomic.LoadInt64(&ran) == 0 {
+		t.Fatal(st, ran)
+	}
+}
@@ -0,0 +1,94 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// NSKey task key scoped to a namespace, the tasks registered under an NSKey can be handled as a group
+// with RemoveNamespace, CountNamespace and NamespaceKeys, and are limited by WithNamespaceQuota.
+// Equal keys of different namespaces name different tasks.
+type NSKey struct {
+	NS  string
+	Key interface{}
+}
+
+// quota of a namespace, accessed atomically
+type nsQuota struct {
+	limit    int64
+	admitted int64
+}
+
+// WithNamespaceQuota limit the number of tasks held under the keys of the namespace ns, the tasks waiting
+// in the add buffer included. The adds beyond the quota return ErrNamespaceQuota.
+func WithNamespaceQuota(ns string, n int) Option {
+	return func(tw *TimeWheel) {
+		if n <= 0 {
+			return
+		}
+		if tw.nsQuotas == nil {
+			tw.nsQuotas = make(map[string]*nsQuota)
+		}
+		tw.nsQuotas[ns] = &nsQuota{limit: int64(n)}
+	}
+}
+
+// AddTaskNS add new task like AddTask under the key NSKey{ns, key}
+func (tw *TimeWheel) AddTaskNS(ns string, interval time.Duration, times int, key interface{}, data TaskData, job Job) error {
+	if key == nil {
+		return ErrInvalidKey
+	}
+	return tw.AddTask(interval, times, NSKey{NS: ns, Key: key}, data, job)
+}
+
+// RemoveNamespace remove the tasks of the namespace, return how many were removed
+func (tw *TimeWheel) RemoveNamespace(ns string) int {
+	n := 0
+	for _, key := range tw.tagIndex.nsKeys(ns) {
+		if tw.RemoveTask(key) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// CountNamespace get the number of registered tasks of the namespace
+func (tw *TimeWheel) CountNamespace(ns string) int {
+	return tw.tagIndex.nsCount(ns)
+}
+
+// NamespaceKeys get the keys of the registered tasks of the namespace, in no particular order
+func (tw *TimeWheel) NamespaceKeys(ns string) []interface{} {
+	return tw.tagIndex.nsKeys(ns)
+}
+
+// count a new task under the key against the quota of its namespace, report whether it is admitted
+func (tw *TimeWheel) admitNS(key interface{}) bool {
+	q := tw.quotaOf(key)
+	if q == nil {
+		return true
+	}
+	if atomic.AddInt64(&q.admitted, 1) <= q.limit {
+		return true
+	}
+	atomic.AddInt64(&q.admitted, -1)
+	return false
+}
+
+// undo the count of a task under the key against the quota of its namespace
+func (tw *TimeWheel) unadmitNS(key interface{}) {
+	if q := tw.quotaOf(key); q != nil {
+		atomic.AddInt64(&q.admitted, -1)
+	}
+}
+
+func (tw *TimeWheel) quotaOf(key interface{}) *nsQuota {
+	if tw.nsQuotas == nil {
+		return nil
+	}
+	k, ok := key.(NSKey)
+	if !ok {
+		return nil
+	}
+	return tw.nsQuotas[k.NS]
+}
@@ -0,0 +1,74 @@
+package timewheel
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNamespaces(t *testing.T) {
+	tw := New(time.Millisecond, 32, WithNamespaceQuota("a", 3), WithNamespaceQuota("b", 5))
+	tw.Start()
+	defer tw.Stop()
+	var runs int64
+	job := func(TaskData) { atomic.AddInt64(&runs, 1) }
+	for i := 0; i < 3; i++ {
+		if err := tw.AddTaskNS("a", 2*time.Millisecond, -1, i, nil, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.AddTaskNS("a", time.Millisecond, 1, 9, nil, job); !errors.Is(err, ErrNamespaceQuota) {
+		t.Fatalf("a over its quota: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := tw.AddTaskNS("b", 3*time.Millisecond, 2, i, nil, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.AddTaskNS("b", time.Millisecond, 1, 9, nil, job); !errors.Is(err, ErrNamespaceQuota) {
+		t.Fatalf("b over its quota: %v", err)
+	}
+	// the same key in another namespace and outside of any
+	if err := tw.AddTaskNS("c", time.Hour, 1, 0, nil, job); err != nil {
+		t.Fatal(err)
+	}
+	tw.AddTask(time.Hour, 1, 0, nil, job)
+	settle(tw)
+	if tw.CountNamespace("a") != 3 || tw.CountNamespace("b") != 5 || tw.Len() != 10 {
+		t.Fatalf("a %d, b %d, %d tasks", tw.CountNamespace("a"), tw.CountNamespace("b"), tw.Len())
+	}
+
+	// the exhausted tasks leave the namespace and free its quota
+	for i := 0; tw.CountNamespace("b") != 0; i++ {
+		if i == 1000 {
+			t.Fatalf("b still counts %d tasks", tw.CountNamespace("b"))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if keys := tw.NamespaceKeys("b"); len(keys) != 0 {
+		t.Fatalf("b keys %v", keys)
+	}
+	for i := 0; i < 5; i++ {
+		if err := tw.AddTaskNS("b", time.Hour, 1, i, nil, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	settle(tw)
+
+	// a is ticking while it is removed
+	if n := tw.RemoveNamespace("a"); n != 3 {
+		t.Fatalf("%d tasks of a removed", n)
+	}
+	if tw.CountNamespace("a") != 0 || tw.Len() != 7 {
+		t.Fatalf("a %d, %d tasks", tw.CountNamespace("a"), tw.Len())
+	}
+	for i := 0; i < 3; i++ {
+		if err := tw.AddTaskNS("a", time.Hour, 1, i, nil, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !tw.HasTask(NSKey{"c", 0}) || !tw.HasTask(0) {
+		t.Fatal("the key 0 of c or outside of the namespaces is gone")
+	}
+}
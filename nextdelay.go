@@ -0,0 +1,60 @@
+package timewheel
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// JobNext callback function choosing the delay of the next run, ok false ends the task
+type JobNext func(data TaskData) (next time.Duration, ok bool)
+
+// AddTaskNext add new task running until the job returns false, the delay returned by the job moves
+// the next run that far from its return. A delay AddTask would reject is reported to Errors as
+// ErrorNextDelay and the next run keeps the interval.
+func (tw *TimeWheel) AddTaskNext(interval time.Duration, key interface{}, data TaskData, job JobNext, opts ...TaskOption) error {
+	if job == nil {
+		return ErrInvalidParams
+	}
+	return tw.addTaskWith(interval, -1, key, data, wrapJobNext(job), opts)
+}
+
+// adapt the job, its result is handed to callJob through the context
+func wrapJobNext(job JobNext) JobCtx {
+	return func(ctx context.Context, data TaskData) {
+		next, ok := job(data)
+		if v, found := ctx.Value(jobErrKey{}).(*runValue); found {
+			v.next, v.stop, v.chosen = next, !ok, true
+		}
+	}
+}
+
+// apply the result of a job added with AddTaskNext, called on the job goroutine once it returned
+func (tw *TimeWheel) nextDone(r *jobRun, v *runValue) {
+	task := r.task
+	if !v.chosen || r.final {
+		return
+	}
+	var apply func()
+	if v.stop {
+		// the run becomes the final one, runJob completes the task
+		r.final = true
+		apply = func() {
+			tw.unregister(task)
+		}
+	} else {
+		if err := tw.checkInterval(v.next); err != nil {
+			tw.reportError(ErrorNextDelay, task.key, err)
+			return
+		}
+		apply = func() {
+			// the task may be removed or run again meanwhile
+			if t, ok := tw.taskRecord.Load(task.key); !ok || t != task || task.times == 0 ||
+				atomic.LoadInt64(&task.stats.lastFire) != r.exec.Fired.UnixNano() {
+				return
+			}
+			tw.rescheduleTask(task, v.next)
+		}
+	}
+	tw.execLater(task, apply)
+}
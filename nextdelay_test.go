@@ -0,0 +1,98 @@
+package timewheel
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAddTaskNext(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Millisecond, 64, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var mu sync.Mutex
+	var at []time.Time
+	delays := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}
+	done := make(chan struct{}, 1)
+	err := tw.AddTaskNext(5*time.Millisecond, "k", nil, func(TaskData) (time.Duration, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		at = append(at, c.Now())
+		if n := len(at); n <= len(delays) {
+			return delays[n-1], true
+		}
+		return 0, false
+	}, OnExhausted(func(interface{}, TaskData) { done <- struct{}{} }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	settle(tw)
+	for i := 0; i < 80; i++ {
+		c.Tick(time.Millisecond)
+		settle(tw)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("not exhausted")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(at) != 3 {
+		t.Fatal(at)
+	}
+	if d := at[1].Sub(at[0]); d < 10*time.Millisecond || d > 12*time.Millisecond {
+		t.Fatal(d)
+	}
+	if d := at[2].Sub(at[1]); d < 20*time.Millisecond || d > 22*time.Millisecond {
+		t.Fatal(d)
+	}
+	if _, err := tw.TaskStats("k"); err != ErrTaskNotFound {
+		t.Fatal(err)
+	}
+	errs := tw.Errors()
+	tw.AddTaskNext(5*time.Millisecond, "bad", nil, func(TaskData) (time.Duration, bool) { return -1, true })
+	for i := 0; i < 8; i++ {
+		c.Tick(time.Millisecond)
+		settle(tw)
+	}
+	select {
+	case e := <-errs:
+		if e.Kind != ErrorNextDelay || e.Key != "bad" {
+			t.Fatal(e)
+		}
+	default:
+		t.Fatal("no error")
+	}
+}
+
+func TestAddTaskNextBlockingWorkers(t *testing.T) {
+	tw := New(10*time.Millisecond, 16, WithWorkers(1, 0, Block))
+	tw.Start()
+	defer tw.Stop()
+	var runs int64
+	for i := 0; i < 4; i++ {
+		tw.AddTaskNext(10*time.Millisecond, i, nil, func(TaskData) (time.Duration, bool) {
+			atomic.AddInt64(&runs, 1)
+			return 20 * time.Millisecond, true
+		})
+	}
+	time.Sleep(300 * time.Millisecond)
+	if n := atomic.LoadInt64(&runs); n < 8 {
+		t.Fatal(n)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- tw.AddTask(time.Second, 1, "late", nil, func(TaskData) {})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("wheel blocked")
+	}
+}
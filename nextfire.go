@@ -0,0 +1,77 @@
+package timewheel
+
+import "time"
+
+// AddTaskScheduled add new task like AddTask and get the estimated time of its first run, the one Upcoming
+// reports right after the add. The task is added on the wheel goroutine, so the call waits for it.
+func (tw *TimeWheel) AddTaskScheduled(interval time.Duration, times int, key interface{}, data TaskData, job Job) (time.Time, error) {
+	if job == nil {
+		return time.Time{}, ErrInvalidParams
+	}
+	task, err := tw.newTask(interval, times, key, data, wrapJob(job))
+	if err != nil {
+		return time.Time{}, err
+	}
+	tw.startOnAdd()
+	if tw.isStopped() {
+		tw.dropTask(task)
+		return time.Time{}, ErrWheelStopped
+	}
+	var at time.Time
+	execErr := tw.exec(func() {
+		tw.taskAccepted()
+		tw.addTask(task)
+		// a duplicate is merged into the registered task, see WithDuplicatePolicy
+		t, ok := tw.taskRecord.Load(key)
+		if !ok {
+			return
+		}
+		if t != task && tw.dupPolicy == DuplicateError {
+			err = ErrDuplicateKey
+			return
+		}
+		at = tw.estimateTask(t)
+	})
+	if execErr != nil {
+		tw.dropTask(task)
+		return time.Time{}, execErr
+	}
+	return at, err
+}
+
+// NextFire get the estimated time of the next run of the task, the time of the tick expected to run it.
+// A task waiting for its prerequisite has no estimate, the zero time is returned.
+func (tw *TimeWheel) NextFire(key interface{}) (time.Time, error) {
+	if key == nil {
+		return time.Time{}, ErrInvalidKey
+	}
+	if !keyComparable(key) {
+		return time.Time{}, ErrKeyNotComparable
+	}
+	var at time.Time
+	err := ErrTaskNotFound
+	execErr := tw.exec(func() {
+		if t, ok := tw.taskRecord.Load(key); ok && t.times != 0 {
+			at, err = tw.estimateTask(t), nil
+		}
+	})
+	if execErr != nil {
+		return time.Time{}, execErr
+	}
+	return at, err
+}
+
+// estimated fire time of the registered task, only called on the wheel goroutine
+func (tw *TimeWheel) estimateTask(t *task) time.Time {
+	if t.dep != nil {
+		return time.Time{}
+	}
+	for _, queue := range [][]*task{tw.deferred, tw.blackedOut, tw.carry} {
+		for _, w := range queue {
+			if w == t {
+				return tw.estimateFire(0)
+			}
+		}
+	}
+	return tw.estimateFire(tw.backend.ticksUntil(t))
+}
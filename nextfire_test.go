@@ -0,0 +1,57 @@
+package timewheel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAddTaskScheduled(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 16, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	c.Tick(time.Second)
+	for _, d := range []time.Duration{time.Second, 7 * time.Second, 40 * time.Second} {
+		fired := make(chan time.Time, 1)
+		at, err := tw.AddTaskScheduled(d, 1, d, nil, func(TaskData) { fired <- c.Now() })
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next, err := tw.NextFire(d); err != nil || !next.Equal(at) {
+			t.Fatalf("%v: next fire %v, added with %v: %v", d, next, at, err)
+		}
+		// the run is on the tick of the estimate
+		for i := 0; i < 60 && len(fired) == 0; i++ {
+			c.Tick(time.Second)
+			settle(tw)
+		}
+		select {
+		case got := <-fired:
+			if !got.Equal(at) {
+				t.Fatalf("%v: fired at %v, estimated %v", d, got, at)
+			}
+		default:
+			t.Fatalf("%v: never fired", d)
+		}
+	}
+	if _, err := tw.NextFire("none"); err != ErrTaskNotFound {
+		t.Fatalf("next fire of a missing key: %v", err)
+	}
+}
+
+func TestAddTaskScheduledRealClock(t *testing.T) {
+	const tick = 10 * time.Millisecond
+	tw := New(tick, 16)
+	tw.Start()
+	defer tw.Stop()
+	for _, d := range []time.Duration{15 * time.Millisecond, 60 * time.Millisecond, 250 * time.Millisecond} {
+		fired := make(chan time.Time, 1)
+		at, err := tw.AddTaskScheduled(d, 1, d, nil, func(TaskData) { fired <- time.Now() })
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := (<-fired).Sub(at); diff < -tick || diff > tick {
+			t.Fatalf("%v: fired %v off the estimate", d, diff)
+		}
+	}
+}
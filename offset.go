@@ -0,0 +1,42 @@
+package timewheel
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+// WithRandomStartOffset start the wheel at a random slot and tick at a random phase of the interval,
+// so instances sharing a configuration do not fire together. The delays of the tasks are kept,
+// only the absolute phase moves. WithAlignToWallClock takes precedence for the phase.
+func WithRandomStartOffset() Option {
+	return func(tw *TimeWheel) {
+		tw.randomStart = true
+	}
+}
+
+// WithRandSource set the source of the random start offset and the jitter, default is the global source
+func WithRandSource(src rand.Source) Option {
+	return func(tw *TimeWheel) {
+		if src != nil {
+			tw.rand = rand.New(src)
+		}
+	}
+}
+
+// random number in [0, n)
+func (tw *TimeWheel) int63n(n int64) int64 {
+	if tw.rand != nil {
+		return tw.rand.Int63n(n)
+	}
+	return rand.Int63n(n)
+}
+
+// move the wheel to a random slot and get the delay of the first tick, called by Start
+func (tw *TimeWheel) randomPhase() time.Duration {
+	if wb, ok := wheelOf(tw.backend); ok {
+		wb.currentPos = int(tw.int63n(int64(len(wb.slots))))
+		atomic.StoreInt64(&tw.position, int64(wb.currentPos))
+	}
+	return time.Duration(tw.int63n(int64(tw.interval))) + 1
+}
@@ -0,0 +1,70 @@
+package timewheel
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// delay before the first tick and the start slot of a wheel started with WithRandomStartOffset
+func startOffset(seed int64) (time.Duration, int) {
+	c := newFakeClock()
+	tw := New(time.Second, 60, WithClock(c), WithRandomStartOffset(), WithRandSource(rand.NewSource(seed)))
+	tw.Start()
+	defer tw.Stop()
+	return tw.lastTick.Add(time.Second).Sub(c.Now()), tw.backend.position()
+}
+
+func TestRandomStartOffset(t *testing.T) {
+	phase, pos := startOffset(42)
+	if phase <= 0 || phase > time.Second || (phase == time.Second && pos == 0) {
+		t.Fatalf("no offset: phase %v, slot %d", phase, pos)
+	}
+	if p, s := startOffset(42); p != phase || s != pos {
+		t.Fatalf("seed 42 gave %v/%d then %v/%d", phase, pos, p, s)
+	}
+
+	// the delay of a task is kept, only the phase moves
+	c := newFakeClock()
+	tw := New(time.Second, 60, WithClock(c), WithRandomStartOffset(), WithRandSource(rand.NewSource(42)))
+	tw.Start()
+	defer tw.Stop()
+	added := c.Now()
+	fired := make(chan time.Time, 1)
+	tw.AddTask(3*time.Second, 1, "k", nil, func(TaskData) { fired <- c.Now() })
+	settle(tw)
+	c.Tick(phase)
+	for i := 0; i < 3; i++ {
+		settle(tw)
+		c.Tick(time.Second)
+	}
+	select {
+	case at := <-fired:
+		if d := at.Sub(added); d < 3*time.Second || d > 4*time.Second {
+			t.Fatalf("fired %v after the add", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("never fired")
+	}
+}
+
+func TestRandomStartOffsetSpread(t *testing.T) {
+	phases := make([]int, 10)
+	slots := make([]int, 6)
+	for seed := int64(0); seed < 500; seed++ {
+		phase, pos := startOffset(seed)
+		phases[int(phase*10/time.Second)%10]++
+		slots[pos/10]++
+	}
+	// 50 expected in every phase bucket and 83 in every slot bucket
+	for _, n := range phases {
+		if n < 25 {
+			t.Fatalf("phases %v", phases)
+		}
+	}
+	for _, n := range slots {
+		if n < 40 {
+			t.Fatalf("slots %v", slots)
+		}
+	}
+}
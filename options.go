@@ -0,0 +1,91 @@
+package timewheel
+
+// Option configure the time wheel when calling New
+type Option func(*TimeWheel)
+
+// CatchUpPolicy decide how the ticks missed during a suspension are handled
+type CatchUpPolicy int
+
+const (
+	// FireOnePerTask run every task due in the gap at most once
+	FireOnePerTask CatchUpPolicy = iota
+	// FireAll run every occurrence due in the gap
+	FireAll
+	// Skip drop every occurrence due in the gap
+	Skip
+)
+
+// WithClock set the clock driving the wheel, default is the system clock
+func WithClock(c Clock) Option {
+	return func(tw *TimeWheel) {
+		if c != nil {
+			tw.clock = c
+		}
+	}
+}
+
+// WithTimeScale run the wheel f times faster than the wall clock, a wheel ticking every second with f 100
+// ticks every 10ms. The intervals, the run times and every time reported by the wheel stay on the scaled
+// timeline, which starts at the time New is called. Meant for tests compressing long schedules, it can not
+// be combined with WithAlignToWallClock: New returns nil then.
+func WithTimeScale(f float64) Option {
+	return func(tw *TimeWheel) {
+		if f > 0 {
+			tw.timeScale = f
+		}
+	}
+}
+
+// WithStableOrder hand the tasks due at the same tick with the same priority to the jobs in the order the
+// tasks were added, whatever the rotations and re-insertions. Default is the order of the slot, which may
+// change between the runs.
+func WithStableOrder() Option {
+	return func(tw *TimeWheel) {
+		tw.stableOrder = true
+	}
+}
+
+// WithCatchUpPolicy set the policy for ticks missed while the process was suspended,
+// default is FireOnePerTask
+func WithCatchUpPolicy(p CatchUpPolicy) Option {
+	return func(tw *TimeWheel) {
+		tw.catchUpPolicy = p
+	}
+}
+
+// WithAddBuffer set the buffer size of the add task channel, default is unbuffered.
+// A control call follows the buffered adds its caller made before it.
+func WithAddBuffer(n int) Option {
+	return func(tw *TimeWheel) {
+		if n > 0 {
+			tw.addBuffer = n
+		}
+	}
+}
+
+// WithSharedData keep the TaskData given to AddTask and UpdateTask by reference instead of copying it,
+// the caller must not write the map while the task is registered
+func WithSharedData() Option {
+	return func(tw *TimeWheel) {
+		tw.shareData = true
+	}
+}
+
+// WithPowerOfTwoSlots round the slot number up to a power of two, so the slot of a task
+// is found with a mask instead of a division
+func WithPowerOfTwoSlots() Option {
+	return func(tw *TimeWheel) {
+		tw.pow2Slots = true
+	}
+}
+
+// WithAlignToWallClock tick on the multiples of the interval on the wall clock, Start waits
+// for the next boundary before the first tick
+func WithAlignToWallClock() Option {
+	return func(tw *TimeWheel) {
+		tw.alignTicks = true
+	}
+}
+
+// TaskOption configure a task when calling AddTaskWith
+type TaskOption func(*task)
@@ -0,0 +1,48 @@
+// Package otelwheel trace the time wheel job executions with OpenTelemetry.
+//
+//	tw := timewheel.New(time.Second, 60, timewheel.WithInterceptor(otelwheel.Interceptor(tracer)))
+//
+// Jobs added with AddTaskCtx receive the span context, so the calls made by the job join the trace.
+package otelwheel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nosixtools/timewheel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// attribute keys of the execution span
+const (
+	KeyAttribute       = attribute.Key("timewheel.task.key")
+	ScheduledAttribute = attribute.Key("timewheel.scheduled_time")
+	FiredAttribute     = attribute.Key("timewheel.fired_time")
+	LatenessAttribute  = attribute.Key("timewheel.lateness_ms")
+)
+
+// Interceptor start a span named after the task key around each job execution
+func Interceptor(tracer trace.Tracer) timewheel.Interceptor {
+	return func(ctx context.Context, exec timewheel.Execution, run func(ctx context.Context) error) error {
+		name := fmt.Sprint(exec.Key)
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithTimestamp(exec.Fired),
+			trace.WithAttributes(
+				KeyAttribute.String(name),
+				ScheduledAttribute.String(exec.Scheduled.Format(time.RFC3339Nano)),
+				FiredAttribute.String(exec.Fired.Format(time.RFC3339Nano)),
+				LatenessAttribute.Int64(exec.Fired.Sub(exec.Scheduled).Milliseconds()),
+			))
+		defer span.End()
+
+		err := run(ctx)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	}
+}
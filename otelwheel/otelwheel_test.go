@@ -0,0 +1,87 @@
+package otelwheel
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nosixtools/timewheel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// get the value of the attribute of the span
+func attr(span tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestInterceptor(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	defer tp.Shutdown(context.Background())
+	tw := timewheel.New(10*time.Millisecond, 10, timewheel.WithInterceptor(Interceptor(tp.Tracer("test"))))
+	tw.Start()
+	defer tw.Stop()
+
+	var joined int32
+	tw.AddTaskCtx(10*time.Millisecond, 3, "ok", nil, func(ctx context.Context, _ timewheel.TaskData) {
+		if trace.SpanContextFromContext(ctx).IsValid() {
+			atomic.AddInt32(&joined, 1)
+		}
+	})
+	tw.AddTaskErr(10*time.Millisecond, 1, "fail", nil, func(context.Context, timewheel.TaskData) error {
+		return errors.New("boom")
+	})
+	tw.AddTask(10*time.Millisecond, 1, "panic", nil, func(timewheel.TaskData) { panic("boom") })
+	time.Sleep(150 * time.Millisecond)
+
+	spans := exp.GetSpans()
+	byName := make(map[string][]tracetest.SpanStub)
+	for _, span := range spans {
+		byName[span.Name] = append(byName[span.Name], span)
+	}
+	// one span per firing
+	if len(spans) != 5 || len(byName["ok"]) != 3 || len(byName["fail"]) != 1 || len(byName["panic"]) != 1 {
+		t.Fatal(len(spans), len(byName["ok"]))
+	}
+	if atomic.LoadInt32(&joined) != 3 {
+		t.Fatal("the job context does not carry the span", joined)
+	}
+	for _, span := range byName["ok"] {
+		if v, ok := attr(span, KeyAttribute); !ok || v.AsString() != "ok" {
+			t.Fatal("key", v)
+		}
+		scheduled, _ := attr(span, ScheduledAttribute)
+		fired, _ := attr(span, FiredAttribute)
+		s, err := time.Parse(time.RFC3339Nano, scheduled.AsString())
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := time.Parse(time.RFC3339Nano, fired.AsString())
+		if err != nil || f.Before(s) || !span.StartTime.Equal(f) {
+			t.Fatal("fired", s, f, span.StartTime, err)
+		}
+		if late, ok := attr(span, LatenessAttribute); !ok || late.AsInt64() != f.Sub(s).Milliseconds() {
+			t.Fatal("lateness", late)
+		}
+		if span.Status.Code != codes.Unset {
+			t.Fatal("status", span.Status)
+		}
+	}
+	for _, name := range []string{"fail", "panic"} {
+		span := byName[name][0]
+		if span.Status.Code != codes.Error || len(span.Events) == 0 {
+			t.Fatal(name, span.Status, span.Events)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ResumePolicy decide what happens to the runs missed while a task was paused
+type ResumePolicy int
+
+const (
+	// ResumeSkip drop the missed runs, the task goes on with its next natural run
+	ResumeSkip ResumePolicy = iota
+	// ResumeReplayOne run once right away if any run was missed
+	ResumeReplayOne
+	// ResumeReplayAll run every missed run right away, the runs count towards times
+	ResumeReplayAll
+)
+
+// OnResume set the policy applied by ResumeTask to the missed runs, default is ResumeSkip
+func OnResume(p ResumePolicy) TaskOption {
+	return func(t *task) {
+		t.resume = p
+	}
+}
+
+// PauseTask pause the task, it keeps its place in the wheel but its runs are skipped
+// without consuming its times until ResumeTask is called
+func (tw *TimeWheel) PauseTask(key interface{}) error {
+	return tw.setPaused(key, true)
+}
+
+// ResumeTask resume the paused task, the missed runs are handled by its resume policy
+func (tw *TimeWheel) ResumeTask(key interface{}) error {
+	return tw.setPaused(key, false)
+}
+
+func (tw *TimeWheel) setPaused(key interface{}, paused bool) error {
+	if key == nil {
+		return ErrInvalidKey
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	var err error
+	execErr := tw.exec(func() {
+		task, ok := tw.taskRecord.Load(key)
+		if !ok {
+			err = ErrTaskNotFound
+			return
+		}
+		if !paused {
+			if atomic.CompareAndSwapInt32(&task.paused, 1, 0) {
+				tw.replayMissed(task)
+			}
+			return
+		}
+		if atomic.CompareAndSwapInt32(&task.paused, 0, 1) {
+			task.missed = 0
+		}
+	})
+	if execErr != nil {
+		return execErr
+	}
+	return err
+}
+
+// report whether the task is paused
+func (t *task) isPaused() bool {
+	return atomic.LoadInt32(&t.paused) == 1
+}
+
+// dispatch the runs missed while the task was paused according to its policy
+func (tw *TimeWheel) replayMissed(task *task) {
+	missed := task.missed
+	task.missed = 0
+	tw.replay(task, missed, task.resume)
+}
+
+// dispatch the missed runs of the task right away according to the policy
+func (tw *TimeWheel) replay(task *task, missed int, policy ResumePolicy) {
+	switch policy {
+	case ResumeSkip:
+		return
+	case ResumeReplayOne:
+		if missed > 1 {
+			missed = 1
+		}
+	}
+	at := make([]time.Time, missed)
+	for i := range at {
+		at[i] = task.next.Add(-time.Duration(missed-i) * task.interval)
+	}
+	tw.replayAt(task, at)
+}
+
+// dispatch the runs of the task scheduled at the given times right away, the runs count towards times
+func (tw *TimeWheel) replayAt(task *task, at []time.Time) {
+	for _, scheduled := range at {
+		if task.times == 1 {
+			// the final run, the task leaves the wheel
+			tw.fire(task, scheduled, tw.persistRun(task))
+			tw.unregister(task)
+			return
+		}
+		tw.fire(task, scheduled, nil)
+		if task.times > 0 {
+			task.times--
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// wait until n reaches want, fail on timeout
+func waitCount(t *testing.T, n *int64, want int64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt64(n) < want && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if got := atomic.LoadInt64(n); got != want {
+		t.Fatalf("%d runs, want %d", got, want)
+	}
+}
+
+// times left to the task, -2 if it is not registered
+func taskTimes(tw *TimeWheel, key interface{}) int {
+	times := -2
+	tw.Range(func(k interface{}, info TaskInfo) bool {
+		if k == key {
+			times = info.Times
+			return false
+		}
+		return true
+	})
+	return times
+}
+
+func TestResumePolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		policy ResumePolicy
+		runs   int64 // after the resume
+		times  int   // left after the resume
+	}{
+		{"skip", ResumeSkip, 1, 9},
+		{"replay one", ResumeReplayOne, 2, 8},
+		{"replay all", ResumeReplayAll, 6, 4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newFakeClock()
+			var n int64
+			tw := New(time.Second, 10, WithClock(c))
+			tw.Start()
+			defer tw.Stop()
+			tw.AddTaskWith(time.Second, 10, "k", nil, func(TaskData) { atomic.AddInt64(&n, 1) }, OnResume(tc.policy))
+			settle(tw)
+			c.Tick(time.Second)
+			c.Tick(time.Second)
+			settle(tw)
+			waitCount(t, &n, 1)
+			// paused over 5 intervals
+			tw.PauseTask("k")
+			for i := 0; i < 5; i++ {
+				c.Tick(time.Second)
+			}
+			settle(tw)
+			waitCount(t, &n, 1)
+			tw.ResumeTask("k")
+			settle(tw)
+			waitCount(t, &n, tc.runs)
+			if times := taskTimes(tw, "k"); times != tc.times {
+				t.Fatalf("times %d, want %d", times, tc.times)
+			}
+			// the task goes on with its natural runs
+			c.Tick(time.Second)
+			settle(tw)
+			waitCount(t, &n, tc.runs+1)
+		})
+	}
+}
+
+func TestResumeReplayAllExhausts(t *testing.T) {
+	c := newFakeClock()
+	var n int64
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTaskWith(time.Second, 3, "k", nil, func(TaskData) { atomic.AddInt64(&n, 1) }, OnResume(ResumeReplayAll))
+	settle(tw)
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	tw.PauseTask("k")
+	for i := 0; i < 5; i++ {
+		c.Tick(time.Second)
+	}
+	tw.ResumeTask("k")
+	settle(tw)
+	// the replayed runs stop at times, the task leaves the wheel
+	waitCount(t, &n, 3)
+	if tw.HasTask("k") {
+		t.Fatal("exhausted task still registered")
+	}
+	if err := tw.ResumeTask("k"); err != ErrTaskNotFound {
+		t.Fatalf("resume after exhaustion: %v", err)
+	}
+}
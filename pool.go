@@ -0,0 +1,192 @@
+package timewheel
+
+import (
+	"errors"
+	"hash/maphash"
+	"io"
+	"time"
+)
+
+var _ io.Closer = (*WheelPool)(nil)
+
+// WheelPool identical wheels sharing the tasks by key hash, so the ticks of a large task set
+// are scanned by several goroutines
+type WheelPool struct {
+	seed   maphash.Seed
+	wheels []*TimeWheel
+}
+
+// NewWheelPool create n wheels with the same params and options
+func NewWheelPool(n int, interval time.Duration, slotNum int, opts ...Option) *WheelPool {
+	if n <= 0 {
+		return nil
+	}
+	p := &WheelPool{seed: maphash.MakeSeed(), wheels: make([]*TimeWheel, n)}
+	for i := range p.wheels {
+		if p.wheels[i] = New(interval, slotNum, opts...); p.wheels[i] == nil {
+			return nil
+		}
+	}
+	return p
+}
+
+// wheel owning the key
+func (p *WheelPool) wheel(key interface{}) *TimeWheel {
+	return p.wheels[maphash.Comparable(p.seed, key)%uint64(len(p.wheels))]
+}
+
+// Wheels get the wheels of the pool, used for the per wheel introspection apis
+func (p *WheelPool) Wheels() []*TimeWheel {
+	return append([]*TimeWheel(nil), p.wheels...)
+}
+
+// Start start every wheel
+func (p *WheelPool) Start() {
+	for _, tw := range p.wheels {
+		tw.Start()
+	}
+}
+
+// Stop stop every wheel
+func (p *WheelPool) Stop() {
+	for _, tw := range p.wheels {
+		tw.Stop()
+	}
+}
+
+// Close close every wheel, see TimeWheel.Close. The wheels are all stopped before the first one is waited for.
+func (p *WheelPool) Close() error {
+	p.Stop()
+	var errs []error
+	for _, tw := range p.wheels {
+		if err := tw.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stats get the counters of the wheels added up, see TimeWheel.Stats. LastTick and Position are the largest
+// of the wheels, the histograms are merged and Shedding reports whether any wheel sheds.
+func (p *WheelPool) Stats() WheelStats {
+	s := p.wheels[0].Stats()
+	for _, tw := range p.wheels[1:] {
+		w := tw.Stats()
+		s.Added += w.Added
+		s.Fired += w.Fired
+		s.Removed += w.Removed
+		s.Expired += w.Expired
+		s.Tasks += w.Tasks
+		s.Ticks += w.Ticks
+		s.TickTime += w.TickTime
+		s.LastTick = max(s.LastTick, w.LastTick)
+		s.TickHist = s.TickHist.merge(w.TickHist)
+		s.Lateness = s.Lateness.merge(w.Lateness)
+		s.Position = max(s.Position, w.Position)
+		s.InFlight += w.InFlight
+		s.SlowJobs += w.SlowJobs
+		s.Deferred += w.Deferred
+		s.BlackedOut += w.BlackedOut
+		s.LockLost += w.LockLost
+		s.Queues = s.Queues.add(w.Queues)
+		s.Shedding = s.Shedding || w.Shedding
+	}
+	return s
+}
+
+// add up the histograms of wheels sharing the bounds
+func (s TickHistogram) merge(o TickHistogram) TickHistogram {
+	counts := append([]int64(nil), s.Counts...)
+	for i := range counts {
+		if i < len(o.Counts) {
+			counts[i] += o.Counts[i]
+		}
+	}
+	s.Counts = counts
+	s.Count += o.Count
+	s.Sum += o.Sum
+	s.Overruns += o.Overruns
+	return s
+}
+
+// add up the queues of two wheels
+func (q QueueStats) add(o QueueStats) QueueStats {
+	q.AddQueue += o.AddQueue
+	q.AddQueueCap += o.AddQueueCap
+	q.ExecQueue += o.ExecQueue
+	q.ExecQueueCap += o.ExecQueueCap
+	q.InFlight += o.InFlight
+	q.Carried += o.Carried
+	q.LastTick = max(q.LastTick, o.LastTick)
+	return q
+}
+
+// AddTask add new task to the wheel owning the key
+func (p *WheelPool) AddTask(interval time.Duration, times int, key interface{}, data TaskData, job Job) error {
+	if key == nil {
+		return ErrInvalidParams
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	return p.wheel(key).AddTask(interval, times, key, data, job)
+}
+
+// RemoveTask remove the task from the wheel owning the key
+func (p *WheelPool) RemoveTask(key interface{}) error {
+	if key == nil {
+		return nil
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	return p.wheel(key).RemoveTask(key)
+}
+
+// UpdateTask update task interval and data
+func (p *WheelPool) UpdateTask(key interface{}, interval time.Duration, taskData TaskData) error {
+	if key == nil {
+		return ErrInvalidKey
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	return p.wheel(key).UpdateTask(key, interval, taskData)
+}
+
+// HasTask report whether the task is registered
+func (p *WheelPool) HasTask(key interface{}) bool {
+	if key == nil || !keyComparable(key) {
+		return false
+	}
+	return p.wheel(key).HasTask(key)
+}
+
+// TaskStats get the execution statistics of the task
+func (p *WheelPool) TaskStats(key interface{}) (Stats, error) {
+	if key == nil {
+		return Stats{}, ErrInvalidKey
+	}
+	if !keyComparable(key) {
+		return Stats{}, ErrKeyNotComparable
+	}
+	return p.wheel(key).TaskStats(key)
+}
+
+// Len get the number of registered tasks of every wheel
+func (p *WheelPool) Len() int {
+	n := 0
+	for _, tw := range p.wheels {
+		n += tw.Len()
+	}
+	return n
+}
+
+// Backlog get the number of tasks waiting in the add buffers of every wheel
+func (p *WheelPool) Backlog() int {
+	n := 0
+	for _, tw := range p.wheels {
+		n += tw.Backlog()
+	}
+	return n
+}
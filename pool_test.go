@@ -0,0 +1,169 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWheelPool(t *testing.T) {
+	if NewWheelPool(0, time.Second, 10) != nil {
+		t.Fatal("pool without wheels")
+	}
+	p := NewWheelPool(4, 10*time.Millisecond, 16)
+	p.Start()
+	defer p.Stop()
+	var runs int64
+	for i := 0; i < 100; i++ {
+		if err := p.AddTask(200*time.Millisecond, 1, i, nil, func(TaskData) { atomic.AddInt64(&runs, 1) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 100; i < 200; i++ {
+		p.AddTask(time.Hour, -1, i, nil, func(TaskData) {})
+	}
+	for _, tw := range p.Wheels() {
+		settle(tw)
+	}
+	if p.Len() != 200 {
+		t.Fatalf("len %d", p.Len())
+	}
+	// every key lives on a single wheel and the keys are spread over the wheels
+	for _, tw := range p.Wheels() {
+		if tw.Len() == 0 {
+			t.Fatal("a wheel holds no task")
+		}
+	}
+	for i := 0; i < 200; i++ {
+		owners := 0
+		for _, tw := range p.Wheels() {
+			if tw.HasTask(i) {
+				owners++
+			}
+		}
+		if owners != 1 || !p.HasTask(i) {
+			t.Fatalf("key %d on %d wheels", i, owners)
+		}
+	}
+	if err := p.AddTask(time.Hour, -1, 150, nil, func(TaskData) {}); err != ErrDuplicateKey {
+		t.Fatalf("duplicate key: %v", err)
+	}
+	if err := p.UpdateTask(150, time.Minute, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.RemoveTask(150); err != nil || p.HasTask(150) {
+		t.Fatalf("remove: %v", err)
+	}
+	if _, err := p.TaskStats(151); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddTask(time.Hour, -1, []int{1}, nil, func(TaskData) {}); err != ErrKeyNotComparable {
+		t.Fatalf("slice key: %v", err)
+	}
+	if err := p.AddTask(time.Hour, -1, nil, nil, func(TaskData) {}); err != ErrInvalidParams {
+		t.Fatalf("nil key: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt64(&runs) < 100 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := atomic.LoadInt64(&runs); got != 100 {
+		t.Fatalf("%d runs", got)
+	}
+
+	p.Stop()
+	for _, tw := range p.Wheels() {
+		if !tw.isStopped() {
+			t.Fatal("a wheel is still running")
+		}
+	}
+	if err := p.AddTask(time.Hour, -1, "late", nil, func(TaskData) {}); err != ErrWheelStopped {
+		t.Fatalf("add after stop: %v", err)
+	}
+}
+
+func TestWheelPoolStatsClose(t *testing.T) {
+	c := newFakeClock()
+	p := NewWheelPool(4, time.Second, 16, WithClock(c))
+	p.Start()
+	for i := 0; i < 100; i++ {
+		p.AddTask(time.Second, -1, i, nil, func(TaskData) {})
+	}
+	for _, tw := range p.Wheels() {
+		settle(tw)
+	}
+	// the wheels share the clock, every tick is taken by one of them and caught up by the others
+	for i := 0; i < 12; i++ {
+		c.Tick(time.Second)
+	}
+	for _, tw := range p.Wheels() {
+		settle(tw)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, tw := range p.Wheels() {
+		if !tw.isStopped() || tw.Len() != 0 {
+			t.Fatal("a wheel is still open")
+		}
+	}
+
+	// the counters do not move once the wheels are closed
+	s := p.Stats()
+	var fired, ticks, hist, lateness int64
+	for _, tw := range p.Wheels() {
+		w := tw.Stats()
+		fired += w.Fired
+		ticks += w.Ticks
+		hist += w.TickHist.Counts[0]
+		lateness += w.Lateness.Count
+		if w.Position > s.Position {
+			t.Fatal("position", w.Position, s.Position)
+		}
+	}
+	if s.Added != 100 || s.Fired != fired || s.Fired == 0 || s.Ticks != ticks || s.TickHist.Count != ticks ||
+		s.TickHist.Counts[0] != hist || s.Lateness.Count != lateness || s.Lateness.Count != fired {
+		t.Fatalf("%+v", s)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// tick latency of the pool holding 2M recurring tasks, by number of wheels. The tasks are spread over the
+// slots and run every 10s or more, so the ticks measured scan the slots without running jobs.
+func BenchmarkWheelPoolTick(b *testing.B) {
+	tasks := 2000000
+	if testing.Short() {
+		tasks = 200000
+	}
+	for _, shards := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprint("shards=", shards), func(b *testing.B) {
+			p := NewWheelPool(shards, 10*time.Millisecond, 100, WithAddBuffer(1024))
+			p.Start()
+			defer p.Close()
+			job := func(TaskData) {}
+			for k := 0; k < tasks; k++ {
+				if err := p.AddTask(10*time.Second+time.Duration(k%100)*10*time.Millisecond, -1, k, nil, job); err != nil {
+					b.Fatal(err)
+				}
+			}
+			for p.Backlog() > 0 {
+				time.Sleep(time.Millisecond)
+			}
+			before := p.Stats()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				time.Sleep(10 * time.Millisecond)
+			}
+			b.StopTimer()
+			after := p.Stats()
+			if ticks := after.Ticks - before.Ticks; ticks > 0 {
+				b.ReportMetric(float64((after.TickTime-before.TickTime).Microseconds())/float64(ticks), "tick-µs")
+			}
+			b.ReportMetric(float64(after.LastTick.Microseconds()), "last-tick-µs")
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// position of the wheel with the tick that moved it there, published as a whole by the wheel goroutine
+type tickMark struct {
+	slot     int
+	at       time.Time // time of the tick
+	interval time.Duration
+}
+
+// publish the position of the wheel reached by the tick at at, only called on the wheel goroutine
+func (tw *TimeWheel) markTick(at time.Time) {
+	pos := tw.backend.position()
+	atomic.StoreInt64(&tw.position, int64(pos))
+	tw.mark.Store(&tickMark{slot: pos, at: at, interval: tw.interval})
+}
+
+// Position get the slot the next tick processes, the time elapsed since the last tick and the time left until the
+// next one. The three values come from the same tick. The heap backend reports the tick count as the slot.
+// A hibernating wheel reports no time left once the next tick is overdue.
+func (tw *TimeWheel) Position() (slot int, sinceTick time.Duration, untilNextTick time.Duration) {
+	m := tw.mark.Load()
+	if m == nil {
+		return int(atomic.LoadInt64(&tw.position)), 0, tw.Interval()
+	}
+	sinceTick = tw.clock.Now().Round(0).Sub(m.at)
+	if sinceTick < 0 {
+		sinceTick = 0
+	}
+	untilNextTick = m.interval - sinceTick
+	if untilNextTick < 0 {
+		untilNextTick = 0
+	}
+	return m.slot, sinceTick, untilNextTick
+}
@@ -0,0 +1,32 @@
+package timewheel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPosition(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 8, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	slot, since, until := tw.Position()
+	if slot != 0 || since != 0 || until != time.Second {
+		t.Fatal(slot, since, until)
+	}
+	for i := 1; i <= 10; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+		slot, since, until = tw.Position()
+		if slot != i%8 || since != 0 || until != time.Second {
+			t.Fatal(i, slot, since, until)
+		}
+	}
+	c.mu.Lock()
+	c.now = c.now.Add(300 * time.Millisecond)
+	c.mu.Unlock()
+	slot, since, until = tw.Position()
+	if slot != 2 || since != 300*time.Millisecond || until != 700*time.Millisecond {
+		t.Fatal(slot, since, until)
+	}
+}
@@ -0,0 +1,88 @@
+package timewheel
+
+import "time"
+
+// Postpone move the pending run of the task d later, the following runs keep the cadence of the task as if
+// the run was not moved: a task running on the hour postponed by 10 minutes runs at 10 past once then on
+// the hour again, the runs the postponed one passed are skipped. An overdue run is moved d from now, a task
+// waiting for its prerequisite starts d later once released. Postponing the run past the end of the task
+// fails with ErrInvalidParams.
+func (tw *TimeWheel) Postpone(key interface{}, d time.Duration) error {
+	return tw.postpone(key, d, false)
+}
+
+// PostponeReanchor move the pending run of the task d later like Postpone, the following runs count from it
+func (tw *TimeWheel) PostponeReanchor(key interface{}, d time.Duration) error {
+	return tw.postpone(key, d, true)
+}
+
+// move the pending run on the wheel goroutine
+func (tw *TimeWheel) postpone(key interface{}, d time.Duration, reanchor bool) error {
+	if key == nil {
+		return ErrInvalidKey
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	if d <= 0 {
+		return ErrInvalidParams
+	}
+	if d > tw.maxDelay() {
+		return ErrDelayTooLarge
+	}
+	var err error
+	execErr := tw.exec(func() {
+		task, ok := tw.taskRecord.Load(key)
+		if !ok {
+			err = ErrTaskNotFound
+			return
+		}
+		if task.isHeld() {
+			err = ErrTaskStillRunning
+			return
+		}
+		if task.dep != nil {
+			task.dep.delay += d
+			return
+		}
+		now := tw.clock.Now()
+		next := task.next
+		if next.Before(now) {
+			next = now
+		}
+		next = next.Add(d)
+		if !task.until.IsZero() && next.After(task.until) {
+			err = ErrInvalidParams
+			return
+		}
+		if !tw.undefer(task) {
+			tw.backend.remove(task)
+		}
+		if reanchor {
+			task.anchor = time.Time{}
+		} else if task.anchor.IsZero() {
+			task.anchor = task.next
+		}
+		task.next = next
+		tw.backend.push(task, tw.delayTicks(next.Sub(now)))
+	})
+	if execErr != nil {
+		return execErr
+	}
+	return err
+}
+
+// first run of the cadence after the postponed run, zero if the schedule ends
+func (t *task) anchoredFollowing() time.Time {
+	if t.schedule == nil {
+		every := t.effective()
+		return t.anchor.Add((t.next.Sub(t.anchor)/every + 1) * every)
+	}
+	next := t.anchor
+	for {
+		next = t.schedule.Next(next)
+		if next.IsZero() || next.After(t.next) {
+			return next
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPostpone(t *testing.T) {
+	for _, reanchor := range []bool{false, true} {
+		c := newFakeClock()
+		start := c.Now()
+		tw := New(10*time.Millisecond, 8, WithClock(c))
+		tw.Start()
+		var mu sync.Mutex
+		var at []time.Duration
+		tw.AddTask(100*time.Millisecond, 3, "k", nil, func(TaskData) {
+			mu.Lock()
+			at = append(at, c.Now().Sub(start))
+			mu.Unlock()
+		})
+		settle(tw)
+		var err error
+		if reanchor {
+			err = tw.PostponeReanchor("k", 30*time.Millisecond)
+		} else {
+			err = tw.Postpone("k", 30*time.Millisecond)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 50; i++ {
+			c.Tick(10 * time.Millisecond)
+			settle(tw)
+		}
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		want := []time.Duration{140 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}
+		if reanchor {
+			want = []time.Duration{140 * time.Millisecond, 240 * time.Millisecond, 340 * time.Millisecond}
+		}
+		if len(at) != 3 || at[0] != want[0] || at[1] != want[1] || at[2] != want[2] {
+			t.Fatal(reanchor, at)
+		}
+		mu.Unlock()
+		tw.Stop()
+	}
+}
+
+func TestPostponePastEnd(t *testing.T) {
+	c := newFakeClock()
+	tw := New(10*time.Millisecond, 8, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTaskWith(100*time.Millisecond, -1, "k", nil, func(TaskData) {}, Until(c.Now().Add(150*time.Millisecond)))
+	settle(tw)
+	if err := tw.Postpone("k", time.Second); err != ErrInvalidParams {
+		t.Fatal(err)
+	}
+	if err := tw.Postpone("k", 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPostponeOverdue(t *testing.T) {
+	c := newFakeClock()
+	start := c.Now()
+	tw := New(10*time.Millisecond, 8, WithClock(c), WithTickCap(1))
+	tw.Start()
+	defer tw.Stop()
+	var mu sync.Mutex
+	at := map[string]time.Duration{}
+	for _, k := range []string{"a", "b"} {
+		k := k
+		tw.AddTask(10*time.Millisecond, 1, k, nil, func(TaskData) {
+			mu.Lock()
+			at[k] = c.Now().Sub(start)
+			mu.Unlock()
+		})
+	}
+	settle(tw)
+	c.Tick(10 * time.Millisecond)
+	c.Tick(10 * time.Millisecond)
+	settle(tw)
+	// one of them is over the cap, overdue
+	mu.Lock()
+	late := "a"
+	if _, ok := at["a"]; ok {
+		late = "b"
+	}
+	mu.Unlock()
+	if err := tw.Postpone(late, 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		c.Tick(10 * time.Millisecond)
+		settle(tw)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	// moved 50ms from the tick it was due on, fired on the tick after like any delay
+	if at[late] != 80*time.Millisecond {
+		t.Fatal(at)
+	}
+}
@@ -0,0 +1,121 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// WithPreciseTasks back the tasks marked Precise by a dedicated timer instead of a slot, at most max of them,
+// the adds beyond return ErrTooManyTasks. Default is no precise task, Precise is then ignored.
+func WithPreciseTasks(max int) Option {
+	return func(tw *TimeWheel) {
+		if max > 0 {
+			tw.preciseMax = int64(max)
+		}
+	}
+}
+
+// Precise run the task on time instead of on the tick processing its slot, see WithPreciseTasks. The task is
+// backed by a timer of the wheel clock, it is still removed, updated and run like the other tasks. With a clock
+// creating no timer, see TimerClock, the task runs on the tick like the others. The jitter of the task is ignored.
+func Precise() TaskOption {
+	return func(t *task) {
+		t.precise = true
+	}
+}
+
+// PreciseTasks get the number of precise tasks held by the wheel and the maximum, see WithPreciseTasks
+func (tw *TimeWheel) PreciseTasks() (n int, max int) {
+	return int(atomic.LoadInt64(&tw.preciseNum)), int(tw.preciseMax)
+}
+
+// count the precise task against the maximum, a plain task is always admitted
+func (tw *TimeWheel) admitPrecise(t *task) error {
+	if !t.precise {
+		return nil
+	}
+	if tw.preciseMax == 0 {
+		t.precise = false
+		return nil
+	}
+	if atomic.AddInt64(&tw.preciseNum, 1) > tw.preciseMax {
+		atomic.AddInt64(&tw.preciseNum, -1)
+		t.precise = false
+		return fmt.Errorf("%w, %d precise tasks", ErrTooManyTasks, tw.preciseMax)
+	}
+	return nil
+}
+
+// timers handing the due precise tasks to the wheel goroutine, the other tasks go to the wrapped backend
+type preciseBackend struct {
+	backend
+	tw     *TimeWheel
+	timers map[*task]Timer
+}
+
+// sequence of the armed timers, a timer firing after its task moved is ignored
+var timerSeq uint64
+
+func (b *preciseBackend) push(t *task, ticks int) {
+	if !t.precise {
+		b.backend.push(t, ticks)
+		return
+	}
+	tw := b.tw
+	seq := atomic.AddUint64(&timerSeq, 1)
+	timer := afterFunc(tw.clock, t.next.Sub(tw.clock.Now()), func() {
+		tw.exec(func() {
+			if b.timers[t] == nil || t.timerSeq != seq {
+				return
+			}
+			delete(b.timers, t)
+			tw.runDueTask(t)
+		})
+	})
+	if timer == nil {
+		b.backend.push(t, ticks)
+		return
+	}
+	t.timerSeq = seq
+	b.timers[t] = timer
+}
+
+func (b *preciseBackend) remove(t *task) {
+	timer, ok := b.timers[t]
+	if !ok {
+		b.backend.remove(t)
+		return
+	}
+	timer.Stop()
+	delete(b.timers, t)
+	t.timerSeq = 0
+}
+
+func (b *preciseBackend) each(fn func(t *task)) {
+	b.backend.each(fn)
+	for t := range b.timers {
+		fn(t)
+	}
+}
+
+func (b *preciseBackend) len() int {
+	return b.backend.len() + len(b.timers)
+}
+
+func (b *preciseBackend) ticksUntil(t *task) int {
+	if _, ok := b.timers[t]; !ok {
+		return b.backend.ticksUntil(t)
+	}
+	if d := t.next.Sub(b.tw.clock.Now()); d > 0 {
+		return int(d / b.tw.interval)
+	}
+	return 0
+}
+
+func (b *preciseBackend) clear() {
+	for _, timer := range b.timers {
+		timer.Stop()
+	}
+	b.timers = nil
+	b.backend.clear()
+}
@@ -0,0 +1,166 @@
+package timewheel
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPreciseTasks(t *testing.T) {
+	tw := New(time.Second, 8, WithPreciseTasks(2))
+	tw.Start()
+	defer tw.Stop()
+	fired := make(chan time.Duration, 64)
+	begin := time.Now()
+	job := func(TaskData) { fired <- time.Since(begin) }
+	if err := tw.AddTaskWith(50*time.Millisecond, 1, "precise", nil, job, Precise()); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.AddTaskWith(50*time.Millisecond, 1, "plain", nil, job); err != nil {
+		t.Fatal(err)
+	}
+	if n, max := tw.PreciseTasks(); n != 1 || max != 2 {
+		t.Fatal(n, max)
+	}
+	if d := <-fired; d < 48*time.Millisecond || d > 60*time.Millisecond {
+		t.Fatal("precise", d)
+	}
+	if d := <-fired; d < 900*time.Millisecond {
+		t.Fatal("plain", d)
+	}
+	if n, _ := tw.PreciseTasks(); n != 0 {
+		t.Fatal(n)
+	}
+	// removal and cap
+	tw.AddTaskWith(30*time.Millisecond, -1, "a", nil, job, Precise())
+	tw.AddTaskWith(30*time.Millisecond, -1, "b", nil, job, Precise())
+	if err := tw.AddTaskWith(30*time.Millisecond, -1, "c", nil, job, Precise()); !errors.Is(err, ErrTooManyTasks) {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	tw.RemoveTask("a")
+	tw.RemoveTask("b")
+	time.Sleep(10 * time.Millisecond)
+	for len(fired) > 0 {
+		<-fired
+	}
+	time.Sleep(100 * time.Millisecond)
+	if len(fired) != 0 {
+		t.Fatal("fired after removal")
+	}
+	if n, _ := tw.PreciseTasks(); n != 0 {
+		t.Fatal(n)
+	}
+}
+
+// fake clock creating timers, see Advance
+type timerClock struct {
+	*fakeClock
+	mu     sync.Mutex
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+func (c *timerClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{at: c.Now().Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return &fakeTimerHandle{c, t}
+}
+
+type fakeTimerHandle struct {
+	c *timerClock
+	t *fakeTimer
+}
+
+func (h *fakeTimerHandle) Stop() bool {
+	h.c.mu.Lock()
+	defer h.c.mu.Unlock()
+	stopped := h.t.stopped
+	h.t.stopped = true
+	return !stopped
+}
+
+// advance the time by d without a tick and fire the due timers
+func (c *timerClock) Advance(d time.Duration) {
+	c.fakeClock.mu.Lock()
+	c.now = c.now.Add(d)
+	now := c.now
+	c.fakeClock.mu.Unlock()
+	c.mu.Lock()
+	var due []func()
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		switch {
+		case t.stopped:
+		case !t.at.After(now):
+			t.stopped = true
+			due = append(due, t.f)
+		default:
+			pending = append(pending, t)
+		}
+	}
+	c.timers = pending
+	c.mu.Unlock()
+	for _, f := range due {
+		go f()
+	}
+}
+
+func TestPreciseTasksInjectedClock(t *testing.T) {
+	c := &timerClock{fakeClock: newFakeClock()}
+	tw := New(time.Second, 8, WithClock(c), WithPreciseTasks(2))
+	tw.Start()
+	defer tw.Stop()
+	fired := make(chan string, 2)
+	job := func(data TaskData) { fired <- data["name"].(string) }
+	tw.AddTaskWith(50*time.Millisecond, 1, "precise", TaskData{"name": "precise"}, job, Precise())
+	tw.AddTaskWith(50*time.Millisecond, 1, "plain", TaskData{"name": "plain"}, job)
+	settle(tw)
+	c.Advance(40 * time.Millisecond)
+	settle(tw)
+	if len(fired) != 0 {
+		t.Fatal("fired early")
+	}
+	c.Advance(10 * time.Millisecond)
+	select {
+	case name := <-fired:
+		if name != "precise" {
+			t.Fatal(name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("precise task not fired by the clock timer")
+	}
+	c.Tick(950 * time.Millisecond)
+	if name := <-fired; name != "plain" {
+		t.Fatal(name)
+	}
+}
+
+func TestPreciseTasksClockWithoutTimers(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 8, WithClock(c), WithPreciseTasks(2))
+	tw.Start()
+	defer tw.Stop()
+	fired := make(chan struct{}, 1)
+	tw.AddTaskWith(50*time.Millisecond, 1, "precise", nil, func(TaskData) { fired <- struct{}{} }, Precise())
+	settle(tw)
+	select {
+	case <-fired:
+		t.Fatal("fired off the clock")
+	case <-time.After(100 * time.Millisecond):
+	}
+	c.Tick(time.Second)
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("not fired on the tick")
+	}
+}
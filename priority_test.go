@@ -0,0 +1,101 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPriorityDispatchOrder(t *testing.T) {
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			c := newFakeClock()
+			var mu sync.Mutex
+			var order []int
+			// a single worker runs the jobs in the order they were dispatched
+			tw := New(time.Second, 10, WithClock(c), WithBackend(b.backend), WithWorkers(1, 64, Block))
+			tw.Start()
+			defer tw.Stop()
+			for i := 0; i < 30; i++ {
+				prio := i % 3
+				tw.AddTaskWith(time.Second, 1, i, nil, func(TaskData) {
+					mu.Lock()
+					order = append(order, prio)
+					mu.Unlock()
+				}, Priority(prio))
+			}
+			settle(tw)
+			c.Tick(time.Second)
+			c.Tick(time.Second)
+			settle(tw)
+			deadline := time.Now().Add(time.Second)
+			for {
+				mu.Lock()
+				n := len(order)
+				mu.Unlock()
+				if n == 30 || time.Now().After(deadline) {
+					break
+				}
+				time.Sleep(time.Millisecond)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(order) != 30 {
+				t.Fatalf("%d runs", len(order))
+			}
+			for i := 1; i < len(order); i++ {
+				if order[i] > order[i-1] {
+					t.Fatalf("dispatch order %v", order)
+				}
+			}
+		})
+	}
+}
+
+func TestSortByPriority(t *testing.T) {
+	tasks := make([]*task, 6)
+	for i := range tasks {
+		tasks[i] = &task{key: i}
+	}
+	// a single priority keeps the order of the slot
+	sortByPriority(tasks)
+	for i, task := range tasks {
+		if task.key != i {
+			t.Fatalf("reordered %d to %d", task.key, i)
+		}
+	}
+	for i, task := range tasks {
+		task.priority = i % 2
+	}
+	sortByPriority(tasks)
+	want := []int{1, 3, 5, 0, 2, 4}
+	for i, task := range tasks {
+		if task.key != want[i] {
+			t.Fatalf("position %d holds %v, want %d", i, task.key, want[i])
+		}
+	}
+}
+
+func BenchmarkSortByPriority(b *testing.B) {
+	for _, mixed := range []bool{false, true} {
+		name := "single"
+		if mixed {
+			name = "mixed"
+		}
+		b.Run(name, func(b *testing.B) {
+			tasks := make([]*task, 500)
+			for i := range tasks {
+				tasks[i] = &task{}
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for j, t := range tasks {
+					if mixed {
+						t.priority = (j * 7) % 5
+					}
+				}
+				sortByPriority(tasks)
+			}
+		})
+	}
+}
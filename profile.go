@@ -0,0 +1,33 @@
+package timewheel
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+)
+
+// ProfileLabelKey pprof label carrying the task key on the job goroutines, see WithProfileLabels
+const ProfileLabelKey = "timewheel_key"
+
+// WithProfileLabels label the goroutine running a job with the task key, so the profiles group the samples
+// by task. The label is also set on the context given to the interceptor and the jobs. keyString formats
+// the key, nil means fmt.Sprint. Default is no label since formatting the key costs a little on every run.
+func WithProfileLabels(keyString func(key interface{}) string) Option {
+	return func(tw *TimeWheel) {
+		if keyString == nil {
+			keyString = func(key interface{}) string {
+				return fmt.Sprint(key)
+			}
+		}
+		tw.keyLabel = keyString
+	}
+}
+
+// call fn with the context labelled with the task key if the labels are enabled
+func (tw *TimeWheel) withKeyLabel(ctx context.Context, key interface{}, fn func(ctx context.Context)) {
+	if tw.keyLabel == nil {
+		fn(ctx)
+		return
+	}
+	pprof.Do(ctx, pprof.Labels(ProfileLabelKey, tw.keyLabel(key)), fn)
+}
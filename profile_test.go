@@ -0,0 +1,53 @@
+package timewheel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+)
+
+// label of the job context and whether the goroutine profile shows it on the job goroutine
+type jobLabel struct {
+	value     string
+	ok        bool
+	goroutine bool
+}
+
+func labelOf(t *testing.T, opts ...Option) jobLabel {
+	t.Helper()
+	tw := New(time.Millisecond, 8, opts...)
+	tw.Start()
+	defer tw.Stop()
+	got := make(chan jobLabel, 1)
+	tw.AddTaskCtx(time.Millisecond, 1, 7, nil, func(ctx context.Context, d TaskData) {
+		var l jobLabel
+		l.value, l.ok = pprof.Label(ctx, ProfileLabelKey)
+		var buf bytes.Buffer
+		pprof.Lookup("goroutine").WriteTo(&buf, 1)
+		l.goroutine = strings.Contains(buf.String(), `"`+ProfileLabelKey+`":"`+l.value+`"`)
+		got <- l
+	})
+	select {
+	case l := <-got:
+		return l
+	case <-time.After(time.Second):
+		t.Fatal("the job never ran")
+	}
+	return jobLabel{}
+}
+
+func TestProfileLabels(t *testing.T) {
+	if l := labelOf(t, WithProfileLabels(func(key interface{}) string { return fmt.Sprintf("key-%d", key) })); l.value != "key-7" || !l.goroutine {
+		t.Fatalf("custom stringer: %+v", l)
+	}
+	if l := labelOf(t, WithProfileLabels(nil)); l.value != "7" || !l.goroutine {
+		t.Fatalf("default stringer: %+v", l)
+	}
+	if l := labelOf(t); l.ok {
+		t.Fatalf("labelled without the option: %+v", l)
+	}
+}
@@ -0,0 +1,184 @@
+// Package prommetrics export the time wheel measurements to prometheus.
+//
+//	collector := prommetrics.New("myapp")
+//	prometheus.MustRegister(collector)
+//	tw := timewheel.New(time.Second, 60, timewheel.WithMetrics(collector))
+//	tw.Start()
+//
+//	http.Handle("/metrics", promhttp.Handler())
+//	http.ListenAndServe(":8080", nil)
+package prommetrics
+
+import (
+	"time"
+
+	"github.com/nosixtools/timewheel"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector a prometheus collector implementing timewheel.Metrics
+type Collector struct {
+	tasks        prometheus.Gauge
+	backlog      prometheus.Gauge
+	added        prometheus.Counter
+	removed      prometheus.Counter
+	fired        prometheus.Counter
+	panics       prometheus.Counter
+	jobDuration  prometheus.Histogram
+	tickDuration prometheus.Histogram
+	tickOverruns prometheus.Counter
+
+	// series by metric label, see NewWithLabels
+	labelNames    []string
+	firedBy       *prometheus.CounterVec
+	jobDurationBy *prometheus.HistogramVec
+}
+
+var _ timewheel.Metrics = (*Collector)(nil)
+var _ prometheus.Collector = (*Collector)(nil)
+var _ timewheel.TickOverrunMetrics = (*Collector)(nil)
+var _ timewheel.LabeledMetrics = (*Collector)(nil)
+
+// New create a collector, metric names are prefixed with namespace
+func New(namespace string) *Collector {
+	return &Collector{
+		tasks: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: "timewheel", Name: "tasks",
+			Help: "Number of tasks currently registered.",
+		}),
+		backlog: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: "timewheel", Name: "add_backlog",
+			Help: "Number of tasks waiting in the add channel.",
+		}),
+		added: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "timewheel", Name: "tasks_added_total",
+			Help: "Number of tasks added.",
+		}),
+		removed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "timewheel", Name: "tasks_removed_total",
+			Help: "Number of tasks removed.",
+		}),
+		fired: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "timewheel", Name: "tasks_fired_total",
+			Help: "Number of task firings.",
+		}),
+		panics: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "timewheel", Name: "job_panics_total",
+			Help: "Number of jobs that panicked.",
+		}),
+		jobDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "timewheel", Name: "job_duration_seconds",
+			Help:    "Job execution duration.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		tickDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: "timewheel", Name: "tick_duration_seconds",
+			Help:    "Tick processing duration.",
+			Buckets: prometheus.ExponentialBuckets(0.0001, 4, 8),
+		}),
+		tickOverruns: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: "timewheel", Name: "tick_overruns_total",
+			Help: "Number of ticks lasting longer than the tick interval.",
+		}),
+	}
+}
+
+// NewWithLabels create a collector like New, the firings and the job durations are also recorded by the metric
+// labels of the tasks, see timewheel.MetricLabels. The tasks without labels fall in the series of empty labels.
+func NewWithLabels(namespace string, labels ...string) *Collector {
+	c := New(namespace)
+	c.labelNames = append([]string(nil), labels...)
+	c.firedBy = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "timewheel", Name: "labeled_tasks_fired_total",
+		Help: "Number of task firings by metric label.",
+	}, c.labelNames)
+	c.jobDurationBy = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "timewheel", Name: "labeled_job_duration_seconds",
+		Help:    "Job execution duration by metric label.",
+		Buckets: prometheus.DefBuckets,
+	}, c.labelNames)
+	return c
+}
+
+func (c *Collector) metrics() []prometheus.Collector {
+	m := []prometheus.Collector{c.tasks, c.backlog, c.added, c.removed, c.fired, c.panics, c.jobDuration, c.tickDuration,
+		c.tickOverruns}
+	if c.firedBy != nil {
+		m = append(m, c.firedBy, c.jobDurationBy)
+	}
+	return m
+}
+
+// values of the label names, the missing ones are empty
+func (c *Collector) values(labels map[string]string) []string {
+	values := make([]string, len(c.labelNames))
+	for i, name := range c.labelNames {
+		values[i] = labels[name]
+	}
+	return values
+}
+
+// Describe implement prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.metrics() {
+		m.Describe(ch)
+	}
+}
+
+// Collect implement prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c.metrics() {
+		m.Collect(ch)
+	}
+}
+
+// TaskAdded implement timewheel.Metrics
+func (c *Collector) TaskAdded() {
+	c.added.Inc()
+}
+
+// TaskRemoved implement timewheel.Metrics
+func (c *Collector) TaskRemoved() {
+	c.removed.Inc()
+}
+
+// TaskFired implement timewheel.Metrics
+func (c *Collector) TaskFired() {
+	c.fired.Inc()
+}
+
+// JobDone implement timewheel.Metrics
+func (c *Collector) JobDone(d time.Duration, panicked bool) {
+	c.jobDuration.Observe(d.Seconds())
+	if panicked {
+		c.panics.Inc()
+	}
+}
+
+// TaskFiredLabeled implement timewheel.LabeledMetrics
+func (c *Collector) TaskFiredLabeled(labels map[string]string) {
+	c.TaskFired()
+	if c.firedBy != nil {
+		c.firedBy.WithLabelValues(c.values(labels)...).Inc()
+	}
+}
+
+// JobDoneLabeled implement timewheel.LabeledMetrics
+func (c *Collector) JobDoneLabeled(labels map[string]string, d time.Duration, panicked bool) {
+	c.JobDone(d, panicked)
+	if c.jobDurationBy != nil {
+		c.jobDurationBy.WithLabelValues(c.values(labels)...).Observe(d.Seconds())
+	}
+}
+
+// TickOverrun implement timewheel.TickOverrunMetrics
+func (c *Collector) TickOverrun(d time.Duration) {
+	c.tickOverruns.Inc()
+}
+
+// TickDone implement timewheel.Metrics
+func (c *Collector) TickDone(d time.Duration, tasks int, backlog int) {
+	c.tickDuration.Observe(d.Seconds())
+	c.tasks.Set(float64(tasks))
+	c.backlog.Set(float64(backlog))
+}
@@ -0,0 +1,100 @@
+package prommetrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nosixtools/timewheel"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	c := New("test")
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		t.Fatal(err)
+	}
+	tw := timewheel.New(10*time.Millisecond, 10, timewheel.WithMetrics(c))
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(10*time.Millisecond, 3, "a", nil, func(timewheel.TaskData) {})
+	tw.AddTask(10*time.Millisecond, 1, "b", nil, func(timewheel.TaskData) { panic("boom") })
+	tw.AddTask(time.Hour, 1, "c", nil, func(timewheel.TaskData) {})
+	time.Sleep(100 * time.Millisecond)
+	tw.RemoveTask("c")
+	time.Sleep(30 * time.Millisecond)
+
+	if v := testutil.ToFloat64(c.added); v != 3 {
+		t.Fatal("added", v)
+	}
+	if v := testutil.ToFloat64(c.removed); v != 1 {
+		t.Fatal("removed", v)
+	}
+	if v := testutil.ToFloat64(c.fired); v != 4 {
+		t.Fatal("fired", v)
+	}
+	if v := testutil.ToFloat64(c.panics); v != 1 {
+		t.Fatal("panics", v)
+	}
+	if v := testutil.ToFloat64(c.tasks); v != 0 {
+		t.Fatal("tasks", v)
+	}
+	if n, err := testutil.GatherAndCount(reg, "test_timewheel_job_duration_seconds", "test_timewheel_tick_duration_seconds"); err != nil || n != 2 {
+		t.Fatal(n, err)
+	}
+}
+
+func TestCollectorTickOverruns(t *testing.T) {
+	c := New("test")
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		t.Fatal(err)
+	}
+	c.TickDone(time.Millisecond, 0, 0)
+	c.TickOverrun(50 * time.Millisecond)
+	if v := testutil.ToFloat64(c.tickOverruns); v != 1 {
+		t.Fatal("overruns", v)
+	}
+	if n, err := testutil.GatherAndCount(reg, "test_timewheel_tick_overruns_total"); err != nil || n != 1 {
+		t.Fatal(n, err)
+	}
+}
+
+func TestCollectorLabels(t *testing.T) {
+	c := NewWithLabels("test", "tenant", "job")
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		t.Fatal(err)
+	}
+	tw := timewheel.New(10*time.Millisecond, 10, timewheel.WithMetrics(c), timewheel.WithMetricLabels("tenant", "job"))
+	tw.Start()
+	defer tw.Stop()
+	job := func(timewheel.TaskData) {}
+	tw.AddTaskWith(10*time.Millisecond, 3, "a", nil, job, timewheel.MetricLabels(map[string]string{"tenant": "x"}))
+	tw.AddTaskWith(10*time.Millisecond, 2, "b", nil, job, timewheel.MetricLabels(map[string]string{"tenant": "y", "job": "sync"}))
+	tw.AddTask(10*time.Millisecond, 1, "c", nil, job)
+	time.Sleep(100 * time.Millisecond)
+
+	for _, s := range []struct {
+		values []string
+		want   float64
+	}{
+		{[]string{"x", ""}, 3},
+		{[]string{"y", "sync"}, 2},
+		{[]string{"", ""}, 1},
+	} {
+		if v := testutil.ToFloat64(c.firedBy.WithLabelValues(s.values...)); v != s.want {
+			t.Fatal("fired", s.values, v)
+		}
+	}
+	if v := testutil.ToFloat64(c.fired); v != 6 {
+		t.Fatal("fired", v)
+	}
+	if n, err := testutil.GatherAndCount(reg, "test_timewheel_labeled_tasks_fired_total"); err != nil || n != 3 {
+		t.Fatal(n, err)
+	}
+	if n, err := testutil.GatherAndCount(reg, "test_timewheel_labeled_job_duration_seconds"); err != nil || n != 3 {
+		t.Fatal(n, err)
+	}
+}
@@ -0,0 +1,34 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// QueueStats depth of the queues of the wheel
+type QueueStats struct {
+	AddQueue     int           // tasks waiting in the add buffer
+	AddQueueCap  int           // size of the add buffer
+	ExecQueue    int           // runs waiting for the previous run of their key or for a worker
+	ExecQueueCap int           // size of the worker queue, 0 without a worker pool
+	InFlight     int           // runs dispatched and not returned yet
+	Carried      int           // due tasks waiting for the next scan chunk, see WithScanChunk
+	LastTick     time.Duration // duration of the most recent tick
+}
+
+// QueueStats get the depth of the queues, cheap and safe to call from any goroutine
+func (tw *TimeWheel) QueueStats() QueueStats {
+	qs := QueueStats{
+		AddQueue:    len(tw.addTaskChannel),
+		AddQueueCap: cap(tw.addTaskChannel),
+		ExecQueue:   int(atomic.LoadInt64(&tw.queuedNum)),
+		InFlight:    int(atomic.LoadInt64(&tw.inflightNum)),
+		Carried:     int(atomic.LoadInt64(&tw.carryNum)),
+		LastTick:    time.Duration(atomic.LoadInt64(&tw.lastTickCost)),
+	}
+	if tw.workers != nil && tw.sequencer == nil {
+		qs.ExecQueue += len(tw.workers.runs)
+		qs.ExecQueueCap = cap(tw.workers.runs)
+	}
+	return qs
+}
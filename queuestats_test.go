@@ -0,0 +1,59 @@
+package timewheel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueueStats(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c), WithWorkers(1, 8, Block), WithAddBuffer(16))
+	tw.Start()
+	defer tw.Stop()
+	if qs := tw.QueueStats(); qs.AddQueueCap != 16 || qs.ExecQueueCap != 8 || qs.AddQueue != 0 || qs.ExecQueue != 0 {
+		t.Fatalf("idle %+v", qs)
+	}
+
+	// the adds queue up behind a stalled loop
+	release := stall(tw)
+	for i := 0; i < 3; i++ {
+		go tw.AddTask(time.Hour, -1, i, nil, func(TaskData) {})
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for tw.QueueStats().AddQueue < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if qs := tw.QueueStats(); qs.AddQueue != 3 {
+		t.Fatalf("stalled loop %+v", qs)
+	}
+	release()
+	settle(tw)
+	if qs := tw.QueueStats(); qs.AddQueue != 0 {
+		t.Fatalf("released loop %+v", qs)
+	}
+
+	// the runs queue up behind a stalled worker
+	block := make(chan struct{})
+	for i := 0; i < 6; i++ {
+		tw.AddTask(time.Second, 1, 10+i, nil, func(TaskData) { <-block })
+	}
+	settle(tw)
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	qs := tw.QueueStats()
+	if qs.InFlight != 6 || qs.ExecQueue != 5 {
+		t.Fatalf("stalled worker %+v", qs)
+	}
+	if qs.LastTick <= 0 {
+		t.Fatalf("last tick %v", qs.LastTick)
+	}
+	close(block)
+	deadline = time.Now().Add(5 * time.Second)
+	for tw.QueueStats().InFlight > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if qs := tw.QueueStats(); qs.InFlight != 0 || qs.ExecQueue != 0 {
+		t.Fatalf("drained %+v", qs)
+	}
+}
@@ -0,0 +1,236 @@
+package timewheel
+
+import (
+	"hash/maphash"
+	"reflect"
+	"sync"
+)
+
+// number of record shards, must be a power of two
+const recordShards = 32
+
+// registered tasks by key, see recordOf. The keys of the methods are asserted to the key type of the
+// record, accepts reports whether they are of it.
+type taskRecord interface {
+	accepts(key interface{}) bool
+	hash(key interface{}) uint64
+	Load(key interface{}) (*task, bool)
+	view(key interface{}, fn func(t *task)) bool
+	LoadOrStore(key interface{}, t *task) (actual *task, loaded bool)
+	CompareAndDelete(key interface{}, t *task) bool
+	CompareAndSwap(key interface{}, old, t *task) bool
+	deleteBatch(tasks []*task) int
+	clear()
+	Len() int
+	Range(fn func(key interface{}, t *task) bool)
+}
+
+// registered tasks keyed by K, sharded by key hash so concurrent callers rarely share a lock.
+// A TimeWheelOf[K] records its tasks in a recordOf[K], the others in a recordOf[interface{}].
+type recordOf[K comparable] struct {
+	seed   maphash.Seed
+	shards [recordShards]recordShard[K]
+}
+
+type recordShard[K comparable] struct {
+	sync.RWMutex
+	tasks map[K]*task
+}
+
+func newTaskRecord() taskRecord {
+	return newRecordOf[interface{}]()
+}
+
+func newRecordOf[K comparable]() *recordOf[K] {
+	r := &recordOf[K]{seed: maphash.MakeSeed()}
+	for i := range r.shards {
+		r.shards[i].tasks = make(map[K]*task)
+	}
+	return r
+}
+
+func (r *recordOf[K]) shard(key K) *recordShard[K] {
+	return &r.shards[maphash.Comparable(r.seed, key)&(recordShards-1)]
+}
+
+// report whether the key is of the key type
+func (r *recordOf[K]) accepts(key interface{}) bool {
+	_, ok := key.(K)
+	return ok
+}
+
+// hash of the accepted key, stable for the record
+func (r *recordOf[K]) hash(key interface{}) uint64 {
+	return maphash.Comparable(r.seed, key.(K))
+}
+
+// get the task of the key
+func (r *recordOf[K]) Load(key interface{}) (*task, bool) {
+	k, ok := key.(K)
+	if !ok {
+		return nil, false
+	}
+	return r.load(k)
+}
+
+// get the task of the typed key, the key is not boxed
+func (r *recordOf[K]) load(key K) (*task, bool) {
+	s := r.shard(key)
+	s.RLock()
+	t, ok := s.tasks[key]
+	s.RUnlock()
+	return t, ok
+}
+
+// call fn with the task of the key under the read lock of its shard, report whether the key is recorded.
+// A task is recycled once it left the record, so fn can read it from any goroutine, see taskPool.
+func (r *recordOf[K]) view(key interface{}, fn func(t *task)) bool {
+	k, ok := key.(K)
+	if !ok {
+		return false
+	}
+	s := r.shard(k)
+	s.RLock()
+	defer s.RUnlock()
+	t, ok := s.tasks[k]
+	if ok {
+		fn(t)
+	}
+	return ok
+}
+
+// get the task of the key, or record t if the key is absent. The key must be accepted.
+func (r *recordOf[K]) LoadOrStore(key interface{}, t *task) (actual *task, loaded bool) {
+	k := key.(K)
+	s := r.shard(k)
+	s.Lock()
+	defer s.Unlock()
+	if old, ok := s.tasks[k]; ok {
+		return old, true
+	}
+	s.tasks[k] = t
+	return t, false
+}
+
+// delete the key if it still maps to t
+func (r *recordOf[K]) CompareAndDelete(key interface{}, t *task) bool {
+	k, ok := key.(K)
+	if !ok {
+		return false
+	}
+	s := r.shard(k)
+	s.Lock()
+	defer s.Unlock()
+	if s.tasks[k] != t {
+		return false
+	}
+	delete(s.tasks, k)
+	return true
+}
+
+// replace old by t under the key, report whether the key still mapped to old
+func (r *recordOf[K]) CompareAndSwap(key interface{}, old, t *task) bool {
+	k, ok := key.(K)
+	if !ok {
+		return false
+	}
+	s := r.shard(k)
+	s.Lock()
+	defer s.Unlock()
+	if s.tasks[k] != old {
+		return false
+	}
+	s.tasks[k] = t
+	return true
+}
+
+// delete the keys still mapping to the tasks taking each shard lock once, return how many were deleted
+func (r *recordOf[K]) deleteBatch(tasks []*task) int {
+	var byShard [recordShards][]*task
+	for _, t := range tasks {
+		k, ok := t.key.(K)
+		if !ok {
+			continue
+		}
+		i := maphash.Comparable(r.seed, k) & (recordShards - 1)
+		byShard[i] = append(byShard[i], t)
+	}
+	n := 0
+	for i, batch := range byShard {
+		if len(batch) == 0 {
+			continue
+		}
+		s := &r.shards[i]
+		s.Lock()
+		for _, t := range batch {
+			k := t.key.(K)
+			if s.tasks[k] == t {
+				delete(s.tasks, k)
+				n++
+			}
+		}
+		s.Unlock()
+	}
+	return n
+}
+
+// forget every task
+func (r *recordOf[K]) clear() {
+	for i := range r.shards {
+		s := &r.shards[i]
+		s.Lock()
+		s.tasks = make(map[K]*task)
+		s.Unlock()
+	}
+}
+
+// count the tasks of every shard
+func (r *recordOf[K]) Len() int {
+	n := 0
+	for i := range r.shards {
+		s := &r.shards[i]
+		s.RLock()
+		n += len(s.tasks)
+		s.RUnlock()
+	}
+	return n
+}
+
+// copy the tasks of every shard, fn is called without holding any lock
+func (r *recordOf[K]) Range(fn func(key interface{}, t *task) bool) {
+	tasks := make([]*task, 0, r.Len())
+	for i := range r.shards {
+		s := &r.shards[i]
+		s.RLock()
+		for _, t := range s.tasks {
+			tasks = append(tasks, t)
+		}
+		s.RUnlock()
+	}
+	for _, t := range tasks {
+		if !fn(t.key, t) {
+			return
+		}
+	}
+}
+
+// report whether the key can be used as a map key, a comparable type may still hold
+// a non comparable value in an interface so those kinds are compared under recover
+func keyComparable(key interface{}) (ok bool) {
+	t := reflect.TypeOf(key)
+	if !t.Comparable() {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Interface:
+	default:
+		return true
+	}
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	_ = key == key
+	return true
+}
@@ -0,0 +1,258 @@
+package timewheel
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRecordShards(t *testing.T) {
+	r := newTaskRecord()
+	tasks := make([]*task, 1000)
+	for i := range tasks {
+		tasks[i] = &task{key: i}
+		if _, loaded := r.LoadOrStore(i, tasks[i]); loaded {
+			t.Fatal(i)
+		}
+	}
+	if r.Len() != 1000 {
+		t.Fatal(r.Len())
+	}
+	n := 0
+	r.Range(func(key interface{}, v *task) bool {
+		if v != tasks[key.(int)] {
+			t.Fatal(key)
+		}
+		n++
+		return true
+	})
+	if n != 1000 {
+		t.Fatal(n)
+	}
+	if r.CompareAndDelete(1, tasks[2]) || !r.CompareAndDelete(1, tasks[1]) {
+		t.Fatal("compare and delete")
+	}
+	if n := r.deleteBatch(tasks[:10]); n != 9 || r.Len() != 990 {
+		t.Fatal(n, r.Len())
+	}
+}
+
+func TestRangeWhileTicking(t *testing.T) {
+	tw := New(2*time.Millisecond, 16)
+	tw.Start()
+	defer tw.Stop()
+	for i := 0; i < 16; i++ {
+		tw.AddTask(2*time.Millisecond, -1, i, nil, func(TaskData) {})
+	}
+	var stop int32
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; atomic.LoadInt32(&stop) == 0; i++ {
+			tw.AddTask(time.Duration(1+i%5)*time.Millisecond, 3, 64+i%64, nil, func(TaskData) {})
+			time.Sleep(50 * time.Microsecond)
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		time.Sleep(100 * time.Microsecond)
+		tw.Range(func(key interface{}, info TaskInfo) bool {
+			if info.Key != key {
+				t.Fatal(key, info.Key)
+			}
+			return true
+		})
+		tw.RemoveTasksWhere(func(key interface{}, info TaskInfo) bool {
+			return key.(int) >= 64 && key.(int)%7 == 0
+		})
+	}
+	atomic.StoreInt32(&stop, 1)
+	wg.Wait()
+	checkInvariants(t, tw)
+	tw.Stop()
+	// the tasks left are listed once the wheel goroutine exited
+	n := 0
+	tw.Range(func(interface{}, TaskInfo) bool {
+		n++
+		return true
+	})
+	if n != tw.Len() {
+		t.Fatal(n, tw.Len())
+	}
+}
+
+// record under a single lock, the baseline of BenchmarkRecordContention
+type lockedRecord struct {
+	sync.RWMutex
+	tasks map[interface{}]*task
+}
+
+func (r *lockedRecord) Load(key interface{}) (*task, bool) {
+	r.RLock()
+	defer r.RUnlock()
+	t, ok := r.tasks[key]
+	return t, ok
+}
+
+func (r *lockedRecord) LoadOrStore(key interface{}, t *task) (*task, bool) {
+	r.Lock()
+	defer r.Unlock()
+	if old, ok := r.tasks[key]; ok {
+		return old, true
+	}
+	r.tasks[key] = t
+	return t, false
+}
+
+func (r *lockedRecord) CompareAndDelete(key interface{}, t *task) bool {
+	r.Lock()
+	defer r.Unlock()
+	if r.tasks[key] != t {
+		return false
+	}
+	delete(r.tasks, key)
+	return true
+}
+
+// 64 goroutines adding, looking up and removing keys: go test -bench RecordContention
+func BenchmarkRecordContention(b *testing.B) {
+	type record interface {
+		Load(key interface{}) (*task, bool)
+		LoadOrStore(key interface{}, t *task) (*task, bool)
+		CompareAndDelete(key interface{}, t *task) bool
+	}
+	for _, c := range []struct {
+		name string
+		r    record
+	}{
+		{"single", &lockedRecord{tasks: make(map[interface{}]*task)}},
+		{"sharded", newTaskRecord()},
+	} {
+		b.Run(c.name, func(b *testing.B) {
+			var id int64
+			procs := runtime.GOMAXPROCS(0)
+			b.SetParallelism((64 + procs - 1) / procs)
+			b.RunParallel(func(pb *testing.PB) {
+				g := atomic.AddInt64(&id, 1)
+				keys := make([]interface{}, 128)
+				for i := range keys {
+					keys[i] = fmt.Sprint(g, "-", i)
+				}
+				t := &task{}
+				for i := 0; pb.Next(); i++ {
+					key := keys[i%len(keys)]
+					switch i % 4 {
+					case 0:
+						c.r.LoadOrStore(key, t)
+					case 3:
+						c.r.CompareAndDelete(key, t)
+					default:
+						c.r.Load(key)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestRecordBatchedDeletes(t *testing.T) {
+	c := newFakeClock()
+	tw := New(10*time.Millisecond, 8, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var runs int64
+	for k := 0; k < 1000; k++ {
+		tw.AddTask(10*time.Millisecond, 1, k, nil, func(TaskData) { atomic.AddInt64(&runs, 1) })
+	}
+	tw.AddTask(time.Hour, 1, "left", nil, func(TaskData) {})
+	settle(tw)
+	c.Tick(10 * time.Millisecond)
+	c.Tick(10 * time.Millisecond)
+	settle(tw)
+	waitCount(t, &runs, 1000)
+	// the record catches up once the slot scan is done
+	if n := tw.Len(); n != 1 {
+		t.Fatal(n)
+	}
+	for k := 0; k < 1000; k++ {
+		if tw.HasTask(k) {
+			t.Fatal(k)
+		}
+	}
+	if !tw.HasTask("left") {
+		t.Fatal("left")
+	}
+}
+
+// a slot of 50k one-shot tasks ticking under 8 HasTask callers: go test -bench HotSlot
+func BenchmarkHotSlotHasTask(b *testing.B) { benchHotSlot(b, 8) }
+func BenchmarkHotSlotAlone(b *testing.B)   { benchHotSlot(b, 0) }
+
+func benchHotSlot(b *testing.B, readers int) {
+	for i := 0; i < b.N; i++ {
+		c := newFakeClock()
+		tw := New(10*time.Millisecond, 8, WithClock(c), WithScanChunk(1<<20))
+		tw.Start()
+		for k := 0; k < 50000; k++ {
+			tw.AddTask(10*time.Millisecond, 1, k, nil, func(TaskData) {})
+		}
+		settle(tw)
+		var stop int32
+		var calls int64
+		var wg sync.WaitGroup
+		for g := 0; g < readers; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for k := g; atomic.LoadInt32(&stop) == 0; k++ {
+					tw.HasTask(k % 50000)
+					atomic.AddInt64(&calls, 1)
+				}
+			}(g)
+		}
+		begin := time.Now()
+		c.Tick(10 * time.Millisecond)
+		c.Tick(10 * time.Millisecond)
+		tw.exec(func() {})
+		b.ReportMetric(float64(time.Since(begin).Microseconds()), "tick-us")
+		atomic.StoreInt32(&stop, 1)
+		wg.Wait()
+		b.ReportMetric(float64(calls), "hastask-calls")
+		tw.Stop()
+	}
+}
+
+func TestKeyNotComparable(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	type withSlice struct{ a interface{} }
+	job := func(TaskData) {}
+	for _, k := range []interface{}{[]byte("x"), withSlice{[]int{1}}, map[int]int{}} {
+		if err := tw.AddTask(time.Second, 1, k, nil, job); err != ErrKeyNotComparable {
+			t.Fatalf("add %T: %v", k, err)
+		}
+		if err := tw.RemoveTask(k); err != ErrKeyNotComparable {
+			t.Fatalf("remove %T: %v", k, err)
+		}
+		if err := tw.UpdateTask(k, time.Second, nil); err != ErrKeyNotComparable {
+			t.Fatalf("update %T: %v", k, err)
+		}
+		if tw.HasTask(k) {
+			t.Fatalf("has %T", k)
+		}
+	}
+	// the wheel is still running
+	var runs int64
+	if err := tw.AddTask(time.Second, 1, withSlice{1}, nil, func(TaskData) { atomic.AddInt64(&runs, 1) }); err != nil {
+		t.Fatal(err)
+	}
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	waitCount(t, &runs, 1)
+}
@@ -0,0 +1,65 @@
+// Package redislock share the execution lock of the time wheels of several instances through redis.
+//
+//	locker := redislock.New(client, "myapp")
+//	tw := timewheel.New(time.Second, 60, timewheel.WithLocker(locker, 0))
+//
+// A lock is a key set with SET NX PX holding a token of the instance, Unlock only deletes the keys
+// holding the token so an expired lock taken by another instance is left alone.
+package redislock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/nosixtools/timewheel"
+	"github.com/redis/go-redis/v9"
+)
+
+// delete the key if it holds the token
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Locker a timewheel.Locker backed by redis
+type Locker struct {
+	client  redis.UniversalClient
+	prefix  string
+	token   string
+	timeout time.Duration
+}
+
+var _ timewheel.Locker = (*Locker)(nil)
+
+// New create a locker, the redis keys are prefixed with prefix
+func New(client redis.UniversalClient, prefix string) *Locker {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return &Locker{
+		client:  client,
+		prefix:  prefix + ":timewheel:lock:",
+		token:   hex.EncodeToString(b),
+		timeout: 5 * time.Second,
+	}
+}
+
+func (l *Locker) ctx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), l.timeout)
+}
+
+// TryLock implement timewheel.Locker
+func (l *Locker) TryLock(name string, ttl time.Duration) (bool, error) {
+	ctx, cancel := l.ctx()
+	defer cancel()
+	return l.client.SetNX(ctx, l.prefix+name, l.token, ttl).Result()
+}
+
+// Unlock implement timewheel.Locker
+func (l *Locker) Unlock(name string) error {
+	ctx, cancel := l.ctx()
+	defer cancel()
+	return unlockScript.Run(ctx, l.client, []string{l.prefix + name}, l.token).Err()
+}
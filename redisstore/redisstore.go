@@ -0,0 +1,296 @@
+// Package redisstore store the named tasks of a time wheel in redis.
+//
+//	store := redisstore.New(client, "myapp")
+//	tw := timewheel.New(time.Second, 60,
+//		timewheel.WithJobRegistry(registry),
+//		timewheel.WithStore(store, 10*time.Minute))
+//
+// The writes are Lua scripts, so each of them is atomic. The updates and deletes of a run are
+// bound to the generation of the task and rejected once the task was removed and added again.
+// The tasks returned by Due are claimed for the lease, see WithLease, the wheels sharing the
+// prefix do not load the same task twice. The updates renew the claim, so a task whose interval
+// exceeds the lease can be claimed by another wheel: keep the lease above the longest interval.
+// A task added within the horizon is loaded by the wheel adding it without being claimed, the
+// wheels sharing a prefix should add their tasks beyond the horizon.
+//
+// The redis keys of a prefix share a hash tag, they live in the same slot of a cluster.
+//
+// The task keys must be strings and the task data is stored as JSON, so the keys of
+// the restored TaskData are strings.
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nosixtools/timewheel"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultLease time a task returned by Due stays claimed, see WithLease
+const DefaultLease = time.Hour
+
+// Option configure the store when calling New
+type Option func(*Store)
+
+// WithLease set the time a task returned by Due stays claimed without being updated, default is DefaultLease
+func WithLease(d time.Duration) Option {
+	return func(s *Store) {
+		if d > 0 {
+			s.lease = d
+		}
+	}
+}
+
+// Store a timewheel.Store backed by redis, the specs live in a hash, the generations in another,
+// the next run times in a sorted set and the claimed tasks in a sorted set by lease expiry
+type Store struct {
+	client  redis.UniversalClient
+	tasks   string
+	gens    string
+	due     string
+	claims  string
+	lease   time.Duration
+	timeout time.Duration
+	now     func() time.Time
+}
+
+var _ timewheel.Store = (*Store)(nil)
+
+// New create a store, the redis keys are prefixed with prefix
+func New(client redis.UniversalClient, prefix string, opts ...Option) *Store {
+	tag := "{" + prefix + "}"
+	s := &Store{
+		client:  client,
+		tasks:   tag + ":timewheel:tasks",
+		gens:    tag + ":timewheel:gens",
+		due:     tag + ":timewheel:due",
+		claims:  tag + ":timewheel:claims",
+		lease:   DefaultLease,
+		timeout: 5 * time.Second,
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// the generations are compared as decimal strings, "0" matches any
+
+// KEYS: tasks, gens, due. ARGV: key, record, next, gen
+var addScript = redis.NewScript(`
+if redis.call('HSETNX', KEYS[1], ARGV[1], ARGV[2]) == 0 then
+	return 0
+end
+if ARGV[4] ~= '0' then
+	redis.call('HSET', KEYS[2], ARGV[1], ARGV[4])
+end
+redis.call('ZADD', KEYS[3], ARGV[3], ARGV[1])
+return 1
+`)
+
+// KEYS: tasks, gens, due, claims. ARGV: key, record, next, gen, lease expiry
+var updateScript = redis.NewScript(`
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 0 then
+	return -1
+end
+local gen = redis.call('HGET', KEYS[2], ARGV[1])
+if ARGV[4] ~= '0' and gen and gen ~= '0' and gen ~= ARGV[4] then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+if ARGV[4] ~= '0' and not gen then
+	redis.call('HSET', KEYS[2], ARGV[1], ARGV[4])
+end
+if redis.call('ZSCORE', KEYS[4], ARGV[1]) then
+	redis.call('ZADD', KEYS[4], ARGV[5], ARGV[1])
+else
+	redis.call('ZADD', KEYS[3], ARGV[3], ARGV[1])
+end
+return 1
+`)
+
+// KEYS: tasks, gens, due, claims. ARGV: key, gen
+var deleteScript = redis.NewScript(`
+local gen = redis.call('HGET', KEYS[2], ARGV[1])
+if ARGV[2] ~= '0' and gen and gen ~= '0' and gen ~= ARGV[2] then
+	return 0
+end
+redis.call('HDEL', KEYS[2], ARGV[1])
+redis.call('ZREM', KEYS[3], ARGV[1])
+redis.call('ZREM', KEYS[4], ARGV[1])
+return redis.call('HDEL', KEYS[1], ARGV[1])
+`)
+
+// KEYS: tasks, due, claims. ARGV: before, now, lease expiry
+var dueScript = redis.NewScript(`
+local keys = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+for _, k in ipairs(redis.call('ZRANGEBYSCORE', KEYS[3], '-inf', ARGV[2])) do
+	table.insert(keys, k)
+end
+local records = {}
+for _, k in ipairs(keys) do
+	redis.call('ZREM', KEYS[2], k)
+	redis.call('ZADD', KEYS[3], ARGV[3], k)
+	table.insert(records, redis.call('HGET', KEYS[1], k))
+end
+return records
+`)
+
+// stored form of timewheel.TaskSpec
+type record struct {
+	Key      string                 `json:"key"`
+	Gen      uint64                 `json:"gen,omitempty"`
+	Interval time.Duration          `json:"interval"`
+	Times    int                    `json:"times"`
+	Next     time.Time              `json:"next"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+	JobName  string                 `json:"job_name"`
+	Tags     []string               `json:"tags,omitempty"`
+	Priority int                    `json:"priority,omitempty"`
+	Until    time.Time              `json:"until,omitempty"`
+	Expires  time.Time              `json:"expires,omitempty"`
+	Aligned  bool                   `json:"aligned,omitempty"`
+}
+
+func encode(spec timewheel.TaskSpec) (string, []byte, error) {
+	key, ok := spec.Key.(string)
+	if !ok {
+		return "", nil, fmt.Errorf("redisstore: key %v is not a string", spec.Key)
+	}
+	r := record{Key: key, Gen: spec.Gen, Interval: spec.Interval, Times: spec.Times, Next: spec.Next, JobName: spec.JobName, Tags: spec.Tags, Priority: spec.Priority, Until: spec.Until, Expires: spec.Expires,
+		Aligned: spec.Aligned}
+	if spec.Data != nil {
+		r.Data = make(map[string]interface{}, len(spec.Data))
+		for k, v := range spec.Data {
+			r.Data[fmt.Sprint(k)] = v
+		}
+	}
+	b, err := json.Marshal(r)
+	return key, b, err
+}
+
+func decode(b []byte, now time.Time) (timewheel.TaskSpec, error) {
+	var r record
+	if err := json.Unmarshal(b, &r); err != nil {
+		return timewheel.TaskSpec{}, err
+	}
+	spec := timewheel.TaskSpec{Key: r.Key, Gen: r.Gen, Interval: r.Interval, Times: r.Times, Next: r.Next, JobName: r.JobName, Tags: r.Tags, Priority: r.Priority, Until: r.Until, Expires: r.Expires,
+		Aligned: r.Aligned}
+	if d := r.Next.Sub(now); d > 0 {
+		spec.Delay = d
+	}
+	if r.Data != nil {
+		spec.Data = make(timewheel.TaskData, len(r.Data))
+		for k, v := range r.Data {
+			spec.Data[k] = v
+		}
+	}
+	return spec, nil
+}
+
+func (s *Store) ctx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), s.timeout)
+}
+
+func millis(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
+
+// Add implement timewheel.Store
+func (s *Store) Add(spec timewheel.TaskSpec) error {
+	key, b, err := encode(spec)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := s.ctx()
+	defer cancel()
+	added, err := addScript.Run(ctx, s.client, []string{s.tasks, s.gens, s.due},
+		key, b, millis(spec.Next), strconv.FormatUint(spec.Gen, 10)).Int()
+	if err != nil {
+		return err
+	}
+	if added == 0 {
+		return timewheel.ErrDuplicateKey
+	}
+	return nil
+}
+
+// Get implement timewheel.Store
+func (s *Store) Get(key interface{}) (timewheel.TaskSpec, bool, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+	b, err := s.client.HGet(ctx, s.tasks, fmt.Sprint(key)).Bytes()
+	if err == redis.Nil {
+		return timewheel.TaskSpec{}, false, nil
+	}
+	if err != nil {
+		return timewheel.TaskSpec{}, false, err
+	}
+	spec, err := decode(b, s.now())
+	return spec, err == nil, err
+}
+
+// Update implement timewheel.Store
+func (s *Store) Update(spec timewheel.TaskSpec) error {
+	key, b, err := encode(spec)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := s.ctx()
+	defer cancel()
+	updated, err := updateScript.Run(ctx, s.client, []string{s.tasks, s.gens, s.due, s.claims},
+		key, b, millis(spec.Next), strconv.FormatUint(spec.Gen, 10), millis(s.now().Add(s.lease))).Int()
+	if err != nil {
+		return err
+	}
+	switch updated {
+	case -1:
+		return timewheel.ErrTaskNotFound
+	case 0:
+		return timewheel.ErrStaleGeneration
+	}
+	return nil
+}
+
+// Delete implement timewheel.Store
+func (s *Store) Delete(key interface{}, gen uint64) (bool, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+	deleted, err := deleteScript.Run(ctx, s.client, []string{s.tasks, s.gens, s.due, s.claims},
+		fmt.Sprint(key), strconv.FormatUint(gen, 10)).Int()
+	return deleted > 0, err
+}
+
+// Due implement timewheel.Store, the due tasks are moved to the claims in the same script
+func (s *Store) Due(before time.Time) ([]timewheel.TaskSpec, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+	now := s.now()
+	values, err := dueScript.Run(ctx, s.client, []string{s.tasks, s.due, s.claims},
+		millis(before), millis(now), millis(now.Add(s.lease))).Slice()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			err = nil
+		}
+		return nil, err
+	}
+	specs := make([]timewheel.TaskSpec, 0, len(values))
+	for _, v := range values {
+		str, ok := v.(string)
+		if !ok {
+			continue
+		}
+		spec, err := decode([]byte(str), now)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
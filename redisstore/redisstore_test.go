@@ -0,0 +1,196 @@
+package redisstore
+
+import (
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/nosixtools/timewheel"
+	"github.com/redis/go-redis/v9"
+)
+
+func newStore(t *testing.T, opts ...Option) (*Store, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return New(client, "test", opts...), mr
+}
+
+func keys(specs []timewheel.TaskSpec) []string {
+	var ks []string
+	for _, spec := range specs {
+		ks = append(ks, spec.Key.(string))
+	}
+	sort.Strings(ks)
+	return ks
+}
+
+func TestRedisStoreBasic(t *testing.T) {
+	s, _ := newStore(t)
+	now := time.Now()
+	if err := s.Add(timewheel.TaskSpec{Key: "a", Interval: time.Second, Times: 3, Next: now.Add(time.Second), JobName: "j"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(timewheel.TaskSpec{Key: "a", Next: now}); err != timewheel.ErrDuplicateKey {
+		t.Fatal(err)
+	}
+	if err := s.Add(timewheel.TaskSpec{Key: 1}); err == nil {
+		t.Fatal("int key")
+	}
+	s.Add(timewheel.TaskSpec{Key: "b", Interval: time.Hour, Times: -1, Next: now.Add(time.Hour), JobName: "j", Data: timewheel.TaskData{"x": 1.0}})
+	spec, ok, err := s.Get("b")
+	if !ok || err != nil || spec.Data["x"] != 1.0 || spec.Delay < 59*time.Minute {
+		t.Fatal(spec, ok, err)
+	}
+	if err := s.Update(timewheel.TaskSpec{Key: "a", Interval: time.Second, Times: 2, Next: now.Add(2 * time.Minute), JobName: "j"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Update(timewheel.TaskSpec{Key: "zz", Next: now}); err != timewheel.ErrTaskNotFound {
+		t.Fatal(err)
+	}
+	if due, _ := s.Due(now.Add(time.Minute)); len(due) != 0 {
+		t.Fatal("due", due)
+	}
+	due, err := s.Due(now.Add(3 * time.Minute))
+	if err != nil || len(due) != 1 || due[0].Times != 2 {
+		t.Fatal("due", due, err)
+	}
+	if ok, _ := s.Delete("b", 0); !ok {
+		t.Fatal("delete")
+	}
+	if ok, _ := s.Delete("b", 0); ok {
+		t.Fatal("delete absent")
+	}
+	if _, ok, _ := s.Get("b"); ok {
+		t.Fatal("deleted task found")
+	}
+}
+
+func TestRedisStoreGeneration(t *testing.T) {
+	s, _ := newStore(t)
+	now := time.Now()
+	s.Add(timewheel.TaskSpec{Key: "a", Gen: 1, Interval: time.Second, Times: -1, Next: now, JobName: "j"})
+	if err := s.Update(timewheel.TaskSpec{Key: "a", Gen: 2, Next: now}); err != timewheel.ErrStaleGeneration {
+		t.Fatal("stale update", err)
+	}
+	if ok, err := s.Delete("a", 2); ok || err != nil {
+		t.Fatal("stale delete", ok, err)
+	}
+	if err := s.Update(timewheel.TaskSpec{Key: "a", Gen: 1, Times: 5, Next: now}); err != nil {
+		t.Fatal(err)
+	}
+	// 0 matches any generation
+	if err := s.Update(timewheel.TaskSpec{Key: "a", Times: 4, Next: now}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.Delete("a", 1); !ok || err != nil {
+		t.Fatal("delete", ok, err)
+	}
+	// a late run of the removed task does not touch the task added again
+	s.Add(timewheel.TaskSpec{Key: "a", Gen: 3, Times: 7, Next: now})
+	if err := s.Update(timewheel.TaskSpec{Key: "a", Gen: 1, Times: 6, Next: now}); err != timewheel.ErrStaleGeneration {
+		t.Fatal("late update", err)
+	}
+	if ok, _ := s.Delete("a", 1); ok {
+		t.Fatal("late delete")
+	}
+	if spec, _, _ := s.Get("a"); spec.Gen != 3 || spec.Times != 7 {
+		t.Fatal(spec)
+	}
+}
+
+func TestRedisStoreClaims(t *testing.T) {
+	s, _ := newStore(t, WithLease(time.Minute))
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	s.Add(timewheel.TaskSpec{Key: "a", Times: -1, Interval: time.Second, Next: now, JobName: "j"})
+	s.Add(timewheel.TaskSpec{Key: "b", Times: -1, Interval: time.Second, Next: now, JobName: "j"})
+	other := New(s.client, "test", WithLease(time.Minute))
+	other.now = s.now
+	due, _ := s.Due(now.Add(time.Second))
+	if ks := keys(due); len(ks) != 2 {
+		t.Fatal("due", ks)
+	}
+	if due, _ = other.Due(now.Add(time.Second)); len(due) != 0 {
+		t.Fatal("claimed twice", keys(due))
+	}
+	// the update of a run renews the claim
+	now = now.Add(50 * time.Second)
+	s.Update(timewheel.TaskSpec{Key: "a", Times: -1, Interval: time.Second, Next: now, JobName: "j"})
+	now = now.Add(20 * time.Second)
+	due, _ = other.Due(now.Add(time.Second))
+	if ks := keys(due); len(ks) != 1 || ks[0] != "b" {
+		t.Fatal("expired claims", ks)
+	}
+	s.Delete("a", 0)
+	now = now.Add(2 * time.Minute)
+	if due, _ = other.Due(now); len(due) != 1 || keys(due)[0] != "b" {
+		t.Fatal("deleted task claimed", keys(due))
+	}
+}
+
+func newWheel(s *Store, horizon time.Duration, runs *int64) *timewheel.TimeWheel {
+	reg := timewheel.NewJobRegistry(false)
+	reg.Register("count", func(timewheel.TaskData) { atomic.AddInt64(runs, 1) })
+	return timewheel.New(10*time.Millisecond, 100, timewheel.WithJobRegistry(reg), timewheel.WithStore(s, horizon))
+}
+
+func TestRedisStoreWheel(t *testing.T) {
+	s, _ := newStore(t, WithLease(time.Second))
+	var runs int64
+	tw := newWheel(s, time.Hour, &runs)
+	tw.Start()
+	tw.AddNamedTask(50*time.Millisecond, 100, "fast", "count", nil)
+	tw.AddNamedTask(2*time.Second, 1, "slow", "count", nil)
+	tw.AddNamedTask(time.Second, 1, "cancel", "count", nil)
+	if err := tw.RemoveTask("cancel"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(180 * time.Millisecond)
+	tw.Stop()
+	before := atomic.LoadInt64(&runs)
+	if _, ok, _ := s.Get("cancel"); ok {
+		t.Fatal("removed task stored")
+	}
+	fast, ok, err := s.Get("fast")
+	if !ok || err != nil || fast.Times > 100-int(before)+1 || fast.Times < 90 {
+		t.Fatal("fast", fast.Times, before, ok, err)
+	}
+
+	// restart once the claims of the stopped wheel expired
+	time.Sleep(1100 * time.Millisecond)
+	var runs2 int64
+	tw2 := newWheel(s, time.Hour, &runs2)
+	tw2.Start()
+	defer tw2.Stop()
+	time.Sleep(150 * time.Millisecond)
+	if !tw2.HasTask("fast") || !tw2.HasTask("slow") || atomic.LoadInt64(&runs2) == 0 {
+		t.Fatal("not resumed", atomic.LoadInt64(&runs2))
+	}
+}
+
+func TestRedisStoreHorizon(t *testing.T) {
+	s, _ := newStore(t)
+	var runs int64
+	tw := newWheel(s, 200*time.Millisecond, &runs)
+	tw.Start()
+	defer tw.Stop()
+	if err := tw.AddNamedTask(400*time.Millisecond, 1, "later", "count", nil); err != nil {
+		t.Fatal(err)
+	}
+	if tw.HasTask("later") {
+		t.Fatal("task beyond the horizon loaded")
+	}
+	if _, ok, _ := s.Get("later"); !ok {
+		t.Fatal("task not stored")
+	}
+	time.Sleep(600 * time.Millisecond)
+	if atomic.LoadInt64(&runs) != 1 {
+		t.Fatal("refilled task runs", atomic.LoadInt64(&runs))
+	}
+	if _, ok, _ := s.Get("later"); ok {
+		t.Fatal("task kept after its last run")
+	}
+}
@@ -0,0 +1,59 @@
+package timewheel
+
+// run the job in place of the wheel goroutine, see RunInline: the wheel goroutine stops ticking until the job
+// returns and only serves the control calls meanwhile, so the calls the job makes take effect before the next tick
+func (tw *TimeWheel) runInline(r *jobRun) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tw.runJob(r)
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		case task := <-tw.addTaskChannel:
+			tw.addTask(task)
+		case req := <-tw.removeTaskChannel:
+			tw.drainAdds()
+			tw.reply(req.reply, func() error {
+				return tw.removeTask(req)
+			})
+		case req := <-tw.updateTaskChannel:
+			tw.drainAdds()
+			tw.reply(req.reply, func() error {
+				return tw.updateTask(req)
+			})
+		case fn := <-tw.execChannel:
+			tw.drainAdds()
+			fn()
+		case <-tw.stopChannel:
+			// the calls of the job return ErrWheelStopped
+			<-done
+			return
+		}
+		if len(tw.finished) > 0 || len(tw.swept) > 0 {
+			tw.flushFinished()
+		}
+	}
+}
+
+// hand the remove request to the wheel goroutine and wait for its reply
+func (tw *TimeWheel) sendRemove(req *removeRequest) error {
+	select {
+	case tw.removeTaskChannel <- req:
+	case <-tw.stopChannel:
+		return ErrWheelStopped
+	}
+	return <-req.reply
+}
+
+// hand the update request to the wheel goroutine and wait for its reply
+func (tw *TimeWheel) sendUpdate(req *updateRequest) error {
+	select {
+	case tw.updateTaskChannel <- req:
+	case <-tw.stopChannel:
+		return ErrWheelStopped
+	}
+	return <-req.reply
+}
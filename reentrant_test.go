@@ -0,0 +1,147 @@
+package timewheel
+
+import (
+	"testing"
+	"time"
+)
+
+// keep the single worker busy until block is closed, the next runs go inline
+func occupyWorker(t *testing.T, tw *TimeWheel, c *fakeClock, block chan struct{}) {
+	t.Helper()
+	started := make(chan struct{})
+	if err := tw.AddTask(time.Second, 1, "blocker", nil, func(TaskData) {
+		close(started)
+		<-block
+	}); err != nil {
+		t.Fatal(err)
+	}
+	settle(tw)
+	for {
+		c.Tick(time.Second)
+		select {
+		case <-started:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestInlineJobReentrant(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 8, WithClock(c), WithWorkers(1, 0, RunInline))
+	tw.Start()
+	defer tw.Stop()
+	block := make(chan struct{})
+	occupyWorker(t, tw, c, block)
+	done := make(chan error, 3)
+	if err := tw.AddTask(time.Second, -1, "self", nil, func(TaskData) {
+		done <- tw.AddTask(time.Second, 1, "next", nil, func(TaskData) {})
+		done <- tw.UpdateTask("next", 2*time.Second, nil)
+		done <- tw.RemoveTask("self")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatal(i, err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("deadlock")
+		}
+	}
+	close(block)
+	settle(tw)
+	if tw.HasTask("self") || !tw.HasTask("next") {
+		t.Fatal(tw.HasTask("self"), tw.HasTask("next"))
+	}
+	if s := tw.WorkerStats(); s.Inline == 0 {
+		t.Fatal("not inline")
+	}
+}
+
+func TestInlineJobStop(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 8, WithClock(c), WithWorkers(1, 0, RunInline))
+	tw.Start()
+	block := make(chan struct{})
+	defer close(block)
+	occupyWorker(t, tw, c, block)
+	started, stopped := make(chan struct{}), make(chan struct{})
+	done := make(chan error, 1)
+	if err := tw.AddTask(time.Second, 1, "stopper", nil, func(TaskData) {
+		close(started)
+		<-stopped
+		done <- tw.AddTask(time.Second, 1, "late", nil, func(TaskData) {})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// the wheel does not tick while the job runs inline
+	for running := false; !running; {
+		c.Tick(time.Second)
+		select {
+		case <-started:
+			running = true
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	settle(tw)
+	tw.Stop()
+	close(stopped)
+	select {
+	case err := <-done:
+		if err != ErrWheelStopped {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("deadlock")
+	}
+}
+
+// the wheel goroutine waits for the single worker while the job on it calls the wheel
+func TestBlockedJobReentrant(t *testing.T) {
+	tw := New(5*time.Millisecond, 16, WithWorkers(1, 0, Block))
+	tw.Start()
+	defer tw.Stop()
+	if err := tw.AddTask(5*time.Millisecond, -1, "busy", nil, func(TaskData) {}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 3)
+	if err := tw.AddTask(5*time.Millisecond, 1, "slow", nil, func(TaskData) {
+		// the busy task is due meanwhile and fills the queue
+		time.Sleep(50 * time.Millisecond)
+		done <- tw.UpdateTask("busy", 10*time.Millisecond, nil)
+		found := ErrTaskNotFound
+		tw.Range(func(key interface{}, _ TaskInfo) bool {
+			if key == "busy" {
+				found = nil
+			}
+			return true
+		})
+		done <- found
+		done <- tw.RemoveTask("busy")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatal(i, err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("deadlock")
+		}
+	}
+	settle(tw)
+	if tw.HasTask("busy") {
+		t.Fatal("busy task not removed")
+	}
+	if tw.WorkerStats().Blocked == 0 {
+		t.Fatal("queue never full")
+	}
+	checkInvariants(t, tw)
+}
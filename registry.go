@@ -0,0 +1,107 @@
+package timewheel
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// JobRegistry jobs registered under names, so tasks can be persisted and recreated by job name
+type JobRegistry struct {
+	mu           sync.RWMutex
+	jobs         map[string]Job
+	allowReplace bool
+}
+
+// NewJobRegistry create a empty registry, allowReplace decide whether registering a name twice
+// replaces the job or fails
+func NewJobRegistry(allowReplace bool) *JobRegistry {
+	return &JobRegistry{jobs: make(map[string]Job), allowReplace: allowReplace}
+}
+
+// Register register the job under name
+func (r *JobRegistry) Register(name string, job Job) error {
+	if name == "" || job == nil {
+		return fmt.Errorf("%w, illegal job params", ErrInvalidParams)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.jobs[name]; ok && !r.allowReplace {
+		return fmt.Errorf("job %q already registered", name)
+	}
+	r.jobs[name] = job
+	return nil
+}
+
+// Lookup get the job registered under name
+func (r *JobRegistry) Lookup(name string) (Job, error) {
+	r.mu.RLock()
+	job, ok := r.jobs[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownJob, name)
+	}
+	return job, nil
+}
+
+// Resolver resolve the restored tasks by their job name
+func (r *JobRegistry) Resolver() JobResolver {
+	return func(spec TaskSpec) (Job, error) {
+		return r.Lookup(spec.JobName)
+	}
+}
+
+// WithJobRegistry set the registry used by AddNamedTask
+func WithJobRegistry(r *JobRegistry) Option {
+	return func(tw *TimeWheel) {
+		tw.registry = r
+	}
+}
+
+// AddNamedTask add new task running the job registered under jobName,
+// the name is kept on the task so Snapshot can describe it
+func (tw *TimeWheel) AddNamedTask(interval time.Duration, times int, key interface{}, jobName string, data TaskData) error {
+	if tw.registry == nil {
+		return ErrNoJobRegistry
+	}
+	job, err := tw.registry.Lookup(jobName)
+	if err != nil {
+		return err
+	}
+	task, err := tw.newTask(interval, times, key, data, wrapJob(job))
+	if err != nil {
+		return err
+	}
+	task.jobName = jobName
+	return tw.submitNamed(task)
+}
+
+// persist the named task then send it to the wheel goroutine
+func (tw *TimeWheel) submitNamed(task *task) error {
+	key, gen := task.key, task.gen
+	if tw.store != nil {
+		// the refill must not load the stored task before it reaches the wheel
+		tw.storePending.add(key)
+		defer tw.storePending.done(key)
+		keep, err := tw.storeAdd(task)
+		if err != nil || !keep {
+			// loaded by the refill when it gets close
+			tw.dropTask(task)
+			return err
+		}
+	}
+	logged := false
+	if w := tw.wal.Load(); w != nil {
+		if err := w.append(walRecord{Op: walAdd, Spec: task.spec(tw.clock.Now()), Gen: gen}); err != nil {
+			tw.dropTask(task)
+			return err
+		}
+		logged = true
+	}
+	err := tw.submit(context.Background(), task)
+	if err != nil && logged {
+		tw.walAppend(walRecord{Op: walRemove, Spec: TaskSpec{Key: key}, Gen: gen})
+	}
+	return err
+}
@@ -0,0 +1,80 @@
+package timewheel
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJobRegistry(t *testing.T) {
+	r := NewJobRegistry(false)
+	if err := r.Register("a", func(TaskData) {}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register("a", func(TaskData) {}); err == nil {
+		t.Fatal("registered twice")
+	}
+	if err := r.Register("", func(TaskData) {}); err == nil {
+		t.Fatal("empty name")
+	}
+	if _, err := r.Lookup("b"); !errors.Is(err, ErrUnknownJob) {
+		t.Fatal(err)
+	}
+
+	got := make(chan string, 1)
+	r = NewJobRegistry(true)
+	r.Register("a", func(TaskData) { got <- "old" })
+	if err := r.Register("a", func(TaskData) { got <- "new" }); err != nil {
+		t.Fatal(err)
+	}
+	job, _ := r.Lookup("a")
+	job(nil)
+	if v := <-got; v != "new" {
+		t.Fatal(v)
+	}
+}
+
+func TestAddNamedTask(t *testing.T) {
+	if err := New(time.Second, 4).AddNamedTask(time.Second, 1, "k", "send", nil); err != ErrNoJobRegistry {
+		t.Fatal(err)
+	}
+	got := make(chan string, 10)
+	r := NewJobRegistry(false)
+	r.Register("send", func(data TaskData) { got <- "send " + data["to"].(string) })
+	r.Register("other", func(TaskData) { got <- "other" })
+	c := newFakeClock()
+	tw := New(time.Second, 8, WithClock(c), WithJobRegistry(r))
+	tw.Start()
+	if err := tw.AddNamedTask(time.Second, 1, "k", "unknown", nil); !errors.Is(err, ErrUnknownJob) {
+		t.Fatal(err)
+	}
+	if tw.HasTask("k") {
+		t.Fatal("task of an unknown job added")
+	}
+	if err := tw.AddNamedTask(3*time.Second, 2, "k", "send", TaskData{"to": "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	specs, err := tw.Snapshot()
+	tw.Stop()
+	if err != nil || len(specs) != 1 || specs[0].JobName != "send" {
+		t.Fatal(specs, err)
+	}
+
+	// the restored task runs the job of its name
+	tw = New(time.Second, 8, WithClock(c), WithJobRegistry(r))
+	tw.Start()
+	defer tw.Stop()
+	if err := tw.Restore(specs, r.Resolver()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 8; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	if len(got) != 2 || <-got != "send bob" || <-got != "send bob" {
+		t.Fatal(len(got))
+	}
+	if specs[0].JobName = "gone"; tw.Restore(specs, r.Resolver()) == nil {
+		t.Fatal("restored an unknown job")
+	}
+}
@@ -0,0 +1,99 @@
+package timewheel
+
+import "sync/atomic"
+
+// RemoveTasks remove the tasks of keys in a single pass through the wheel goroutine, the record locks are
+// taken once per shard rather than once per key. removed counts the tasks removed, missing holds the keys
+// no task was found for. Duplicate keys are removed once, nil and not comparable keys are reported missing.
+func (tw *TimeWheel) RemoveTasks(keys []interface{}) (removed int, missing []interface{}) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	if tw.isStopped() {
+		return 0, append(missing, keys...)
+	}
+
+	uniq := make([]interface{}, 0, len(keys))
+	seen := make(map[interface{}]struct{}, len(keys))
+	for _, key := range keys {
+		if key == nil || !keyComparable(key) {
+			missing = append(missing, key)
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		uniq = append(uniq, key)
+	}
+
+	stored := make(map[interface{}]bool)
+	if tw.store != nil {
+		for _, key := range uniq {
+			ok, err := tw.store.Delete(key, 0)
+			if err != nil {
+				tw.logger.Printf("timewheel: store delete failed, key: %v, err: %v", key, err)
+			}
+			stored[key] = ok
+		}
+	}
+
+	var found, named []interface{}
+	err := tw.exec(func() {
+		found, named = tw.removeTasks(uniq)
+	})
+	if err != nil {
+		found = nil
+	}
+	for _, key := range named {
+		tw.walAppend(walRecord{Op: walRemove, Spec: TaskSpec{Key: key}})
+	}
+
+	gone := make(map[interface{}]struct{}, len(found))
+	for _, key := range found {
+		gone[key] = struct{}{}
+	}
+	for _, key := range uniq {
+		if _, ok := gone[key]; ok || stored[key] {
+			removed++
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	return removed, missing
+}
+
+// remove the tasks of the distinct keys, return the keys found and those of the named tasks
+func (tw *TimeWheel) removeTasks(keys []interface{}) (found, named []interface{}) {
+	var batch []*task
+	for _, key := range keys {
+		task, ok := tw.taskRecord.Load(key)
+		if !ok {
+			continue
+		}
+		found = append(found, key)
+		if task.jobName != "" {
+			named = append(named, key)
+		}
+		tw.emit(tw.hooks.OnTaskRemoved, task)
+		tw.publish(EventRemoved, task)
+		if task.isHeld() {
+			// a held key may be released by the job meanwhile
+			tw.unregister(task)
+		} else {
+			batch = append(batch, task)
+		}
+	}
+	tw.taskRecord.deleteBatch(batch)
+	for _, task := range batch {
+		tw.unlink(task)
+	}
+	atomic.AddInt64(&tw.removedNum, int64(len(found)))
+	for _, key := range found {
+		if tw.metrics != nil {
+			tw.metrics.TaskRemoved()
+		}
+		tw.prerequisiteGone(key)
+	}
+	return found, named
+}
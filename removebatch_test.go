@@ -0,0 +1,61 @@
+package timewheel
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRemoveTasksBatch(t *testing.T) {
+	tw := New(10*time.Millisecond, 16)
+	tw.Start()
+	defer tw.Stop()
+	for i := 0; i < 10; i++ {
+		if err := tw.AddTask(time.Hour, -1, i, nil, func(TaskData) {}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a duplicate is removed once, the absent and invalid keys are reported
+	removed, missing := tw.RemoveTasks([]interface{}{1, 2, 2, 3, 42, nil, []int{1}})
+	if removed != 3 || fmt.Sprint(missing) != "[<nil> [1] 42]" {
+		t.Fatalf("removed %d missing %v", removed, missing)
+	}
+	for _, k := range []int{1, 2, 3} {
+		if tw.RemoveTask(k) != ErrTaskNotFound {
+			t.Fatalf("key %d still there", k)
+		}
+	}
+	if tw.RemoveTask(4) != nil {
+		t.Fatal("4 gone")
+	}
+	if tw.Len() != 6 {
+		t.Fatal(tw.Len())
+	}
+}
+
+// removing 10k keys one by one or in a batch: go test -bench RemoveTasks
+func BenchmarkRemoveTasks(b *testing.B) {
+	for _, batch := range []bool{false, true} {
+		b.Run(fmt.Sprint("batch=", batch), func(b *testing.B) {
+			tw := New(10*time.Millisecond, 64)
+			tw.Start()
+			defer tw.Stop()
+			keys := make([]interface{}, 10000)
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				for j := range keys {
+					keys[j] = j
+					tw.AddTask(time.Hour, -1, j, nil, func(TaskData) {})
+				}
+				b.StartTimer()
+				if batch {
+					tw.RemoveTasks(keys)
+				} else {
+					for _, k := range keys {
+						tw.RemoveTask(k)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package timewheel
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRemoveTasksWhere(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 16, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var kept, removed int64
+	for i := 0; i < 10000; i++ {
+		key := fmt.Sprintf("user:%d:reminder:%d", i%2, i)
+		counter := &kept
+		if i%2 == 1 {
+			counter = &removed
+		}
+		if err := tw.AddTask(time.Second, -1, key, nil, func(TaskData) { atomic.AddInt64(counter, 1) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n := tw.RemoveTasksWhere(func(key interface{}, info TaskInfo) bool {
+		return strings.HasPrefix(key.(string), "user:1:")
+	})
+	if n != 5000 {
+		t.Fatalf("removed %d tasks, want 5000", n)
+	}
+	if tw.Len() != 5000 {
+		t.Fatalf("%d tasks left", tw.Len())
+	}
+	settle(tw)
+	total := 0
+	for _, l := range tw.SlotLengths() {
+		total += l
+	}
+	if total != 5000 {
+		t.Fatalf("%d tasks left in the slots", total)
+	}
+
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt64(&kept) < 5000 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := atomic.LoadInt64(&kept); got != 5000 {
+		t.Fatalf("%d runs of the kept tasks, want 5000", got)
+	}
+	if got := atomic.LoadInt64(&removed); got != 0 {
+		t.Fatalf("%d runs of the removed tasks", got)
+	}
+
+	if tw.RemoveTasksWhere(nil) != 0 {
+		t.Fatal("nil predicate removed tasks")
+	}
+}
@@ -0,0 +1,62 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ReplaceTask swap the task registered under key for a new one in a single step on the wheel goroutine,
+// the key is never seen missing. The old task is unlinked and the new one runs interval from now.
+// ErrTaskNotFound is returned if the key is not registered, the runs of the old task in flight go on.
+func (tw *TimeWheel) ReplaceTask(key interface{}, interval time.Duration, times int, data TaskData, job Job) error {
+	if interval <= 0 || key == nil || job == nil || times < -1 || times == 0 {
+		return ErrInvalidParams
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	named := false
+	var err error
+	execErr := tw.exec(func() {
+		old, ok := tw.taskRecord.Load(key)
+		if !ok {
+			err = ErrTaskNotFound
+			return
+		}
+		if old.isHeld() {
+			err = ErrTaskStillRunning
+			return
+		}
+		var t *task
+		if t, err = tw.allocTask(interval, times, key, data, wrapJob(job)); err != nil {
+			return
+		}
+		if !tw.taskRecord.CompareAndSwap(key, old, t) {
+			tw.dropTask(t)
+			err = ErrTaskNotFound
+			return
+		}
+		named = old.jobName != ""
+		tw.emit(tw.hooks.OnTaskRemoved, old)
+		tw.publish(EventRemoved, old)
+		tw.tagIndex.remove(old)
+		if !tw.undefer(old) {
+			tw.backend.remove(old)
+		}
+		old.times = 0
+		tw.dropTask(old)
+
+		atomic.AddInt64(&tw.addedNum, 1)
+		tw.emit(tw.hooks.OnTaskAdded, t)
+		tw.publish(EventAdded, t)
+		tw.backend.push(t, tw.delayTicks(interval))
+	})
+	if execErr != nil {
+		return execErr
+	}
+	if err == nil && named {
+		// the new task has a plain job, the stored definition is obsolete
+		tw.forgetNamed(key)
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReplaceTask(t *testing.T) {
+	tw := New(time.Millisecond, 64)
+	tw.Start()
+	defer tw.Stop()
+	job := func(TaskData) {}
+	if err := tw.ReplaceTask("k", time.Millisecond, 1, nil, job); err != ErrTaskNotFound {
+		t.Fatalf("replace of a missing key: %v", err)
+	}
+	ch, cancel := tw.Subscribe(1 << 16)
+	defer cancel()
+	tw.AddTask(2*time.Millisecond, -1, "k", nil, job)
+	settle(tw)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	var missing int64
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if !tw.HasTask("k") {
+				atomic.AddInt64(&missing, 1)
+			}
+		}
+	}()
+	for i := 1; i < 500; i++ {
+		if err := tw.ReplaceTask("k", 2*time.Millisecond, -1, nil, job); err != nil {
+			t.Fatal(err)
+		}
+		if i%10 == 0 {
+			time.Sleep(3 * time.Millisecond)
+		}
+	}
+	close(stop)
+	<-done
+	checkInvariants(t, tw)
+	if n := atomic.LoadInt64(&missing); n != 0 || tw.Len() != 1 {
+		t.Fatalf("key missing %d times, %d tasks", n, tw.Len())
+	}
+
+	// a replaced task never fires again
+	removed := make(map[uint64]bool)
+	fired := 0
+	for len(ch) > 0 {
+		ev := <-ch
+		switch ev.Type {
+		case EventRemoved:
+			removed[ev.Info.Generation] = true
+		case EventFired:
+			if removed[ev.Info.Generation] {
+				t.Fatalf("generation %d fired after it was replaced", ev.Info.Generation)
+			}
+			fired++
+		}
+	}
+	if len(removed) != 499 || fired == 0 {
+		t.Fatalf("%d replaced, %d fired", len(removed), fired)
+	}
+}
@@ -0,0 +1,65 @@
+package timewheel
+
+import "time"
+
+// ResetTask move the next run of the task a full interval from now, times, data and job are kept.
+// Called from the job of the task it moves the run after the current one, the final run
+// can not be reset since the task is already gone.
+func (tw *TimeWheel) ResetTask(key interface{}) error {
+	return tw.resetTask(key, 0)
+}
+
+// ResetTaskTo move the next run of the task d from now
+func (tw *TimeWheel) ResetTaskTo(key interface{}, d time.Duration) error {
+	if d <= 0 {
+		return ErrInvalidParams
+	}
+	if d > tw.maxDelay() {
+		return ErrDelayTooLarge
+	}
+	return tw.resetTask(key, d)
+}
+
+// reschedule the task on the wheel goroutine, d 0 means the interval of the task
+func (tw *TimeWheel) resetTask(key interface{}, d time.Duration) error {
+	if key == nil {
+		return ErrInvalidKey
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	var err error
+	execErr := tw.exec(func() {
+		task, ok := tw.taskRecord.Load(key)
+		if !ok {
+			err = ErrTaskNotFound
+			return
+		}
+		if task.isHeld() {
+			err = ErrTaskStillRunning
+			return
+		}
+		if d == 0 {
+			d = task.interval
+		}
+		tw.rescheduleTask(task, d)
+	})
+	if execErr != nil {
+		return execErr
+	}
+	return err
+}
+
+// move the next run of the registered task d from now, only called on the wheel goroutine
+func (tw *TimeWheel) rescheduleTask(task *task, d time.Duration) {
+	if task.dep != nil {
+		// still waiting, the countdown starts once released
+		task.dep.delay = d
+		return
+	}
+	if !tw.undefer(task) {
+		tw.backend.remove(task)
+	}
+	task.next = tw.clock.Now().Add(d)
+	tw.backend.push(task, tw.delayTicks(d))
+}
@@ -0,0 +1,105 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// a session expiring after 3s of inactivity, every activity resets its timeout
+func TestResetSlidingTimeout(t *testing.T) {
+	c := newFakeClock()
+	var expired int64
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(3*time.Second, 1, "session", nil, func(TaskData) { atomic.AddInt64(&expired, 1) })
+	settle(tw)
+	// active for 6s
+	for i := 0; i < 6; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+		if err := tw.ResetTask("session"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	if atomic.LoadInt64(&expired) != 0 || !tw.HasTask("session") {
+		t.Fatal("expired while reset")
+	}
+	// a full interval of inactivity
+	c.Tick(time.Second)
+	settle(tw)
+	waitCount(t, &expired, 1)
+	if tw.HasTask("session") {
+		t.Fatal("expired session still registered")
+	}
+	if err := tw.ResetTask("session"); err != ErrTaskNotFound {
+		t.Fatalf("reset of an expired session: %v", err)
+	}
+}
+
+func TestResetTaskTo(t *testing.T) {
+	c := newFakeClock()
+	var n int64
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(2*time.Second, -1, "k", nil, func(TaskData) { atomic.AddInt64(&n, 1) })
+	settle(tw)
+	if err := tw.ResetTaskTo("k", 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	if atomic.LoadInt64(&n) != 0 {
+		t.Fatal("fired before the reset delay")
+	}
+	c.Tick(time.Second)
+	settle(tw)
+	waitCount(t, &n, 1)
+	// the following runs keep the interval of the task
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	waitCount(t, &n, 2)
+
+	if err := tw.ResetTaskTo("k", 0); err != ErrInvalidParams {
+		t.Fatalf("zero delay: %v", err)
+	}
+	if err := tw.ResetTask(nil); err != ErrInvalidKey {
+		t.Fatalf("nil key: %v", err)
+	}
+}
+
+// a job resetting its own task moves the run after the current one
+func TestResetFromJob(t *testing.T) {
+	c := newFakeClock()
+	var n int64
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(time.Second, -1, "k", nil, func(TaskData) {
+		if atomic.AddInt64(&n, 1) == 1 {
+			tw.ResetTaskTo("k", 3*time.Second)
+		}
+	})
+	settle(tw)
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	waitCount(t, &n, 1)
+	for i := 0; i < 3; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	waitCount(t, &n, 1)
+	c.Tick(time.Second)
+	settle(tw)
+	waitCount(t, &n, 2)
+}
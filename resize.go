@@ -0,0 +1,78 @@
+package timewheel
+
+import "sync/atomic"
+
+// Resize change the number of slots of the wheel backend while the wheel runs, e.g. when the wheel holds
+// far more tasks than it was sized for. The tasks keep the tick of their next run, they are moved to the
+// new slots at most a scan chunk at a time between the ticks, see WithScanChunk, so resizing a big wheel
+// does not stall the ticking. Resize returns once every task moved. The count is rounded up with
+// WithPowerOfTwoSlots, the heap backend has no slots and only records it.
+func (tw *TimeWheel) Resize(slotNum int) error {
+	if slotNum <= 0 {
+		return ErrInvalidParams
+	}
+	if tw.pow2Slots {
+		slotNum = nextPowerOfTwo(slotNum)
+	}
+	tw.resizeMu.Lock()
+	defer tw.resizeMu.Unlock()
+
+	var wb *wheelBackend
+	err := tw.exec(func() {
+		atomic.StoreInt64(&tw.slotNum, int64(slotNum))
+		var ok bool
+		if wb, ok = wheelOf(tw.backend); !ok {
+			return
+		}
+		tw.logger.Printf("timewheel: resize, slots: %d -> %d, tasks: %d", len(wb.slots), slotNum, wb.len())
+		newStore := tw.newSlotStore
+		if newStore == nil {
+			newStore = NewSliceSlotStore
+		}
+		wb.resize(slotNum, newStore)
+		tw.busyLast = nil
+		tw.markTick(tw.lastTick)
+	})
+	for moving := wb != nil; err == nil && moving; {
+		err = tw.exec(func() {
+			moving = wb.migrate(tw.scanChunk)
+		})
+	}
+	return err
+}
+
+// replace the slots by slotNum empty ones, the tasks stay in the old slots until migrate moved them
+func (b *wheelBackend) resize(slotNum int, newStore func() SlotStore) {
+	// a resize given up half way
+	for b.migrate(-1) {
+	}
+	b.moving, b.movingPos, b.movingNext = b.slots, b.currentPos, 0
+	b.gen++
+	b.currentPos = 0
+	b.makeSlots(slotNum, newStore)
+}
+
+// move at most n tasks from the old slots to the slots, all of them if n is negative,
+// report whether tasks are left to move
+func (b *wheelBackend) migrate(n int) bool {
+	for b.moving != nil && n != 0 {
+		b.moving[b.movingNext].Scan(func(e *SlotEntry) bool {
+			if n == 0 {
+				return true
+			}
+			n--
+			t := e.task
+			pos, circle := b.getPositionAndCircle(b.ticksUntil(t))
+			t.circle, t.slot, t.slotGen = circle, pos, b.gen
+			b.slots[pos].Push(&t.entry)
+			return false
+		})
+		if b.moving[b.movingNext].Len() > 0 {
+			break
+		}
+		if b.movingNext++; b.movingNext == len(b.moving) {
+			b.moving = nil
+		}
+	}
+	return b.moving != nil
+}
@@ -0,0 +1,61 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestResize(t *testing.T) {
+	// fire times of the tasks, with the wheel resized up then down and up again mid run or not at all
+	run := func(resize bool) map[string][]time.Time {
+		c := newFakeClock()
+		tw := New(10*time.Millisecond, 8, WithClock(c), WithScanChunk(3))
+		tw.Start()
+		defer tw.Stop()
+		var mu sync.Mutex
+		fired := map[string][]time.Time{}
+		for i := 0; i < 60; i++ {
+			key := fmt.Sprint(i)
+			times := 1
+			if i%3 == 0 {
+				times = 4
+			}
+			tw.AddTask(time.Duration(i%37+1)*10*time.Millisecond, times, key, nil, func(TaskData) {
+				now := c.Now()
+				mu.Lock()
+				fired[key] = append(fired[key], now)
+				mu.Unlock()
+			})
+		}
+		settle(tw)
+		for tick := 1; tick <= 200; tick++ {
+			if resize && (tick == 5 || tick == 40 || tick == 90) {
+				n := map[int]int{5: 50, 40: 3, 90: 17}[tick]
+				done := make(chan error)
+				go func() { done <- tw.Resize(n) }()
+				// the moves interleave with the ticks
+				c.Tick(10 * time.Millisecond)
+				settle(tw)
+				if err := <-done; err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+			c.Tick(10 * time.Millisecond)
+			settle(tw)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return fired
+	}
+	want, got := run(false), run(true)
+	if len(want) != 60 {
+		t.Fatalf("%d tasks fired", len(want))
+	}
+	// no run is early, late, doubled or lost
+	if fmt.Sprint(want) != fmt.Sprint(got) {
+		t.Fatalf("\nwant %v\ngot  %v", want, got)
+	}
+}
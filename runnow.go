@@ -0,0 +1,76 @@
+package timewheel
+
+// RunOption configure a run dispatched by RunNowWith
+type RunOption func(*runNow)
+
+type runNow struct {
+	count    bool
+	reanchor bool
+}
+
+// CountRun count the run towards the times of the task, the task leaves the wheel after its final run
+func CountRun() RunOption {
+	return func(r *runNow) {
+		r.count = true
+	}
+}
+
+// Reanchor move the next scheduled run of the task a full interval from now
+func Reanchor() RunOption {
+	return func(r *runNow) {
+		r.reanchor = true
+	}
+}
+
+// RunNow dispatch a run of the task right away, the run is extra: it does not count towards times and
+// the schedule of the task is unchanged, see RunNowWith
+func (tw *TimeWheel) RunNow(key interface{}) error {
+	return tw.RunNowWith(key)
+}
+
+// RunNowWith dispatch a run of the task right away, opts decide whether it counts towards times and
+// whether the schedule restarts from now. The run goes through the worker pool, the sequential keys and
+// the interceptor like a scheduled one, a paused task runs too. If the last run of the task started less
+// than its minimum gap ago the run is deferred like a due one, see MinGap: the next scheduled run moves to
+// the end of the gap and counts towards times, opts are ignored.
+func (tw *TimeWheel) RunNowWith(key interface{}, opts ...RunOption) error {
+	if key == nil {
+		return ErrInvalidKey
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	var req runNow
+	for _, opt := range opts {
+		opt(&req)
+	}
+	var err error
+	execErr := tw.exec(func() {
+		task, ok := tw.taskRecord.Load(key)
+		if !ok || task.times == 0 {
+			err = ErrTaskNotFound
+			return
+		}
+		if tw.holdRunNow(task) {
+			return
+		}
+		now := tw.clock.Now()
+		if req.count && task.times == 1 {
+			// the final run, the task leaves the wheel
+			tw.fireRun(task, now, tw.persistRun(task), true)
+			tw.unregister(task)
+			return
+		}
+		tw.fireRun(task, now, nil, false)
+		if req.count && task.times > 0 {
+			task.times--
+		}
+		if req.reanchor {
+			tw.rescheduleTask(task, task.interval)
+		}
+	})
+	if execErr != nil {
+		return execErr
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunNow(t *testing.T) {
+	c := newFakeClock()
+	tw := New(10*time.Millisecond, 8, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var n int64
+	tw.AddTask(time.Second, 1, "k", nil, func(TaskData) { atomic.AddInt64(&n, 1) })
+	if err := tw.RunNow("k"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.RunNow("x"); err != ErrTaskNotFound {
+		t.Fatal(err)
+	}
+	// an extra run, the task is still scheduled
+	waitCount(t, &n, 1)
+	if !tw.HasTask("k") {
+		t.Fatal("task gone after RunNow")
+	}
+}
+
+func TestRunNowWith(t *testing.T) {
+	for _, count := range []bool{false, true} {
+		c := newFakeClock()
+		tw := New(10*time.Millisecond, 8, WithClock(c))
+		tw.Start()
+		var n int64
+		tw.AddTaskWith(30*time.Millisecond, 3, "k", nil, func(TaskData) { atomic.AddInt64(&n, 1) }, MinGap(20*time.Millisecond))
+		settle(tw)
+		step := func(k int) {
+			for i := 0; i < k; i++ {
+				c.Tick(10 * time.Millisecond)
+				settle(tw)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		step(4)
+		if atomic.LoadInt64(&n) != 1 {
+			t.Fatal(n)
+		}
+		// within the gap, the next run moves to the end of the gap
+		if err := tw.RunNow("k"); err != nil {
+			t.Fatal(err)
+		}
+		settle(tw)
+		if st, _ := tw.TaskStats("k"); atomic.LoadInt64(&n) != 1 || st.Deferred != 1 {
+			t.Fatal(n, st.Deferred)
+		}
+		step(1)
+		if atomic.LoadInt64(&n) != 1 {
+			t.Fatal("run within the gap", n)
+		}
+		step(2)
+		if atomic.LoadInt64(&n) != 2 {
+			t.Fatal("deferred run", n)
+		}
+		step(2)
+		var opts []RunOption
+		if count {
+			opts = append(opts, CountRun())
+		}
+		if err := tw.RunNowWith("k", opts...); err != nil {
+			t.Fatal(err)
+		}
+		step(1)
+		if atomic.LoadInt64(&n) != 3 {
+			t.Fatal(n)
+		}
+		step(12)
+		// the deferred run counts towards the 3 times
+		want := int64(4)
+		if count {
+			want = 3
+		}
+		if got := atomic.LoadInt64(&n); got != want || tw.HasTask("k") {
+			t.Fatal(count, got)
+		}
+		tw.Stop()
+	}
+}
+
+func TestRunNowReanchor(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 16, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var n int64
+	tw.AddTask(10*time.Second, -1, "k", nil, func(TaskData) { atomic.AddInt64(&n, 1) })
+	settle(tw)
+	for i := 0; i < 4; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	before, err := tw.NextFire("k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.RunNowWith("k", Reanchor()); err != nil {
+		t.Fatal(err)
+	}
+	waitCount(t, &n, 1)
+	// the next run is an interval from the run now, not from the add
+	after, err := tw.NextFire("k")
+	if err != nil || after.Sub(before) != 4*time.Second {
+		t.Fatalf("next fire %v, was %v: %v", after, before, err)
+	}
+}
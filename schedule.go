@@ -0,0 +1,102 @@
+package timewheel
+
+import (
+	"context"
+	"time"
+)
+
+// Schedule give the run times of a task
+type Schedule interface {
+	// Next get the first run time after t, the zero time means no more runs
+	Next(t time.Time) time.Time
+}
+
+// AddScheduledTask add new task running at the times given by s, the wheel is told the next run
+// after every run so a long gap costs a single placement. The interval reported by TaskInfo
+// is the gap between the first two runs.
+func (tw *TimeWheel) AddScheduledTask(s Schedule, key interface{}, data TaskData, job Job) error {
+	if s == nil || job == nil {
+		return ErrInvalidParams
+	}
+	return tw.addScheduled(s, -1, key, data, job)
+}
+
+// add the task running times at the times given by s
+func (tw *TimeWheel) addScheduled(s Schedule, times int, key interface{}, data TaskData, job Job) error {
+	now := tw.clock.Now()
+	first := s.Next(now)
+	if first.IsZero() {
+		return ErrInvalidParams
+	}
+	interval := tw.Interval()
+	if second := s.Next(first); !second.IsZero() && second.After(first) {
+		interval = second.Sub(first)
+	}
+	task, err := tw.newTask(interval, times, key, data, wrapJob(job))
+	if err != nil {
+		return err
+	}
+	task.schedule = s
+	task.next = first
+	task.atNext = true
+	return tw.submit(context.Background(), task)
+}
+
+// ideal time of the run after the next one, zero if the schedule ends
+func (t *task) following() time.Time {
+	if !t.anchor.IsZero() {
+		return t.anchoredFollowing()
+	}
+	if t.schedule != nil {
+		return t.schedule.Next(t.next)
+	}
+	return t.next.Add(t.effective())
+}
+
+// report whether the next run is the last one, by the schedule or the deadline
+func (t *task) lastRun() bool {
+	if t.schedule == nil && t.until.IsZero() {
+		return false
+	}
+	following := t.following()
+	return following.IsZero() || (!t.until.IsZero() && following.After(t.until))
+}
+
+// Weekly schedule running at a time of day on some days of the week
+type Weekly struct {
+	Days     []time.Weekday
+	At       time.Duration  // time of day as an offset from midnight, below 24h
+	Location *time.Location // nil means time.Local
+}
+
+// Next implement Schedule
+func (w Weekly) Next(t time.Time) time.Time {
+	if w.At < 0 || w.At >= 24*time.Hour {
+		return time.Time{}
+	}
+	loc := w.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	t = t.In(loc)
+	y, m, d := t.Date()
+	h, min, sec := int(w.At/time.Hour), int(w.At/time.Minute%60), int(w.At/time.Second%60)
+	nsec := int(w.At % time.Second)
+	// today may be a match with the time passed, so a week and a day are checked
+	for i := 0; i <= 7; i++ {
+		at := time.Date(y, m, d+i, h, min, sec, nsec, loc)
+		if w.has(at.Weekday()) && at.After(t) {
+			return at
+		}
+	}
+	return time.Time{}
+}
+
+func (w Weekly) has(day time.Weekday) bool {
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
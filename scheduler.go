@@ -0,0 +1,15 @@
+package timewheel
+
+import "time"
+
+// Scheduler the scheduling methods of the wheel, for the code that only adds and removes tasks.
+// *TimeWheel implements it, timewheeltest.Fake is a double running the jobs on demand.
+type Scheduler interface {
+	AddTask(interval time.Duration, times int, key interface{}, data TaskData, job Job) error
+	RemoveTask(key interface{}) error
+	UpdateTask(key interface{}, interval time.Duration, taskData TaskData) error
+	HasTask(key interface{}) bool
+	Stop()
+}
+
+var _ Scheduler = (*TimeWheel)(nil)
@@ -0,0 +1,109 @@
+package timewheel
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Scope a group of tasks removed together once its context is done or it is closed, see TimeWheel.Scope.
+// The keys of the scoped tasks live in the keys of the wheel, a key taken by another task is rejected.
+type Scope struct {
+	tw     *TimeWheel
+	closed int32 // set on the wheel goroutine, accessed atomically
+	once   sync.Once
+	done   chan struct{}
+}
+
+// Scope create a scope of the wheel, its tasks are removed in a single pass once ctx is done
+func (tw *TimeWheel) Scope(ctx context.Context) *Scope {
+	s := &Scope{tw: tw, done: make(chan struct{})}
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				s.Close()
+			case <-s.done:
+			case <-tw.stopChannel:
+			}
+		}()
+	}
+	return s
+}
+
+// report whether the scope is closed, the tasks added to a closed scope are dropped by the wheel goroutine
+func (s *Scope) isClosed() bool {
+	return s != nil && atomic.LoadInt32(&s.closed) == 1
+}
+
+// AddTask add new task to the scope like TimeWheel.AddTask, it fails with ErrScopeClosed once the scope is closed
+func (s *Scope) AddTask(interval time.Duration, times int, key interface{}, data TaskData, job Job) error {
+	return s.AddTaskWith(interval, times, key, data, job)
+}
+
+// AddTaskWith add new task to the scope like TimeWheel.AddTaskWith
+func (s *Scope) AddTaskWith(interval time.Duration, times int, key interface{}, data TaskData, job Job, opts ...TaskOption) error {
+	if job == nil {
+		return ErrInvalidParams
+	}
+	if s.isClosed() {
+		return ErrScopeClosed
+	}
+	opts = append(opts, func(t *task) {
+		t.scope = s
+	})
+	return s.tw.addTaskWith(interval, times, key, data, wrapJob(job), opts)
+}
+
+// AfterFunc run f once after d in the scope, the returned key can be passed to RemoveTask
+func (s *Scope) AfterFunc(d time.Duration, f func()) (interface{}, error) {
+	if f == nil {
+		return nil, ErrInvalidParams
+	}
+	key := anonKey(atomic.AddUint64(&anonSeq, 1))
+	if err := s.AddTask(d, 1, key, nil, func(TaskData) { f() }); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// RemoveTask remove the task from the wheel
+func (s *Scope) RemoveTask(key interface{}) error {
+	return s.tw.RemoveTask(key)
+}
+
+// Len get the number of registered tasks of the scope
+func (s *Scope) Len() int {
+	return s.tw.tagIndex.scopeCount(s)
+}
+
+// Done get a channel closed once the scope is closed
+func (s *Scope) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close remove the tasks of the scope in a single pass and reject the later adds, return how many
+// tasks were removed. Closing a closed scope does nothing.
+func (s *Scope) Close() int {
+	n := 0
+	s.once.Do(func() {
+		defer close(s.done)
+		var named []interface{}
+		err := s.tw.exec(func() {
+			atomic.StoreInt32(&s.closed, 1)
+			keys := s.tw.tagIndex.scopeKeys(s)
+			var found []interface{}
+			found, named = s.tw.removeTasks(keys)
+			n = len(found)
+		})
+		if err != nil {
+			atomic.StoreInt32(&s.closed, 1)
+			return
+		}
+		for _, key := range named {
+			s.tw.walAppend(walRecord{Op: walRemove, Spec: TaskSpec{Key: key}})
+		}
+	})
+	return n
+}
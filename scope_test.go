@@ -0,0 +1,56 @@
+package timewheel
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScope(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 8, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	s1 := tw.Scope(ctx1)
+	s2 := tw.Scope(context.Background())
+	var n1, n2, nd int64
+	for i := 0; i < 3; i++ {
+		if err := s1.AddTask(time.Second, -1, [2]int{1, i}, nil, func(TaskData) { atomic.AddInt64(&n1, 1) }); err != nil {
+			t.Fatal(err)
+		}
+		s2.AddTask(time.Second, -1, [2]int{2, i}, nil, func(TaskData) { atomic.AddInt64(&n2, 1) })
+	}
+	tw.AddTask(time.Second, -1, "direct", nil, func(TaskData) { atomic.AddInt64(&nd, 1) })
+	if err := s1.AddTask(time.Second, 1, "direct", nil, func(TaskData) {}); err != ErrDuplicateKey {
+		t.Fatal(err)
+	}
+	if s1.Len() != 3 || s2.Len() != 3 {
+		t.Fatal(s1.Len(), s2.Len())
+	}
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	cancel1()
+	<-s1.Done()
+	if s1.Len() != 0 || tw.Len() != 4 {
+		t.Fatal(s1.Len(), tw.Len())
+	}
+	if s1.AddTask(time.Second, 1, "late", nil, func(TaskData) {}) != ErrScopeClosed {
+		t.Fatal("closed")
+	}
+	a1, a2, ad := atomic.LoadInt64(&n1), atomic.LoadInt64(&n2), atomic.LoadInt64(&nd)
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	// the other scope and the direct task go on
+	waitCount(t, &n2, a2+6)
+	waitCount(t, &nd, ad+2)
+	if atomic.LoadInt64(&n1) != a1 {
+		t.Fatal("closed scope task ran", a1, atomic.LoadInt64(&n1))
+	}
+	if s2.Close() != 3 || s2.Close() != 0 || tw.Len() != 1 {
+		t.Fatal("close s2")
+	}
+}
@@ -0,0 +1,94 @@
+package timewheel
+
+import (
+	"fmt"
+	"time"
+)
+
+// Sequence schedule running after each of the delays in turn from Start, then done. With RepeatLast the
+// last delay repeats forever.
+type Sequence struct {
+	Start      time.Time
+	Delays     []time.Duration
+	RepeatLast bool
+}
+
+// Next implement Schedule
+func (s Sequence) Next(t time.Time) time.Time {
+	at := s.Start
+	for _, d := range s.Delays {
+		at = at.Add(d)
+		if at.After(t) {
+			return at
+		}
+	}
+	if !s.RepeatLast || len(s.Delays) == 0 {
+		return time.Time{}
+	}
+	last := s.Delays[len(s.Delays)-1]
+	return at.Add((t.Sub(at)/last + 1) * last)
+}
+
+// AddTaskSequence add new task running after each of the delays in turn, the first one counted from now,
+// then done like a task running out of times. With repeatLast the last delay repeats until the task is
+// removed. Every delay is checked like an interval. See UpdateSequence to replace the delays left.
+func (tw *TimeWheel) AddTaskSequence(delays []time.Duration, repeatLast bool, key interface{}, data TaskData, job Job) error {
+	if job == nil {
+		return ErrInvalidParams
+	}
+	s, times, err := tw.newSequence(tw.clock.Now(), delays, repeatLast)
+	if err != nil {
+		return err
+	}
+	return tw.addScheduled(s, times, key, data, job)
+}
+
+// UpdateSequence replace the delays left of a task added by AddTaskSequence, the pending run keeps its time
+// and the new delays are counted from it
+func (tw *TimeWheel) UpdateSequence(key interface{}, delays []time.Duration, repeatLast bool) error {
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	err := ErrTaskNotFound
+	if execErr := tw.exec(func() {
+		t, ok := tw.taskRecord.Load(key)
+		if !ok || t.times == 0 || t.isHeld() {
+			return
+		}
+		if _, ok := t.schedule.(Sequence); !ok {
+			err = fmt.Errorf("%w, task %v is not a sequence", ErrInvalidParams, key)
+			return
+		}
+		var s Sequence
+		var times int
+		if s, times, err = tw.newSequence(t.next, delays, repeatLast); err != nil {
+			return
+		}
+		t.schedule = s
+		if times > 0 {
+			// the pending run and the new delays
+			times++
+		}
+		t.times = times
+	}); execErr != nil {
+		return execErr
+	}
+	return err
+}
+
+// check the delays and build the sequence starting at start with the times of its runs
+func (tw *TimeWheel) newSequence(start time.Time, delays []time.Duration, repeatLast bool) (Sequence, int, error) {
+	if len(delays) == 0 {
+		return Sequence{}, 0, fmt.Errorf("%w, no delay", ErrInvalidParams)
+	}
+	for _, d := range delays {
+		if err := tw.checkInterval(d); err != nil {
+			return Sequence{}, 0, err
+		}
+	}
+	s := Sequence{Start: start, Delays: append([]time.Duration(nil), delays...), RepeatLast: repeatLast}
+	if repeatLast {
+		return s, -1, nil
+	}
+	return s, len(delays), nil
+}
@@ -0,0 +1,63 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSequence(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 16, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	start := c.Now()
+	var mu sync.Mutex
+	fired := map[string][]time.Duration{}
+	rec := func(k string) Job {
+		return func(TaskData) {
+			mu.Lock()
+			fired[k] = append(fired[k], c.Now().Sub(start))
+			mu.Unlock()
+		}
+	}
+	if err := tw.AddTaskSequence([]time.Duration{time.Second, 0}, false, "bad", nil, rec("bad")); err == nil {
+		t.Fatal("zero delay")
+	}
+	tw.AddTaskSequence([]time.Duration{time.Second, 5 * time.Second, 30 * time.Second, 5 * time.Minute}, false, "seq", nil, rec("seq"))
+	tw.AddTaskSequence([]time.Duration{2 * time.Second, 3 * time.Second}, true, "rep", nil, rec("rep"))
+	tw.AddTaskSequence([]time.Duration{10 * time.Second, 10 * time.Second, 10 * time.Second}, false, "upd", nil, rec("upd"))
+	settle(tw)
+	for i := 0; i < 400; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+		if i == 12 {
+			if err := tw.UpdateSequence("upd", []time.Duration{time.Second}, false); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	// the first run is on the tick after its delay, the next ones follow the sequence from it
+	check := func(k string, want []time.Duration, n int) {
+		got := fired[k]
+		if n >= 0 && len(got) != n || len(got) < len(want) {
+			t.Fatal(k, got)
+		}
+		for i, w := range want {
+			if got[i] != w {
+				t.Fatalf("%s: run %d at %v, want %v", k, i, got[i], w)
+			}
+		}
+	}
+	check("seq", []time.Duration{2 * time.Second, 6 * time.Second, 36 * time.Second, 336 * time.Second}, 4)
+	// the last delay repeats
+	check("rep", []time.Duration{3 * time.Second, 5 * time.Second, 8 * time.Second, 11 * time.Second, 14 * time.Second}, -1)
+	// the update replaced the rest of the sequence by a single delay
+	check("upd", []time.Duration{11 * time.Second, 20 * time.Second, 21 * time.Second}, 3)
+	if tw.HasTask("seq") || !tw.HasTask("rep") || tw.HasTask("upd") {
+		t.Fatal("lifecycle")
+	}
+}
@@ -0,0 +1,84 @@
+package timewheel
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// WithSequentialKeys run the jobs of a key one after another in firing order, a run overrunning
+// the interval delays the next one instead of overlapping it. Default is a goroutine per run.
+func WithSequentialKeys() Option {
+	return func(tw *TimeWheel) {
+		tw.sequencer = &sequencer{queues: make(map[interface{}]*runQueue), queued: &tw.queuedNum}
+	}
+}
+
+// pending runs of a key, drained by a single goroutine while the key is active
+type runQueue struct {
+	runs []func()
+}
+
+// funnel the runs of every key through a fifo
+type sequencer struct {
+	mu     sync.Mutex
+	queues map[interface{}]*runQueue
+	queued *int64 // runs waiting in the queues, accessed atomically
+}
+
+// queue the run, start a consumer if the key is idle
+func (s *sequencer) run(key interface{}, fn func()) {
+	s.mu.Lock()
+	if q, ok := s.queues[key]; ok {
+		q.runs = append(q.runs, fn)
+		atomic.AddInt64(s.queued, 1)
+		s.mu.Unlock()
+		return
+	}
+	s.queues[key] = &runQueue{}
+	s.mu.Unlock()
+	go s.drain(key, fn)
+}
+
+// run fn then the queued runs of the key, the queue is dropped once empty
+func (s *sequencer) drain(key interface{}, fn func()) {
+	for {
+		fn()
+		s.mu.Lock()
+		q := s.queues[key]
+		if len(q.runs) == 0 {
+			delete(s.queues, key)
+			s.mu.Unlock()
+			return
+		}
+		fn = q.runs[0]
+		q.runs[0] = nil
+		q.runs = q.runs[1:]
+		atomic.AddInt64(s.queued, -1)
+		s.mu.Unlock()
+	}
+}
+
+// run the job on its own goroutine, behind the previous runs of its key or on the worker pool,
+// the jobs of a DelayQueue run on the wheel goroutine
+func (tw *TimeWheel) dispatch(r *jobRun) {
+	tw.activeRuns.track(r)
+	if r.task.group != "" && !tw.groups[r.task.group].acquire(r) {
+		return
+	}
+	if tw.pull {
+		r.inline = true
+		tw.runJob(r)
+		return
+	}
+	if tw.sequencer != nil {
+		tw.sequencer.run(r.task.key, func() {
+			tw.runJob(r)
+		})
+		return
+	}
+	if tw.workers != nil {
+		tw.submitRun(r)
+		return
+	}
+	go tw.runJob(r)
+}
@@ -0,0 +1,113 @@
+package timewheel
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSequentialKeys(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"goroutines", nil},
+		{"workers", []Option{WithWorkers(4, 16, Block)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newFakeClock()
+			var mu sync.Mutex
+			last := make(map[interface{}]time.Time)
+			running := make(map[interface{}]bool)
+			runs := 0
+			var failure string
+			record := func(ctx context.Context, exec Execution, run func(ctx context.Context) error) error {
+				mu.Lock()
+				if running[exec.Key] {
+					failure = "overlapping runs"
+				}
+				if !exec.Scheduled.After(last[exec.Key]) {
+					failure = "runs out of order"
+				}
+				running[exec.Key] = true
+				last[exec.Key] = exec.Scheduled
+				mu.Unlock()
+				err := run(ctx)
+				mu.Lock()
+				running[exec.Key] = false
+				runs++
+				mu.Unlock()
+				return err
+			}
+			opts := append([]Option{WithClock(c), WithSequentialKeys(), WithInterceptor(record)}, tc.opts...)
+			tw := New(time.Second, 8, opts...)
+			tw.Start()
+			defer tw.Stop()
+			// the runs overrun the interval, the ticks come faster than the jobs
+			job := func(TaskData) { time.Sleep(time.Millisecond) }
+			for k := 0; k < 4; k++ {
+				tw.AddTask(time.Second, -1, k, nil, job)
+			}
+			settle(tw)
+			for i := 0; i < 20; i++ {
+				c.Tick(time.Second)
+			}
+			// added again while its runs are queued
+			tw.RemoveTask(0)
+			tw.AddTask(time.Second, -1, 0, nil, job)
+			for i := 0; i < 10; i++ {
+				c.Tick(time.Second)
+			}
+			settle(tw)
+			waitSequencer(t, tw)
+			mu.Lock()
+			defer mu.Unlock()
+			if failure != "" {
+				t.Fatal(failure)
+			}
+			if runs < 100 {
+				t.Fatalf("%d runs", runs)
+			}
+		})
+	}
+}
+
+func TestSequencerQueuesDropped(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 8, WithClock(c), WithSequentialKeys())
+	tw.Start()
+	defer tw.Stop()
+	release := make(chan struct{})
+	tw.AddTask(time.Second, 3, "a", nil, func(TaskData) { <-release })
+	for i := 0; i < 4; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	tw.sequencer.mu.Lock()
+	queued := len(tw.sequencer.queues["a"].runs)
+	tw.sequencer.mu.Unlock()
+	if queued != 2 {
+		t.Fatalf("%d queued runs, want 2", queued)
+	}
+	close(release)
+	waitSequencer(t, tw)
+}
+
+// wait for the queues of the sequencer to drain
+func waitSequencer(t *testing.T, tw *TimeWheel) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		tw.sequencer.mu.Lock()
+		n := len(tw.sequencer.queues)
+		tw.sequencer.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d queues left", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
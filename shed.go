@@ -0,0 +1,127 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// LoadShedding configure the load shedding, see WithLoadShedding. A tick is under pressure when one of the
+// signals set is raised, a zero signal is ignored.
+type LoadShedding struct {
+	Below  int     // the tasks of a priority below it are shed
+	Factor float64 // multiplier of the interval of the shed tasks, above 1
+
+	TickLoad    float64       // ratio of the tick duration to the interval, such as 0.8
+	MaxLateness time.Duration // lateness of a run dispatched during the tick
+	MaxInFlight int64         // runs dispatched and not returned yet
+	PoolFull    bool          // the worker pool dropped, blocked or inlined a run, see WithWorkers
+
+	Sustain   time.Duration // time under pressure before shedding starts, 0 starts on the first tick
+	Stabilize time.Duration // time without pressure before shedding stops, 0 stops on the first tick
+}
+
+// WithLoadShedding stretch the interval of the tasks of a priority below cfg.Below by cfg.Factor while the
+// wheel is overloaded, see LoadShedding for the signals. The stretch is applied and removed when a task is
+// placed back after a run, so only the gaps change, the times of the tasks are not touched. Critical
+// tasks and scheduled tasks are never shed. See WheelStats.Shedding and TaskInfo.Shed.
+func WithLoadShedding(cfg LoadShedding) Option {
+	return func(tw *TimeWheel) {
+		if cfg.Factor > 1 {
+			tw.shed = &shedder{cfg: cfg}
+		}
+	}
+}
+
+// Critical keep the task on its cadence whatever the load, see WithLoadShedding
+func Critical() TaskOption {
+	return func(t *task) {
+		t.critical = true
+	}
+}
+
+// load shedding state, only used by the wheel goroutine but active, read by Stats
+type shedder struct {
+	cfg    LoadShedding
+	active int32 // 1 while shedding, accessed atomically
+
+	since    time.Time     // start of the pressure, or of the calm while shedding
+	late     time.Duration // longest lateness of the runs dispatched since the last check
+	poolMark int64         // drops, blocks and inlines of the worker pool at the last check
+}
+
+// record the lateness of a run being dispatched
+func (s *shedder) observe(late time.Duration) {
+	if late > s.late {
+		s.late = late
+	}
+}
+
+// report whether the wheel is shedding
+func (tw *TimeWheel) isShedding() bool {
+	return tw.shed != nil && atomic.LoadInt32(&tw.shed.active) == 1
+}
+
+// read the signals after the tick and start or stop shedding
+func (tw *TimeWheel) checkShedding(cost time.Duration) {
+	s := tw.shed
+	pressure := tw.underPressure(cost)
+	s.late = 0
+	now := tw.clock.Now()
+	shedding := s.active == 1
+	if pressure == shedding {
+		s.since = time.Time{}
+		return
+	}
+	if s.since.IsZero() {
+		s.since = now
+	}
+	wait := s.cfg.Sustain
+	if shedding {
+		wait = s.cfg.Stabilize
+	}
+	if now.Sub(s.since) < wait {
+		return
+	}
+	s.since = time.Time{}
+	if shedding {
+		atomic.StoreInt32(&s.active, 0)
+		tw.logger.Printf("timewheel: load shedding stopped")
+		return
+	}
+	atomic.StoreInt32(&s.active, 1)
+	tw.logger.Printf("timewheel: load shedding started, priority below: %d, factor: %v", s.cfg.Below, s.cfg.Factor)
+}
+
+// report whether a signal of the load shedding is raised
+func (tw *TimeWheel) underPressure(cost time.Duration) bool {
+	s := tw.shed
+	cfg := s.cfg
+	pressure := false
+	if cfg.TickLoad > 0 && float64(cost) >= cfg.TickLoad*float64(tw.interval) {
+		pressure = true
+	}
+	if cfg.MaxLateness > 0 && s.late >= cfg.MaxLateness {
+		pressure = true
+	}
+	if cfg.MaxInFlight > 0 && atomic.LoadInt64(&tw.inflightNum) >= cfg.MaxInFlight {
+		pressure = true
+	}
+	if cfg.PoolFull && tw.workers != nil {
+		p := tw.workers
+		mark := atomic.LoadInt64(&p.dropped) + atomic.LoadInt64(&p.blocked) + atomic.LoadInt64(&p.inline)
+		if mark != s.poolMark || (cap(p.runs) > 0 && len(p.runs) == cap(p.runs)) {
+			pressure = true
+		}
+		s.poolMark = mark
+	}
+	return pressure
+}
+
+// stretch or restore the interval of the task being placed back after a run
+func (tw *TimeWheel) applyShed(t *task) {
+	shed := int64(0)
+	if tw.isShedding() && !t.critical && t.schedule == nil && t.priority < tw.shed.cfg.Below {
+		shed = int64(float64(t.unshed()) * tw.shed.cfg.Factor)
+	}
+	atomic.StoreInt64(&t.shed, shed)
+}
@@ -0,0 +1,63 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadShedding(t *testing.T) {
+	c := newFakeClock()
+	tw := New(10*time.Millisecond, 16, WithClock(c),
+		WithLoadShedding(LoadShedding{Below: 1, Factor: 4, MaxInFlight: 20, Stabilize: 30 * time.Millisecond}))
+	tw.Start()
+	defer tw.Stop()
+	var low, crit int64
+	tw.AddTaskWith(20*time.Millisecond, -1, "low", nil, func(TaskData) { atomic.AddInt64(&low, 1) })
+	tw.AddTaskWith(20*time.Millisecond, -1, "crit", nil, func(TaskData) { atomic.AddInt64(&crit, 1) }, Critical())
+	settle(tw)
+	// runs of each task over n ticks
+	step := func(n int) (dl, dc int64) {
+		l, k := atomic.LoadInt64(&low), atomic.LoadInt64(&crit)
+		for i := 0; i < n; i++ {
+			c.Tick(10 * time.Millisecond)
+			settle(tw)
+		}
+		time.Sleep(5 * time.Millisecond)
+		return atomic.LoadInt64(&low) - l, atomic.LoadInt64(&crit) - k
+	}
+	if dl, dc := step(10); dl != dc {
+		t.Fatal("before", dl, dc)
+	}
+	// saturate: 30 runs in flight
+	block := make(chan struct{})
+	for i := 0; i < 30; i++ {
+		tw.AfterFunc(10*time.Millisecond, func() { <-block })
+	}
+	step(2)
+	if !tw.Stats().Shedding {
+		t.Fatal("not shedding")
+	}
+	// stretched once placed back after its next run
+	if dl, dc := step(40); dl > dc/3 || dc != 20 {
+		t.Fatal("during", dl, dc)
+	}
+	if !infoOf(tw, "low").Shed || infoOf(tw, "crit").Shed {
+		t.Fatal(infoOf(tw, "low"), infoOf(tw, "crit"))
+	}
+	close(block)
+	for deadline := time.Now().Add(time.Second); tw.Stats().InFlight > 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	step(4)
+	if tw.Stats().Shedding {
+		t.Fatal("still shedding")
+	}
+	// the stretched run placed before the recovery delays the first one
+	if dl, dc := step(40); dl < dc-4 || dc != 20 {
+		t.Fatal("after", dl, dc)
+	}
+	if infoOf(tw, "low").Shed {
+		t.Fatal("low still stretched")
+	}
+}
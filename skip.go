@@ -0,0 +1,36 @@
+package timewheel
+
+import "sync/atomic"
+
+// SkipNext skip the next due run of the task, see SkipNextN
+func (tw *TimeWheel) SkipNext(key interface{}) error {
+	return tw.SkipNextN(key, 1)
+}
+
+// SkipNextN skip the next n due runs of the task, they do not run nor count towards times and the task
+// keeps its cadence. The skips add up, the runs left to skip are reported by TaskInfo.Skip. The final run
+// of a task with an end is not skipped.
+func (tw *TimeWheel) SkipNextN(key interface{}, n int) error {
+	if key == nil {
+		return ErrInvalidKey
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	if n <= 0 {
+		return ErrInvalidParams
+	}
+	var err error
+	execErr := tw.exec(func() {
+		task, ok := tw.taskRecord.Load(key)
+		if !ok || task.times == 0 {
+			err = ErrTaskNotFound
+			return
+		}
+		atomic.AddInt32(&task.skip, int32(n))
+	})
+	if execErr != nil {
+		return execErr
+	}
+	return err
+}
@@ -0,0 +1,43 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSkipNext(t *testing.T) {
+	c := newFakeClock()
+	tw := New(10*time.Millisecond, 4, WithClock(c), WithTaskTrace(16))
+	tw.Start()
+	defer tw.Stop()
+	var n int64
+	tw.AddTask(30*time.Millisecond, 3, "k", nil, func(TaskData) { atomic.AddInt64(&n, 1) })
+	settle(tw)
+	if err := tw.SkipNext("k"); err != nil {
+		t.Fatal(err)
+	}
+	var info TaskInfo
+	tw.Range(func(k interface{}, i TaskInfo) bool { info = i; return true })
+	if info.Skip != 1 {
+		t.Fatal(info)
+	}
+	for i := 0; i < 20; i++ {
+		c.Tick(10 * time.Millisecond)
+		settle(tw)
+	}
+	// 4 occurrences, the skipped one did not count
+	waitCount(t, &n, 3)
+	if tw.HasTask("k") {
+		t.Fatal("task left after its runs")
+	}
+	skipped := 0
+	for _, ev := range tw.TaskTrace("k") {
+		if ev.Kind == TraceSkipped {
+			skipped++
+		}
+	}
+	if skipped != 1 {
+		t.Fatal(tw.TaskTrace("k"))
+	}
+}
@@ -0,0 +1,174 @@
+package timewheel
+
+import "container/list"
+
+// minimum capacity kept by a slot when shrinking
+const minSlotCap = 64
+
+// SlotStore tasks of a slot of the wheel backend in insertion order, see WithSlotStore.
+// A store is only used by the wheel goroutine.
+type SlotStore interface {
+	// Push append the entry
+	Push(e *SlotEntry)
+	// Remove unlink the entry keeping the order of the others, report whether it was in the store
+	Remove(e *SlotEntry) bool
+	// Scan call keep for every entry in order and unlink the entries it returns false for,
+	// keep does not modify the store
+	Scan(keep func(e *SlotEntry) bool)
+	// Each call fn for every entry in order
+	Each(fn func(e *SlotEntry))
+	// Len number of entries
+	Len() int
+}
+
+// SlotEntry a task stored in a slot, opaque to the store but for Handle
+type SlotEntry struct {
+	// Handle free for the store to find the entry back, e.g. its list element, reset it when the entry is unlinked
+	Handle interface{}
+	task   *task
+	index  int // position in a sliceSlot
+}
+
+// NewSlotEntry create a detached entry for testing a SlotStore, see slotstoretest
+func NewSlotEntry(key interface{}) *SlotEntry {
+	t := &task{key: key}
+	t.entry.task = t
+	return &t.entry
+}
+
+// Key get the key of the task of the entry
+func (e *SlotEntry) Key() interface{} {
+	return e.task.key
+}
+
+// WithSlotStore set the store of every slot of the wheel backend, default is NewSliceSlotStore
+func WithSlotStore(newStore func() SlotStore) Option {
+	return func(tw *TimeWheel) {
+		tw.newSlotStore = newStore
+	}
+}
+
+// NewSliceSlotStore create a store backed by a slice, scanning is cache friendly and removal is constant time
+// and allocates nothing
+func NewSliceSlotStore() SlotStore {
+	return &sliceSlot{}
+}
+
+// the entries know their index, a removed entry leaves a nil hole so the others keep their order and index,
+// the holes are compacted by Scan or once they are the majority
+type sliceSlot struct {
+	entries []*SlotEntry
+	holes   int
+}
+
+func (s *sliceSlot) Push(e *SlotEntry) {
+	e.index = len(s.entries)
+	s.entries = append(s.entries, e)
+}
+
+func (s *sliceSlot) Len() int {
+	return len(s.entries) - s.holes
+}
+
+func (s *sliceSlot) Remove(e *SlotEntry) bool {
+	i := e.index
+	if i < 0 || i >= len(s.entries) || s.entries[i] != e {
+		return false
+	}
+	if i == len(s.entries)-1 {
+		s.truncate(i)
+		return true
+	}
+	s.entries[i] = nil
+	if s.holes++; s.holes > len(s.entries)/2 {
+		s.Scan(func(*SlotEntry) bool { return true })
+	}
+	return true
+}
+
+// the kept entries are compacted in place
+func (s *sliceSlot) Scan(keep func(e *SlotEntry) bool) {
+	kept := 0
+	for i, e := range s.entries {
+		if e == nil || !keep(e) {
+			continue
+		}
+		if kept != i {
+			e.index = kept
+			s.entries[kept] = e
+		}
+		kept++
+	}
+	s.holes = 0
+	s.truncate(kept)
+}
+
+func (s *sliceSlot) Each(fn func(e *SlotEntry)) {
+	for _, e := range s.entries {
+		if e != nil {
+			fn(e)
+		}
+	}
+}
+
+// keep the first n entries, release the memory of a mostly empty slot
+func (s *sliceSlot) truncate(n int) {
+	for i := n; i < len(s.entries); i++ {
+		s.entries[i] = nil
+	}
+	s.entries = s.entries[:n]
+	if c := cap(s.entries); c > minSlotCap && n < c/4 {
+		entries := make([]*SlotEntry, n, c/2)
+		copy(entries, s.entries)
+		s.entries = entries
+	}
+}
+
+// NewListSlotStore create a store backed by a doubly linked list, removal is constant time
+func NewListSlotStore() SlotStore {
+	return &listSlot{}
+}
+
+type listSlot struct {
+	l list.List
+}
+
+func (s *listSlot) Push(e *SlotEntry) {
+	e.Handle = s.l.PushBack(e)
+}
+
+func (s *listSlot) Len() int {
+	return s.l.Len()
+}
+
+func (s *listSlot) Remove(e *SlotEntry) bool {
+	el, ok := e.Handle.(*list.Element)
+	if !ok || el.Value != e {
+		return false
+	}
+	// Remove ignores an element of another list
+	n := s.l.Len()
+	s.l.Remove(el)
+	if s.l.Len() == n {
+		return false
+	}
+	e.Handle = nil
+	return true
+}
+
+func (s *listSlot) Scan(keep func(e *SlotEntry) bool) {
+	for el := s.l.Front(); el != nil; {
+		next := el.Next()
+		if e := el.Value.(*SlotEntry); !keep(e) {
+			s.l.Remove(el)
+			e.Handle = nil
+		}
+		el = next
+	}
+}
+
+func (s *listSlot) Each(fn func(e *SlotEntry)) {
+	for el := s.l.Front(); el != nil; el = el.Next() {
+		fn(el.Value.(*SlotEntry))
+	}
+}
@@ -0,0 +1,37 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSlotStores(t *testing.T) {
+	for name, store := range map[string]func() SlotStore{"slice": NewSliceSlotStore, "list": NewListSlotStore} {
+		t.Run(name, func(t *testing.T) {
+			c := newFakeClock()
+			tw := New(time.Second, 10, WithClock(c), WithSlotStore(store))
+			tw.Start()
+			defer tw.Stop()
+			var n int64
+			job := func(TaskData) { atomic.AddInt64(&n, 1) }
+			for i := 0; i < 100; i++ {
+				tw.AddTask(time.Second, 2, i, nil, job)
+			}
+			tw.AddTask(5*time.Second, -1, "long", nil, job)
+			settle(tw)
+			for i := 0; i < 50; i += 2 {
+				tw.RemoveTask(i)
+			}
+			for i := 0; i < 4; i++ {
+				c.Tick(time.Second)
+				settle(tw)
+			}
+			// 75 tasks ran twice, the long one not yet
+			waitCount(t, &n, 150)
+			if tw.Len() != 1 || !tw.HasTask("long") {
+				t.Fatalf("%d tasks left", tw.Len())
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// WithSlotCapacity set a soft capacity of the slots of the wheel backend, a task landing in a full slot
+// is placed in the nearest following slot with room within maxShift ticks, so it never fires early.
+// When every slot in range is full the task stays in its slot. Default is no capacity.
+func WithSlotCapacity(n int, maxShift int) Option {
+	return func(tw *TimeWheel) {
+		if n > 0 && maxShift > 0 {
+			tw.slotCap = n
+			tw.maxShift = maxShift
+		}
+	}
+}
+
+// DisplaceStats placements moved to a later slot by the slot capacity
+type DisplaceStats struct {
+	Tasks int64         // placements moved to a later slot
+	Delay time.Duration // total delay added by the moves
+}
+
+// Displaced get the placements moved by the slot capacity, see WithSlotCapacity
+func (tw *TimeWheel) Displaced() DisplaceStats {
+	wb, ok := wheelOf(tw.backend)
+	if !ok {
+		return DisplaceStats{}
+	}
+	return DisplaceStats{
+		Tasks: atomic.LoadInt64(&wb.displacedNum),
+		Delay: time.Duration(atomic.LoadInt64(&wb.displacedTicks)) * tw.Interval(),
+	}
+}
+
+// number of extra ticks to reach a slot with room, 0 if the slot has room. ok is false when none is found.
+func (b *wheelBackend) displace(ticks int) (shift int, ok bool) {
+	if b.slotCap == 0 {
+		return 0, true
+	}
+	pos, _ := b.getPositionAndCircle(ticks)
+	if b.slots[pos].Len() < b.slotCap {
+		return 0, true
+	}
+	for shift = 1; shift <= b.maxShift; shift++ {
+		pos, _ = b.getPositionAndCircle(ticks + shift)
+		if b.slots[pos].Len() < b.slotCap {
+			atomic.AddInt64(&b.displacedNum, 1)
+			atomic.AddInt64(&b.displacedTicks, int64(shift))
+			return shift, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,61 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSlotCapacity(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 200, WithClock(c), WithSlotCapacity(100, 150), WithAddBuffer(1024))
+	tw.Start()
+	defer tw.Stop()
+	start := c.Now()
+	var early, n int64
+	for i := 0; i < 10000; i++ {
+		tw.AddTask(5*time.Second, 1, i, nil, func(TaskData) {
+			atomic.AddInt64(&n, 1)
+			if c.Now().Sub(start) < 5*time.Second {
+				atomic.AddInt64(&early, 1)
+			}
+		})
+	}
+	settle(tw)
+	// 100 tasks in each of the 100 slots following the requested one
+	full := 0
+	for _, l := range tw.SlotLengths() {
+		if l > 100 {
+			t.Fatalf("slot of %d tasks", l)
+		}
+		if l == 100 {
+			full++
+		}
+	}
+	if full != 100 {
+		t.Fatalf("%d full slots", full)
+	}
+	if d := tw.Displaced(); d.Tasks != 9900 || d.Delay != 495000*time.Second {
+		t.Fatalf("displaced %+v", d)
+	}
+	var max time.Duration
+	tw.Range(func(_ interface{}, info TaskInfo) bool {
+		if info.Displaced > max {
+			max = info.Displaced
+		}
+		return true
+	})
+	if max != 99*time.Second {
+		t.Fatalf("largest displacement %v", max)
+	}
+	for i := 0; i < 120; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	for i := 0; i < 200 && atomic.LoadInt64(&n) != 10000; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if atomic.LoadInt64(&n) != 10000 || atomic.LoadInt64(&early) != 0 {
+		t.Fatalf("%d runs, %d early", atomic.LoadInt64(&n), atomic.LoadInt64(&early))
+	}
+}
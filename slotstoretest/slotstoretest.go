@@ -0,0 +1,170 @@
+// Package slotstoretest check a timewheel.SlotStore implementation against the behavior the wheel relies on.
+//
+//	func TestMyStore(t *testing.T) {
+//		slotstoretest.Run(t, NewMyStore)
+//	}
+package slotstoretest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nosixtools/timewheel"
+)
+
+// Run check the stores created by newStore, every subtest uses a fresh store
+func Run(t *testing.T, newStore func() timewheel.SlotStore) {
+	t.Run("PushLen", func(t *testing.T) {
+		s := newStore()
+		if s.Len() != 0 {
+			t.Fatalf("new store holds %d entries", s.Len())
+		}
+		entries := push(s, 5)
+		if s.Len() != 5 {
+			t.Fatalf("got %d entries, want 5", s.Len())
+		}
+		expect(t, s, entries)
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		s := newStore()
+		entries := push(s, 5)
+		for _, i := range []int{2, 0, 4} {
+			if !s.Remove(entries[i]) {
+				t.Fatalf("remove of entry %d failed", i)
+			}
+		}
+		if s.Remove(entries[2]) {
+			t.Fatal("entry removed twice")
+		}
+		if s.Remove(timewheel.NewSlotEntry("absent")) {
+			t.Fatal("absent entry removed")
+		}
+		expect(t, s, []*timewheel.SlotEntry{entries[1], entries[3]})
+	})
+
+	t.Run("RemoveFromOtherStore", func(t *testing.T) {
+		a, b := newStore(), newStore()
+		entries := push(a, 2)
+		if b.Remove(entries[0]) {
+			t.Fatal("entry of another store removed")
+		}
+		expect(t, a, entries)
+	})
+
+	t.Run("Scan", func(t *testing.T) {
+		s := newStore()
+		entries := push(s, 6)
+		var seen []*timewheel.SlotEntry
+		s.Scan(func(e *timewheel.SlotEntry) bool {
+			seen = append(seen, e)
+			return e.Key().(int)%2 == 0
+		})
+		same(t, "scanned", seen, entries)
+		kept := []*timewheel.SlotEntry{entries[0], entries[2], entries[4]}
+		expect(t, s, kept)
+		if s.Remove(entries[1]) {
+			t.Fatal("scanned out entry removed")
+		}
+		for _, e := range kept {
+			if !s.Remove(e) {
+				t.Fatalf("remove of kept entry %v failed", e.Key())
+			}
+		}
+		expect(t, s, nil)
+	})
+
+	t.Run("Repush", func(t *testing.T) {
+		a, b := newStore(), newStore()
+		entries := push(a, 3)
+		a.Scan(func(e *timewheel.SlotEntry) bool {
+			return e != entries[1]
+		})
+		b.Push(entries[1])
+		if a.Remove(entries[1]) {
+			t.Fatal("entry removed from its former store")
+		}
+		if !b.Remove(entries[1]) {
+			t.Fatal("re-pushed entry not removed")
+		}
+		expect(t, a, []*timewheel.SlotEntry{entries[0], entries[2]})
+		expect(t, b, nil)
+	})
+
+	t.Run("Large", func(t *testing.T) {
+		s := newStore()
+		entries := push(s, 1000)
+		s.Scan(func(e *timewheel.SlotEntry) bool {
+			return e.Key().(int) >= 990
+		})
+		expect(t, s, entries[990:])
+		entries = append(entries[990:], push(s, 3)...)
+		expect(t, s, entries)
+	})
+}
+
+// Benchmark measure a tick worth of operations on the stores created by newStore: n entries are pushed,
+// a quarter is removed one by one and the rest is scanned out
+func Benchmark(b *testing.B, newStore func() timewheel.SlotStore, n int) {
+	entries := make([]*timewheel.SlotEntry, n)
+	for i := range entries {
+		entries[i] = timewheel.NewSlotEntry(i)
+	}
+	s := newStore()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, e := range entries {
+			s.Push(e)
+		}
+		for j := 0; j < n; j += 4 {
+			s.Remove(entries[j])
+		}
+		s.Scan(func(e *timewheel.SlotEntry) bool {
+			return false
+		})
+	}
+}
+
+func push(s timewheel.SlotStore, n int) []*timewheel.SlotEntry {
+	base := s.Len()
+	entries := make([]*timewheel.SlotEntry, n)
+	for i := range entries {
+		entries[i] = timewheel.NewSlotEntry(base + i)
+		s.Push(entries[i])
+	}
+	return entries
+}
+
+// check the entries of the store and their order with Each and Len
+func expect(t *testing.T, s timewheel.SlotStore, want []*timewheel.SlotEntry) {
+	t.Helper()
+	if s.Len() != len(want) {
+		t.Fatalf("got %d entries, want %d", s.Len(), len(want))
+	}
+	var got []*timewheel.SlotEntry
+	s.Each(func(e *timewheel.SlotEntry) {
+		got = append(got, e)
+	})
+	same(t, "stored", got, want)
+}
+
+func same(t *testing.T, what string, got, want []*timewheel.SlotEntry) {
+	t.Helper()
+	if fmt.Sprint(keys(got)) != fmt.Sprint(keys(want)) {
+		t.Fatalf("%s entries %v, want %v", what, keys(got), keys(want))
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("%s entry %d is a different entry with the same key", what, i)
+		}
+	}
+}
+
+func keys(entries []*timewheel.SlotEntry) []interface{} {
+	k := make([]interface{}, len(entries))
+	for i, e := range entries {
+		k[i] = e.Key()
+	}
+	return k
+}
@@ -0,0 +1,14 @@
+package slotstoretest_test
+
+import (
+	"testing"
+
+	"github.com/nosixtools/timewheel"
+	"github.com/nosixtools/timewheel/slotstoretest"
+)
+
+func TestSlice(t *testing.T) { slotstoretest.Run(t, timewheel.NewSliceSlotStore) }
+func TestList(t *testing.T)  { slotstoretest.Run(t, timewheel.NewListSlotStore) }
+
+func BenchmarkSlice(b *testing.B) { slotstoretest.Benchmark(b, timewheel.NewSliceSlotStore, 256) }
+func BenchmarkList(b *testing.B)  { slotstoretest.Benchmark(b, timewheel.NewListSlotStore, 256) }
@@ -0,0 +1,66 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// SlowJobHandler receive the executions lasting longer than the slow job threshold
+type SlowJobHandler func(key interface{}, d time.Duration, info TaskInfo)
+
+// WithSlowJobThreshold call handler on the job goroutine after every execution lasting longer than d
+func WithSlowJobThreshold(d time.Duration, handler SlowJobHandler) Option {
+	return func(tw *TimeWheel) {
+		tw.slowThreshold = d
+		tw.slowHandler = handler
+	}
+}
+
+// SlowJobCount get the number of executions that exceeded the slow job threshold
+func (tw *TimeWheel) SlowJobCount() int64 {
+	return atomic.LoadInt64(&tw.slowNum)
+}
+
+// check the execution duration against the threshold
+func (tw *TimeWheel) checkSlowJob(key interface{}, info TaskInfo, d time.Duration) {
+	if tw.slowThreshold <= 0 || d <= tw.slowThreshold {
+		return
+	}
+	atomic.AddInt64(&tw.slowNum, 1)
+	if tw.slowHandler == nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tw.logger.Printf("timewheel: slow job handler panic recovered, key: %v, panic: %v", key, r)
+		}
+	}()
+	tw.slowHandler(key, d, info)
+}
+
+// FailureAlertHandler receive the task whose runs failed failures times in a row, err is the last error
+type FailureAlertHandler func(key interface{}, failures int64, err error, info TaskInfo)
+
+// WithFailureAlert call handler on the job goroutine once a task failed n runs in a row, a run failing
+// by its error or its panic. The handler is called once per streak, a successful run starts a new one.
+func WithFailureAlert(n int, handler FailureAlertHandler) Option {
+	return func(tw *TimeWheel) {
+		if n > 0 {
+			tw.alertThreshold = int64(n)
+			tw.alertHandler = handler
+		}
+	}
+}
+
+// call the alert handler when the failures reach the threshold
+func (tw *TimeWheel) checkFailureAlert(key interface{}, info TaskInfo, failures int64, err error) {
+	if tw.alertHandler == nil || failures != tw.alertThreshold {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tw.logger.Printf("timewheel: failure alert handler panic recovered, key: %v, panic: %v", key, r)
+		}
+	}()
+	tw.alertHandler(key, failures, err, info)
+}
@@ -0,0 +1,44 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSlowJobThreshold(t *testing.T) {
+	var mu sync.Mutex
+	slow := map[interface{}][]time.Duration{}
+	c := newFakeClock()
+	tw := New(time.Second, 4, WithClock(c), WithSlowJobThreshold(10*time.Millisecond, func(key interface{}, d time.Duration, info TaskInfo) {
+		mu.Lock()
+		slow[key] = append(slow[key], d)
+		mu.Unlock()
+		if info.Key != key {
+			t.Error("info", info.Key)
+		}
+	}))
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(time.Second, 3, "slow", nil, func(TaskData) { time.Sleep(20 * time.Millisecond) })
+	tw.AddTask(time.Second, 3, "fast", nil, func(TaskData) {})
+	c.Tick(time.Second)
+	for i := 0; i < 3; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+		time.Sleep(25 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(slow) != 1 || len(slow["slow"]) != 3 {
+		t.Fatal(slow)
+	}
+	for _, d := range slow["slow"] {
+		if d < 20*time.Millisecond {
+			t.Fatal(d)
+		}
+	}
+	if n := tw.SlowJobCount(); n != 3 {
+		t.Fatal(n)
+	}
+}
@@ -0,0 +1,283 @@
+package timewheel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// TaskSpec serializable description of a scheduled task
+type TaskSpec struct {
+	Key      interface{}
+	Gen      uint64 // generation of the task, kept by Restore, see AddTaskGen and Store
+	Interval time.Duration
+	Times    int           // remaining run times, -1 means no limit
+	Delay    time.Duration // remaining delay until the next run when the snapshot was taken
+	Next     time.Time     // time of the next run
+	Data     TaskData
+	JobName  string // name of the registered job, empty for plain jobs
+	Tags     []string
+	Priority int
+	Until    time.Time // no run is scheduled after it, zero means no deadline
+	Expires  time.Time // the task is removed at this time, see TTL, zero means never
+	Aligned  bool      // the runs stay on the multiples of the interval, see AlignToPeriod
+
+	// prerequisite of a waiting task, see DependsOn, Delay then counts from its completion and Next is zero
+	DependsOn         interface{}
+	DependsOnFirstRun bool
+
+	// acknowledged runs, see Acked, a spec whose Times is 0 only holds an unacknowledged final run
+	AckTimeout  time.Duration
+	MaxAttempts int
+	Redeliver   time.Time // the unacknowledged run is dispatched again at this time, zero if none
+	Attempt     int       // attempts of the unacknowledged run
+
+	Schedule Schedule  // run times of the task, see AddScheduledTask, nil means every interval
+	Anchor   time.Time // ideal time of the postponed run, see Postpone
+	Paused   bool      // see PauseTask
+	Missed   int       // runs skipped while paused
+	Resume   ResumePolicy
+	Skip     int         // due runs left to skip, see SkipNext
+	Held     []time.Time // ideal times of the runs held by HoldDeliveries, nil if the runs are dispatched
+
+	// the other options, see the TaskOption of the same name
+	Jitter           time.Duration
+	MinGap           time.Duration
+	Precise          bool
+	Critical         bool
+	Group            string
+	Labels           map[string]string
+	BackoffFactor    float64
+	BackoffMax       time.Duration
+	BreakerThreshold int
+	BreakerCoolDown  time.Duration
+}
+
+// describe the task, only called on the wheel goroutine
+func (t *task) spec(now time.Time) TaskSpec {
+	delay := t.next.Sub(now)
+	if delay < 0 {
+		delay = 0
+	}
+	spec := TaskSpec{
+		Key:      t.key,
+		Gen:      t.gen,
+		Interval: t.interval,
+		Times:    t.times,
+		Delay:    delay,
+		Next:     t.next,
+		Data:     copyTaskData(t.taskData),
+		JobName:  t.jobName,
+		Tags:     append([]string(nil), t.tags...),
+		Priority: t.priority,
+		Until:    t.until,
+		Expires:  t.expires,
+		Aligned:  t.alignPeriod,
+	}
+	if t.ack != nil {
+		spec.AckTimeout, spec.MaxAttempts = t.ack.timeout, t.ack.maxAttempts
+	}
+	if t.dep != nil {
+		spec.Delay, spec.Next = t.dep.delay, time.Time{}
+		spec.DependsOn, spec.DependsOnFirstRun = t.dep.key, t.dep.firstRun
+	}
+	spec.Schedule = t.schedule
+	spec.Anchor = t.anchor
+	spec.Paused = t.isPaused()
+	spec.Missed = t.missed
+	spec.Resume = t.resume
+	spec.Skip = int(atomic.LoadInt32(&t.skip))
+	if t.mailbox != nil {
+		spec.Held = append(make([]time.Time, 0, len(t.mailbox.runs)), t.mailbox.runs...)
+	}
+	spec.Jitter = t.jitter
+	spec.MinGap = t.minGap
+	spec.Precise = t.precise
+	spec.Critical = t.critical
+	spec.Group = t.group
+	spec.Labels = t.labels
+	if t.backoff != nil {
+		spec.BackoffFactor, spec.BackoffMax = t.backoff.factor, t.backoff.max
+	}
+	if t.breaker != nil {
+		spec.BreakerThreshold, spec.BreakerCoolDown = int(t.breaker.threshold), t.breaker.coolDown
+	}
+	return spec
+}
+
+// report why the task can not be described by a spec, nil if it can
+func (t *task) representable() error {
+	var held []string
+	if t.batch != nil {
+		held = append(held, "batch handler")
+	}
+	if t.onDone != nil {
+		held = append(held, "OnExhausted callback")
+	}
+	if len(t.then) > 0 {
+		held = append(held, "chained steps")
+	}
+	if len(t.attached) > 0 {
+		held = append(held, "attached jobs")
+	}
+	if len(held) > 0 {
+		return fmt.Errorf("%w, task %v holds %s", ErrNotRepresentable, t.key, strings.Join(held, ", "))
+	}
+	return nil
+}
+
+// JobResolver map a restored task back to its job, see JobRegistry.Resolver
+type JobResolver func(spec TaskSpec) (Job, error)
+
+// JobResolverCtx map a restored task back to a job receiving a context, see RestoreCtx
+type JobResolverCtx func(spec TaskSpec) (JobCtx, error)
+
+// Snapshot describe every scheduled task, taken on the wheel goroutine so the result is consistent.
+// The task data are shallow copies. A task holding callbacks, a batch handler, chained steps, attached
+// jobs or an OnExhausted callback, can not be described: it is left out and reported by an error wrapping
+// ErrNotRepresentable, the specs of the other tasks are returned with it.
+func (tw *TimeWheel) Snapshot() ([]TaskSpec, error) {
+	var specs []TaskSpec
+	var errs []error
+	if err := tw.exec(func() {
+		now := tw.clock.Now()
+		specs = make([]TaskSpec, 0, tw.backend.len())
+		each := func(t *task) {
+			if t.times == 0 {
+				return
+			}
+			if err := t.representable(); err != nil {
+				errs = append(errs, err)
+				return
+			}
+			specs = append(specs, t.spec(now))
+		}
+		tw.eachWaiting(each)
+		tw.eachDependent(each)
+		tw.backend.each(each)
+		if len(tw.unacked) > 0 {
+			specs = tw.unackedSpecs(specs, now)
+		}
+	}); err != nil {
+		return nil, err
+	}
+	return specs, errors.Join(errs...)
+}
+
+// Restore register the tasks described by specs, the job of every task is given by resolve.
+// The next run is spec.Next, or spec.Delay from now when Next is zero, so the time spent
+// while the process was down is deducted; overdue tasks run on the next tick.
+// Every spec is tried, the errors are joined.
+func (tw *TimeWheel) Restore(specs []TaskSpec, resolve JobResolver) error {
+	if resolve == nil {
+		return ErrInvalidParams
+	}
+	return tw.RestoreCtx(specs, func(spec TaskSpec) (JobCtx, error) {
+		job, err := resolve(spec)
+		if err != nil || job == nil {
+			return nil, err
+		}
+		return wrapJob(job), nil
+	})
+}
+
+// RestoreCtx register the tasks like Restore, the jobs receive a context, as the acked tasks need, see Ack
+func (tw *TimeWheel) RestoreCtx(specs []TaskSpec, resolve JobResolverCtx) error {
+	if resolve == nil {
+		return ErrInvalidParams
+	}
+	var errs []error
+	for _, spec := range specs {
+		if err := tw.restoreTask(spec, resolve); err != nil {
+			errs = append(errs, fmt.Errorf("restore task %v: %w", spec.Key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (tw *TimeWheel) restoreTask(spec TaskSpec, resolve JobResolverCtx) error {
+	job, err := resolve(spec)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return ErrInvalidParams
+	}
+	times := spec.Times
+	if times == 0 && !spec.Redeliver.IsZero() {
+		// only the unacknowledged final run is left
+		times = 1
+	}
+	task, err := tw.newTask(spec.Interval, times, spec.Key, spec.Data, job)
+	if err != nil {
+		return err
+	}
+	task.applySpec(spec)
+	if err = tw.acceptOptions(task); err != nil {
+		tw.dropTask(task)
+		return err
+	}
+	DependsOn(spec.DependsOn, spec.DependsOnFirstRun)(task)
+	task.next = spec.Next
+	if task.next.IsZero() {
+		task.next = tw.clock.Now().Add(spec.Delay)
+	}
+	Acked(spec.AckTimeout, spec.MaxAttempts)(task)
+	if !spec.Redeliver.IsZero() && task.ack != nil {
+		task.attempts = spec.Attempt
+		if spec.Times == 0 {
+			task.next = spec.Redeliver
+		} else {
+			task.redeliver = spec.Redeliver
+		}
+	}
+	task.atNext = true
+	return tw.submit(context.Background(), task)
+}
+
+// set the generation and the definition described by spec on the new task, the timing is left to the caller
+func (t *task) applySpec(spec TaskSpec) {
+	if spec.Gen != 0 {
+		t.gen = spec.Gen
+	}
+	t.jobName = spec.JobName
+	t.tags = append([]string(nil), spec.Tags...)
+	t.priority = spec.Priority
+	t.until = spec.Until
+	t.expires = spec.Expires
+	t.alignPeriod = spec.Aligned
+	t.schedule = spec.Schedule
+	t.anchor = spec.Anchor
+	if spec.Paused {
+		t.paused = 1
+	}
+	t.missed = spec.Missed
+	t.resume = spec.Resume
+	t.skip = int32(spec.Skip)
+	if spec.Held != nil {
+		t.mailbox = &mailbox{runs: append([]time.Time(nil), spec.Held...)}
+	}
+	t.jitter = spec.Jitter
+	t.minGap = spec.MinGap
+	t.precise = spec.Precise
+	t.critical = spec.Critical
+	t.group = spec.Group
+	t.labels = spec.Labels
+	Backoff(spec.BackoffFactor, spec.BackoffMax)(t)
+	CircuitBreaker(spec.BreakerThreshold, spec.BreakerCoolDown)(t)
+}
+
+// shallow copy of the data
+func copyTaskData(data TaskData) TaskData {
+	if data == nil {
+		return nil
+	}
+	c := make(TaskData, len(data))
+	for k, v := range data {
+		c[k] = v
+	}
+	return c
+}
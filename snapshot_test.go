@@ -0,0 +1,155 @@
+package timewheel
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSnapshotFullDefinition(t *testing.T) {
+	c := newFakeClock()
+	opts := []Option{WithClock(c), WithPreciseTasks(4), WithMutexGroup("g", 1, GroupQueue), WithMetricLabels("team")}
+	src := New(time.Minute, 60, opts...)
+	src.Start()
+	defer src.Stop()
+	job := func(TaskData) {}
+	w := Weekly{Days: []time.Weekday{time.Monday}, At: 8 * time.Hour, Location: time.UTC}
+	if err := src.AddScheduledTask(w, "weekly", nil, job); err != nil {
+		t.Fatal(err)
+	}
+	err := src.AddTaskWith(time.Hour, -1, "k", nil, job, Jitter(time.Second), MinGap(time.Minute), Precise(),
+		Critical(), InGroup("g"), MetricLabels(map[string]string{"team": "a"}), OnResume(ResumeReplayAll),
+		Backoff(2, time.Hour), CircuitBreaker(3, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = src.SkipNextN("k", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err = src.HoldDeliveries("k"); err != nil {
+		t.Fatal(err)
+	}
+	if err = src.PauseTask("k"); err != nil {
+		t.Fatal(err)
+	}
+	specs, err := src.Snapshot()
+	if err != nil || len(specs) != 2 {
+		t.Fatal(specs, err)
+	}
+
+	dst := New(time.Minute, 60, opts...)
+	dst.Start()
+	defer dst.Stop()
+	if err = dst.Restore(specs, func(TaskSpec) (Job, error) { return job, nil }); err != nil {
+		t.Fatal(err)
+	}
+	again, err := dst.Snapshot()
+	if err != nil || len(again) != 2 {
+		t.Fatal(again, err)
+	}
+	byKey := func(specs []TaskSpec) map[interface{}]TaskSpec {
+		m := map[interface{}]TaskSpec{}
+		for _, s := range specs {
+			m[s.Key] = s
+		}
+		return m
+	}
+	before, after := byKey(specs), byKey(again)
+	for _, key := range []string{"weekly", "k"} {
+		if !reflect.DeepEqual(before[key], after[key]) {
+			t.Errorf("%s: restored as %+v, want %+v", key, after[key], before[key])
+		}
+	}
+	k := after["k"]
+	if !k.Paused || k.Skip != 2 || k.Held == nil || !k.Precise || !k.Critical || k.Group != "g" ||
+		k.Jitter != time.Second || k.MinGap != time.Minute || k.Resume != ResumeReplayAll ||
+		k.BackoffFactor != 2 || k.BreakerThreshold != 3 || k.Labels["team"] != "a" {
+		t.Fatalf("%+v", k)
+	}
+	if !reflect.DeepEqual(after["weekly"].Schedule, Schedule(w)) {
+		t.Fatal(after["weekly"].Schedule)
+	}
+	if n, _ := dst.PreciseTasks(); n != 1 {
+		t.Fatal(n)
+	}
+}
+
+func TestSnapshotNotRepresentable(t *testing.T) {
+	tw := New(time.Minute, 60)
+	tw.Start()
+	defer tw.Stop()
+	job := func(TaskData) {}
+	tw.AddTask(time.Hour, 1, "plain", nil, job)
+	tw.AddTaskWith(time.Hour, 1, "done", nil, job, OnExhausted(func(interface{}, TaskData) {}))
+	tw.AddBatchTask(time.Hour, 1, "batch", nil, NewBatchHandler(func([]TaskEntry) {}))
+	specs, err := tw.Snapshot()
+	if !errors.Is(err, ErrNotRepresentable) {
+		t.Fatal(err)
+	}
+	if len(specs) != 1 || specs[0].Key != "plain" {
+		t.Fatal(specs)
+	}
+}
+
+// fire times of the keys over ticks, the wheel is snapshot after restoreAt ticks and restored on a
+// fresh wheel after down of downtime
+func firingTimes(t *testing.T, ticks, restoreAt int, down time.Duration) map[interface{}][]time.Time {
+	c := newFakeClock()
+	var mu sync.Mutex
+	fired := make(map[interface{}][]time.Time)
+	resolve := func(spec TaskSpec) (Job, error) {
+		key := spec.Key
+		return func(TaskData) {
+			mu.Lock()
+			fired[key] = append(fired[key], c.Now())
+			mu.Unlock()
+		}, nil
+	}
+	tw := New(time.Second, 8, WithClock(c))
+	tw.Start()
+	for _, spec := range []TaskSpec{
+		{Key: "one", Interval: 5 * time.Second, Times: 1, Data: TaskData{"a": 1}},
+		{Key: "rec", Interval: 3 * time.Second, Times: -1},
+		{Key: "three", Interval: 2 * time.Second, Times: 3},
+	} {
+		job, _ := resolve(spec)
+		tw.AddTask(spec.Interval, spec.Times, spec.Key, spec.Data, job)
+	}
+	for i := 0; i < ticks; i++ {
+		if i == restoreAt {
+			specs, err := tw.Snapshot()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tw.Stop()
+			c.mu.Lock()
+			c.now = c.now.Add(down)
+			c.mu.Unlock()
+			tw = New(time.Second, 8, WithClock(c))
+			tw.Start()
+			if err := tw.Restore(specs, resolve); err != nil {
+				t.Fatal(err)
+			}
+		}
+		c.Tick(time.Second)
+		settle(tw)
+	}
+	tw.Stop()
+	return fired
+}
+
+func TestSnapshotRestoreFireTimes(t *testing.T) {
+	want := firingTimes(t, 14, -1, 0)
+	if len(want["one"]) != 1 || len(want["rec"]) != 4 || len(want["three"]) != 3 {
+		t.Fatal(want)
+	}
+	if got := firingTimes(t, 14, 2, 0); !reflect.DeepEqual(got, want) {
+		t.Fatalf("restored\n%v\nwant\n%v", got, want)
+	}
+	// the downtime is deducted, the restored tasks fire at the original times
+	if got := firingTimes(t, 13, 1, time.Second); !reflect.DeepEqual(got, want) {
+		t.Fatalf("restored after a downtime\n%v\nwant\n%v", got, want)
+	}
+}
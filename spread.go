@@ -0,0 +1,19 @@
+package timewheel
+
+// WithPhaseSpreading shift the first run of every task by up to one interval worth of ticks, the shift
+// is derived from the key so tasks sharing an interval fire on different ticks. The shift only delays
+// the first run, the cadence of the task is exact from there.
+func WithPhaseSpreading() Option {
+	return func(tw *TimeWheel) {
+		tw.spreadPhase = true
+	}
+}
+
+// ticks the first run of the task is delayed by, stable for a key
+func (tw *TimeWheel) phaseTicks(task *task) int {
+	n := uint64(task.interval / tw.interval)
+	if n <= 1 {
+		return 0
+	}
+	return int(tw.taskRecord.hash(task.key) % n)
+}
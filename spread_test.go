@@ -0,0 +1,67 @@
+package timewheel
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPhaseSpreading(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 60, WithClock(c), WithPhaseSpreading(), WithAddBuffer(64))
+	tw.Start()
+	defer tw.Stop()
+	var tick int64
+	var mu sync.Mutex
+	fires := make(map[int][]int64)
+	for i := 0; i < 1000; i++ {
+		i := i
+		tw.AddTask(10*time.Second, -1, i, nil, func(TaskData) {
+			mu.Lock()
+			fires[i] = append(fires[i], atomic.LoadInt64(&tick))
+			mu.Unlock()
+		})
+	}
+	settle(tw)
+	hot := tw.HottestSlots(20)
+	if len(hot) != 10 || hot[0].Count > 150 {
+		t.Fatalf("hottest slots %v", hot)
+	}
+
+	for i := 0; i < 30; i++ {
+		atomic.AddInt64(&tick, 1)
+		c.Tick(time.Second)
+		settle(tw)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for k, f := range fires {
+		// never earlier than the interval, then every 10 ticks
+		if len(f) < 2 || f[0] < 11 || f[0] > 20 {
+			t.Fatalf("task %d fired on ticks %v", k, f)
+		}
+		for j := 1; j < len(f); j++ {
+			if f[j]-f[j-1] != 10 {
+				t.Fatalf("task %d fired on ticks %v", k, f)
+			}
+		}
+	}
+	if len(fires) != 1000 {
+		t.Fatalf("%d tasks fired", len(fires))
+	}
+}
+
+func TestPhaseSpreadingStable(t *testing.T) {
+	tw := New(time.Second, 60, WithPhaseSpreading())
+	k := &task{key: "k", interval: 10 * time.Second}
+	shift := tw.phaseTicks(k)
+	for i := 0; i < 10; i++ {
+		if s := tw.phaseTicks(&task{key: "k", interval: 10 * time.Second}); s != shift {
+			t.Fatalf("shift %d then %d", shift, s)
+		}
+	}
+	if s := tw.phaseTicks(&task{key: "k", interval: time.Second}); s != 0 {
+		t.Fatalf("a task of one tick shifted by %d", s)
+	}
+}
@@ -0,0 +1,42 @@
+package timewheel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStableOrder(t *testing.T) {
+	for _, kind := range []Backend{Wheel, Heap} {
+		c := newFakeClock()
+		tw := New(10*time.Millisecond, 3, WithClock(c), WithStableOrder(), WithBackend(kind))
+		tw.Start()
+		events, cancel := tw.Subscribe(1 << 12)
+		tw.AddTask(20*time.Millisecond, -1, "A", nil, func(TaskData) {})
+		tw.AddTask(40*time.Millisecond, 50, "B", nil, func(TaskData) {})
+		settle(tw)
+		for i := 0; i < 50*4+4; i++ {
+			c.Tick(10 * time.Millisecond)
+			settle(tw)
+		}
+		cancel()
+		var aAt time.Time
+		n := 0
+		for ev := range events {
+			if ev.Type != EventFired {
+				continue
+			}
+			if ev.Key == "A" {
+				aAt = ev.Time
+				continue
+			}
+			if !aAt.Equal(ev.Time) {
+				t.Fatal(kind, n, "B before A")
+			}
+			n++
+		}
+		if n != 50 {
+			t.Fatal(n)
+		}
+		tw.Stop()
+	}
+}
@@ -0,0 +1,36 @@
+package timewheel
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStartContext(t *testing.T) {
+	c := newFakeClock()
+	tw := New(10*time.Millisecond, 8, WithClock(c))
+	ctx, cancel := context.WithCancel(context.Background())
+	tw.StartContext(ctx)
+	var n int64
+	tw.AddTask(10*time.Millisecond, -1, "k", nil, func(TaskData) { atomic.AddInt64(&n, 1) })
+	settle(tw)
+	c.Tick(10 * time.Millisecond)
+	c.Tick(10 * time.Millisecond)
+	settle(tw)
+	cancel()
+	select {
+	case <-tw.loopDone:
+	case <-time.After(time.Second):
+		t.Fatal("wheel still running")
+	}
+	got := atomic.LoadInt64(&n)
+	if err := tw.AddTask(time.Second, 1, "x", nil, func(TaskData) {}); err != ErrWheelStopped {
+		t.Fatal(err)
+	}
+	tw.Stop()
+	time.Sleep(10 * time.Millisecond)
+	if atomic.LoadInt64(&n) != got {
+		t.Fatal("ticked after cancel")
+	}
+}
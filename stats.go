@@ -0,0 +1,102 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats execution statistics of a task
+type Stats struct {
+	Runs         int64         // times the task was dispatched
+	LastFire     time.Time     // time of the last dispatch
+	LastDuration time.Duration // duration of the last finished run
+	LastError    error         // error of the last finished run, nil if it succeeded
+	Failures     int64         // consecutive failed runs, reset by a success and by UpdateTask
+	LastFailure  error         // error of the last failed run, kept after a success
+	LastFailedAt time.Time     // time the last failed run returned
+	Deferred     int64         // runs deferred by MinGap
+	Redelivered  int64         // runs dispatched again for lack of an Ack, see Acked
+	Breaker      BreakerState  // state of the circuit breaker, see CircuitBreaker
+	LastLateness time.Duration // time from the ideal time of the last run to its dispatch
+	MaxLateness  time.Duration // longest lateness of the runs, see WithLatenessAlert
+}
+
+// statistics of a task, accessed atomically
+type taskStats struct {
+	runs         int64
+	lastFire     int64
+	lastDuration int64
+	lastErr      atomic.Value // errBox
+	failures     int64
+	lastFailure  atomic.Value // errBox
+	lastFailedAt int64
+	deferred     int64
+	redelivered  int64
+	lastLate     int64
+	maxLate      int64
+}
+
+// atomic.Value needs a consistent concrete type
+type errBox struct {
+	err error
+}
+
+// record a dispatch
+func (s *taskStats) fired(now time.Time) {
+	atomic.AddInt64(&s.runs, 1)
+	atomic.StoreInt64(&s.lastFire, now.UnixNano())
+}
+
+// record a finished run, return the number of consecutive failures
+func (s *taskStats) done(now time.Time, d time.Duration, err error) int64 {
+	atomic.StoreInt64(&s.lastDuration, int64(d))
+	s.lastErr.Store(errBox{err})
+	if err == nil {
+		atomic.StoreInt64(&s.failures, 0)
+		return 0
+	}
+	s.lastFailure.Store(errBox{err})
+	atomic.StoreInt64(&s.lastFailedAt, now.UnixNano())
+	return atomic.AddInt64(&s.failures, 1)
+}
+
+func (s *taskStats) snapshot() Stats {
+	st := Stats{
+		Runs:         atomic.LoadInt64(&s.runs),
+		LastDuration: time.Duration(atomic.LoadInt64(&s.lastDuration)),
+		Failures:     atomic.LoadInt64(&s.failures),
+		Deferred:     atomic.LoadInt64(&s.deferred),
+		Redelivered:  atomic.LoadInt64(&s.redelivered),
+		LastLateness: time.Duration(atomic.LoadInt64(&s.lastLate)),
+		MaxLateness:  time.Duration(atomic.LoadInt64(&s.maxLate)),
+	}
+	if n := atomic.LoadInt64(&s.lastFire); n != 0 {
+		st.LastFire = time.Unix(0, n)
+	}
+	if b, ok := s.lastErr.Load().(errBox); ok {
+		st.LastError = b.err
+	}
+	if b, ok := s.lastFailure.Load().(errBox); ok {
+		st.LastFailure = b.err
+		st.LastFailedAt = time.Unix(0, atomic.LoadInt64(&s.lastFailedAt))
+	}
+	return st
+}
+
+// TaskStats get the execution statistics of the task
+func (tw *TimeWheel) TaskStats(key interface{}) (Stats, error) {
+	if key == nil {
+		return Stats{}, ErrInvalidKey
+	}
+	if !keyComparable(key) {
+		return Stats{}, ErrKeyNotComparable
+	}
+	var st Stats
+	if !tw.taskRecord.view(key, func(t *task) {
+		st = t.stats.snapshot()
+		st.Breaker = t.breaker.load()
+	}) {
+		return Stats{}, ErrTaskNotFound
+	}
+	return st, nil
+}
@@ -0,0 +1,64 @@
+package timewheel
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTaskStats(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 4, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var runs int32
+	boom := errors.New("boom")
+	job := func(context.Context, TaskData) error {
+		if atomic.AddInt32(&runs, 1) == 2 {
+			return boom
+		}
+		time.Sleep(time.Millisecond)
+		return nil
+	}
+	if err := tw.AddTaskErr(time.Second, -1, "a", nil, job); err != nil {
+		t.Fatal(err)
+	}
+	if st, err := tw.TaskStats("a"); err != nil || st.Runs != 0 || !st.LastFire.IsZero() {
+		t.Fatal(st, err)
+	}
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	first := c.Now()
+	st, _ := tw.TaskStats("a")
+	if st.Runs != 1 || !st.LastFire.Equal(first) || st.LastDuration < time.Millisecond || st.LastError != nil {
+		t.Fatal("first run", st)
+	}
+	c.Tick(time.Second)
+	settle(tw)
+	st, _ = tw.TaskStats("a")
+	if st.Runs != 2 || !st.LastFire.Equal(first.Add(time.Second)) || st.LastError != boom || st.Failures != 1 {
+		t.Fatal("failed run", st)
+	}
+	c.Tick(time.Second)
+	settle(tw)
+	st, _ = tw.TaskStats("a")
+	if st.Runs != 3 || st.LastError != nil || st.Failures != 0 || st.LastFailure != boom || !st.LastFailedAt.Equal(first.Add(time.Second)) {
+		t.Fatal("recovered run", st)
+	}
+
+	// a key added again starts over
+	tw.RemoveTask("a")
+	if _, err := tw.TaskStats("a"); err != ErrTaskNotFound {
+		t.Fatal(err)
+	}
+	tw.AddTaskErr(time.Second, -1, "a", nil, job)
+	if st, _ = tw.TaskStats("a"); st.Runs != 0 || st.LastFailure != nil {
+		t.Fatal("stats kept", st)
+	}
+	if _, err := tw.TaskStats(nil); err != ErrInvalidKey {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,123 @@
+package timewheel
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStoppedWheel(t *testing.T) {
+	tw := New(time.Second, 4)
+	tw.Start()
+	tw.AddTask(time.Second, -1, "a", nil, func(TaskData) {})
+	tw.Stop()
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- tw.AddTask(time.Second, 1, i, nil, func(TaskData) {})
+		}(i)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("AddTask blocked on a stopped wheel")
+	}
+	close(errs)
+	for err := range errs {
+		if err != ErrWheelStopped {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.RemoveTask("a"); err != ErrWheelStopped {
+		t.Fatal(err)
+	}
+	if err := tw.UpdateTask("a", time.Second, nil); err != ErrWheelStopped {
+		t.Fatal(err)
+	}
+}
+
+func TestAddTaskConcurrentStop(t *testing.T) {
+	for n := 0; n < 20; n++ {
+		tw := New(time.Millisecond, 4)
+		tw.Start()
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				// succeeds before the stop, fails with ErrWheelStopped after it, never blocks
+				if err := tw.AddTask(time.Second, 1, i, nil, func(TaskData) {}); err != nil && err != ErrWheelStopped {
+					t.Error(err)
+				}
+			}(i)
+		}
+		tw.Stop()
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("AddTask blocked by a concurrent Stop")
+		}
+	}
+}
+
+// clock counting the tickers created
+type tickerCountClock struct {
+	*fakeClock
+	tickers int64
+}
+
+func (c *tickerCountClock) NewTicker(d time.Duration) Ticker {
+	atomic.AddInt64(&c.tickers, 1)
+	return c.fakeClock
+}
+
+func TestConcurrentStartStop(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		c := &tickerCountClock{fakeClock: newFakeClock()}
+		tw := New(time.Second, 8, WithClock(c))
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for g := 0; g < 10; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				<-start
+				for i := 0; i < 20; i++ {
+					tw.Start()
+					if g%2 == 1 && i > 10 {
+						tw.Stop()
+					}
+				}
+			}(g)
+		}
+		close(start)
+		wg.Wait()
+		// exactly one Start won
+		if n := atomic.LoadInt64(&c.tickers); n != 1 {
+			t.Fatalf("%d tickers", n)
+		}
+		select {
+		case <-tw.loopDone:
+		case <-time.After(5 * time.Second):
+			t.Fatal("wheel still running")
+		}
+		tw.Start()
+		if err := tw.AddTask(time.Second, 1, "k", nil, func(TaskData) {}); err != ErrWheelStopped {
+			t.Fatalf("start after stop: %v", err)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package timewheel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Store durable storage of the named tasks, see the redisstore package.
+// Only the tasks added with AddNamedTask are stored.
+type Store interface {
+	// Add persist a new task, fail with ErrDuplicateKey if the key exists
+	Add(spec TaskSpec) error
+	// Get get the task of the key
+	Get(key interface{}) (TaskSpec, bool, error)
+	// Update overwrite the task if the stored one is of the generation spec.Gen, fail with ErrStaleGeneration
+	// if it is not and with ErrTaskNotFound if the key is not stored
+	Update(spec TaskSpec) error
+	// Delete delete the task if it is of the generation gen, 0 means any, report whether it was deleted
+	Delete(key interface{}, gen uint64) (bool, error)
+	// Due claim the tasks whose next run is before t, a claimed task is not returned again while its
+	// claim holds, the updates of the task keep it claimed
+	Due(before time.Time) ([]TaskSpec, error)
+}
+
+// WithStore persist the named tasks in s, only the tasks due within horizon are kept in the wheel,
+// the others are loaded from s periodically. The job registry must be set. The runs are recorded from
+// the job goroutines, so they are bound to the generation of the task: the record of a run landing after
+// the task was removed and added again is rejected by s.
+func WithStore(s Store, horizon time.Duration) Option {
+	return func(tw *TimeWheel) {
+		tw.store = s
+		tw.horizon = horizon
+	}
+}
+
+// keys whose store update is in flight, refill must not load them again
+type pendingKeys struct {
+	mu   sync.Mutex
+	keys map[interface{}]int
+}
+
+func (p *pendingKeys) add(key interface{}) {
+	p.mu.Lock()
+	if p.keys == nil {
+		p.keys = make(map[interface{}]int)
+	}
+	p.keys[key]++
+	p.mu.Unlock()
+}
+
+func (p *pendingKeys) done(key interface{}) {
+	p.mu.Lock()
+	if p.keys[key]--; p.keys[key] <= 0 {
+		delete(p.keys, key)
+	}
+	p.mu.Unlock()
+}
+
+func (p *pendingKeys) has(key interface{}) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.keys[key] > 0
+}
+
+// persist a new named task, report whether it is due soon enough to be kept in the wheel
+func (tw *TimeWheel) storeAdd(t *task) (bool, error) {
+	now := tw.clock.Now()
+	if err := tw.store.Add(t.spec(now)); err != nil {
+		return false, err
+	}
+	return t.next.Sub(now) <= tw.horizon, nil
+}
+
+// persist the state of the task after the run being dispatched, nil if the task is not stored.
+// Called on the wheel goroutine, the returned func does the io.
+func (tw *TimeWheel) storeRun(t *task) func() {
+	if tw.store == nil || t.jobName == "" {
+		return nil
+	}
+	key, gen := t.key, t.gen
+	tw.storePending.add(key)
+	if t.times == 1 {
+		return func() {
+			defer tw.storePending.done(key)
+			if _, err := tw.store.Delete(key, gen); err != nil {
+				tw.logger.Printf("timewheel: store delete failed, key: %v, err: %v", key, err)
+				tw.reportError(ErrorStore, key, err)
+			}
+		}
+	}
+	spec := t.spec(tw.clock.Now())
+	if spec.Times > 0 {
+		spec.Times--
+	}
+	spec.Next = spec.Next.Add(spec.Interval)
+	spec.Delay += spec.Interval
+	return func() {
+		defer tw.storePending.done(key)
+		// a stale generation means the task was removed since, its run is not recorded
+		if err := tw.store.Update(spec); err != nil && !errors.Is(err, ErrStaleGeneration) && !errors.Is(err, ErrTaskNotFound) {
+			tw.logger.Printf("timewheel: store update failed, key: %v, err: %v", key, err)
+			tw.reportError(ErrorStore, key, err)
+		}
+	}
+}
+
+// update the stored task which may not be loaded in the wheel
+func (tw *TimeWheel) storeUpdate(key interface{}, interval time.Duration, data TaskData) error {
+	spec, ok, err := tw.store.Get(key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTaskNotFound
+	}
+	spec.Interval = interval
+	spec.Data = data
+	return tw.store.Update(spec)
+}
+
+// replace the data of the stored task
+func (tw *TimeWheel) storeSetData(key interface{}, data TaskData) error {
+	spec, ok, err := tw.store.Get(key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTaskNotFound
+	}
+	spec.Data = data
+	return tw.store.Update(spec)
+}
+
+// load the stored tasks due within the horizon periodically
+func (tw *TimeWheel) refillLoop() {
+	ticker := tw.clock.NewTicker(tw.horizon / 2)
+	defer ticker.Stop()
+	for {
+		tw.refill()
+		select {
+		case <-ticker.C():
+		case <-tw.stopChannel:
+			return
+		}
+	}
+}
+
+// load the stored tasks due within the horizon that are not in the wheel yet
+func (tw *TimeWheel) refill() {
+	if tw.registry == nil {
+		tw.logger.Printf("timewheel: store refill needs a job registry")
+		return
+	}
+	specs, err := tw.store.Due(tw.clock.Now().Add(tw.horizon))
+	if err != nil {
+		tw.logger.Printf("timewheel: store refill failed, err: %v", err)
+		tw.reportError(ErrorStore, nil, err)
+		return
+	}
+	resolve := tw.registry.Resolver()
+	for _, spec := range specs {
+		if tw.HasTask(spec.Key) || tw.storePending.has(spec.Key) {
+			continue
+		}
+		job, err := resolve(spec)
+		if err != nil {
+			tw.logger.Printf("timewheel: store refill failed, key: %v, err: %v", spec.Key, err)
+			tw.reportError(ErrorTaskRejected, spec.Key, err)
+			continue
+		}
+		task, err := tw.newTask(spec.Interval, spec.Times, spec.Key, spec.Data, wrapJob(job))
+		if err != nil {
+			continue
+		}
+		// the task keeps the generation of its record, its runs are recorded under it
+		task.applySpec(spec)
+		if err = tw.acceptOptions(task); err != nil {
+			tw.dropTask(task)
+			tw.logger.Printf("timewheel: store refill failed, key: %v, err: %v", spec.Key, err)
+			tw.reportError(ErrorTaskRejected, spec.Key, err)
+			continue
+		}
+		task.next = spec.Next
+		task.atNext = true
+		if err := tw.submit(context.Background(), task); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// in memory Store, beforeUpdate is called outside the lock by Update
+type memStore struct {
+	mu           sync.Mutex
+	specs        map[interface{}]TaskSpec
+	claimed      map[interface{}]bool
+	beforeUpdate func(TaskSpec)
+}
+
+func newMemStore() *memStore {
+	return &memStore{specs: make(map[interface{}]TaskSpec), claimed: make(map[interface{}]bool)}
+}
+
+func sameGen(stored, gen uint64) bool {
+	return gen == 0 || stored == 0 || stored == gen
+}
+
+func (s *memStore) Add(spec TaskSpec) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.specs[spec.Key]; ok {
+		return ErrDuplicateKey
+	}
+	s.specs[spec.Key] = spec
+	return nil
+}
+
+func (s *memStore) Get(key interface{}) (TaskSpec, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	spec, ok := s.specs[key]
+	return spec, ok, nil
+}
+
+func (s *memStore) Update(spec TaskSpec) error {
+	if s.beforeUpdate != nil {
+		s.beforeUpdate(spec)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.specs[spec.Key]
+	if !ok {
+		return ErrTaskNotFound
+	}
+	if !sameGen(old.Gen, spec.Gen) {
+		return ErrStaleGeneration
+	}
+	s.specs[spec.Key] = spec
+	return nil
+}
+
+func (s *memStore) Delete(key interface{}, gen uint64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.specs[key]
+	if !ok || !sameGen(old.Gen, gen) {
+		return false, nil
+	}
+	delete(s.specs, key)
+	delete(s.claimed, key)
+	return true, nil
+}
+
+func (s *memStore) Due(before time.Time) ([]TaskSpec, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var specs []TaskSpec
+	for key, spec := range s.specs {
+		if !s.claimed[key] && !spec.Next.After(before) {
+			s.claimed[key] = true
+			specs = append(specs, spec)
+		}
+	}
+	return specs, nil
+}
+
+func TestStoreStaleRun(t *testing.T) {
+	s := newMemStore()
+	updating, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	s.beforeUpdate = func(TaskSpec) {
+		once.Do(func() {
+			close(updating)
+			<-release
+		})
+	}
+	reg := NewJobRegistry(false)
+	reg.Register("nop", func(TaskData) {})
+	tw := New(10*time.Millisecond, 10, WithJobRegistry(reg), WithStore(s, time.Hour))
+	errs := tw.Errors()
+	tw.Start()
+	defer tw.Stop()
+	if err := tw.AddNamedTask(20*time.Millisecond, -1, "k", "nop", nil); err != nil {
+		t.Fatal(err)
+	}
+	// the record of the first run lands after the task was removed and added again
+	<-updating
+	if err := tw.RemoveTask("k"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.AddNamedTask(time.Hour, 5, "k", "nop", nil); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	time.Sleep(20 * time.Millisecond)
+	spec, ok, _ := s.Get("k")
+	if !ok || spec.Times != 5 || spec.Interval != time.Hour {
+		t.Fatal("stale run recorded", spec, ok)
+	}
+	specs, _ := tw.Snapshot()
+	if len(specs) != 1 || spec.Gen == 0 || spec.Gen != specs[0].Gen {
+		t.Fatal("generation", spec.Gen, specs)
+	}
+	select {
+	case err := <-errs:
+		t.Fatal("stale run reported", err)
+	default:
+	}
+}
+
+func TestStoreRefillClaims(t *testing.T) {
+	s := newMemStore()
+	s.Add(TaskSpec{Key: "k", Gen: 42, Interval: 20 * time.Millisecond, Times: 3, Next: time.Now().Add(20 * time.Millisecond), JobName: "nop"})
+	runs := make(chan struct{}, 10)
+	reg := NewJobRegistry(false)
+	reg.Register("nop", func(TaskData) { runs <- struct{}{} })
+	tw := New(10*time.Millisecond, 10, WithJobRegistry(reg), WithStore(s, time.Hour))
+	tw.Start()
+	defer tw.Stop()
+	<-runs
+	settle(tw)
+	// the refilled task keeps the generation of its record, its runs are recorded
+	spec, ok, _ := s.Get("k")
+	if !ok || spec.Gen != 42 || spec.Times != 2 {
+		t.Fatal("run not recorded", spec, ok)
+	}
+	if due, _ := s.Due(time.Now().Add(time.Hour)); len(due) != 0 {
+		t.Fatal("refilled task not claimed", due)
+	}
+	<-runs
+	<-runs
+	settle(tw)
+	if _, ok, _ := s.Get("k"); ok {
+		t.Fatal("task stored after its last run")
+	}
+}
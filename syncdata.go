@@ -0,0 +1,68 @@
+package timewheel
+
+import "sync"
+
+// SyncTaskData state shared by the runs of a recurring task, safe for concurrent use.
+// Every run receives a shallow copy of the TaskData, so a job writing its TaskData does not
+// carry anything to the next run; store a *SyncTaskData in the TaskData instead:
+//
+//	state := timewheel.NewSyncTaskData()
+//	tw.AddTask(time.Second, -1, "counter", timewheel.TaskData{"state": state}, func(data timewheel.TaskData) {
+//		data["state"].(*timewheel.SyncTaskData).Update("runs", func(v interface{}, ok bool) interface{} {
+//			if !ok {
+//				return 1
+//			}
+//			return v.(int) + 1
+//		})
+//	})
+type SyncTaskData struct {
+	mu   sync.RWMutex
+	data map[interface{}]interface{}
+}
+
+// NewSyncTaskData create a empty SyncTaskData
+func NewSyncTaskData() *SyncTaskData {
+	return &SyncTaskData{data: make(map[interface{}]interface{})}
+}
+
+// Get get the value of the key
+func (d *SyncTaskData) Get(key interface{}) (interface{}, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	v, ok := d.data[key]
+	return v, ok
+}
+
+// Set set the value of the key
+func (d *SyncTaskData) Set(key, value interface{}) {
+	d.mu.Lock()
+	d.data[key] = value
+	d.mu.Unlock()
+}
+
+// Delete delete the key
+func (d *SyncTaskData) Delete(key interface{}) {
+	d.mu.Lock()
+	delete(d.data, key)
+	d.mu.Unlock()
+}
+
+// Update replace the value of the key with fn applied to the current value atomically,
+// ok reports whether the key existed
+func (d *SyncTaskData) Update(key interface{}, fn func(value interface{}, ok bool) interface{}) {
+	d.mu.Lock()
+	v, ok := d.data[key]
+	d.data[key] = fn(v, ok)
+	d.mu.Unlock()
+}
+
+// Snapshot copy the data
+func (d *SyncTaskData) Snapshot() TaskData {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	c := make(TaskData, len(d.data))
+	for k, v := range d.data {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,58 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// the job keeps a counter across its runs while the data is polled, run with -race
+func TestSyncTaskData(t *testing.T) {
+	tw := New(time.Millisecond, 16)
+	tw.Start()
+	defer tw.Stop()
+	state := NewSyncTaskData()
+	var runs int64
+	err := tw.AddTask(time.Millisecond, -1, "counter", TaskData{"state": state}, func(data TaskData) {
+		data["state"].(*SyncTaskData).Update("runs", func(v interface{}, ok bool) interface{} {
+			if !ok {
+				return 1
+			}
+			return v.(int) + 1
+		})
+		// the run writes its own copy
+		data["scratch"] = true
+		atomic.AddInt64(&runs, 1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		tw.Range(func(key interface{}, info TaskInfo) bool { return true })
+		data, err := tw.GetTaskData("counter")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := data["scratch"]; ok {
+			t.Fatal("a run wrote the data of the task")
+		}
+		data["state"].(*SyncTaskData).Snapshot()
+	}
+	tw.RemoveTask("counter")
+	for tw.QueueStats().InFlight > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	v, ok := state.Get("runs")
+	if !ok || int64(v.(int)) != atomic.LoadInt64(&runs) {
+		t.Fatalf("counter %v after %d runs", v, atomic.LoadInt64(&runs))
+	}
+	state.Delete("runs")
+	if _, ok := state.Get("runs"); ok {
+		t.Fatal("deleted key")
+	}
+	state.Set("a", 1)
+	if snap := state.Snapshot(); len(snap) != 1 || snap["a"] != 1 {
+		t.Fatalf("snapshot %v", snap)
+	}
+}
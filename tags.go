@@ -0,0 +1,261 @@
+package timewheel
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Tags tag the task, the tagged tasks can be handled as a group with RemoveByTag, PauseByTag and CountByTag
+func Tags(tags ...string) TaskOption {
+	return func(t *task) {
+		t.tags = append(t.tags[:0:0], tags...)
+	}
+}
+
+// Priority set the priority of the task, the tasks due at the same tick are dispatched
+// from the highest priority, default is 0
+func Priority(p int) TaskOption {
+	return func(t *task) {
+		t.priority = p
+	}
+}
+
+// OnExhausted call fn on the job goroutine once the final run of the task returned,
+// it is not called for a task removed before its final run
+func OnExhausted(fn func(key interface{}, data TaskData)) TaskOption {
+	return func(t *task) {
+		t.onDone = fn
+	}
+}
+
+// Jitter delay every run of the task by a random duration below d, spreading tasks sharing an interval
+func Jitter(d time.Duration) TaskOption {
+	return func(t *task) {
+		if d > 0 {
+			t.jitter = d
+		}
+	}
+}
+
+// Until stop the task at end, the last run is the last one scheduled at or before end.
+// The final run calls the OnExhausted callback like a task running out of times.
+func Until(end time.Time) TaskOption {
+	return func(t *task) {
+		t.until = end
+	}
+}
+
+// AddTaskWith add new task like AddTask, configured by opts
+func (tw *TimeWheel) AddTaskWith(interval time.Duration, times int, key interface{}, data TaskData, job Job, opts ...TaskOption) error {
+	if job == nil {
+		return ErrInvalidParams
+	}
+	return tw.addTaskWith(interval, times, key, data, wrapJob(job), opts)
+}
+
+// add the task configured by opts, the job is checked
+func (tw *TimeWheel) addTaskWith(interval time.Duration, times int, key interface{}, data TaskData, job JobCtx, opts []TaskOption) error {
+	_, err := tw.addTaskGen(interval, times, key, data, job, opts)
+	return err
+}
+
+// add the task configured by opts, return its generation
+func (tw *TimeWheel) addTaskGen(interval time.Duration, times int, key interface{}, data TaskData, job JobCtx, opts []TaskOption) (uint64, error) {
+	task, err := tw.newTask(interval, times, key, data, job)
+	if err != nil {
+		return 0, err
+	}
+	for _, opt := range opts {
+		opt(task)
+	}
+	if err := tw.acceptOptions(task); err != nil {
+		tw.dropTask(task)
+		return 0, err
+	}
+	if !task.until.IsZero() && task.next.After(task.until) {
+		tw.dropTask(task)
+		return 0, fmt.Errorf("%w, no run before the end time", ErrInvalidParams)
+	}
+	// the task may be gone once submitted
+	gen := task.gen
+	if err := tw.submit(context.Background(), task); err != nil {
+		return 0, err
+	}
+	return gen, nil
+}
+
+// AddTaskUntil add new task running every interval until end, see Until
+func (tw *TimeWheel) AddTaskUntil(interval time.Duration, end time.Time, key interface{}, data TaskData, job Job) error {
+	return tw.AddTaskWith(interval, -1, key, data, job, Until(end))
+}
+
+// RemoveByTag remove the tasks carrying tag, return how many were removed
+func (tw *TimeWheel) RemoveByTag(tag string) int {
+	n := 0
+	for _, key := range tw.tagIndex.keys(tag) {
+		if tw.RemoveTask(key) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// PauseByTag pause the tasks carrying tag, return how many were paused
+func (tw *TimeWheel) PauseByTag(tag string) int {
+	n := 0
+	for _, key := range tw.tagIndex.keys(tag) {
+		if tw.PauseTask(key) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// ResumeByTag resume the tasks carrying tag, return how many were resumed
+func (tw *TimeWheel) ResumeByTag(tag string) int {
+	n := 0
+	for _, key := range tw.tagIndex.keys(tag) {
+		if tw.ResumeTask(key) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// CountByTag get the number of registered tasks carrying tag
+func (tw *TimeWheel) CountByTag(tag string) int {
+	return tw.tagIndex.count(tag)
+}
+
+// registered tasks by tag and by namespace, written on the wheel goroutine
+type tagIndex struct {
+	mu     sync.RWMutex
+	tags   map[string]map[*task]struct{}
+	spaces map[string]map[*task]struct{}
+	scopes map[*Scope]map[*task]struct{}
+}
+
+func (x *tagIndex) add(t *task) {
+	k, named := t.key.(NSKey)
+	if len(t.tags) == 0 && !named && t.scope == nil {
+		return
+	}
+	x.mu.Lock()
+	if t.scope != nil {
+		if x.scopes == nil {
+			x.scopes = make(map[*Scope]map[*task]struct{})
+		}
+		group := x.scopes[t.scope]
+		if group == nil {
+			group = make(map[*task]struct{})
+			x.scopes[t.scope] = group
+		}
+		group[t] = struct{}{}
+	}
+	if named {
+		if x.spaces == nil {
+			x.spaces = make(map[string]map[*task]struct{})
+		}
+		group := x.spaces[k.NS]
+		if group == nil {
+			group = make(map[*task]struct{})
+			x.spaces[k.NS] = group
+		}
+		group[t] = struct{}{}
+	}
+	if x.tags == nil {
+		x.tags = make(map[string]map[*task]struct{})
+	}
+	for _, tag := range t.tags {
+		group := x.tags[tag]
+		if group == nil {
+			group = make(map[*task]struct{})
+			x.tags[tag] = group
+		}
+		group[t] = struct{}{}
+	}
+	x.mu.Unlock()
+}
+
+// drop the task from its groups, empty groups are deleted
+func (x *tagIndex) remove(t *task) {
+	k, named := t.key.(NSKey)
+	if len(t.tags) == 0 && !named && t.scope == nil {
+		return
+	}
+	x.mu.Lock()
+	if group := x.scopes[t.scope]; group != nil {
+		delete(group, t)
+		if len(group) == 0 {
+			delete(x.scopes, t.scope)
+		}
+	}
+	if group := x.spaces[k.NS]; named && group != nil {
+		delete(group, t)
+		if len(group) == 0 {
+			delete(x.spaces, k.NS)
+		}
+	}
+	for _, tag := range t.tags {
+		if group := x.tags[tag]; group != nil {
+			delete(group, t)
+			if len(group) == 0 {
+				delete(x.tags, tag)
+			}
+		}
+	}
+	x.mu.Unlock()
+}
+
+// forget every task
+func (x *tagIndex) clear() {
+	x.mu.Lock()
+	x.tags, x.spaces, x.scopes = nil, nil, nil
+	x.mu.Unlock()
+}
+
+func (x *tagIndex) keys(tag string) []interface{} {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return groupKeys(x.tags[tag])
+}
+
+func (x *tagIndex) count(tag string) int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.tags[tag])
+}
+
+func (x *tagIndex) scopeKeys(s *Scope) []interface{} {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return groupKeys(x.scopes[s])
+}
+
+func (x *tagIndex) scopeCount(s *Scope) int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.scopes[s])
+}
+
+func (x *tagIndex) nsKeys(ns string) []interface{} {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return groupKeys(x.spaces[ns])
+}
+
+func (x *tagIndex) nsCount(ns string) int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.spaces[ns])
+}
+
+func groupKeys(group map[*task]struct{}) []interface{} {
+	keys := make([]interface{}, 0, len(group))
+	for t := range group {
+		keys = append(keys, t.key)
+	}
+	return keys
+}
@@ -0,0 +1,234 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// number of tags holding at least a task
+func tagGroups(tw *TimeWheel) int {
+	tw.tagIndex.mu.RLock()
+	defer tw.tagIndex.mu.RUnlock()
+	return len(tw.tagIndex.tags)
+}
+
+func TestTags(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var runs int64
+	job := func(TaskData) { atomic.AddInt64(&runs, 1) }
+	for i := 0; i < 10; i++ {
+		parity := "odd"
+		if i%2 == 0 {
+			parity = "even"
+		}
+		if err := tw.AddTaskWith(time.Second, 1, i, nil, job, Tags("all", parity)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.AddTask(time.Second, 1, "untagged", nil, job)
+	settle(tw)
+	if tw.CountByTag("all") != 10 || tw.CountByTag("even") != 5 || tw.CountByTag("odd") != 5 || tw.CountByTag("none") != 0 {
+		t.Fatalf("counts all=%d even=%d odd=%d", tw.CountByTag("all"), tw.CountByTag("even"), tw.CountByTag("odd"))
+	}
+	if n := tw.RemoveByTag("even"); n != 5 {
+		t.Fatalf("removed %d", n)
+	}
+	if tw.CountByTag("all") != 5 || tw.CountByTag("even") != 0 || tw.Len() != 6 {
+		t.Fatalf("after remove all=%d even=%d len=%d", tw.CountByTag("all"), tw.CountByTag("even"), tw.Len())
+	}
+	if n := tw.PauseByTag("odd"); n != 5 {
+		t.Fatalf("paused %d", n)
+	}
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	if got := atomic.LoadInt64(&runs); got != 1 {
+		t.Fatalf("%d runs with the odd tasks paused", got)
+	}
+	if n := tw.ResumeByTag("odd"); n != 5 {
+		t.Fatalf("resumed %d", n)
+	}
+	for i := 0; i < 10; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	if got := atomic.LoadInt64(&runs); got != 6 {
+		t.Fatalf("%d runs, want 6", got)
+	}
+	// the exhausted tasks left the index
+	if tw.CountByTag("all") != 0 || tagGroups(tw) != 0 {
+		t.Fatalf("index leaks %d tags", tagGroups(tw))
+	}
+}
+
+func TestTagsNoLeak(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 4, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	job := func(TaskData) {}
+	// exhausted, removed, removed before its first run and across circles
+	tw.AddTaskWith(time.Second, 2, "a", nil, job, Tags("x", "y"))
+	tw.AddTaskWith(time.Second, -1, "b", nil, job, Tags("x"))
+	tw.AddTaskWith(10*time.Second, -1, "c", nil, job, Tags("y"))
+	tw.AddTaskWith(9*time.Second, 1, "d", nil, job, Tags("z"))
+	settle(tw)
+	if tagGroups(tw) != 3 {
+		t.Fatalf("%d tags", tagGroups(tw))
+	}
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	tw.RemoveTask("b")
+	tw.RemoveTask("c")
+	for i := 0; i < 12; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	if tw.Len() != 0 || tagGroups(tw) != 0 {
+		t.Fatalf("len %d, index leaks %d tags", tw.Len(), tagGroups(tw))
+	}
+
+	// a task added again under the same key keeps only its new tags
+	tw.AddTaskWith(time.Second, -1, "a", nil, job, Tags("x"))
+	tw.RemoveTask("a")
+	tw.AddTaskWith(time.Second, -1, "a", nil, job, Tags("y"))
+	settle(tw)
+	if tw.CountByTag("x") != 0 || tw.CountByTag("y") != 1 {
+		t.Fatalf("x=%d y=%d", tw.CountByTag("x"), tw.CountByTag("y"))
+	}
+}
+
+func TestOnExhausted(t *testing.T) {
+	for _, times := range []int{1, 5} {
+		c := newFakeClock()
+		tw := New(time.Second, 10, WithClock(c))
+		tw.Start()
+		var runs, returned int64
+		calls := make(chan TaskData, 2)
+		block := make(chan struct{})
+		job := func(TaskData) {
+			atomic.AddInt64(&runs, 1)
+			time.Sleep(time.Millisecond)
+			atomic.AddInt64(&returned, 1)
+		}
+		tw.AddTaskWith(time.Second, times, "k", TaskData{"row": 42}, job, OnExhausted(func(key interface{}, data TaskData) {
+			// the final run returned
+			if key != "k" || data["row"] != 42 || atomic.LoadInt64(&returned) != int64(times) {
+				t.Errorf("times=%d: callback with %v %v after %d runs", times, key, data, atomic.LoadInt64(&returned))
+			}
+			calls <- data
+			// a blocked callback does not hold the ticks
+			<-block
+		}))
+		settle(tw)
+		for i := 0; i <= times+3; i++ {
+			c.Tick(time.Second)
+			settle(tw)
+			// one run at a time
+			for atomic.LoadInt64(&returned) != atomic.LoadInt64(&runs) {
+				time.Sleep(time.Millisecond)
+			}
+		}
+		select {
+		case <-calls:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("times=%d: no callback", times)
+		}
+		c.Tick(time.Second)
+		close(block)
+		settle(tw)
+		tw.Stop()
+		if len(calls) != 0 || atomic.LoadInt64(&runs) != int64(times) {
+			t.Fatalf("times=%d: %d runs, %d more callbacks", times, runs, len(calls))
+		}
+	}
+}
+
+func TestOnExhaustedRemoved(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var calls int64
+	tw.AddTaskWith(time.Second, 5, "k", nil, func(TaskData) {}, OnExhausted(func(interface{}, TaskData) {
+		atomic.AddInt64(&calls, 1)
+	}))
+	settle(tw)
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	if err := tw.RemoveTask("k"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 6; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	time.Sleep(10 * time.Millisecond)
+	if n := atomic.LoadInt64(&calls); n != 0 {
+		t.Fatalf("%d callbacks for a removed task", n)
+	}
+}
+
+func TestUntil(t *testing.T) {
+	// the runs come at 1s, 2s, 3s...
+	for _, tc := range []struct {
+		name string
+		end  time.Duration
+		want int64
+	}{
+		{"at a run", 3 * time.Second, 3},
+		{"just after a run", 3*time.Second + time.Millisecond, 3},
+		{"just before a run", 3*time.Second - time.Millisecond, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newFakeClock()
+			tw := New(time.Second, 10, WithClock(c))
+			tw.Start()
+			defer tw.Stop()
+			var n, done int64
+			err := tw.AddTaskWith(time.Second, -1, "k", nil, func(TaskData) { atomic.AddInt64(&n, 1) },
+				Until(c.Now().Add(tc.end)), OnExhausted(func(interface{}, TaskData) { atomic.AddInt64(&done, 1) }))
+			if err != nil {
+				t.Fatal(err)
+			}
+			settle(tw)
+			for i := 0; i < 6; i++ {
+				c.Tick(time.Second)
+				settle(tw)
+			}
+			waitCount(t, &done, 1)
+			waitCount(t, &n, tc.want)
+			if tw.HasTask("k") {
+				t.Fatal("task still registered after its end")
+			}
+		})
+	}
+
+	// a lagging wheel does not extend the task
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c), WithCatchUpPolicy(FireAll))
+	tw.Start()
+	defer tw.Stop()
+	var n int64
+	tw.AddTaskUntil(time.Second, c.Now().Add(3*time.Second), "k", nil, func(TaskData) { atomic.AddInt64(&n, 1) })
+	settle(tw)
+	c.Tick(time.Second)
+	// 5 ticks late
+	c.Tick(6 * time.Second)
+	settle(tw)
+	c.Tick(time.Second)
+	settle(tw)
+	if tw.HasTask("k") {
+		t.Fatal("task extended by the lag")
+	}
+	waitCount(t, &n, 3)
+
+	if err := tw.AddTaskUntil(time.Second, c.Now().Add(-time.Hour), "x", nil, func(TaskData) {}); err == nil {
+		t.Fatal("end before the first run accepted")
+	}
+}
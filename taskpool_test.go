@@ -0,0 +1,85 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTaskStatsWhileRecycling(t *testing.T) {
+	tw := New(time.Millisecond, 16)
+	tw.Start()
+	defer tw.Stop()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				tw.AddTask(time.Millisecond, 1, fmt.Sprint(g, "-", i%16), nil, func(TaskData) {})
+			}
+		}(g)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				tw.TaskStats(fmt.Sprint(g, "-", i%16))
+			}
+		}(g)
+	}
+	wg.Wait()
+	checkInvariants(t, tw)
+}
+
+func TestRecycledTaskIsClean(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 8, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	done := make(chan struct{})
+	tw.AddTaskWith(time.Second, 1, "a", TaskData{"x": 1}, func(TaskData) { close(done) }, Tags("t"), Priority(3))
+	settle(tw)
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	<-done
+	settle(tw)
+	got := make(chan TaskData, 1)
+	tw.AddTask(time.Second, 1, "b", nil, func(d TaskData) { got <- d })
+	settle(tw)
+	if st, err := tw.TaskStats("b"); err != nil || st.Runs != 0 {
+		t.Fatal(st, err)
+	}
+	if n := tw.CountByTag("t"); n != 0 {
+		t.Fatal(n)
+	}
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	if d := <-got; len(d) != 0 {
+		t.Fatal(d)
+	}
+}
+
+// one shot tasks added and fired, with and without recycling: go test -bench OneShot -benchmem
+func BenchmarkOneShotTasks(b *testing.B) {
+	for _, pooled := range []bool{false, true} {
+		b.Run(fmt.Sprintf("pooled=%v", pooled), func(b *testing.B) {
+			defer func(v bool) { poolTasks = v }(poolTasks)
+			poolTasks = pooled
+			c := newFakeClock()
+			tw := New(time.Millisecond, 64, WithClock(c), WithAddBuffer(1024))
+			tw.Start()
+			defer tw.Stop()
+			job := func(TaskData) {}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				tw.AddTask(time.Millisecond, 1, i, nil, job)
+				if i%1024 == 1023 {
+					c.Tick(time.Millisecond)
+					c.Tick(time.Millisecond)
+				}
+			}
+			b.StopTimer()
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package timewheel
+
+import "sync/atomic"
+
+// WithTickCap dispatch at most n tasks per tick, the due tasks beyond the cap are deferred
+// and dispatched first at the next ticks in the order they became due. Default is no cap.
+func WithTickCap(n int) Option {
+	return func(tw *TimeWheel) {
+		if n > 0 {
+			tw.tickCap = n
+		}
+	}
+}
+
+// Deferred get the number of due tasks waiting for a tick with room under the tick cap
+func (tw *TimeWheel) Deferred() int {
+	return int(atomic.LoadInt64(&tw.deferredNum))
+}
+
+// dispatch the deferred tasks then the tasks of the current tick within the cap
+func (tw *TimeWheel) advanceCapped() {
+	tw.budget = tw.tickCap
+	if tw.fairGroup != nil {
+		tw.advanceFair(tw.runDueTaskCapped)
+		return
+	}
+	for tw.budget > 0 && len(tw.deferred) > 0 {
+		task := tw.deferred[0]
+		tw.deferred[0] = nil
+		tw.deferred = tw.deferred[1:]
+		atomic.AddInt64(&tw.deferredNum, -1)
+		// removed while deferred
+		if task.times != 0 {
+			tw.budget--
+			tw.runDueTask(task)
+		}
+		task.release()
+	}
+	tw.backend.advance(eachDue(tw.runDueTaskCapped))
+}
+
+// run the due task if the cap allows it, defer it otherwise
+func (tw *TimeWheel) runDueTaskCapped(task *task) {
+	if task.times == 0 {
+		tw.runDueTask(task)
+		return
+	}
+	if tw.budget > 0 {
+		tw.budget--
+		tw.runDueTask(task)
+		return
+	}
+	// the queue holds its own reference, the task may be removed while it waits
+	task.retain()
+	tw.deferred = append(tw.deferred, task)
+	atomic.AddInt64(&tw.deferredNum, 1)
+	tw.trace(task, TraceDeferred)
+}
+
+// take the task out of the deferred queues, report whether it was there
+func (tw *TimeWheel) undefer(task *task) bool {
+	return takeTask(&tw.deferred, &tw.deferredNum, task) || takeTask(&tw.blackedOut, &tw.blackedOutNum, task) ||
+		takeTask(&tw.carry, &tw.carryNum, task) || tw.unpark(task)
+}
+
+// call fn for every due task waiting in a queue instead of the backend
+func (tw *TimeWheel) eachWaiting(fn func(t *task)) {
+	for _, queue := range [][]*task{tw.deferred, tw.blackedOut, tw.carry} {
+		for _, t := range queue {
+			fn(t)
+		}
+	}
+}
+
+// take the task out of the queue and drop the reference of the queue
+func takeTask(queue *[]*task, num *int64, task *task) bool {
+	q := *queue
+	for i, t := range q {
+		if t == task {
+			copy(q[i:], q[i+1:])
+			q[len(q)-1] = nil
+			*queue = q[:len(q)-1]
+			atomic.AddInt64(num, -1)
+			task.release()
+			return true
+		}
+	}
+	return false
+}
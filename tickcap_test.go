@@ -0,0 +1,63 @@
+package timewheel
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTickCap(t *testing.T) {
+	c := newFakeClock()
+	var mu sync.Mutex
+	var fired []int
+	tw := New(time.Second, 10, WithClock(c), WithTickCap(500), WithHooks(Hooks{OnTaskFired: func(key interface{}, _ TaskInfo) {
+		mu.Lock()
+		fired = append(fired, key.(int))
+		mu.Unlock()
+	}}))
+	tw.Start()
+	defer tw.Stop()
+	for i := 0; i < 5000; i++ {
+		tw.AddTask(time.Second, 1, i, nil, func(TaskData) {})
+	}
+	// a recurring task of the next slot waits behind the deferred ones
+	tw.AddTask(2*time.Second, 2, -1, nil, func(TaskData) {})
+	settle(tw)
+	c.Tick(time.Second)
+	settle(tw)
+	for tick := 1; tick <= 10; tick++ {
+		c.Tick(time.Second)
+		settle(tw)
+		if n := atomic.LoadInt64(&tw.firedNum); n != int64(tick*500) {
+			t.Fatalf("tick %d: %d tasks dispatched", tick, n)
+		}
+		if want := 5000 - tick*500; tw.Deferred() != want+1 && tw.Deferred() != want {
+			t.Fatalf("tick %d: %d deferred, want %d", tick, tw.Deferred(), want)
+		}
+	}
+	c.Tick(time.Second)
+	settle(tw)
+	// the hooks run behind the wheel goroutine
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(fired)
+		mu.Unlock()
+		if n >= 5001 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(fired) != 5001 || fired[5000] != -1 || tw.Deferred() != 0 {
+		t.Fatalf("%d tasks dispatched, %d deferred", len(fired), tw.Deferred())
+	}
+	// the deferred tasks keep the order they became due
+	for i := 1; i < 5000; i++ {
+		if fired[i] < fired[i-1] {
+			t.Fatalf("%d dispatched before %d", fired[i-1], fired[i])
+		}
+	}
+}
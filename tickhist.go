@@ -0,0 +1,96 @@
+package timewheel
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTickBuckets upper bounds of the buckets of the tick duration histogram
+var DefaultTickBuckets = []time.Duration{
+	100 * time.Microsecond, 250 * time.Microsecond, 500 * time.Microsecond,
+	time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 500 * time.Millisecond, time.Second,
+}
+
+// WithTickBuckets set the upper bounds of the buckets of the tick duration histogram, default is
+// DefaultTickBuckets. The bounds are sorted, a tick longer than the last one lands in the overflow bucket.
+func WithTickBuckets(bounds ...time.Duration) Option {
+	return func(tw *TimeWheel) {
+		if len(bounds) > 0 {
+			tw.tickHist = newTickHistogram(bounds)
+		}
+	}
+}
+
+// TickHistogram distribution of the durations of the ticks, from the processing of the expired tasks to the
+// dispatch of the due tasks, the wait for the next tick excluded
+type TickHistogram struct {
+	Bounds   []time.Duration // upper bound of every bucket but the overflow one
+	Counts   []int64         // ticks of every bucket, the last one counts the ticks longer than every bound
+	Count    int64           // ticks observed
+	Sum      time.Duration   // total duration of the ticks observed
+	Overruns int64           // ticks longer than the interval
+}
+
+// fixed bucket histogram updated atomically by the wheel goroutine
+type tickHistogram struct {
+	bounds   []time.Duration
+	counts   []int64
+	sum      int64
+	overruns int64
+}
+
+func newTickHistogram(bounds []time.Duration) *tickHistogram {
+	bounds = append([]time.Duration(nil), bounds...)
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+	return &tickHistogram{bounds: bounds, counts: make([]int64, len(bounds)+1)}
+}
+
+// count the tick, report whether it overran the interval
+func (h *tickHistogram) observe(d, interval time.Duration) bool {
+	i := sort.Search(len(h.bounds), func(i int) bool { return d <= h.bounds[i] })
+	atomic.AddInt64(&h.counts[i], 1)
+	atomic.AddInt64(&h.sum, int64(d))
+	if d <= interval {
+		return false
+	}
+	atomic.AddInt64(&h.overruns, 1)
+	return true
+}
+
+func (h *tickHistogram) snapshot() TickHistogram {
+	s := TickHistogram{
+		Bounds:   append([]time.Duration(nil), h.bounds...),
+		Counts:   make([]int64, len(h.counts)),
+		Sum:      time.Duration(atomic.LoadInt64(&h.sum)),
+		Overruns: atomic.LoadInt64(&h.overruns),
+	}
+	for i := range h.counts {
+		s.Counts[i] = atomic.LoadInt64(&h.counts[i])
+		s.Count += s.Counts[i]
+	}
+	return s
+}
+
+// Quantile estimate the duration under which the fraction q of the ticks fall, by the upper bound of the bucket
+// reaching it. The overflow bucket reports the last bound, zero is returned when no tick is observed.
+func (s TickHistogram) Quantile(q float64) time.Duration {
+	if s.Count == 0 || len(s.Bounds) == 0 {
+		return 0
+	}
+	rank := int64(q * float64(s.Count))
+	var n int64
+	for i, c := range s.Counts[:len(s.Bounds)] {
+		n += c
+		if n > rank || n == s.Count {
+			return s.Bounds[i]
+		}
+	}
+	return s.Bounds[len(s.Bounds)-1]
+}
+
+// TickOverrunMetrics optional interface of the Metrics receiving the ticks lasting longer than the interval
+type TickOverrunMetrics interface {
+	TickOverrun(d time.Duration)
+}
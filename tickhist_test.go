@@ -0,0 +1,51 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// metrics counting the tick overruns
+type overrunMetrics struct {
+	countMetrics
+	overruns int64
+}
+
+func (m *overrunMetrics) TickOverrun(d time.Duration) { atomic.AddInt64(&m.overruns, 1) }
+
+func TestTickHistogram(t *testing.T) {
+	c := newFakeClock()
+	m := &overrunMetrics{}
+	// a single worker without a queue, the tick waits for it to dispatch the second job
+	tw := New(10*time.Millisecond, 16, WithClock(c), WithMetrics(m), WithWorkers(1, 0, Block),
+		WithTickBuckets(20*time.Millisecond, time.Millisecond))
+	tw.Start()
+	defer tw.Stop()
+	for i := 0; i < 3; i++ {
+		c.Tick(10 * time.Millisecond)
+	}
+	settle(tw)
+	h := tw.Stats().TickHist
+	if h.Overruns != 0 || h.Count != 3 || h.Counts[2] != 0 || h.Bounds[0] != time.Millisecond {
+		t.Fatalf("idle ticks %+v", h)
+	}
+
+	slow := func(TaskData) { time.Sleep(30 * time.Millisecond) }
+	tw.AddTask(10*time.Millisecond, 1, "a", nil, slow)
+	tw.AddTask(10*time.Millisecond, 1, "b", nil, slow)
+	settle(tw)
+	c.Tick(10 * time.Millisecond)
+	c.Tick(10 * time.Millisecond)
+	// the control calls are served while the tick waits for the worker, wait for the tick itself
+	for deadline := time.Now().Add(time.Second); tw.Stats().TickHist.Count < 5 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	h = tw.Stats().TickHist
+	if h.Count != 5 || h.Overruns != 1 || h.Counts[2] != 1 || atomic.LoadInt64(&m.overruns) != 1 {
+		t.Fatalf("slow tick %+v, %d overruns reported", h, atomic.LoadInt64(&m.overruns))
+	}
+	if q := h.Quantile(1); q != 20*time.Millisecond {
+		t.Fatalf("max quantile %v", q)
+	}
+}
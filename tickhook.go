@@ -0,0 +1,36 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// TickHook callback called once per tick with the processed slot and the number of runs dispatched
+type TickHook func(pos int, due int)
+
+// SetTickHook set the callback called after every tick, nil removes it. The hook runs on the wheel
+// goroutine so it must be fast and must not call the methods of the wheel waiting for it,
+// a panicking hook is recovered.
+func (tw *TimeWheel) SetTickHook(h func(pos int, due int)) {
+	if h == nil {
+		tw.tickHook.Store(nil)
+		return
+	}
+	hook := TickHook(h)
+	tw.tickHook.Store(&hook)
+}
+
+// call the tick hook if it is set
+func (tw *TimeWheel) callTickHook(pos int, fired int64) {
+	h := tw.tickHook.Load()
+	if h == nil {
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tw.logger.Printf("timewheel: tick hook panic recovered, position: %d, panic: %v", pos, r)
+			tw.reportError(ErrorCallbackPanic, nil, fmt.Errorf("tick hook panic: %v", r))
+		}
+	}()
+	(*h)(pos, int(atomic.LoadInt64(&tw.firedNum)-fired))
+}
@@ -0,0 +1,46 @@
+package timewheel
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTickHook(t *testing.T) {
+	c := newFakeClock()
+	l := &captureLogger{}
+	tw := New(time.Second, 4, WithClock(c), WithLogger(l))
+	var pos, due []int
+	tw.SetTickHook(func(p, d int) {
+		pos = append(pos, p)
+		due = append(due, d)
+		// the wheel survives a panicking hook
+		if len(pos) == 2 {
+			panic("tick hook")
+		}
+	})
+	tw.Start()
+	defer tw.Stop()
+	for i := 0; i < 5; i++ {
+		tw.AddTask(2*time.Second, 1, i, nil, func(TaskData) {})
+	}
+	tw.AddTask(time.Second, 2, "r", nil, func(TaskData) {})
+	settle(tw)
+	for i := 0; i < 6; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	tw.SetTickHook(nil)
+	c.Tick(time.Second)
+	settle(tw)
+	var gotPos, gotDue []int
+	tw.exec(func() {
+		gotPos, gotDue = pos, due
+	})
+	if !reflect.DeepEqual(gotPos, []int{0, 1, 2, 3, 0, 1}) || !reflect.DeepEqual(gotDue, []int{0, 1, 6, 0, 0, 0}) {
+		t.Fatalf("positions %v, due %v", gotPos, gotDue)
+	}
+	if !l.has("tick hook panic recovered") {
+		t.Fatal("the panic is not logged")
+	}
+}
@@ -0,0 +1,70 @@
+package timewheel
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// AddTaskAtTimes add new task running once at each of the given times then done, the times may be in
+// any order and a time given twice runs once. TaskInfo.Times reports the runs left and TaskInfo.Next
+// the next one. A time not after now is rejected, see AddTaskAtFutureTimes to drop them instead.
+func (tw *TimeWheel) AddTaskAtTimes(times []time.Time, key interface{}, data TaskData, job Job) error {
+	return tw.addTaskAtTimes(times, false, key, data, job)
+}
+
+// AddTaskAtFutureTimes add new task like AddTaskAtTimes, the times not after now are dropped.
+// An error is returned only if no time is left.
+func (tw *TimeWheel) AddTaskAtFutureTimes(times []time.Time, key interface{}, data TaskData, job Job) error {
+	return tw.addTaskAtTimes(times, true, key, data, job)
+}
+
+func (tw *TimeWheel) addTaskAtTimes(times []time.Time, dropPast bool, key interface{}, data TaskData, job Job) error {
+	if job == nil {
+		return ErrInvalidParams
+	}
+	l, err := newTimeList(times, tw.clock.Now(), dropPast)
+	if err != nil {
+		return err
+	}
+	return tw.addScheduled(l, len(l), key, data, job)
+}
+
+// run times of a task added by AddTaskAtTimes, sorted without duplicates
+type timeList []time.Time
+
+func newTimeList(times []time.Time, now time.Time, dropPast bool) (timeList, error) {
+	l := make(timeList, 0, len(times))
+	for _, at := range times {
+		if at.After(now) {
+			l = append(l, at)
+		} else if !dropPast {
+			return nil, fmt.Errorf("%w, run time %v is not after now", ErrInvalidParams, at)
+		}
+	}
+	if len(l) == 0 {
+		return nil, fmt.Errorf("%w, no run time after now", ErrInvalidParams)
+	}
+	sort.Slice(l, func(i, j int) bool {
+		return l[i].Before(l[j])
+	})
+	n := 1
+	for _, at := range l[1:] {
+		if at.After(l[n-1]) {
+			l[n] = at
+			n++
+		}
+	}
+	return l[:n], nil
+}
+
+// Next implement Schedule
+func (l timeList) Next(t time.Time) time.Time {
+	i := sort.Search(len(l), func(i int) bool {
+		return l[i].After(t)
+	})
+	if i == len(l) {
+		return time.Time{}
+	}
+	return l[i]
+}
@@ -0,0 +1,74 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func infoOf(tw *TimeWheel, key interface{}) (info TaskInfo) {
+	tw.Range(func(k interface{}, i TaskInfo) bool {
+		if k == key {
+			info = i
+		}
+		return true
+	})
+	return
+}
+
+func TestAtTimes(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 8, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	now := c.Now()
+	offs := []int{30, 5, 12, 5, 90, 7, 61, 20}
+	var ts []time.Time
+	for _, o := range offs {
+		ts = append(ts, now.Add(time.Duration(o)*time.Second))
+	}
+	if err := tw.AddTaskAtTimes(append(ts, now.Add(-time.Second)), "k", nil, func(TaskData) {}); err == nil {
+		t.Fatal("past accepted")
+	}
+	var mu sync.Mutex
+	var fired []time.Time
+	if err := tw.AddTaskAtTimes(ts, "k", nil, func(TaskData) {
+		mu.Lock()
+		fired = append(fired, c.Now())
+		mu.Unlock()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	settle(tw)
+	info := infoOf(tw, "k")
+	if info.Times != 7 || !info.Next.Equal(now.Add(5*time.Second)) {
+		t.Fatal(info)
+	}
+	for i := 0; i < 100; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+	}
+	time.Sleep(20 * time.Millisecond)
+	want := []int{5, 7, 12, 20, 30, 61, 90}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(fired) != len(want) {
+		t.Fatal(fired)
+	}
+	for i, w := range want {
+		d := fired[i].Sub(now.Add(time.Duration(w) * time.Second))
+		if d < 0 || d > time.Second {
+			t.Fatal(i, d)
+		}
+	}
+	if tw.HasTask("k") {
+		t.Fatal("still there")
+	}
+	if err := tw.AddTaskAtFutureTimes([]time.Time{now, now.Add(200 * time.Second)}, "f", nil, func(TaskData) {}); err != nil {
+		t.Fatal(err)
+	}
+	settle(tw)
+	if info := infoOf(tw, "f"); info.Times != 1 {
+		t.Fatal(info)
+	}
+}
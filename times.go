@@ -0,0 +1,46 @@
+package timewheel
+
+// SetTimes change the remaining run times of the task, -1 means no limit.
+// The params are checked like AddTask, a task whose final run is dispatched is gone and can not be changed.
+func (tw *TimeWheel) SetTimes(key interface{}, times int) error {
+	if key == nil {
+		return ErrInvalidKey
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	if times < -1 || times == 0 {
+		return ErrInvalidParams
+	}
+	var err error
+	named := false
+	execErr := tw.exec(func() {
+		task, ok := tw.taskRecord.Load(key)
+		if !ok {
+			err = ErrTaskNotFound
+			return
+		}
+		if task.isHeld() {
+			err = ErrTaskStillRunning
+			return
+		}
+		task.times = times
+		named = task.jobName != ""
+	})
+	if execErr != nil {
+		return execErr
+	}
+	if err != nil || !named {
+		return err
+	}
+	tw.walAppend(walRecord{Op: walTimes, Spec: TaskSpec{Key: key, Times: times}})
+	if tw.store != nil {
+		spec, ok, err := tw.store.Get(key)
+		if err != nil || !ok {
+			return err
+		}
+		spec.Times = times
+		return tw.store.Update(spec)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSetTimes(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var n int64
+	tick := func(k int) {
+		for i := 0; i < k; i++ {
+			c.Tick(time.Second)
+		}
+		settle(tw)
+	}
+	tw.AddTask(time.Second, 2, "k", nil, func(TaskData) { atomic.AddInt64(&n, 1) })
+	settle(tw)
+	tick(2)
+	waitCount(t, &n, 1)
+	// on its last run, raised to 3
+	if err := tw.SetTimes("k", 3); err != nil {
+		t.Fatal(err)
+	}
+	tick(2)
+	waitCount(t, &n, 3)
+	if got := taskTimes(tw, "k"); got != 1 {
+		t.Fatalf("times %d, want 1", got)
+	}
+	// unlimited then back to finite
+	if err := tw.SetTimes("k", -1); err != nil {
+		t.Fatal(err)
+	}
+	tick(5)
+	waitCount(t, &n, 8)
+	if got := taskTimes(tw, "k"); got != -1 {
+		t.Fatalf("times %d, want -1", got)
+	}
+	if err := tw.SetTimes("k", 2); err != nil {
+		t.Fatal(err)
+	}
+	tick(1)
+	waitCount(t, &n, 9)
+	// lowered to a final run
+	if err := tw.SetTimes("k", 1); err != nil {
+		t.Fatal(err)
+	}
+	tick(1)
+	waitCount(t, &n, 10)
+	if tw.HasTask("k") {
+		t.Fatal("exhausted task still registered")
+	}
+	tick(3)
+	waitCount(t, &n, 10)
+	if err := tw.SetTimes("k", 5); err != ErrTaskNotFound {
+		t.Fatalf("exhausted task: %v", err)
+	}
+
+	tw.AddTask(time.Second, -1, "v", nil, func(TaskData) {})
+	for _, times := range []int{0, -2} {
+		if err := tw.SetTimes("v", times); err != ErrInvalidParams {
+			t.Fatalf("times %d: %v", times, err)
+		}
+	}
+	if err := tw.SetTimes(nil, 1); err != ErrInvalidKey {
+		t.Fatalf("nil key: %v", err)
+	}
+}
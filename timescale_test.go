@@ -0,0 +1,35 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTimeScale(t *testing.T) {
+	if New(time.Second, 60, WithTimeScale(10), WithAlignToWallClock()) != nil {
+		t.Fatal("align accepted")
+	}
+	// an hour ticks every 2ms on the wall clock, a day takes 48ms
+	tw := New(time.Hour, 24, WithTimeScale(1800000))
+	tw.Start()
+	defer tw.Stop()
+	var n int64
+	tw.AddTask(24*time.Hour, -1, "daily", nil, func(TaskData) { atomic.AddInt64(&n, 1) })
+	start := time.Now()
+	for atomic.LoadInt64(&n) < 4 && time.Since(start) < time.Second {
+		time.Sleep(time.Millisecond)
+	}
+	elapsed := time.Since(start)
+	tw.Stop()
+	<-tw.loopDone
+	if got := atomic.LoadInt64(&n); got != 4 || elapsed < 4*24*2*time.Millisecond {
+		t.Fatalf("%d runs in %v", got, elapsed)
+	}
+	tw.Range(func(k interface{}, info TaskInfo) bool {
+		if info.Interval != 24*time.Hour {
+			t.Fatal(info)
+		}
+		return true
+	})
+}
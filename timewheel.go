@@ -1,98 +1,782 @@
 package timewheel
 
 import (
-	"container/list"
+	"context"
 	"errors"
+	"fmt"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+var (
+	// ErrInvalidParams the params of the task are illegal
+	ErrInvalidParams = errors.New("illegal task params")
+	// ErrInvalidKey the task key is nil
+	ErrInvalidKey = errors.New("illegal key, please try again")
+	// ErrDuplicateKey a task is already registered under the key
+	ErrDuplicateKey = errors.New("duplicate task key")
+	// ErrUnknownJob no job is registered under the name, see JobRegistry
+	ErrUnknownJob = errors.New("unknown job name")
+	// ErrNoJobRegistry the wheel has no job registry, see WithJobRegistry
+	ErrNoJobRegistry = errors.New("no job registry, please set one with WithJobRegistry")
+	// ErrQueueFull the add buffer is full
+	ErrQueueFull = errors.New("add task queue is full")
+	// ErrWheelStopped the wheel is stopped
+	ErrWheelStopped = errors.New("time wheel is stopped")
+	// ErrTooManyTasks the wheel holds the maximum number of tasks
+	ErrTooManyTasks = errors.New("too many tasks")
+	// ErrTaskNotFound no task is registered under the key
+	ErrTaskNotFound = errors.New("task not exists, please check you task key")
+	// ErrTaskStillRunning the final run of the task under the key has not returned, see WithHoldFinalKey
+	ErrTaskStillRunning = errors.New("final run of the task is still running")
+	// ErrKeyNotComparable the task key can not be used as a map key
+	ErrKeyNotComparable = errors.New("task key is not comparable")
+	// ErrKeyType the task key is not of the key type of the TimeWheelOf
+	ErrKeyType = errors.New("task key is not of the key type of the wheel")
+	// ErrNamespaceQuota the namespace of the key holds the maximum number of tasks, see WithNamespaceQuota
+	ErrNamespaceQuota = errors.New("namespace task quota reached")
+	// ErrNotAcked the run was not acknowledged within the redelivery timeout, see Acked
+	ErrNotAcked = errors.New("run not acknowledged in time")
+	// ErrTaskRemoved the task left the wheel before its next run, see WaitForNextRun
+	ErrTaskRemoved = errors.New("task removed before its next run")
+	// ErrRunTooSoon the last run of the task started less than its minimum gap ago, see MinGap.
+	//
+	// Deprecated: RunNowWith defers such a run instead of failing, nothing returns it.
+	ErrRunTooSoon = errors.New("last run started less than the minimum gap ago")
+	// ErrStaleGeneration the task under the key is of another generation, see RemoveTaskIf
+	ErrStaleGeneration = errors.New("task generation is stale")
+	// ErrDelayTooLarge the delay is beyond the longest the wheel can hold, whose circle count fits an int32
+	ErrDelayTooLarge = errors.New("task delay too large")
+	// ErrFutureCanceled the future was canceled before its function ran, see Future.Cancel
+	ErrFutureCanceled = errors.New("future canceled")
+	// ErrWheelStalled the wheel goroutine did not tick for longer than expected, see Healthy
+	ErrWheelStalled = errors.New("time wheel stalled")
+	// ErrScopeClosed the scope is closed, see Scope
+	ErrScopeClosed = errors.New("scope closed")
+	// ErrLoopPanic the wheel goroutine panicked while handling the call, see WithPanicHandler
+	ErrLoopPanic = errors.New("time wheel goroutine panicked")
+	// ErrJobNotAttached no job is attached to the task under the id, see DetachJob
+	ErrJobNotAttached = errors.New("job not attached")
+	// ErrNotRepresentable the task holds callbacks a TaskSpec can not describe, see Snapshot
+	ErrNotRepresentable = errors.New("task can not be described by a spec")
+	// ErrBuilderSubmitted the task builder was submitted already, see TaskBuilder.Submit
+	ErrBuilderSubmitted = errors.New("task builder already submitted")
+	// ErrDefaultInUse the default wheel is started and can not be replaced, see SetDefault
+	ErrDefaultInUse = errors.New("default time wheel already in use")
+	// ErrWALOpen the write ahead log of the wheel is open already, see RecoverFromWAL
+	ErrWALOpen = errors.New("write ahead log is already open")
+	// ErrExpvarPublished the expvar name is published already, see PublishExpvar
+	ErrExpvarPublished = errors.New("expvar name already published")
+)
+
 // time wheel struct
 type TimeWheel struct {
-	interval       time.Duration
-	ticker         *time.Ticker
-	slots          []*list.List
-	currentPos     int
-	slotNum        int
-	addTaskChannel chan *task
-	stopChannel    chan bool
-	taskRecord     *sync.Map
+	interval          time.Duration
+	ticker            Ticker
+	clock             Clock
+	backend           backend
+	backendKind       Backend
+	newSlotStore      func() SlotStore
+	slotNum           int64 // accessed atomically, see Resize
+	addTaskChannel    chan *task
+	addBuffer         int
+	removeTaskChannel chan *removeRequest
+	updateTaskChannel chan *updateRequest
+	execChannel       chan func()
+	stopChannel       chan struct{}
+	ctxDone           <-chan struct{} // the wheel stops once it is closed, see StartContext
+	stopOnce          sync.Once
+	closeOnce         sync.Once
+	resizeMu          sync.Mutex
+	loopDone          chan struct{} // closed once the wheel goroutine exited, or by Stop if it never started
+	taskRecord        taskRecord
+	tagIndex          tagIndex
+	metrics           Metrics
+	logger            Logger
+	hooks             Hooks
+	hookQueue         *hookQueue
+	events            eventBus
+	interceptor       Interceptor
+	labelNames        map[string]struct{} // allowed metric labels, see WithMetricLabels
+	keyLabel          func(key interface{}) string
+	shareData         bool
+	pow2Slots         bool
+	slotCap           int
+	maxShift          int
+	dupPolicy         DuplicatePolicy
+	bareIntegers      BareIntegerPolicy
+	alignTicks        bool
+	stableOrder       bool
+	pull              bool    // the jobs hand the entries of a DelayQueue over on the wheel goroutine
+	timeScale         float64 // speed of the clock, see WithTimeScale
+	holdKeys          bool
+	randomStart       bool
+	autoStart         bool
+	spreadPhase       bool
+	phased            bool // the first tick is off the interval, the ticker is replaced after it
+	hibernate         bool
+	idle              bool       // the ticker is stopped while the wheel is empty, see WithIdleHibernation
+	idleNow           int32      // idle, accessed atomically
+	rand              *rand.Rand // nil means the global source, only used by the wheel goroutine and Start
+	maxTasks          int64
+	preciseMax        int64
+	nsQuotas          map[string]*nsQuota
+	onLimit           func(n int)
+	sequencer         *sequencer
+	workers           *workerPool
+	emitter           *emitQueue
+	shed              *shedder
+	mailboxSize       int
+	groups            map[string]*mutexGroup // read only once New returned
+	activeRuns        runIndex
+	slowThreshold     time.Duration
+	busyThreshold     int
+	busyEvery         time.Duration
+	busyHandler       BusySlotHandler
+	busyLast          map[int]time.Time // last warning of the slots, see WithBusySlotWarning
+	slowHandler       SlowJobHandler
+	locker            Locker
+	lockTTL           time.Duration
+	deadThreshold     int64
+	deadHandler       DeadLetterHandler
+	alertThreshold    int64
+	alertHandler      FailureAlertHandler
+	lateThreshold     time.Duration
+	lateHandler       LatenessHandler
+	lateHist          *tickHistogram
+	watchPeriod       time.Duration
+	watchStaleness    time.Duration
+	watchHandler      WatchdogHandler
+	panicHandler      LoopPanicHandler
+	panicLimit        int
+	panicWindow       time.Duration
+	panicTimes        []time.Time // recent panics of the wheel goroutine, see loopPanic
+	registry          *JobRegistry
+	store             Store
+	horizon           time.Duration
+	storePending      pendingKeys
+	wal               atomic.Pointer[writeAheadLog]
+	tickHook          atomic.Pointer[TickHook]
+	mark              atomic.Pointer[tickMark] // see Position
+	middleware        atomic.Pointer[[]Middleware]
+	mwMu              sync.Mutex // serializes Use
+	walWindow         time.Duration
+	coalesced         coalescedIndex // keys of the coalesced groups, see Coalesce
+	coalescing        int32          // 1 once a group is created, accessed atomically
+
+	state int32 // lifecycle state, accessed atomically
+
+	// counters, accessed atomically
+	taskNum       int64
+	firedNum      int64
+	removedNum    int64
+	tickNum       int64
+	lastTickCost  int64
+	tickTime      int64 // total duration of the ticks
+	tickHist      *tickHistogram
+	addedNum      int64
+	position      int64
+	slowNum       int64
+	deferredNum   int64
+	blackedOutNum int64
+	carryNum      int64
+	expiredNum    int64
+	waitingNum    int64
+	admitted      int64 // tasks counted against maxTasks
+	inflightNum   int64
+	queuedNum     int64
+	limitHit      int32
+	preciseNum    int64
+	busyNum       int64
+	peakTasks     int64
+	lockLost      int64
+	heartbeat     int64 // time of the last tick in unix nanoseconds, see Healthy
+	loopPanics    int64
+	fatal         int32 // 1 once the wheel stopped itself, see WithLoopPanicLimit
+
+	// catch up missed ticks
+	catchUpPolicy CatchUpPolicy
+	lastTick      time.Time
+	catchingUp    bool
+	caughtUp      map[*task]struct{}
+
+	// tick cap, due tasks over the cap wait in deferred
+	tickCap  int
+	budget   int
+	deferred []*task
+
+	// tasks with a TTL, see expireTasks
+	expiring expiryQueue
+
+	// runs of the acked tasks waiting for their Ack, see checkUnacked
+	unacked []*delivery
+
+	// blackout windows, the due tasks of a window wait in blackedOut under BlackoutDefer
+	blackouts      []Blackout
+	blackoutPolicy BlackoutPolicy
+	inBlackout     bool
+	blackedOut     []*task
+	tickAt         time.Time // wall clock time of the tick being handled
+
+	// firing gate checked once per tick, the tasks due while it is closed wait in gatedTasks
+	gate       func() bool
+	gatePolicy CatchUpPolicy
+	gateClosed int32
+	gatedTasks []*task
+
+	// due tasks beyond the scan chunk, handled between the requests of the loop
+	scanChunk int
+	chunkLeft int
+	carry     []*task
+
+	// tasks waiting for their prerequisite by its key, see DependsOn
+	dependents   map[interface{}][]*task
+	prereqPolicy PrerequisitePolicy
+
+	// due tasks ordered round-robin across groups, see WithFairDispatch
+	fairGroup FairGroup
+	fairBatch []*task
+
+	// last scheduling decisions by task key, see WithTaskTrace
+	traceSize    int
+	traces       map[interface{}]*traceRing
+	traceRetired []interface{}
+
+	// internal errors with no caller to return them to, see Errors
+	errs      atomic.Pointer[errorSink]
+	errBuffer int
+
+	// runs of the batched tasks held until the end of the tick, see AddBatchTask
+	batching bool
+	batches  []*BatchHandler
+
+	// tasks done during the loop iteration, their keys leave the record at its end, see flushFinished
+	finished []*task // counted in taskNum
+	swept    []*task // removed before
 }
 
 // Job callback function
 type Job func(TaskData)
 
-// TaskData callback params
+// TaskData callback params, the wheel keeps a shallow copy of the data given to AddTask and UpdateTask,
+// see WithSharedData, and every run receives a shallow copy
 type TaskData map[interface{}]interface{}
 
-// task struct
-type task struct {
-	interval time.Duration
-	times    int //-1:no limit >=1:run times
-	circle   int
+// remove request handled by the wheel goroutine
+type removeRequest struct {
+	key   interface{}
+	gen   uint64 // generation of the task to remove, 0 means any
+	reply chan error
+	named bool // set by the wheel goroutine for named tasks
+}
+
+// update request handled by the wheel goroutine
+type updateRequest struct {
 	key      interface{}
-	job      Job
+	interval time.Duration
 	taskData TaskData
+	gen      uint64 // generation of the task to update, 0 means any
+	reply    chan error
+	named    bool // set by the wheel goroutine for named tasks
+}
+
+// task struct
+type task struct {
+	gen       uint64 // generation stamped when the task is created, see RemoveTaskIf
+	interval  time.Duration
+	times     int //-1:no limit >=1:run times
+	circle    int
+	slot      int           // index of the slot holding the task
+	slotGen   int           // generation of the slots holding the task, see Resize
+	displaced time.Duration // delay added by the slot capacity
+
+	// position in the heap backend
+	heapIndex int
+	due       int64
+	seq       uint64
+
+	// position in the slot of the wheel backend
+	entry SlotEntry
+
+	key         interface{}
+	job         JobCtx
+	run         JobCtx // job wrapped in the middlewares, see Use
+	jobName     string // name of the job in the registry, empty for plain jobs
+	scope       *Scope // scope removing the task, see TimeWheel.Scope
+	taskData    TaskData
+	next        time.Time // ideal time of the next run
+	atNext      bool      // place the task by next instead of interval when it is added
+	paused      int32     // 1 if the runs are skipped, accessed atomically
+	skip        int32     // due runs left to skip, accessed atomically, see SkipNext
+	anchor      time.Time // ideal time of the postponed run, the following runs keep its cadence, see Postpone
+	tags        []string
+	priority    int
+	jitter      time.Duration
+	minGap      time.Duration // see MinGap
+	breaker     *breaker      // see CircuitBreaker
+	precise     bool          // backed by a timer, see Precise
+	timerSeq    uint64        // sequence of the armed timer of a precise task
+	backoff     *backoff      // see Backoff
+	stretched   int64         // interval stretched by Backoff, 0 if not stretched, accessed atomically
+	shed        int64         // interval stretched by the load shedding, 0 if not shed, accessed atomically
+	critical    bool          // never shed, see Critical
+	ack         *ackPolicy    // see Acked
+	redeliver   time.Time     // the unacknowledged run restored by Restore is dispatched again at this time
+	attempts    int           // attempts of the restored unacknowledged run
+	resume      ResumePolicy
+	missed      int          // runs skipped while paused
+	gated       int          // runs held by the firing gate, see WithFiringGate
+	waiters     []chan error // released by the next run, see WaitForNextRun
+	then        []ChainStep
+	attached    []attachedJob                        // see AttachJob
+	mailbox     *mailbox                             // runs held by HoldDeliveries, nil if they are dispatched
+	group       string                               // see InGroup
+	batch       *BatchHandler                        // see AddBatchTask
+	onDone      func(key interface{}, data TaskData) // see OnExhausted
+	until       time.Time                            // no run after it, zero means no deadline
+	alignPeriod bool                                 // see AlignToPeriod
+	schedule    Schedule                             // run times of the task, nil means every interval
+	dep         *dependency                          // prerequisite the task waits for, nil once released
+	labels      map[string]string                    // see MetricLabels
+	ttl         time.Duration                        // see TTL
+	expires     time.Time                            // the task is removed at this time, zero means never
+	held        int32                                // 1 while the final run holds the key, accessed atomically
+	trace       *traceRing                           // see WithTaskTrace
+	stats       taskStats
+	refs        int32 // references held by the wheel and the running jobs, accessed atomically
+}
+
+// finished tasks are recycled. A task is put back once its last reference is dropped, the wheel drops its own
+// only after the task left the record, so the other goroutines only read the tasks of the record under the
+// lock of their shard, see taskRecord.view, and the jobs hold a reference to the task of their run.
+var taskPool = sync.Pool{
+	New: func() interface{} {
+		return new(task)
+	},
+}
+
+// recycle the tasks through taskPool, only turned off by the benchmarks
+var poolTasks = true
+
+// hold a reference to the task
+func (t *task) retain() {
+	atomic.AddInt32(&t.refs, 1)
+}
+
+// drop a reference, the task is zeroed and recycled with the last one
+func (t *task) release() {
+	if atomic.AddInt32(&t.refs, -1) == 0 && poolTasks {
+		*t = task{}
+		taskPool.Put(t)
+	}
 }
 
 // New create a empty time wheel
-func New(interval time.Duration, slotNum int) *TimeWheel {
+func New(interval time.Duration, slotNum int, opts ...Option) *TimeWheel {
 	if interval <= 0 || slotNum <= 0 {
 		return nil
 	}
 	tw := &TimeWheel{
-		interval:       interval,
-		slots:          make([]*list.List, slotNum),
-		currentPos:     0,
-		slotNum:        slotNum,
-		addTaskChannel: make(chan *task),
-		stopChannel:    make(chan bool),
-		taskRecord:     &sync.Map{},
+		interval:          interval,
+		slotNum:           int64(slotNum),
+		removeTaskChannel: make(chan *removeRequest),
+		updateTaskChannel: make(chan *updateRequest),
+		execChannel:       make(chan func()),
+		stopChannel:       make(chan struct{}),
+		loopDone:          make(chan struct{}),
+		taskRecord:        newTaskRecord(),
+		clock:             realClock{},
+		logger:            nopLogger{},
+		catchUpPolicy:     FireOnePerTask,
+		scanChunk:         DefaultScanChunk,
 	}
 
-	tw.init()
+	for _, opt := range opts {
+		opt(tw)
+	}
+	if tw.timeScale > 0 && tw.timeScale != 1 {
+		if tw.alignTicks {
+			return nil
+		}
+		tw.clock = newScaledClock(tw.clock, tw.timeScale)
+	}
+	tw.addTaskChannel = make(chan *task, tw.addBuffer)
+	if tw.pow2Slots {
+		tw.slotNum = int64(nextPowerOfTwo(slotNum))
+	}
+	if tw.tickHist == nil {
+		tw.tickHist = newTickHistogram(DefaultTickBuckets)
+	}
+	if tw.lateHist == nil {
+		tw.lateHist = newTickHistogram(DefaultLatenessBuckets)
+	}
+	tw.backend = newBackend(tw.backendKind, int(tw.slotNum), tw.newSlotStore)
+	if wb, ok := tw.backend.(*wheelBackend); ok {
+		wb.slotCap, wb.maxShift, wb.interval = tw.slotCap, tw.maxShift, tw.interval
+		wb.stable = tw.stableOrder
+		if tw.traces != nil {
+			wb.onStay = func(t *task) {
+				tw.trace(t, TraceRotation)
+			}
+		}
+		if tw.slotCap > 0 {
+			wb.onFull = func(t *task) {
+				tw.reportError(ErrorSlotFull, t.key, fmt.Errorf("no slot with room within %d ticks", tw.maxShift))
+			}
+		}
+	}
+	if hb, ok := tw.backend.(*heapBackend); ok {
+		hb.stable = tw.stableOrder
+	}
+	if tw.preciseMax > 0 {
+		tw.backend = &preciseBackend{backend: tw.backend, tw: tw, timers: make(map[*task]Timer)}
+	}
 
 	return tw
 }
 
-// Start start the time wheel
+// lifecycle state of the wheel
+const (
+	stateNew int32 = iota
+	stateStarted
+	stateStopped
+)
+
+// Start start the time wheel, only the first call starts it, a stopped wheel can not be started
 func (tw *TimeWheel) Start() {
-	tw.ticker = time.NewTicker(tw.interval)
+	tw.startWith(nil)
+}
+
+// StartContext start the time wheel like Start, the wheel stops like on Stop once ctx is done.
+// Calling Stop as well is safe.
+func (tw *TimeWheel) StartContext(ctx context.Context) {
+	tw.startWith(ctx.Done())
+}
+
+// start the wheel, it stops once done is closed
+func (tw *TimeWheel) startWith(done <-chan struct{}) {
+	if !atomic.CompareAndSwapInt32(&tw.state, stateNew, stateStarted) {
+		return
+	}
+	tw.ctxDone = done
+	now := tw.clock.Now().Round(0)
+	first := tw.interval
+	if tw.randomStart {
+		first = tw.randomPhase()
+	}
+	if tw.alignTicks {
+		// tick once at the next boundary then every interval from there
+		first = now.Truncate(tw.interval).Add(tw.interval).Sub(now)
+	}
+	tw.lastTick = now.Add(first - tw.interval)
+	tw.markTick(tw.lastTick)
+	tw.beat()
+	tw.ticker = tw.clock.NewTicker(first)
+	tw.phased = first != tw.interval
+	if tw.workers != nil {
+		tw.startWorkers()
+	}
 	go tw.start()
+	if tw.store != nil {
+		go tw.refillLoop()
+	}
+	if tw.watchHandler != nil {
+		go tw.watchLoop()
+	}
+	if tw.emitter != nil {
+		go tw.emitLoop()
+	}
 }
 
-// Stop stop the time wheel
+// Stop stop the time wheel, the wheel can not be restarted and later calls return ErrWheelStopped
 func (tw *TimeWheel) Stop() {
-	tw.stopChannel <- true
+	tw.stopOnce.Do(func() {
+		if atomic.SwapInt32(&tw.state, stateStopped) == stateNew {
+			close(tw.loopDone)
+			tw.closeHooks()
+		}
+		close(tw.stopChannel)
+		if w := tw.wal.Load(); w != nil {
+			if err := w.close(); err != nil {
+				tw.logger.Printf("timewheel: close write ahead log failed, err: %v", err)
+			}
+		}
+	})
+}
+
+// report whether Stop is called
+func (tw *TimeWheel) isStopped() bool {
+	select {
+	case <-tw.stopChannel:
+		return true
+	default:
+		return false
+	}
 }
 
 func (tw *TimeWheel) start() {
-	for {
-		select {
-		case <-tw.ticker.C:
-			tw.tickHandler()
-		case task := <-tw.addTaskChannel:
-			tw.addTask(task)
-		case <-tw.stopChannel:
+	defer close(tw.loopDone)
+	defer tw.closeHooks()
+	for tw.guardedStep() {
+	}
+}
+
+// handle one event of the wheel goroutine, report false once the wheel is stopped
+func (tw *TimeWheel) step() bool {
+	var carried chan struct{}
+	if len(tw.carry) > 0 {
+		carried = carryReady
+	}
+	select {
+	case now := <-tw.tickChannel():
+		if tw.phased {
 			tw.ticker.Stop()
-			return
+			tw.ticker = tw.clock.NewTicker(tw.interval)
+			tw.phased = false
 		}
+		tw.onTicker(now)
+	case task := <-tw.addTaskChannel:
+		tw.addTask(task)
+	case req := <-tw.removeTaskChannel:
+		tw.drainAdds()
+		tw.reply(req.reply, func() error {
+			return tw.removeTask(req)
+		})
+	case req := <-tw.updateTaskChannel:
+		tw.drainAdds()
+		tw.reply(req.reply, func() error {
+			return tw.updateTask(req)
+		})
+	case fn := <-tw.execChannel:
+		tw.drainAdds()
+		fn()
+	case <-carried:
+		tw.runCarry()
+	case <-tw.ctxDone:
+		tw.ctxDone = nil
+		tw.Stop()
+	case <-tw.stopChannel:
+		tw.flushFinished()
+		tw.ticker.Stop()
+		if tw.workers != nil {
+			tw.flushPending()
+			tw.flushOverflow()
+			close(tw.workers.runs)
+		}
+		return false
+	}
+	if len(tw.finished) > 0 || len(tw.swept) > 0 {
+		tw.flushFinished()
 	}
+	if tw.workers != nil && len(tw.workers.pending) > 0 {
+		tw.flushPending()
+	}
+	if tw.workers != nil && len(tw.workers.overflow) > 0 {
+		tw.flushOverflow()
+	}
+	if tw.hibernate && !tw.idle {
+		tw.checkIdle()
+	}
+	return true
+}
+
+// run fn on the wheel goroutine and wait for it
+func (tw *TimeWheel) exec(fn func()) error {
+	done := make(chan struct{})
+	select {
+	case tw.execChannel <- func() {
+		// a panicking fn still releases the caller, see guardedStep
+		defer close(done)
+		fn()
+	}:
+	case <-tw.stopChannel:
+		return ErrWheelStopped
+	}
+	<-done
+	return nil
+}
+
+// run fn on the wheel goroutine without waiting for it, the task is not recycled before fn returns.
+// Used from the job goroutines: the wheel goroutine may be waiting for a worker, see Block.
+func (tw *TimeWheel) execLater(t *task, fn func()) {
+	t.retain()
+	go func() {
+		defer t.release()
+		tw.exec(fn)
+	}()
 }
 
 // AddTask add new task to the time wheel
 func (tw *TimeWheel) AddTask(interval time.Duration, times int, key interface{}, data TaskData, job Job) error {
+	return tw.AddTaskContext(context.Background(), interval, times, key, data, job)
+}
+
+// AddTaskContext add new task like AddTask, but give up and return ctx.Err() if ctx is done
+// before the wheel accepts the task
+func (tw *TimeWheel) AddTaskContext(ctx context.Context, interval time.Duration, times int, key interface{}, data TaskData, job Job) error {
+	if job == nil {
+		return ErrInvalidParams
+	}
+	task, err := tw.newTask(interval, times, key, data, wrapJob(job))
+	if err != nil {
+		return err
+	}
+	return tw.submit(ctx, task)
+}
+
+// AddTaskCtx add new task whose job receives a context, the context carries the values set by the interceptor
+func (tw *TimeWheel) AddTaskCtx(interval time.Duration, times int, key interface{}, data TaskData, job JobCtx) error {
+	task, err := tw.newTask(interval, times, key, data, job)
+	if err != nil {
+		return err
+	}
+	return tw.submit(context.Background(), task)
+}
+
+// send the task to the wheel goroutine, the task is registered there so a task not sent leaves no trace
+func (tw *TimeWheel) submit(ctx context.Context, task *task) error {
+	tw.startOnAdd()
+	if tw.isStopped() {
+		tw.dropTask(task)
+		return ErrWheelStopped
+	}
+	// the select may pick the send even though ctx is done
+	if err := ctx.Err(); err != nil {
+		tw.dropTask(task)
+		return err
+	}
+	if task.dep != nil {
+		return tw.submitDependent(task)
+	}
+	select {
+	case tw.addTaskChannel <- task:
+		tw.taskAccepted()
+		return nil
+	case <-ctx.Done():
+		tw.dropTask(task)
+		return ctx.Err()
+	case <-tw.stopChannel:
+		tw.dropTask(task)
+		return ErrWheelStopped
+	}
+}
+
+// TryAddTask add new task like AddTask, but return ErrQueueFull instead of blocking when the add buffer is full
+func (tw *TimeWheel) TryAddTask(interval time.Duration, times int, key interface{}, data TaskData, job Job) error {
+	if job == nil {
+		return ErrInvalidParams
+	}
+	task, err := tw.newTask(interval, times, key, data, wrapJob(job))
+	if err != nil {
+		return err
+	}
+
+	tw.startOnAdd()
+	if tw.isStopped() {
+		tw.dropTask(task)
+		return ErrWheelStopped
+	}
+	select {
+	case tw.addTaskChannel <- task:
+		tw.taskAccepted()
+		return nil
+	default:
+		tw.dropTask(task)
+		tw.logger.Printf("timewheel: add queue is full, task rejected, key: %v", key)
+		return ErrQueueFull
+	}
+}
+
+// Backlog get the number of tasks waiting in the add buffer
+func (tw *TimeWheel) Backlog() int {
+	return len(tw.addTaskChannel)
+}
+
+// register the tasks waiting in the add buffer, called before a control request so a request
+// follows the adds its caller made before it
+func (tw *TimeWheel) drainAdds() {
+	for n := len(tw.addTaskChannel); n > 0; n-- {
+		tw.addTask(<-tw.addTaskChannel)
+	}
+}
+
+// check the params and create the task
+func (tw *TimeWheel) newTask(interval time.Duration, times int, key interface{}, data TaskData, job JobCtx) (*task, error) {
 	if interval <= 0 || key == nil || job == nil || times < -1 || times == 0 {
-		return errors.New("illegal task params")
+		return nil, ErrInvalidParams
+	}
+	if !keyComparable(key) {
+		return nil, ErrKeyNotComparable
+	}
+	if interval > tw.maxDelay() {
+		return nil, ErrDelayTooLarge
+	}
+
+	if tw.isCoalescing() && tw.coalesced.has(key) {
+		return nil, ErrDuplicateKey
+	}
+	held := false
+	if tw.taskRecord.view(key, func(t *task) { held = t.isHeld() }) {
+		if held {
+			return nil, ErrTaskStillRunning
+		}
+		// the wheel goroutine applies the other policies
+		if tw.dupPolicy == DuplicateError {
+			return nil, ErrDuplicateKey
+		}
 	}
+	return tw.allocTask(interval, times, key, data, job)
+}
 
-	_, ok := tw.taskRecord.Load(key)
-	if ok {
-		return errors.New("duplicate task key")
+// count the task against the limit and take it from the pool, the params are checked
+func (tw *TimeWheel) allocTask(interval time.Duration, times int, key interface{}, data TaskData, job JobCtx) (*task, error) {
+	if !tw.taskRecord.accepts(key) {
+		return nil, ErrKeyType
+	}
+	if err := tw.admit(key); err != nil {
+		return nil, err
 	}
 
-	tw.addTaskChannel <- &task{interval: interval, times: times, key: key, taskData: data, job: job}
-	return nil
+	var t *task
+	if poolTasks {
+		t = taskPool.Get().(*task)
+	} else {
+		t = new(task)
+	}
+	t.gen = atomic.AddUint64(&taskGen, 1)
+	t.interval = interval
+	t.times = times
+	t.key = key
+	t.taskData = tw.ownData(data)
+	t.job = job
+	t.run = tw.wrapMiddleware(job)
+	t.next = tw.clock.Now().Add(interval)
+	t.refs = 1
+	return t, nil
+}
+
+// the task is sent to the wheel goroutine
+func (tw *TimeWheel) taskAccepted() {
+	if tw.metrics != nil {
+		tw.metrics.TaskAdded()
+	}
+}
+
+// copy the data given by the caller unless sharing is enabled
+func (tw *TimeWheel) ownData(data TaskData) TaskData {
+	if tw.shareData {
+		return data
+	}
+	return copyTaskData(data)
+}
+
+// adapt Job to JobCtx
+func wrapJob(job Job) JobCtx {
+	return func(_ context.Context, data TaskData) {
+		job(data)
+	}
 }
 
 // RemoveTask remove the task from time wheel
@@ -100,116 +784,539 @@ func (tw *TimeWheel) RemoveTask(key interface{}) error {
 	if key == nil {
 		return nil
 	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	if tw.isStopped() {
+		return ErrWheelStopped
+	}
 
-	value, ok := tw.taskRecord.Load(key)
+	stored := false
+	if tw.store != nil {
+		var err error
+		if stored, err = tw.store.Delete(key, 0); err != nil {
+			return err
+		}
+	}
 
-	if !ok {
-		return errors.New("task not exists, please check you task key")
-	} else {
-		// lazy remove task
-		task := value.(*task)
-		task.times = 0
-		tw.taskRecord.Delete(task.key)
+	req := &removeRequest{key: key, reply: make(chan error, 1)}
+	err := tw.sendRemove(req)
+	if err == ErrWheelStopped {
+		return err
+	}
+	if err == nil && req.named {
+		tw.walAppend(walRecord{Op: walRemove, Spec: TaskSpec{Key: key}})
+	}
+	if err != nil && !stored {
+		return err
 	}
 	return nil
 }
 
-// UpdateTask update task times and data
+// RemoveTasksWhere remove the tasks matching pred, return how many were removed.
+// pred is called on a snapshot of the registered tasks without holding any lock.
+func (tw *TimeWheel) RemoveTasksWhere(pred func(key interface{}, info TaskInfo) bool) int {
+	if pred == nil {
+		return 0
+	}
+	var keys []interface{}
+	tw.Range(func(key interface{}, info TaskInfo) bool {
+		if pred(key, info) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	n := 0
+	for _, key := range keys {
+		if tw.RemoveTask(key) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// UpdateTask update task interval and data, the new interval applies from the next run.
+// The update is applied on the wheel goroutine, so every run dispatched after UpdateTask returns
+// sees the new data while the running jobs keep the copy they were given. The interval is checked
+// like by AddTask and a rejected update leaves the task untouched, a nil taskData clears the data.
 func (tw *TimeWheel) UpdateTask(key interface{}, interval time.Duration, taskData TaskData) error {
 	if key == nil {
-		return errors.New("illegal key, please try again")
+		return ErrInvalidKey
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	if err := tw.checkInterval(interval); err != nil {
+		return err
+	}
+	if tw.isStopped() {
+		return ErrWheelStopped
 	}
 
-	value, ok := tw.taskRecord.Load(key)
+	taskData = tw.ownData(taskData)
+	req := &updateRequest{key: key, interval: interval, taskData: taskData, reply: make(chan error, 1)}
+	err := tw.sendUpdate(req)
+	if err == ErrWheelStopped {
+		return err
+	}
+	if err == nil && req.named {
+		tw.walAppend(walRecord{Op: walUpdate, Spec: TaskSpec{Key: key, Interval: interval, Data: copyTaskData(taskData)}})
+	}
+	if tw.store != nil && (err != nil || req.named) {
+		// the task may be stored but not loaded in the wheel yet
+		return tw.storeUpdate(key, interval, taskData)
+	}
+	return err
+}
 
-	if !ok {
-		return errors.New("task not exists, please check you task key")
+// Len get the number of registered tasks, a task is counted until the end of the tick running its last time
+func (tw *TimeWheel) Len() int {
+	return tw.taskRecord.Len()
+}
+
+// Range call fn for every registered task until it returns false, fn runs on a snapshot taken on the
+// wheel goroutine and may call the wheel
+func (tw *TimeWheel) Range(fn func(key interface{}, info TaskInfo) bool) {
+	if atomic.LoadInt32(&tw.state) == stateNew {
+		// only the wheel goroutine registers the tasks
+		return
 	}
-	task := value.(*task)
-	task.taskData = taskData
-	task.interval = interval
-	return nil
+	var infos []TaskInfo
+	collect := func() {
+		infos = make([]TaskInfo, 0, tw.taskRecord.Len())
+		tw.taskRecord.Range(func(key interface{}, t *task) bool {
+			infos = append(infos, t.info())
+			return true
+		})
+	}
+	if tw.exec(collect) != nil {
+		// nothing moves the tasks once the wheel goroutine exited
+		<-tw.loopDone
+		collect()
+	}
+	for _, info := range infos {
+		if !fn(info.Key, info) {
+			return
+		}
+	}
+}
+
+// HasTask report whether the task is registered, the key of a task running its last time is released
+// at the end of the tick
+func (tw *TimeWheel) HasTask(key interface{}) bool {
+	if key == nil || !keyComparable(key) {
+		return false
+	}
+	if _, ok := tw.taskRecord.Load(key); ok {
+		return true
+	}
+	return tw.isCoalescing() && tw.coalesced.has(key)
 }
 
-// time wheel initialize
-func (tw *TimeWheel) init() {
-	for i := 0; i < tw.slotNum; i++ {
-		tw.slots[i] = list.New()
+// handle a ticker event, catch up the ticks lost while the process was suspended
+func (tw *TimeWheel) onTicker(now time.Time) {
+	// compare wall clock, the monotonic clock stops while the host sleeps
+	now = now.Round(0)
+	missed := int(now.Sub(tw.lastTick)/tw.interval) - 1
+	last := tw.lastTick
+	tw.lastTick = now
+	if missed > 0 {
+		tw.logger.Printf("timewheel: %d ticks missed, catching up", missed)
+		tw.catchUp(last, missed)
 	}
+	tw.tickAt = now
+	tw.tickHandler()
 }
 
-//
+// advance the wheel over the missed ticks after last according to the catch up policy
+func (tw *TimeWheel) catchUp(last time.Time, missed int) {
+	tw.drainCarry()
+	tw.catchingUp = true
+	if tw.catchUpPolicy == FireOnePerTask {
+		tw.caughtUp = make(map[*task]struct{})
+	}
+	for i := 0; i < missed; i++ {
+		tw.tickAt = last.Add(time.Duration(i+1) * tw.interval)
+		tw.tickHandler()
+	}
+	tw.catchingUp = false
+	tw.caughtUp = nil
+}
+
+// report whether a due task should run, missed occurrences may be dropped while catching up
+func (tw *TimeWheel) shouldRun(task *task) bool {
+	if !tw.catchingUp {
+		return true
+	}
+	switch tw.catchUpPolicy {
+	case Skip:
+		return false
+	case FireOnePerTask:
+		if _, ok := tw.caughtUp[task]; ok {
+			return false
+		}
+		tw.caughtUp[task] = struct{}{}
+	}
+	return true
+}
+
+// run the tasks due at the current tick and move to the next one
 func (tw *TimeWheel) tickHandler() {
-	l := tw.slots[tw.currentPos]
-	tw.scanAddRunTask(l)
-	if tw.currentPos == tw.slotNum-1 {
-		tw.currentPos = 0
+	begin := time.Now()
+	pos := tw.backend.position()
+	fired := atomic.LoadInt64(&tw.firedNum)
+	if len(tw.expiring) > 0 {
+		tw.expireTasks(tw.clock.Now())
+	}
+	if len(tw.unacked) > 0 {
+		tw.checkUnacked(tw.clock.Now())
+	}
+	if tw.busyThreshold > 0 {
+		tw.checkBusySlot()
+	}
+	if len(tw.blackouts) > 0 {
+		tw.checkBlackout(tw.tickAt)
+	}
+	if tw.gate != nil {
+		tw.checkGate()
+	}
+	tw.batching = true
+	if tw.tickCap > 0 {
+		tw.advanceCapped()
+	} else if tw.fairGroup != nil {
+		tw.chunkLeft = tw.scanChunk
+		tw.advanceFair(tw.runDueTaskChunked)
 	} else {
-		tw.currentPos++
+		tw.chunkLeft = tw.scanChunk
+		tw.backend.advance(eachDue(tw.runDueTaskChunked))
+	}
+	tw.batching = false
+	if len(tw.batches) > 0 {
+		tw.flushBatches()
+	}
+	// the wait for the workers counts towards the tick, the ticks caught up wait once at the end
+	if tw.workers != nil && len(tw.workers.pending) > 0 && !tw.catchingUp {
+		tw.flushPending()
+	}
+	cost := time.Since(begin)
+	if tw.tickHist.observe(cost, tw.interval) {
+		tw.logger.Printf("timewheel: tick of position %d took %v, longer than the interval", pos, cost)
+		if m, ok := tw.metrics.(TickOverrunMetrics); ok {
+			m.TickOverrun(cost)
+		}
+	}
+	if tw.metrics != nil {
+		tw.metrics.TickDone(cost, int(atomic.LoadInt64(&tw.taskNum)), len(tw.addTaskChannel))
+	}
+	if tw.shed != nil {
+		tw.checkShedding(cost)
 	}
+	atomic.AddInt64(&tw.tickNum, 1)
+	atomic.StoreInt64(&tw.lastTickCost, int64(cost))
+	atomic.AddInt64(&tw.tickTime, int64(cost))
+	tw.markTick(tw.tickAt)
+	tw.beat()
+	tw.callTickHook(pos, fired)
 }
 
 // add task
 func (tw *TimeWheel) addTask(task *task) {
-	if task.times == 0 {
+	if task.times == 0 || task.scope.isClosed() {
+		tw.dropTask(task)
 		return
 	}
+	if tw.idle {
+		tw.wake()
+	}
+	// the postponed run is over, the task is placed back on its cadence
+	if !task.anchor.IsZero() {
+		task.anchor = time.Time{}
+		task.atNext = true
+	}
 
-	pos, circle := tw.getPositionAndCircle(task.interval)
-	task.circle = circle
+	//record the task
+	spread := 0
+	// a recurring task is registered already, only a new key takes the write lock
+	v, loaded := tw.taskRecord.Load(task.key)
+	if loaded && v.times == 0 && len(tw.finished) > 0 {
+		// the key of a task done in this iteration is free
+		tw.flushFinished()
+		v, loaded = tw.taskRecord.Load(task.key)
+	}
+	if !loaded {
+		v, loaded = tw.taskRecord.LoadOrStore(task.key, task)
+	}
+	fresh := !loaded
+	if fresh {
+		if task.alignPeriod && !task.atNext {
+			task.next = alignedAfter(tw.clock.Now(), task.interval)
+		} else if tw.spreadPhase && !task.atNext {
+			spread = tw.phaseTicks(task)
+			task.next = task.next.Add(time.Duration(spread) * tw.interval)
+		}
+		if n := atomic.AddInt64(&tw.taskNum, 1); n > tw.peakTasks {
+			atomic.StoreInt64(&tw.peakTasks, n)
+		}
+		atomic.AddInt64(&tw.addedNum, 1)
+		tw.tagIndex.add(task)
+		tw.emit(tw.hooks.OnTaskAdded, task)
+		tw.publish(EventAdded, task)
+		tw.trackExpiry(task)
+		if !task.redeliver.IsZero() {
+			tw.restoreDelivery(task)
+		}
+	} else if v != task {
+		if tw.dupPolicy == DuplicateError {
+			tw.logger.Printf("timewheel: duplicate task key rejected, key: %v", task.key)
+			tw.reportError(ErrorTaskRejected, task.key, ErrDuplicateKey)
+		}
+		tw.coalesce(v)
+		tw.dropTask(task)
+		return
+	}
 
-	tw.slots[pos].PushBack(task)
+	d := task.effective()
+	// an aligned or scheduled task is placed by its ideal schedule so it stays on the boundaries
+	if task.atNext || task.alignPeriod || task.schedule != nil {
+		task.atNext = false
+		d = task.next.Sub(tw.clock.Now())
+		if d < 0 {
+			d = 0
+		}
+	} else if task.jitter > 0 {
+		d += time.Duration(tw.int63n(int64(task.jitter)))
+	}
+	if task.dep != nil {
+		tw.park(task, d+time.Duration(spread)*tw.interval)
+		return
+	}
+	tw.backend.push(task, tw.delayTicks(d)+spread)
+	if fresh {
+		tw.trace(task, TraceAdded)
+	} else {
+		tw.trace(task, TraceRescheduled)
+	}
+}
 
-	//record the task
-	tw.taskRecord.Store(task.key, task)
+// remove the task from the record and unlink it from its slot
+func (tw *TimeWheel) removeTask(req *removeRequest) error {
+	key := req.key
+	task, ok := tw.taskRecord.Load(key)
+	if !ok {
+		if req.gen == 0 && tw.isCoalescing() && tw.coalesced.remove(key) {
+			return nil
+		}
+		return ErrTaskNotFound
+	}
+	if req.gen != 0 && task.gen != req.gen {
+		return ErrStaleGeneration
+	}
+	req.named = task.jobName != ""
+
+	tw.emit(tw.hooks.OnTaskRemoved, task)
+	tw.publish(EventRemoved, task)
+	tw.unregister(task)
+	atomic.AddInt64(&tw.removedNum, 1)
+	if tw.metrics != nil {
+		tw.metrics.TaskRemoved()
+	}
+	tw.prerequisiteGone(key)
+	return nil
+}
+
+// drop the registered task from the record and its slot
+func (tw *TimeWheel) unregister(task *task) {
+	// a held key may be released by the job meanwhile
+	if !tw.taskRecord.CompareAndDelete(task.key, task) {
+		return
+	}
+	tw.unlink(task)
 }
 
-// scan task list and run the task
-func (tw *TimeWheel) scanAddRunTask(l *list.List) {
+// drop the task deleted from the record from its slot
+func (tw *TimeWheel) unlink(task *task) {
+	tw.tagIndex.remove(task)
+	if !tw.unpark(task) {
+		tw.backend.remove(task)
+	}
+	tw.trace(task, TraceRemoved)
+	if len(tw.unacked) > 0 {
+		tw.dropUnacked(task)
+	}
+	task.times = 0
+	atomic.AddInt64(&tw.taskNum, -1)
+	tw.dropTask(task)
+}
+
+// check the interval given to an update like newTask does
+func (tw *TimeWheel) checkInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("%w, interval %v is not positive", ErrInvalidParams, interval)
+	}
+	if interval > tw.maxDelay() {
+		return ErrDelayTooLarge
+	}
+	return nil
+}
+
+// update the task data and interval
+func (tw *TimeWheel) updateTask(req *updateRequest) error {
+	task, ok := tw.taskRecord.Load(req.key)
+	if !ok {
+		return ErrTaskNotFound
+	}
+	if req.gen != 0 && task.gen != req.gen {
+		return ErrStaleGeneration
+	}
+	task.taskData = req.taskData
+	task.interval = req.interval
+	// a new definition starts with a clean record
+	atomic.StoreInt64(&task.stats.failures, 0)
+	req.named = task.jobName != ""
+	return nil
+}
+
+// run the due task then re-add it or drop it
+func (tw *TimeWheel) runDueTask(task *task) {
+	if task.times == 0 {
+		tw.tagIndex.remove(task)
+		tw.swept = append(tw.swept, task)
+		return
+	}
+
+	// a paused task keeps its times
+	if task.isPaused() {
+		task.missed++
+		task.next = task.following()
+		tw.addTask(task)
+		return
+	}
+
+	// so does a skipped run
+	if atomic.LoadInt32(&task.skip) > 0 && !task.lastRun() {
+		atomic.AddInt32(&task.skip, -1)
+		tw.trace(task, TraceSkipped)
+		task.next = task.following()
+		tw.addTask(task)
+		return
+	}
+
+	// the deadline is checked against the ideal schedule, so a late tick does not extend it
+	expired := !task.until.IsZero() && task.next.After(task.until)
+	if expired || task.lastRun() {
+		task.times = 1
+	}
 
-	if l == nil {
+	if !expired && (tw.holdForGate(task) || tw.holdForBlackout(task) || tw.holdForGap(task) ||
+		tw.holdForMailbox(task)) {
 		return
 	}
 
-	for item := l.Front(); item != nil; {
-		task := item.Value.(*task)
+	persist := tw.persistRun(task)
 
-		if task.times == 0 {
-			next := item.Next()
-			l.Remove(item)
-			tw.taskRecord.Delete(task.key)
-			item = next
-			continue
+	// dropped occurrences still count towards times
+	ran := !expired && !tw.inBlackout && !tw.isGated() && tw.shouldRun(task) && tw.breakerAllows(task)
+	if ran {
+		tw.fire(task, task.next, persist)
+	} else {
+		if !expired {
+			tw.publish(EventDropped, task)
+		}
+		tw.trace(task, TraceDropped)
+		if persist != nil {
+			go persist()
 		}
+	}
 
-		if task.circle > 0 {
-			task.circle--
-			item = item.Next()
-			continue
+	if task.times == 1 {
+		task.times = 0
+		tw.trace(task, TraceDone)
+		if !ran {
+			// no job to wait for
+			tw.prerequisiteDone(task.key, true)
+		}
+		if ran && tw.holdKeys {
+			// the key is released by the job, see releaseHeld
+			atomic.StoreInt32(&task.held, 1)
+			return
+		}
+		tw.tagIndex.remove(task)
+		tw.finished = append(tw.finished, task)
+	} else {
+		if task.times > 0 {
+			task.times--
 		}
+		if tw.shed != nil {
+			tw.applyShed(task)
+		}
+		task.next = task.following()
+		tw.addTask(task)
+	}
+}
 
-		go task.job(task.taskData)
-		next := item.Next()
-		l.Remove(item)
-		item = next
+// dispatch a run of the task scheduled at the given time
+func (tw *TimeWheel) fire(task *task, scheduled time.Time, persist func()) {
+	tw.fireRun(task, scheduled, persist, task.times == 1)
+}
 
-		if task.times == 1 {
-			task.times = 0
-			tw.taskRecord.Delete(task.key)
-		} else {
-			if task.times > 0 {
-				task.times--
-			}
-			tw.addTask(task)
-		}
+// dispatch a run of the task, final if the task leaves the wheel with it
+func (tw *TimeWheel) fireRun(task *task, scheduled time.Time, persist func(), final bool) {
+	atomic.AddInt64(&tw.firedNum, 1)
+	if tw.metrics != nil {
+		tw.metricFired(task)
+	}
+	tw.emit(tw.hooks.OnTaskFired, task)
+	tw.publish(EventFired, task)
+	tw.trace(task, TraceDispatched)
+	exec := Execution{Key: task.key, Scheduled: scheduled, Fired: tw.clock.Now()}
+	task.stats.fired(exec.Fired)
+	task.retain()
+	atomic.AddInt64(&tw.inflightNum, 1)
+	// every run gets its own copy, a job writing it does not race with the other runs or the snapshots
+	r := &jobRun{
+		task:    task,
+		data:    copyTaskData(task.taskData),
+		exec:    exec,
+		info:    task.info(),
+		final:   final,
+		persist: persist,
+		prereq:  tw.dependents[task.key] != nil,
+		late:    tw.observeLateness(task, scheduled, exec.Fired),
+	}
+	if task.ack != nil {
+		tw.deliver(r)
+	}
+	if len(task.waiters) > 0 {
+		r.waiters, task.waiters = task.waiters, nil
 	}
+	if !tw.holdForBatch(r) {
+		tw.dispatch(r)
+	}
+	if task.attached != nil {
+		tw.fireAttached(task, r)
+	}
+}
+
+// number of whole ticks to wait for the delay
+func (tw *TimeWheel) delayTicks(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(d / tw.interval)
 }
 
-// get the task position
-func (tw *TimeWheel) getPositionAndCircle(d time.Duration) (pos int, circle int) {
-	delaySeconds := int(d.Seconds())
-	intervalSeconds := int(tw.interval.Seconds())
-	circle = int(delaySeconds / intervalSeconds / tw.slotNum)
-	pos = int(tw.currentPos+delaySeconds/intervalSeconds) % tw.slotNum
-	return
+// drop the keys of the tasks done during the loop iteration from the record, a batch takes each record lock once
+// so a tick finishing many tasks does not contend with the callers once per task
+func (tw *TimeWheel) flushFinished() {
+	if n := tw.taskRecord.deleteBatch(tw.finished); n > 0 {
+		atomic.AddInt64(&tw.taskNum, -int64(n))
+	}
+	tw.taskRecord.deleteBatch(tw.swept)
+	for _, queue := range []*[]*task{&tw.finished, &tw.swept} {
+		for i, t := range *queue {
+			(*queue)[i] = nil
+			tw.dropTask(t)
+		}
+		*queue = (*queue)[:0]
+	}
 }
@@ -0,0 +1,131 @@
+package timewheel
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// the job reads and writes its data while the data is replaced, run with -race
+func TestUpdateTaskDataRace(t *testing.T) {
+	tw := New(time.Millisecond, 16)
+	tw.Start()
+	defer tw.Stop()
+	var runs int64
+	err := tw.AddTask(time.Millisecond, -1, "k", TaskData{"v": 0}, func(data TaskData) {
+		if _, ok := data["v"].(int); !ok {
+			t.Errorf("data %v", data)
+		}
+		data["seen"] = true
+		atomic.AddInt64(&runs, 1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for i := 1; time.Now().Before(deadline); i++ {
+		if err := tw.UpdateTask("k", time.Millisecond, TaskData{"v": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if atomic.LoadInt64(&runs) == 0 {
+		t.Fatal("no run")
+	}
+}
+
+// an update returning before a run is dispatched is seen by that run, a running job keeps its copy
+func TestUpdateTaskDataVisible(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var mu sync.Mutex
+	var seen []interface{}
+	release := make(chan struct{})
+	tw.AddTask(time.Second, -1, "k", TaskData{"v": 1}, func(data TaskData) {
+		if data["v"] == 1 {
+			<-release
+		}
+		mu.Lock()
+		seen = append(seen, data["v"])
+		mu.Unlock()
+	})
+	settle(tw)
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	// the first run waits, the update lands meanwhile
+	if err := tw.UpdateTask("k", time.Second, TaskData{"v": 2}); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	c.Tick(time.Second)
+	settle(tw)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(seen)
+		mu.Unlock()
+		if n == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	// the runs overlap, they append in any order
+	if len(seen) != 2 || seen[0] == seen[1] {
+		t.Fatalf("runs saw %v", seen)
+	}
+}
+
+func TestAddTaskCopiesData(t *testing.T) {
+	for _, shared := range []bool{false, true} {
+		c := newFakeClock()
+		opts := []Option{WithClock(c)}
+		if shared {
+			opts = append(opts, WithSharedData())
+		}
+		tw := New(time.Second, 10, opts...)
+		tw.Start()
+		seen := make([]chan interface{}, 3)
+		jobs := make([]Job, 3)
+		for i := range seen {
+			ch := make(chan interface{}, 1)
+			seen[i], jobs[i] = ch, func(data TaskData) { ch <- data["v"] }
+		}
+		// a scratch map reused for several tasks
+		scratch := TaskData{"v": "a"}
+		tw.AddTask(time.Second, 1, "a", scratch, jobs[0])
+		scratch["v"] = "b"
+		tw.AddTask(time.Second, 1, "b", scratch, jobs[1])
+		scratch["v"] = "c"
+		update := TaskData{"v": "u"}
+		tw.AddTask(time.Second, 1, "u", nil, jobs[2])
+		tw.UpdateTask("u", time.Second, update)
+		update["v"] = "changed"
+		c.Tick(time.Second)
+		c.Tick(time.Second)
+		settle(tw)
+		tw.Stop()
+		got := make([]interface{}, 3)
+		for i, ch := range seen {
+			select {
+			case got[i] = <-ch:
+			case <-time.After(5 * time.Second):
+				t.Fatalf("shared=%v: no run of task %d", shared, i)
+			}
+		}
+		want := []interface{}{"a", "b", "u"}
+		if shared {
+			// the tasks keep the caller's maps
+			want = []interface{}{"c", "c", "changed"}
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("shared=%v: runs saw %v, want %v", shared, got, want)
+			}
+		}
+	}
+}
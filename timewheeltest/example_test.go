@@ -0,0 +1,47 @@
+package timewheeltest_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nosixtools/timewheel"
+	"github.com/nosixtools/timewheel/timewheeltest"
+)
+
+// Reminders send a reminder once the delay passed, the unit under test
+type Reminders struct {
+	Sched timewheel.Scheduler
+	Send  func(id string)
+}
+
+// Remind schedule the reminder
+func (r *Reminders) Remind(id string, d time.Duration) error {
+	return r.Sched.AddTask(d, 1, id, nil, func(timewheel.TaskData) { r.Send(id) })
+}
+
+// Cancel drop the reminder
+func (r *Reminders) Cancel(id string) error {
+	return r.Sched.RemoveTask(id)
+}
+
+// The component takes a *TimeWheel in production and the fake in its tests
+func ExampleFake() {
+	fake := timewheeltest.NewFake()
+	r := &Reminders{Sched: fake, Send: func(id string) { fmt.Println("sent", id) }}
+	r.Remind("standup", 15*time.Minute)
+	r.Remind("lunch", 3*time.Hour)
+	r.Cancel("lunch")
+
+	// no waiting, the test runs the job when it wants
+	fake.Fire("standup")
+	fmt.Println(fake.HasTask("standup"), fake.Fire("lunch"))
+	for _, c := range fake.Calls() {
+		fmt.Println(c.Method, c.Key)
+	}
+	// Output:
+	// sent standup
+	// false task not exists, please check you task key
+	// AddTask standup
+	// AddTask lunch
+	// RemoveTask lunch
+}
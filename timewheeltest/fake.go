@@ -0,0 +1,285 @@
+// Package timewheeltest provide a timewheel.Scheduler double for the tests of the code using the wheel.
+// The fake applies the checks of the wheel and records the calls, nothing runs until the test fires a task.
+//
+//	type Reminder struct {
+//		sched timewheel.Scheduler
+//	}
+//
+//	func (r *Reminder) Remind(id string, d time.Duration, send func()) error {
+//		return r.sched.AddTask(d, 1, id, nil, func(timewheel.TaskData) { send() })
+//	}
+//
+//	func TestRemind(t *testing.T) {
+//		fake := timewheeltest.NewFake()
+//		sent := false
+//		r := &Reminder{sched: fake}
+//		if err := r.Remind("a", time.Minute, func() { sent = true }); err != nil {
+//			t.Fatal(err)
+//		}
+//		fake.AssertAdded(t, "a")
+//		if err := fake.Fire("a"); err != nil || !sent {
+//			t.Fatal(err, sent)
+//		}
+//		if fake.HasTask("a") {
+//			t.Fatal("one shot task still registered")
+//		}
+//	}
+package timewheeltest
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nosixtools/timewheel"
+)
+
+// Call a call made to the fake
+type Call struct {
+	Method   string // AddTask, RemoveTask, UpdateTask or Stop
+	Key      interface{}
+	Interval time.Duration
+	Times    int
+	Err      error // error returned to the caller
+}
+
+// a registered task
+type fakeTask struct {
+	interval time.Duration
+	times    int
+	data     timewheel.TaskData
+	job      timewheel.Job
+}
+
+// Fake a timewheel.Scheduler whose tasks run when the test fires them
+type Fake struct {
+	mu      sync.Mutex
+	tasks   map[interface{}]*fakeTask
+	calls   []Call
+	stopped bool
+}
+
+var _ timewheel.Scheduler = (*Fake)(nil)
+
+// NewFake create a fake without tasks
+func NewFake() *Fake {
+	return &Fake{tasks: make(map[interface{}]*fakeTask)}
+}
+
+// AddTask register the task like the wheel does
+func (f *Fake) AddTask(interval time.Duration, times int, key interface{}, data timewheel.TaskData, job timewheel.Job) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	err := f.add(interval, times, key, data, job)
+	f.calls = append(f.calls, Call{Method: "AddTask", Key: key, Interval: interval, Times: times, Err: err})
+	return err
+}
+
+func (f *Fake) add(interval time.Duration, times int, key interface{}, data timewheel.TaskData, job timewheel.Job) error {
+	if interval <= 0 || key == nil || job == nil || times < -1 || times == 0 {
+		return timewheel.ErrInvalidParams
+	}
+	if !keyComparable(key) {
+		return timewheel.ErrKeyNotComparable
+	}
+	if f.stopped {
+		return timewheel.ErrWheelStopped
+	}
+	if _, ok := f.tasks[key]; ok {
+		return timewheel.ErrDuplicateKey
+	}
+	f.tasks[key] = &fakeTask{interval: interval, times: times, data: copyData(data), job: job}
+	return nil
+}
+
+// RemoveTask remove the task like the wheel does
+func (f *Fake) RemoveTask(key interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	err := f.remove(key)
+	f.calls = append(f.calls, Call{Method: "RemoveTask", Key: key, Err: err})
+	return err
+}
+
+func (f *Fake) remove(key interface{}) error {
+	if key == nil {
+		return nil
+	}
+	if !keyComparable(key) {
+		return timewheel.ErrKeyNotComparable
+	}
+	if f.stopped {
+		return timewheel.ErrWheelStopped
+	}
+	if _, ok := f.tasks[key]; !ok {
+		return timewheel.ErrTaskNotFound
+	}
+	delete(f.tasks, key)
+	return nil
+}
+
+// UpdateTask replace the interval and the data of the task like the wheel does
+func (f *Fake) UpdateTask(key interface{}, interval time.Duration, taskData timewheel.TaskData) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	err := f.update(key, interval, taskData)
+	f.calls = append(f.calls, Call{Method: "UpdateTask", Key: key, Interval: interval, Err: err})
+	return err
+}
+
+func (f *Fake) update(key interface{}, interval time.Duration, taskData timewheel.TaskData) error {
+	if key == nil {
+		return timewheel.ErrInvalidKey
+	}
+	if !keyComparable(key) {
+		return timewheel.ErrKeyNotComparable
+	}
+	if interval <= 0 {
+		return fmt.Errorf("%w, interval %v is not positive", timewheel.ErrInvalidParams, interval)
+	}
+	if f.stopped {
+		return timewheel.ErrWheelStopped
+	}
+	t, ok := f.tasks[key]
+	if !ok {
+		return timewheel.ErrTaskNotFound
+	}
+	t.interval, t.data = interval, copyData(taskData)
+	return nil
+}
+
+// HasTask report whether the task is registered
+func (f *Fake) HasTask(key interface{}) bool {
+	if key == nil || !keyComparable(key) {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.tasks[key]
+	return ok
+}
+
+// Stop reject the later calls with ErrWheelStopped like a stopped wheel
+func (f *Fake) Stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stopped = true
+	f.calls = append(f.calls, Call{Method: "Stop"})
+}
+
+// Fire run the job of the task once on the calling goroutine, the run counts towards the times of the
+// task and the task leaves the fake after its last run. It fails with ErrTaskNotFound if no task is
+// registered under the key.
+func (f *Fake) Fire(key interface{}) error {
+	f.mu.Lock()
+	t, ok := f.tasks[key]
+	if !ok {
+		f.mu.Unlock()
+		return timewheel.ErrTaskNotFound
+	}
+	if t.times > 0 {
+		t.times--
+		if t.times == 0 {
+			delete(f.tasks, key)
+		}
+	}
+	job, data := t.job, copyData(t.data)
+	f.mu.Unlock()
+	job(data)
+	return nil
+}
+
+// Interval get the interval of the task, false if no task is registered under the key
+func (f *Fake) Interval(key interface{}) (time.Duration, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.tasks[key]
+	if !ok {
+		return 0, false
+	}
+	return t.interval, true
+}
+
+// Calls get the calls made so far in order
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// AssertAdded fail the test unless a task was added under the key
+func (f *Fake) AssertAdded(t testing.TB, key interface{}) {
+	t.Helper()
+	if !f.called("AddTask", key) {
+		t.Errorf("timewheeltest: no task added under %v", key)
+	}
+}
+
+// AssertRemoved fail the test unless the task under the key was removed
+func (f *Fake) AssertRemoved(t testing.TB, key interface{}) {
+	t.Helper()
+	if !f.called("RemoveTask", key) {
+		t.Errorf("timewheeltest: task %v not removed", key)
+	}
+}
+
+// AssertNotCalled fail the test if the fake received any call
+func (f *Fake) AssertNotCalled(t testing.TB) {
+	t.Helper()
+	if calls := f.Calls(); len(calls) > 0 {
+		t.Errorf("timewheeltest: unexpected calls %+v", calls)
+	}
+}
+
+// report whether a successful call of method was made with the key
+func (f *Fake) called(method string, key interface{}) bool {
+	for _, c := range f.Calls() {
+		if c.Method == method && c.Err == nil && equal(c.Key, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// compare the keys, the keys that are not comparable never match
+func equal(a, b interface{}) (eq bool) {
+	defer func() {
+		if recover() != nil {
+			eq = false
+		}
+	}()
+	return a == b
+}
+
+// report whether the key can be used as a map key like the wheel does
+func keyComparable(key interface{}) (ok bool) {
+	t := reflect.TypeOf(key)
+	if !t.Comparable() {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Interface:
+	default:
+		return true
+	}
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	_ = key == key
+	return true
+}
+
+func copyData(data timewheel.TaskData) timewheel.TaskData {
+	if data == nil {
+		return nil
+	}
+	c := make(timewheel.TaskData, len(data))
+	for k, v := range data {
+		c[k] = v
+	}
+	return c
+}
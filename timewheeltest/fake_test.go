@@ -0,0 +1,52 @@
+package timewheeltest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nosixtools/timewheel"
+)
+
+type reminder struct {
+	sched timewheel.Scheduler
+}
+
+func (r *reminder) remind(id string, d time.Duration, send func()) error {
+	return r.sched.AddTask(d, 1, id, nil, func(timewheel.TaskData) { send() })
+}
+
+func TestFake(t *testing.T) {
+	fake := NewFake()
+	sent := 0
+	r := &reminder{sched: fake}
+	if err := r.remind("a", time.Minute, func() { sent++ }); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.remind("a", time.Minute, func() {}); err != timewheel.ErrDuplicateKey {
+		t.Fatal(err)
+	}
+	if err := fake.AddTask(0, 1, "b", nil, func(timewheel.TaskData) {}); err != timewheel.ErrInvalidParams {
+		t.Fatal(err)
+	}
+	if err := fake.AddTask(time.Second, 1, []int{1}, nil, func(timewheel.TaskData) {}); err != timewheel.ErrKeyNotComparable {
+		t.Fatal(err)
+	}
+	fake.AssertAdded(t, "a")
+	if err := fake.Fire("a"); err != nil || sent != 1 || fake.HasTask("a") {
+		t.Fatal(err, sent)
+	}
+	if fake.Fire("a") != timewheel.ErrTaskNotFound || fake.RemoveTask("a") != timewheel.ErrTaskNotFound {
+		t.Fatal("gone")
+	}
+	if err := fake.UpdateTask("x", -1, nil); !errors.Is(err, timewheel.ErrInvalidParams) {
+		t.Fatal(err)
+	}
+	fake.Stop()
+	if fake.AddTask(time.Second, 1, "c", nil, func(timewheel.TaskData) {}) != timewheel.ErrWheelStopped {
+		t.Fatal("stopped")
+	}
+	if n := len(fake.Calls()); n != 8 {
+		t.Fatal(n, fake.Calls())
+	}
+}
@@ -0,0 +1,25 @@
+package timewheeltest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nosixtools/timewheel"
+)
+
+// AssertInvariants fail the test if the wheel is inconsistent, see TimeWheel.ValidateInvariants.
+// Call it at the end of the concurrency tests, while the wheel still runs.
+func AssertInvariants(t testing.TB, tw *timewheel.TimeWheel) {
+	t.Helper()
+	err := tw.ValidateInvariants()
+	var ie *timewheel.InvariantError
+	if errors.As(err, &ie) {
+		for _, v := range ie.Violations {
+			t.Errorf("timewheeltest: %v", v)
+		}
+		return
+	}
+	if err != nil {
+		t.Errorf("timewheeltest: invariants not checked: %v", err)
+	}
+}
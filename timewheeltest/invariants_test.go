@@ -0,0 +1,16 @@
+package timewheeltest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nosixtools/timewheel"
+)
+
+func TestAssertInvariants(t *testing.T) {
+	tw := timewheel.New(time.Millisecond, 8)
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(time.Second, 1, "a", nil, func(timewheel.TaskData) {})
+	AssertInvariants(t, tw)
+}
@@ -0,0 +1,153 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// number of removed or finished keys whose trace is kept, see WithTaskTrace
+const traceKeep = 1024
+
+// TraceKind kind of a scheduling decision recorded by the task trace, see WithTaskTrace
+type TraceKind int
+
+const (
+	// TraceAdded the task is registered and placed
+	TraceAdded TraceKind = iota
+	// TraceRescheduled the task is placed for its next run
+	TraceRescheduled
+	// TraceRotation the slot of the task is scanned but a rotation is left, Circle is the rotations left after it
+	TraceRotation
+	// TraceDispatched a run of the task is dispatched
+	TraceDispatched
+	// TraceDropped a due run is dropped, while catching up, by the circuit breaker or past the deadline
+	TraceDropped
+	// TraceDeferred the due task waits for the tick cap, the scan chunk or its minimum gap
+	TraceDeferred
+	// TraceBlackedOut the due task waits for the end of a blackout window
+	TraceBlackedOut
+	// TraceRemoved the task is removed
+	TraceRemoved
+	// TraceDone the task ran its last time
+	TraceDone
+	// TraceSkipped a due run is skipped by SkipNext
+	TraceSkipped
+)
+
+func (k TraceKind) String() string {
+	switch k {
+	case TraceAdded:
+		return "added"
+	case TraceRescheduled:
+		return "rescheduled"
+	case TraceRotation:
+		return "rotation"
+	case TraceDispatched:
+		return "dispatched"
+	case TraceDropped:
+		return "dropped"
+	case TraceDeferred:
+		return "deferred"
+	case TraceBlackedOut:
+		return "blacked out"
+	case TraceRemoved:
+		return "removed"
+	case TraceDone:
+		return "done"
+	case TraceSkipped:
+		return "skipped"
+	}
+	return "unknown"
+}
+
+// TraceEvent a scheduling decision about a task
+type TraceEvent struct {
+	Kind   TraceKind
+	At     time.Time // clock time of the decision
+	Tick   int64     // ticks processed before it
+	Slot   int       // slot holding the task, -1 for the heap backend and the precise tasks
+	Circle int       // rotations left before the slot runs the task
+}
+
+// last events of a task, only used by the wheel goroutine
+type traceRing struct {
+	events  []TraceEvent
+	next    int
+	full    bool
+	retired bool // the task is gone, the ring is kept for the last traceKeep keys
+}
+
+// WithTaskTrace keep the last n scheduling decisions of every task, see TaskTrace. The buffers are allocated
+// once per key, the traces of the last removed or finished keys are kept as well. Default is no trace.
+func WithTaskTrace(n int) Option {
+	return func(tw *TimeWheel) {
+		if n > 0 {
+			tw.traceSize = n
+			tw.traces = make(map[interface{}]*traceRing)
+		}
+	}
+}
+
+// TaskTrace get the last scheduling decisions about the task under the key, oldest first, taken on the wheel
+// goroutine. nil without WithTaskTrace or when the key is unknown.
+func (tw *TimeWheel) TaskTrace(key interface{}) []TraceEvent {
+	if tw.traces == nil || key == nil || !keyComparable(key) {
+		return nil
+	}
+	var events []TraceEvent
+	tw.exec(func() {
+		r := tw.traces[key]
+		if r == nil {
+			return
+		}
+		if r.full {
+			events = append(events, r.events[r.next:]...)
+		}
+		events = append(events, r.events[:r.next]...)
+	})
+	return events
+}
+
+// record a decision about the task, only called on the wheel goroutine
+func (tw *TimeWheel) trace(t *task, kind TraceKind) {
+	r := t.trace
+	if r == nil {
+		if tw.traces == nil {
+			return
+		}
+		// the first decision about the task
+		if r = tw.traces[t.key]; r == nil {
+			r = &traceRing{events: make([]TraceEvent, tw.traceSize)}
+			tw.traces[t.key] = r
+		}
+		r.retired = false
+		t.trace = r
+	}
+	e := &r.events[r.next]
+	e.Kind, e.At, e.Tick = kind, tw.clock.Now(), atomic.LoadInt64(&tw.tickNum)
+	e.Slot, e.Circle = -1, 0
+	if _, ok := wheelOf(tw.backend); ok && !t.precise {
+		e.Slot, e.Circle = t.slot, t.circle
+	}
+	if r.next++; r.next == len(r.events) {
+		r.next, r.full = 0, true
+	}
+	if kind == TraceRemoved || kind == TraceDone {
+		tw.retireTrace(t.key, r)
+	}
+}
+
+// keep the trace of the gone task among the last traceKeep ones
+func (tw *TimeWheel) retireTrace(key interface{}, r *traceRing) {
+	r.retired = true
+	tw.traceRetired = append(tw.traceRetired, key)
+	if len(tw.traceRetired) <= traceKeep {
+		return
+	}
+	oldest := tw.traceRetired[0]
+	tw.traceRetired[0] = nil
+	tw.traceRetired = tw.traceRetired[1:]
+	if old := tw.traces[oldest]; old != nil && old.retired {
+		delete(tw.traces, oldest)
+	}
+}
@@ -0,0 +1,38 @@
+package timewheel
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTaskTrace(t *testing.T) {
+	c := newFakeClock()
+	tw := New(10*time.Millisecond, 2, WithClock(c), WithTaskTrace(8))
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(30*time.Millisecond, 3, "k", nil, func(TaskData) {})
+	settle(tw)
+	for i := 0; i < 14; i++ {
+		c.Tick(10 * time.Millisecond)
+	}
+	settle(tw)
+	var kinds []string
+	for _, e := range tw.TaskTrace("k") {
+		kinds = append(kinds, fmt.Sprintf("%v/%d/%d", e.Kind, e.Slot, e.Circle))
+	}
+	// the ring of 8 kept the last two of the three runs
+	want := "[dispatched/1/0 rescheduled/0/1 rotation/0/0 dispatched/0/0 rescheduled/1/1 rotation/1/0 dispatched/1/0 done/1/0]"
+	if got := fmt.Sprint(kinds); got != want {
+		t.Fatalf("trace %s, want %s", got, want)
+	}
+	tw.AddTask(30*time.Millisecond, -1, "r", nil, func(TaskData) {})
+	tw.RemoveTask("r")
+	settle(tw)
+	if tr := tw.TaskTrace("r"); len(tr) != 2 || tr[0].Kind != TraceAdded || tr[1].Kind != TraceRemoved {
+		t.Fatal(tr)
+	}
+	if New(time.Second, 2).TaskTrace("k") != nil {
+		t.Fatal("trace without WithTaskTrace")
+	}
+}
@@ -0,0 +1,68 @@
+package timewheel
+
+import (
+	"container/heap"
+	"sync/atomic"
+	"time"
+)
+
+// TTL remove the task once d elapsed since it was added, a task that has not fired by then never fires
+// and a recurring task stops there. The expiry is reported by OnTaskExpired and EventExpired.
+func TTL(d time.Duration) TaskOption {
+	return func(t *task) {
+		if d > 0 {
+			t.ttl = d
+		}
+	}
+}
+
+// Expired get the number of tasks removed by their TTL
+func (tw *TimeWheel) Expired() int64 {
+	return atomic.LoadInt64(&tw.expiredNum)
+}
+
+// tasks with a TTL ordered by expiry, each entry holds a reference to its task
+type expiryQueue []*task
+
+func (q expiryQueue) Len() int            { return len(q) }
+func (q expiryQueue) Less(i, j int) bool  { return q[i].expires.Before(q[j].expires) }
+func (q expiryQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *expiryQueue) Push(x interface{}) { *q = append(*q, x.(*task)) }
+func (q *expiryQueue) Pop() interface{} {
+	old := *q
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return t
+}
+
+// track the expiry of a newly registered task, only called on the wheel goroutine
+func (tw *TimeWheel) trackExpiry(task *task) {
+	if task.ttl > 0 && task.expires.IsZero() {
+		task.expires = tw.clock.Now().Add(task.ttl)
+	}
+	if task.expires.IsZero() {
+		return
+	}
+	task.retain()
+	heap.Push(&tw.expiring, task)
+}
+
+// remove the tasks whose TTL elapsed, checked at every tick before the due tasks run
+func (tw *TimeWheel) expireTasks(now time.Time) {
+	for len(tw.expiring) > 0 && !tw.expiring[0].expires.After(now) {
+		task := heap.Pop(&tw.expiring).(*task)
+		// the task may be finished, removed or running its final run
+		if t, ok := tw.taskRecord.Load(task.key); ok && t == task && !task.isHeld() {
+			atomic.AddInt64(&tw.expiredNum, 1)
+			tw.emit(tw.hooks.OnTaskExpired, task)
+			tw.publish(EventExpired, task)
+			if task.jobName != "" {
+				go tw.forgetNamed(task.key)
+			}
+			tw.unregister(task)
+			tw.prerequisiteGone(task.key)
+		}
+		task.release()
+	}
+}
@@ -0,0 +1,42 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTTL(t *testing.T) {
+	c := newFakeClock()
+	var expired int64
+	tw := New(time.Second, 10, WithClock(c), WithHooks(Hooks{OnTaskExpired: func(interface{}, TaskInfo) { atomic.AddInt64(&expired, 1) }}))
+	tw.Start()
+	defer tw.Stop()
+	ch, cancel := tw.Subscribe(64)
+	defer cancel()
+	var once, rec int64
+	// valid for 5s but scheduled in 10s
+	tw.AddTaskWith(10*time.Second, 1, "offer", nil, func(TaskData) { atomic.AddInt64(&once, 1) }, TTL(5*time.Second))
+	// cut off after its second run
+	tw.AddTaskWith(time.Second, -1, "rec", nil, func(TaskData) { atomic.AddInt64(&rec, 1) }, TTL(3500*time.Millisecond))
+	settle(tw)
+	for i := 0; i < 12; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+	}
+	waitCount(t, &expired, 2)
+	if atomic.LoadInt64(&once) != 0 || atomic.LoadInt64(&rec) != 2 {
+		t.Fatalf("offer ran %d times, recurring task %d times", once, rec)
+	}
+	if tw.HasTask("offer") || tw.HasTask("rec") || tw.Expired() != 2 {
+		t.Fatalf("expired %d", tw.Expired())
+	}
+	// reported as expired, neither completed nor removed
+	types := make(map[EventType]int)
+	for len(ch) > 0 {
+		types[(<-ch).Type]++
+	}
+	if types[EventExpired] != 2 || types[EventCompleted] != 0 || types[EventRemoved] != 0 {
+		t.Fatalf("events %v", types)
+	}
+}
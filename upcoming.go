@@ -0,0 +1,51 @@
+package timewheel
+
+import (
+	"sort"
+	"time"
+)
+
+// Upcoming list the tasks firing within the duration ordered by their estimated fire time,
+// at most limit of them, limit <= 0 means no limit. Paused tasks are left out.
+// The estimate is taken on the wheel goroutine, ErrWheelStopped yields nil.
+func (tw *TimeWheel) Upcoming(within time.Duration, limit int) []TaskInfo {
+	var infos []TaskInfo
+	tw.exec(func() {
+		end := tw.clock.Now().Add(within)
+		add := func(t *task, ticks int) {
+			if t.times == 0 || t.isPaused() {
+				return
+			}
+			at := tw.estimateFire(ticks)
+			if at.After(end) {
+				return
+			}
+			info := t.info()
+			info.Next = at
+			infos = append(infos, info)
+		}
+		tw.eachWaiting(func(t *task) {
+			add(t, 0)
+		})
+		tw.backend.each(func(t *task) {
+			add(t, tw.backend.ticksUntil(t))
+		})
+	})
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].Next.Before(infos[j].Next)
+	})
+	if limit > 0 && len(infos) > limit {
+		infos = infos[:limit]
+	}
+	return infos
+}
+
+// estimate the fire time of a task ticks away, only called on the wheel goroutine
+func (tw *TimeWheel) estimateFire(ticks int) time.Time {
+	base := tw.lastTick
+	if base.IsZero() {
+		base = tw.clock.Now()
+	}
+	// the task fires on the tick processing its slot, the next tick processes the current position
+	return base.Add(time.Duration(ticks+1) * tw.interval)
+}
@@ -0,0 +1,57 @@
+package timewheel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpcoming(t *testing.T) {
+	type want struct {
+		key int
+		in  time.Duration
+	}
+	check := func(t *testing.T, got []TaskInfo, now time.Time, wants []want) {
+		t.Helper()
+		if len(got) != len(wants) {
+			t.Fatalf("%d upcoming tasks, want %d", len(got), len(wants))
+		}
+		for i, w := range wants {
+			if got[i].Key != w.key || got[i].Next.Sub(now) != w.in {
+				t.Fatalf("position %d: %v in %v, want %d in %v", i, got[i].Key, got[i].Next.Sub(now), w.key, w.in)
+			}
+		}
+	}
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			c := newFakeClock()
+			tw := New(time.Second, 10, WithClock(c), WithBackend(b.backend))
+			tw.Start()
+			defer tw.Stop()
+			// the last one is beyond a rotation
+			for i, d := range []int{10, 3, 1, 3, 15} {
+				tw.AddTask(time.Duration(d)*time.Second, 1, i, nil, func(TaskData) {})
+			}
+			settle(tw)
+			// the slot of a task is reached one tick after its interval
+			check(t, tw.Upcoming(time.Minute, 0), c.Now(), []want{{2, 2 * time.Second}, {1, 4 * time.Second}, {3, 4 * time.Second}, {0, 11 * time.Second}, {4, 16 * time.Second}})
+			check(t, tw.Upcoming(5*time.Second, 0), c.Now(), []want{{2, 2 * time.Second}, {1, 4 * time.Second}, {3, 4 * time.Second}})
+			check(t, tw.Upcoming(time.Minute, 2), c.Now(), []want{{2, 2 * time.Second}, {1, 4 * time.Second}})
+
+			tw.PauseTask(3)
+			c.Tick(time.Second)
+			c.Tick(time.Second)
+			settle(tw)
+			// 2 fired as estimated, the paused task is left out
+			if tw.HasTask(2) {
+				t.Fatal("2 did not fire")
+			}
+			check(t, tw.Upcoming(time.Minute, 0), c.Now(), []want{{1, 2 * time.Second}, {0, 9 * time.Second}, {4, 14 * time.Second}})
+		})
+	}
+	tw := New(time.Second, 10)
+	tw.Start()
+	tw.Stop()
+	if tw.Upcoming(time.Minute, 0) != nil {
+		t.Fatal("stopped wheel")
+	}
+}
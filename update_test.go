@@ -0,0 +1,51 @@
+package timewheel
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpdateTaskValidation(t *testing.T) {
+	c := newFakeClock()
+	tw := New(10*time.Millisecond, 8, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTask(time.Second, -1, "k", TaskData{"a": 1}, func(TaskData) {})
+	settle(tw)
+	next, err := tw.NextFire("k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		interval time.Duration
+		want     error
+	}{{0, ErrInvalidParams}, {-time.Second, ErrInvalidParams}, {math.MaxInt64, ErrDelayTooLarge}} {
+		err := tw.UpdateTask("k", tc.interval, nil)
+		if !errors.Is(err, tc.want) {
+			t.Fatal(tc.interval, err)
+		}
+		if tc.want == ErrInvalidParams && !strings.Contains(err.Error(), "interval") {
+			t.Fatalf("%v: %v does not name the field", tc.interval, err)
+		}
+	}
+	// the rejected updates left the task untouched
+	data, _ := tw.GetTaskData("k")
+	var info TaskInfo
+	tw.Range(func(k interface{}, i TaskInfo) bool { info = i; return true })
+	if info.Interval != time.Second || data["a"] != 1 {
+		t.Fatal(info, data)
+	}
+	if got, _ := tw.NextFire("k"); !got.Equal(next) {
+		t.Fatalf("next fire %v, was %v", got, next)
+	}
+	// a nil data clears it
+	if err := tw.UpdateTask("k", time.Minute, nil); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := tw.GetTaskData("k"); len(data) != 0 {
+		t.Fatal(data)
+	}
+}
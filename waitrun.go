@@ -0,0 +1,67 @@
+package timewheel
+
+import "context"
+
+// WaitForNextRun wait for the next run of the task to return. It fails with ErrTaskRemoved if the task
+// leaves the wheel before running, with ErrTaskNotFound if no task is registered under the key and with
+// the error of ctx once it is done. A run skipped by the worker pool or the locker is not waited for.
+func (tw *TimeWheel) WaitForNextRun(ctx context.Context, key interface{}) error {
+	if key == nil {
+		return ErrInvalidKey
+	}
+	if !keyComparable(key) {
+		return ErrKeyNotComparable
+	}
+	done := make(chan error, 1)
+	var err error
+	execErr := tw.exec(func() {
+		task, ok := tw.taskRecord.Load(key)
+		if !ok || task.times == 0 {
+			err = ErrTaskNotFound
+			return
+		}
+		task.waiters = append(task.waiters, done)
+	})
+	if execErr != nil {
+		return execErr
+	}
+	if err != nil {
+		return err
+	}
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release the waiters with err, the channels are buffered so a gone waiter does not block
+func releaseWaiters(waiters []chan error, err error) {
+	for _, ch := range waiters {
+		ch <- err
+	}
+}
+
+// release the waiters of the run, the waiters of a skipped run wait for the following one
+func (tw *TimeWheel) runDone(r *jobRun, ran bool) {
+	if ran {
+		releaseWaiters(r.waiters, nil)
+		return
+	}
+	task, waiters := r.task, r.waiters
+	task.retain()
+	go func() {
+		defer task.release()
+		err := tw.exec(func() {
+			if task.times == 0 {
+				releaseWaiters(waiters, ErrTaskRemoved)
+				return
+			}
+			task.waiters = append(task.waiters, waiters...)
+		})
+		if err != nil {
+			releaseWaiters(waiters, err)
+		}
+	}()
+}
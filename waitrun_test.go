@@ -0,0 +1,53 @@
+package timewheel
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWaitForNextRun(t *testing.T) {
+	c := newFakeClock()
+	tw := New(10*time.Millisecond, 8, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var n int64
+	tw.AddTask(10*time.Millisecond, -1, "k", nil, func(TaskData) {
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt64(&n, 1)
+	})
+	tw.AddTask(time.Hour, -1, "slow", nil, func(TaskData) {})
+	settle(tw)
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := tw.WaitForNextRun(context.Background(), "k"); err != nil || atomic.LoadInt64(&n) != 1 {
+				t.Error(err, n)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	c.Tick(10 * time.Millisecond)
+	c.Tick(10 * time.Millisecond)
+	wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := tw.WaitForNextRun(ctx, "slow"); err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+	errc := make(chan error)
+	go func() { errc <- tw.WaitForNextRun(context.Background(), "slow") }()
+	time.Sleep(10 * time.Millisecond)
+	tw.RemoveTask("slow")
+	if err := <-errc; err != ErrTaskRemoved {
+		t.Fatal(err)
+	}
+	if err := tw.WaitForNextRun(context.Background(), "none"); err != ErrTaskNotFound {
+		t.Fatal(err)
+	}
+}
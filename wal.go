@@ -0,0 +1,343 @@
+package timewheel
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var errWALClosed = errors.New("write ahead log is closed")
+
+// kind of a log record
+type walOp uint8
+
+const (
+	walAdd    walOp = iota + 1 // a named task is added, Spec is the whole task
+	walUpdate                  // the interval and data of the task are updated
+	walRemove                  // the task is removed or its final run is dispatched
+	walRun                     // a run is dispatched, Spec carries the remaining times and the next run
+	walTimes                   // the remaining times are set
+)
+
+// record of the write ahead log. On disk every record is framed by its length and crc32,
+// the payload is gob encoded, so the types stored in the keys and task data must be registered with gob.
+type walRecord struct {
+	Op   walOp
+	Spec TaskSpec
+	Gen  uint64 // generation of the task, 0 for the records bound to none, see readWAL
+}
+
+// WithWALSync set how long the appends to the write ahead log wait for each other before they share a fsync,
+// default is 0, the appends arriving during a fsync share the next one
+func WithWALSync(window time.Duration) Option {
+	return func(tw *TimeWheel) {
+		if window > 0 {
+			tw.walWindow = window
+		}
+	}
+}
+
+// RecoverFromWAL replay the write ahead log at path, the named tasks it describes are added to the wheel
+// with their jobs resolved by registry. The log is compacted, a torn record at its end is dropped, then
+// every accepted AddNamedTask, RemoveTask, UpdateTask, SetTimes and run of a named task is appended to it.
+// Call it once after Start and before adding named tasks, a missing file starts a empty log.
+func (tw *TimeWheel) RecoverFromWAL(path string, registry *JobRegistry) error {
+	if path == "" || registry == nil {
+		return fmt.Errorf("%w, illegal wal params", ErrInvalidParams)
+	}
+	if tw.isStopped() {
+		return ErrWheelStopped
+	}
+	if tw.wal.Load() != nil {
+		return ErrWALOpen
+	}
+	specs, dropped, err := readWAL(path)
+	if err != nil {
+		return err
+	}
+	if dropped > 0 {
+		tw.logger.Printf("timewheel: %d bytes of torn write ahead log dropped, path: %s", dropped, path)
+	}
+	if err := compactWAL(path, specs); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	w := &writeAheadLog{f: f, window: tw.walWindow}
+	w.cond = sync.NewCond(&w.mu)
+	if !tw.wal.CompareAndSwap(nil, w) {
+		f.Close()
+		return ErrWALOpen
+	}
+	return tw.Restore(specs, registry.Resolver())
+}
+
+// append the record, the failure is logged since the change is already applied
+func (tw *TimeWheel) walAppend(r walRecord) {
+	w := tw.wal.Load()
+	if w == nil {
+		return
+	}
+	if err := w.append(r); err != nil {
+		tw.logger.Printf("timewheel: write ahead log append failed, key: %v, err: %v", r.Spec.Key, err)
+		tw.reportError(ErrorStore, r.Spec.Key, err)
+	}
+}
+
+// log the run of the task being dispatched, nil if the task is not logged.
+// Called on the wheel goroutine, the returned func does the io.
+func (tw *TimeWheel) walRun(t *task) func() {
+	if t.jobName == "" || tw.wal.Load() == nil {
+		return nil
+	}
+	r := walRecord{Op: walRemove, Spec: TaskSpec{Key: t.key}, Gen: t.gen}
+	if t.times != 1 {
+		r = walRecord{Op: walRun, Spec: TaskSpec{Key: t.key, Times: t.times, Next: t.next.Add(t.interval)}, Gen: t.gen}
+		if r.Spec.Times > 0 {
+			r.Spec.Times--
+		}
+	}
+	return func() {
+		tw.walAppend(r)
+	}
+}
+
+// record the run of the task in the store and the log, nil if the task is not persisted
+func (tw *TimeWheel) persistRun(t *task) func() {
+	store, log := tw.storeRun(t), tw.walRun(t)
+	if store == nil || log == nil {
+		if store != nil {
+			return store
+		}
+		return log
+	}
+	return func() {
+		store()
+		log()
+	}
+}
+
+// append only log file with group commit
+type writeAheadLog struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	f       *os.File
+	window  time.Duration
+	pending []byte // records not written yet
+	seq     uint64 // records appended
+	synced  uint64 // records written and synced
+	syncing bool   // a append is writing a batch
+	closed  bool
+	err     error // the first io error, the log is unusable after it
+}
+
+// append the record and wait until it is synced, one of the waiting appends writes the batch
+func (w *writeAheadLog) append(r walRecord) error {
+	b, err := encodeWALRecord(r)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errWALClosed
+	}
+	if w.err != nil {
+		return w.err
+	}
+	w.pending = append(w.pending, b...)
+	w.seq++
+	seq := w.seq
+	for w.synced < seq {
+		if w.syncing {
+			w.cond.Wait()
+			continue
+		}
+		w.syncing = true
+		if w.window > 0 {
+			w.mu.Unlock()
+			time.Sleep(w.window)
+			w.mu.Lock()
+		}
+		w.flush()
+	}
+	return w.err
+}
+
+// write and sync the pending records, called with mu held and syncing set
+func (w *writeAheadLog) flush() {
+	data, target := w.pending, w.seq
+	w.pending = nil
+	w.mu.Unlock()
+	_, err := w.f.Write(data)
+	if err == nil {
+		err = w.f.Sync()
+	}
+	w.mu.Lock()
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	w.synced = target
+	w.syncing = false
+	w.cond.Broadcast()
+}
+
+// sync the pending records and close the file
+func (w *writeAheadLog) close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	for w.syncing {
+		w.cond.Wait()
+	}
+	if w.synced < w.seq {
+		w.syncing = true
+		w.flush()
+	}
+	if err := w.f.Close(); err != nil && w.err == nil {
+		w.err = err
+	}
+	return w.err
+}
+
+// frame the record: length and crc32 of the payload, then the payload
+func encodeWALRecord(r walRecord) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 8))
+	if err := gob.NewEncoder(&buf).Encode(r); err != nil {
+		return nil, fmt.Errorf("encode wal record of task %v: %w", r.Spec.Key, err)
+	}
+	b := buf.Bytes()
+	binary.LittleEndian.PutUint32(b[0:4], uint32(len(b)-8))
+	binary.LittleEndian.PutUint32(b[4:8], crc32.ChecksumIEEE(b[8:]))
+	return b, nil
+}
+
+// replay the log, the records after the first invalid one are dropped,
+// dropped is the number of bytes dropped. The runs are logged by the job goroutines, so the records of a
+// run may land after the task is removed and added again: a record bound to a generation only applies
+// to the task added with it. The compacted records are bound to none, they take the records of any.
+func readWAL(path string) (specs []TaskSpec, dropped int, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var order []interface{}
+	live := make(map[interface{}]*TaskSpec)
+	gens := make(map[interface{}]uint64)
+	off := 0
+	for off < len(data) {
+		if len(data)-off < 8 {
+			break
+		}
+		n := int(binary.LittleEndian.Uint32(data[off : off+4]))
+		sum := binary.LittleEndian.Uint32(data[off+4 : off+8])
+		if n > len(data)-off-8 {
+			break
+		}
+		payload := data[off+8 : off+8+n]
+		if crc32.ChecksumIEEE(payload) != sum {
+			break
+		}
+		var r walRecord
+		if gob.NewDecoder(bytes.NewReader(payload)).Decode(&r) != nil || r.Spec.Key == nil {
+			break
+		}
+		off += 8 + n
+
+		cur := live[r.Spec.Key]
+		if cur != nil && r.Gen != 0 && gens[r.Spec.Key] != 0 && r.Gen != gens[r.Spec.Key] {
+			// a record of a task removed since
+			continue
+		}
+		switch r.Op {
+		case walAdd:
+			if cur == nil {
+				spec := r.Spec
+				live[spec.Key] = &spec
+				gens[spec.Key] = r.Gen
+				order = append(order, spec.Key)
+			}
+		case walUpdate:
+			if cur != nil {
+				cur.Interval = r.Spec.Interval
+				cur.Data = r.Spec.Data
+			}
+		case walRemove:
+			delete(live, r.Spec.Key)
+		case walTimes:
+			if cur != nil {
+				cur.Times = r.Spec.Times
+			}
+		case walRun:
+			// an older run of the task may be appended later
+			if cur != nil && r.Spec.Next.After(cur.Next) {
+				cur.Times = r.Spec.Times
+				cur.Next = r.Spec.Next
+			}
+		}
+	}
+
+	for _, key := range order {
+		if spec, ok := live[key]; ok {
+			spec.Delay = 0
+			specs = append(specs, *spec)
+			delete(live, key)
+		}
+	}
+	return specs, len(data) - off, nil
+}
+
+// rewrite the log with a add record per task, the file is replaced atomically
+func compactWAL(path string, specs []TaskSpec) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, spec := range specs {
+		b, err := encodeWALRecord(walRecord{Op: walAdd, Spec: spec})
+		if err != nil {
+			f.Close()
+			os.Remove(tmp)
+			return err
+		}
+		buf.Write(b)
+	}
+	if _, err = f.Write(buf.Bytes()); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	// sync the directory so the rename survives a crash
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package timewheel
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWAL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wal")
+	reg := NewJobRegistry(false)
+	var runs int64
+	reg.Register("j", func(TaskData) { atomic.AddInt64(&runs, 1) })
+
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c), WithJobRegistry(reg))
+	tw.Start()
+	if err := tw.RecoverFromWAL(path, reg); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := tw.AddNamedTask(time.Second*3, 2, i, "j", TaskData{"n": i}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	tw.RemoveTask(3)
+	tw.UpdateTask(4, 5*time.Second, TaskData{"n": 44})
+	for i := 0; i < 4; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	time.Sleep(50 * time.Millisecond)
+	tw.Stop()
+	if atomic.LoadInt64(&runs) != 19 {
+		t.Fatal(atomic.LoadInt64(&runs))
+	}
+
+	tw2 := New(time.Second, 10, WithClock(c), WithJobRegistry(reg))
+	tw2.Start()
+	if err := tw2.RecoverFromWAL(path, reg); err != nil {
+		t.Fatal(err)
+	}
+	settle(tw2)
+	if tw2.Len() != 19 || tw2.HasTask(3) {
+		t.Fatal(tw2.Len())
+	}
+	specs, _ := tw2.Snapshot()
+	for _, s := range specs {
+		if s.Times != 1 {
+			t.Fatal(s)
+		}
+		if s.Key == 4 && (s.Interval != 5*time.Second || s.Data["n"] != 44) {
+			t.Fatal(s)
+		}
+	}
+	tw2.Stop()
+	// torn tail
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, data[:len(data)-7], 0o644)
+	tw3 := New(time.Second, 10, WithClock(c))
+	tw3.Start()
+	if err := tw3.RecoverFromWAL(path, reg); err != nil {
+		t.Fatal(err)
+	}
+	settle(tw3)
+	if tw3.Len() != 18 {
+		t.Fatal(tw3.Len())
+	}
+	tw3.Stop()
+}
+
+func writeWAL(t testing.TB, path string, records ...walRecord) []int {
+	var data []byte
+	var ends []int
+	for _, r := range records {
+		b, err := encodeWALRecord(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, b...)
+		ends = append(ends, len(data))
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return ends
+}
+
+func TestWALTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wal")
+	var records []walRecord
+	for i := 0; i < 5; i++ {
+		records = append(records, walRecord{Op: walAdd, Spec: TaskSpec{Key: i, Interval: time.Second, Times: -1}, Gen: uint64(i + 1)})
+	}
+	ends := writeWAL(t, path, records...)
+	data, _ := os.ReadFile(path)
+	// cut the log at every byte, the tasks whose record survived whole are recovered
+	for cut := 0; cut <= len(data); cut++ {
+		if err := os.WriteFile(path, data[:cut], 0o644); err != nil {
+			t.Fatal(err)
+		}
+		specs, dropped, err := readWAL(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		whole, end := 0, 0
+		for whole < len(ends) && ends[whole] <= cut {
+			end = ends[whole]
+			whole++
+		}
+		if len(specs) != whole || dropped != cut-end {
+			t.Fatalf("cut at %d: %d tasks, %d bytes dropped, want %d and %d", cut, len(specs), dropped, whole, cut-end)
+		}
+		for i, s := range specs {
+			if s.Key != i {
+				t.Fatalf("cut at %d: task %v at %d", cut, s.Key, i)
+			}
+		}
+	}
+}
+
+func TestWALStaleRunRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wal")
+	at := time.Unix(1700000000, 0)
+	spec := func(times int, next time.Time) TaskSpec {
+		return TaskSpec{Key: "k", Interval: time.Second, Times: times, Next: next, JobName: "j"}
+	}
+	writeWAL(t, path,
+		walRecord{Op: walAdd, Spec: spec(3, at), Gen: 1},
+		walRecord{Op: walRemove, Spec: TaskSpec{Key: "k"}},
+		walRecord{Op: walAdd, Spec: spec(5, at.Add(time.Second)), Gen: 2},
+		// the job goroutines of the removed task log late
+		walRecord{Op: walRun, Spec: spec(2, at.Add(time.Hour)), Gen: 1},
+		walRecord{Op: walRemove, Spec: TaskSpec{Key: "k"}, Gen: 1},
+		walRecord{Op: walRun, Spec: spec(4, at.Add(2*time.Second)), Gen: 2},
+	)
+	specs, _, err := readWAL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) != 1 || specs[0].Times != 4 || !specs[0].Next.Equal(at.Add(2*time.Second)) {
+		t.Fatal(specs)
+	}
+
+	// the compacted records take the runs of the restored task
+	writeWAL(t, path,
+		walRecord{Op: walAdd, Spec: spec(5, at)},
+		walRecord{Op: walRun, Spec: spec(4, at.Add(time.Second)), Gen: 7},
+		walRecord{Op: walRemove, Spec: TaskSpec{Key: "k"}, Gen: 7},
+	)
+	if specs, _, err = readWAL(path); err != nil || len(specs) != 0 {
+		t.Fatal(specs, err)
+	}
+}
+
+func TestWALReAddedTask(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wal")
+	reg := NewJobRegistry(false)
+	release := make(chan struct{})
+	reg.Register("slow", func(TaskData) { <-release })
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c), WithJobRegistry(reg))
+	tw.Start()
+	if err := tw.RecoverFromWAL(path, reg); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.AddNamedTask(time.Second, 1, "k", "slow", nil); err != nil {
+		t.Fatal(err)
+	}
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	// the final run holds its record until the job returns, the key is free meanwhile
+	if err := tw.AddNamedTask(time.Hour, -1, "k", "slow", nil); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	settle(tw)
+	tw.Stop()
+
+	specs, _, err := readWAL(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) != 1 || specs[0].Interval != time.Hour {
+		t.Fatal(specs)
+	}
+}
+
+// added latency of AddNamedTask with the log, the concurrent adds share a fsync
+func BenchmarkAddNamedTaskWAL(b *testing.B) {
+	for _, bc := range []struct {
+		name   string
+		wal    bool
+		window time.Duration
+	}{
+		{"none", false, 0},
+		{"wal", true, 0},
+		{"wal-window", true, time.Millisecond},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			reg := NewJobRegistry(false)
+			reg.Register("j", func(TaskData) {})
+			tw := New(time.Second, 64, WithJobRegistry(reg), WithWALSync(bc.window))
+			tw.Start()
+			defer tw.Stop()
+			if bc.wal {
+				if err := tw.RecoverFromWAL(filepath.Join(b.TempDir(), "wal"), reg); err != nil {
+					b.Fatal(err)
+				}
+			}
+			var n int64
+			b.SetParallelism(16)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if err := tw.AddNamedTask(time.Hour, 1, atomic.AddInt64(&n, 1), "j", nil); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// WatchdogHandler receive the error of Healthy once the wheel goroutine stalls
+type WatchdogHandler func(err error)
+
+// WithWatchdog check the wheel every period once it is started and call handler with the error of
+// Healthy(maxStaleness) when it reports a stall. The handler is called again on every check while
+// the stall lasts, and with nil once the wheel ticks again.
+func WithWatchdog(period, maxStaleness time.Duration, handler WatchdogHandler) Option {
+	return func(tw *TimeWheel) {
+		if period > 0 && maxStaleness > 0 && handler != nil {
+			tw.watchPeriod = period
+			tw.watchStaleness = maxStaleness
+			tw.watchHandler = handler
+		}
+	}
+}
+
+// record a tick of the wheel goroutine, a plain atomic store
+func (tw *TimeWheel) beat() {
+	atomic.StoreInt64(&tw.heartbeat, tw.clock.Now().UnixNano())
+}
+
+// Healthy report whether the wheel goroutine ticked within maxStaleness, which should span a few
+// intervals. It returns ErrWheelStopped once the wheel is stopped and an error wrapping
+// ErrWheelStalled if the last tick is older. A wheel that stopped itself after repeated panics returns
+// ErrLoopPanic. A wheel not started yet or hibernating is healthy.
+func (tw *TimeWheel) Healthy(maxStaleness time.Duration) error {
+	switch tw.Status() {
+	case StatusNew:
+		return nil
+	case StatusStopped:
+		return ErrWheelStopped
+	case StatusFatal:
+		return ErrLoopPanic
+	}
+	if tw.Idle() {
+		return nil
+	}
+	last := atomic.LoadInt64(&tw.heartbeat)
+	if stale := tw.clock.Now().Sub(time.Unix(0, last)); stale > maxStaleness {
+		return fmt.Errorf("%w, no tick for %v", ErrWheelStalled, stale)
+	}
+	return nil
+}
+
+// call the watchdog handler on the transitions and during the stalls, until the wheel is stopped
+func (tw *TimeWheel) watchLoop() {
+	ticker := tw.clock.NewTicker(tw.watchPeriod)
+	defer ticker.Stop()
+	stalled := false
+	for {
+		select {
+		case <-ticker.C():
+		case <-tw.stopChannel:
+			return
+		}
+		err := tw.Healthy(tw.watchStaleness)
+		if err == ErrWheelStopped || (err == nil && !stalled) {
+			continue
+		}
+		if err == ErrLoopPanic {
+			tw.callWatchdog(err)
+			return
+		}
+		stalled = err != nil
+		tw.callWatchdog(err)
+	}
+}
+
+func (tw *TimeWheel) callWatchdog(err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			tw.logger.Printf("timewheel: watchdog handler panic recovered, panic: %v", r)
+		}
+	}()
+	tw.watchHandler(err)
+}
@@ -0,0 +1,47 @@
+package timewheel
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	var mu sync.Mutex
+	var seen []error
+	tw := New(10*time.Millisecond, 16, WithWorkers(1, 0, RunInline), WithWatchdog(20*time.Millisecond, 100*time.Millisecond, func(err error) {
+		mu.Lock()
+		seen = append(seen, err)
+		mu.Unlock()
+	}))
+	if tw.Healthy(time.Millisecond) != nil {
+		t.Fatal("new")
+	}
+	tw.Start()
+	time.Sleep(30 * time.Millisecond)
+	if err := tw.Healthy(100 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	block := make(chan struct{})
+	tw.AddTask(10*time.Millisecond, 1, "busy", nil, func(TaskData) { <-block })
+	tw.AddTask(10*time.Millisecond, 1, "wedge", nil, func(TaskData) { time.Sleep(400 * time.Millisecond) })
+	time.Sleep(300 * time.Millisecond)
+	if err := tw.Healthy(100 * time.Millisecond); !errors.Is(err, ErrWheelStalled) {
+		t.Fatal(err)
+	}
+	close(block)
+	time.Sleep(300 * time.Millisecond)
+	if err := tw.Healthy(100 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	tw.Stop()
+	if tw.Healthy(time.Second) != ErrWheelStopped {
+		t.Fatal("stopped")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) < 2 || !errors.Is(seen[0], ErrWheelStalled) || seen[len(seen)-1] != nil {
+		t.Fatal(seen)
+	}
+}
@@ -0,0 +1,192 @@
+// Package webhook build jobs posting a JSON payload to a URL when their task fires.
+//
+//	job := webhook.NewWebhookJob(http.DefaultClient, "https://example.com/hook", webhook.WithTimeout(5*time.Second))
+//	tw.AddTaskErr(time.Minute, -1, "ping", timewheel.TaskData{"id": 42}, job, timewheel.CircuitBreaker(5, time.Minute))
+//
+// A response other than 2xx fails the run, so the job composes with the failure handling of the wheel
+// such as CircuitBreaker, Backoff and WithDeadLetter.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/nosixtools/timewheel"
+)
+
+// DefaultMaxRedirects default number of redirects followed by a webhook
+const DefaultMaxRedirects = 3
+
+// StatusError a response status other than 2xx
+type StatusError struct {
+	URL  string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook: %s responded %d %s", e.URL, e.Code, http.StatusText(e.Code))
+}
+
+// Result the outcome of a webhook call, Status is 0 when no response was received
+type Result struct {
+	Key     interface{}
+	URL     string
+	Status  int
+	Latency time.Duration
+	Err     error
+}
+
+// WebhookOption configure a webhook job
+type WebhookOption func(*webhook)
+
+// WithHeader set a header of the requests, Content-Type is application/json unless set
+func WithHeader(name, value string) WebhookOption {
+	return func(w *webhook) {
+		w.header.Set(name, value)
+	}
+}
+
+// WithTimeout bound every call, default is the timeout of the client
+func WithTimeout(d time.Duration) WebhookOption {
+	return func(w *webhook) {
+		if d > 0 {
+			w.timeout = d
+		}
+	}
+}
+
+// WithBody build the payload of a call from the task data, its result is marshaled to JSON.
+// Default is the task data with its keys formatted as strings.
+func WithBody(fn func(ctx context.Context, data timewheel.TaskData) (interface{}, error)) WebhookOption {
+	return func(w *webhook) {
+		if fn != nil {
+			w.body = fn
+		}
+	}
+}
+
+// WithMaxRedirects set the number of redirects followed, 0 follows none, default is DefaultMaxRedirects
+func WithMaxRedirects(n int) WebhookOption {
+	return func(w *webhook) {
+		if n >= 0 {
+			w.maxRedirects = n
+		}
+	}
+}
+
+// WithObserver call fn after every call with its status and latency, for example to feed metrics
+func WithObserver(fn func(r Result)) WebhookOption {
+	return func(w *webhook) {
+		w.observe = fn
+	}
+}
+
+type webhook struct {
+	client       *http.Client
+	url          string
+	header       http.Header
+	timeout      time.Duration
+	body         func(ctx context.Context, data timewheel.TaskData) (interface{}, error)
+	maxRedirects int
+	observe      func(r Result)
+}
+
+// NewWebhookJob create a job posting the task data as JSON to url, nil client means http.DefaultClient.
+// The job fails on a transport error, on a status other than 2xx and on a redirect chain longer than
+// the limit, see WithMaxRedirects.
+func NewWebhookJob(client *http.Client, url string, opts ...WebhookOption) timewheel.JobErr {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	w := &webhook{
+		url:          url,
+		header:       http.Header{},
+		body:         stringKeys,
+		maxRedirects: DefaultMaxRedirects,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.header.Get("Content-Type") == "" {
+		w.header.Set("Content-Type", "application/json")
+	}
+	// a copy so the redirect limit does not leak into the client of the caller
+	c := *client
+	limit := w.maxRedirects
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > limit {
+			return fmt.Errorf("webhook: stopped after %d redirects", limit)
+		}
+		return nil
+	}
+	w.client = &c
+	return w.call
+}
+
+func (w *webhook) call(ctx context.Context, data timewheel.TaskData) (err error) {
+	begin := time.Now()
+	status := 0
+	if w.observe != nil {
+		defer func() {
+			key, _ := timewheel.TaskKey(ctx)
+			w.observe(Result{Key: key, URL: w.url, Status: status, Latency: time.Since(begin), Err: err})
+		}()
+	}
+	v, err := w.body(ctx, data)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("webhook: marshal the body: %w", err)
+	}
+	if w.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for name, values := range w.header {
+		req.Header[name] = values
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// drain a bounded part of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	status = resp.StatusCode
+	if status < 200 || status > 299 {
+		return &StatusError{URL: w.url, Code: status}
+	}
+	return nil
+}
+
+// the task data with its keys formatted as strings, encoding/json only takes string keys
+func stringKeys(_ context.Context, data timewheel.TaskData) (interface{}, error) {
+	if data == nil {
+		return struct{}{}, nil
+	}
+	m := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		s, ok := k.(string)
+		if !ok {
+			s = fmt.Sprint(k)
+		}
+		if _, dup := m[s]; dup {
+			return nil, errors.New("webhook: task data keys collide once formatted as strings")
+		}
+		m[s] = v
+	}
+	return m, nil
+}
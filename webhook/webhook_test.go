@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nosixtools/timewheel"
+)
+
+func TestWebhook(t *testing.T) {
+	var got map[string]interface{}
+	var hdr string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			hdr = r.Header.Get("X-Token")
+			json.NewDecoder(r.Body).Decode(&got)
+		case "/fail":
+			w.WriteHeader(500)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+	var res []Result
+	obs := WithObserver(func(r Result) { res = append(res, r) })
+	ok := NewWebhookJob(srv.Client(), srv.URL+"/ok", WithHeader("X-Token", "t"), obs)
+	if err := ok(context.Background(), timewheel.TaskData{"id": 1, 2: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if got["id"] != 1.0 || got["2"] != "x" || hdr != "t" || res[0].Status != 200 {
+		t.Fatal(got, hdr, res)
+	}
+	err := NewWebhookJob(srv.Client(), srv.URL+"/fail", obs)(context.Background(), nil)
+	var se *StatusError
+	if !errors.As(err, &se) || se.Code != 500 || res[1].Status != 500 {
+		t.Fatal(err)
+	}
+	err = NewWebhookJob(srv.Client(), srv.URL+"/slow", WithTimeout(20*time.Millisecond))(context.Background(), nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+	err = NewWebhookJob(srv.Client(), srv.URL+"/loop", WithMaxRedirects(2))(context.Background(), nil)
+	if err == nil {
+		t.Fatal("redirect loop")
+	}
+
+	// through the wheel, the failures count
+	tw := timewheel.New(10*time.Millisecond, 8)
+	tw.Start()
+	defer tw.Stop()
+	tw.AddTaskErr(10*time.Millisecond, -1, "k", nil, NewWebhookJob(nil, srv.URL+"/fail"))
+	var s timewheel.Stats
+	for deadline := time.Now().Add(time.Second); s.Failures < 2 && time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		s, _ = tw.TaskStats("k")
+	}
+	if s.Failures < 2 {
+		t.Fatal(s)
+	}
+}
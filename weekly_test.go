@@ -0,0 +1,65 @@
+package timewheel
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWeeklyNext(t *testing.T) {
+	utc := time.UTC
+	mwf := Weekly{Days: []time.Weekday{time.Monday, time.Wednesday, time.Friday}, At: 8 * time.Hour, Location: utc}
+	all := Weekly{Days: []time.Weekday{0, 1, 2, 3, 4, 5, 6}, At: 8 * time.Hour, Location: utc}
+	one := Weekly{Days: []time.Weekday{time.Sunday}, At: 23*time.Hour + 30*time.Minute, Location: utc}
+	d := func(day, h, m int) time.Time { return time.Date(2024, 1, day, h, m, 0, 0, utc) } // 2024-01-01 is Monday
+	for i, tc := range []struct {
+		w        Weekly
+		from, to time.Time
+	}{
+		{mwf, d(1, 7, 0), d(1, 8, 0)},
+		{mwf, d(1, 8, 0), d(3, 8, 0)},
+		{mwf, d(1, 9, 0), d(3, 8, 0)},
+		{mwf, d(5, 9, 0), d(8, 8, 0)},
+		{mwf, d(6, 0, 0), d(8, 8, 0)},
+		{all, d(1, 9, 0), d(2, 8, 0)},
+		{all, d(6, 7, 59), d(6, 8, 0)},
+		{one, d(7, 23, 30), d(14, 23, 30)},
+		{one, d(7, 23, 0), d(7, 23, 30)},
+		{one, d(1, 0, 0), d(7, 23, 30)},
+		{Weekly{Location: utc}, d(1, 0, 0), time.Time{}},
+	} {
+		if got := tc.w.Next(tc.from); !got.Equal(tc.to) {
+			t.Errorf("%d: got %v want %v", i, got, tc.to)
+		}
+	}
+}
+
+func TestWeeklyFires(t *testing.T) {
+	c := newFakeClock() // Tuesday 2023-11-14 22:13:20 UTC
+	tw := New(time.Minute, 60, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	var mu sync.Mutex
+	var fires []time.Time
+	w := Weekly{Days: []time.Weekday{time.Wednesday, time.Thursday}, At: 8 * time.Hour, Location: time.UTC}
+	if err := tw.AddScheduledTask(w, "w", nil, func(TaskData) {
+		mu.Lock()
+		fires = append(fires, c.Now())
+		mu.Unlock()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	settle(tw)
+	for i := 0; i < 3*24*60; i++ {
+		c.Tick(time.Minute)
+		if i%500 == 0 {
+			settle(tw)
+		}
+	}
+	settle(tw)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(fires) != 2 || fires[0].Weekday() != time.Wednesday || fires[1].Weekday() != time.Thursday {
+		t.Fatal(fires)
+	}
+}
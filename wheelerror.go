@@ -0,0 +1,134 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultErrorBuffer default buffer size of the channel returned by Errors
+const DefaultErrorBuffer = 64
+
+// ErrorKind kind of an internal error, see Errors
+type ErrorKind int
+
+const (
+	// ErrorRunDropped a run is dropped because the worker pool is full, see WithWorkers
+	ErrorRunDropped ErrorKind = iota
+	// ErrorTaskRejected a task is not added while no caller waits for the result, a duplicate key
+	// accepted by a buffered add or a stored task that can not be loaded back
+	ErrorTaskRejected
+	// ErrorCallbackPanic a hook or a tick hook panicked
+	ErrorCallbackPanic
+	// ErrorSlotFull no slot within the shift has room, the task stays in its full slot, see WithSlotCapacity
+	ErrorSlotFull
+	// ErrorStore the store or the write ahead log failed
+	ErrorStore
+	// ErrorEmit an event is dropped or the emitter failed, see WithEmitter
+	ErrorEmit
+	// ErrorNextDelay a job added with AddTaskNext returned a delay AddTask would reject, the interval is kept
+	ErrorNextDelay
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorRunDropped:
+		return "run dropped"
+	case ErrorTaskRejected:
+		return "task rejected"
+	case ErrorCallbackPanic:
+		return "callback panic"
+	case ErrorSlotFull:
+		return "slot full"
+	case ErrorStore:
+		return "store"
+	case ErrorEmit:
+		return "emit"
+	case ErrorNextDelay:
+		return "next delay"
+	}
+	return "unknown"
+}
+
+// WheelError an internal error of the wheel, Key is nil when no task is involved
+type WheelError struct {
+	Kind ErrorKind
+	Key  interface{}
+	Time time.Time
+	Err  error
+}
+
+func (e WheelError) Error() string {
+	if e.Key == nil {
+		return fmt.Sprintf("timewheel: %v: %v", e.Kind, e.Err)
+	}
+	return fmt.Sprintf("timewheel: %v, key: %v: %v", e.Kind, e.Key, e.Err)
+}
+
+func (e WheelError) Unwrap() error {
+	return e.Err
+}
+
+// WithErrorBuffer set the buffer size of the channel returned by Errors, default is DefaultErrorBuffer
+func WithErrorBuffer(n int) Option {
+	return func(tw *TimeWheel) {
+		if n > 0 {
+			tw.errBuffer = n
+		}
+	}
+}
+
+// channel of the internal errors with the count of the errors it dropped
+type errorSink struct {
+	mu      sync.Mutex // serializes the senders so dropping the oldest error always makes room
+	ch      chan WheelError
+	dropped int64 // accessed atomically
+}
+
+// Errors get the channel of the internal errors, the ones no caller is there to receive: dropped runs,
+// tasks rejected while restored, hook panics and so on. The errors are reported from the first call on.
+// The channel is never waited for, when it is full the oldest error is dropped, see DroppedErrors.
+// Every call returns the same channel, it is never closed.
+func (tw *TimeWheel) Errors() <-chan WheelError {
+	if s := tw.errs.Load(); s != nil {
+		return s.ch
+	}
+	n := tw.errBuffer
+	if n == 0 {
+		n = DefaultErrorBuffer
+	}
+	tw.errs.CompareAndSwap(nil, &errorSink{ch: make(chan WheelError, n)})
+	return tw.errs.Load().ch
+}
+
+// DroppedErrors get the number of errors dropped because the channel of Errors was full
+func (tw *TimeWheel) DroppedErrors() int64 {
+	if s := tw.errs.Load(); s != nil {
+		return atomic.LoadInt64(&s.dropped)
+	}
+	return 0
+}
+
+// send the error to the channel of Errors without blocking, dropping the oldest one if it is full
+func (tw *TimeWheel) reportError(kind ErrorKind, key interface{}, err error) {
+	s := tw.errs.Load()
+	if s == nil {
+		return
+	}
+	e := WheelError{Kind: kind, Key: key, Time: tw.clock.Now(), Err: err}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		select {
+		case s.ch <- e:
+			return
+		default:
+		}
+		select {
+		case <-s.ch:
+			atomic.AddInt64(&s.dropped, 1)
+		default:
+		}
+	}
+}
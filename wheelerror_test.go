@@ -0,0 +1,62 @@
+package timewheel
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestErrorsChannel(t *testing.T) {
+	c := newFakeClock()
+	block := make(chan struct{})
+	tw := New(time.Second, 8, WithClock(c), WithWorkers(1, 0, DropNewest), WithSlotCapacity(1, 1),
+		WithHooks(Hooks{OnTaskAdded: func(key interface{}, info TaskInfo) {
+			if key == "boom" {
+				panic("x")
+			}
+		}}))
+	errs := tw.Errors()
+	tw.Start()
+	defer tw.Stop()
+	defer close(block)
+	tw.AddTask(time.Second, 1, "boom", nil, func(TaskData) {})
+	for i := 0; i < 3; i++ {
+		tw.AddTask(3*time.Second, 1, i, nil, func(TaskData) { <-block })
+	}
+	settle(tw)
+	for i := 0; i < 6; i++ {
+		c.Tick(time.Second)
+		settle(tw)
+	}
+	kinds := map[ErrorKind]int{}
+	timeout := time.After(time.Second)
+	// the panicking hook, the slots of capacity 1 and the single busy worker
+	for kinds[ErrorCallbackPanic] == 0 || kinds[ErrorSlotFull] == 0 || kinds[ErrorRunDropped] == 0 {
+		select {
+		case e := <-errs:
+			kinds[e.Kind]++
+			if e.Kind == ErrorRunDropped && e.Err == nil {
+				t.Fatal(e)
+			}
+			if e.Key == nil || e.Time.IsZero() {
+				t.Fatal(e)
+			}
+		case <-timeout:
+			t.Fatal(kinds)
+		}
+	}
+}
+
+func TestErrorsDropOldest(t *testing.T) {
+	tw := New(time.Second, 8, WithErrorBuffer(2))
+	ch := tw.Errors()
+	for i := 0; i < 5; i++ {
+		tw.reportError(ErrorStore, i, errors.New("x"))
+	}
+	if tw.DroppedErrors() != 3 {
+		t.Fatal(tw.DroppedErrors())
+	}
+	if e := <-ch; e.Key != 3 {
+		t.Fatal(e)
+	}
+}
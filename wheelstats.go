@@ -0,0 +1,52 @@
+package timewheel
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// WheelStats counters of the wheel, a copy safe to retain
+type WheelStats struct {
+	Added      int64         // tasks registered
+	Fired      int64         // runs dispatched
+	Removed    int64         // tasks removed by RemoveTask
+	Expired    int64         // tasks removed by their TTL
+	Tasks      int64         // tasks registered now
+	Ticks      int64         // ticks processed
+	TickTime   time.Duration // total time spent processing the ticks
+	LastTick   time.Duration // duration of the most recent tick
+	TickHist   TickHistogram // distribution of the tick durations, see WithTickBuckets
+	Lateness   TickHistogram // distribution of the lateness of the runs, see LatenessHistogram
+	Position   int64         // current position of the wheel
+	InFlight   int64         // runs dispatched and not returned yet
+	SlowJobs   int64         // runs slower than the slow job threshold
+	Deferred   int64         // due tasks waiting under the tick cap
+	BlackedOut int64         // due tasks waiting for the end of a blackout window
+	LockLost   int64         // runs skipped for the lock held by another instance, see WithLocker
+	Queues     QueueStats    // depth of the queues
+	Shedding   bool          // the low priority tasks are stretched, see WithLoadShedding
+}
+
+// Stats get the counters of the wheel, every counter is read atomically but the set is not taken at a single instant
+func (tw *TimeWheel) Stats() WheelStats {
+	return WheelStats{
+		Added:      atomic.LoadInt64(&tw.addedNum),
+		Fired:      atomic.LoadInt64(&tw.firedNum),
+		Removed:    atomic.LoadInt64(&tw.removedNum),
+		Expired:    atomic.LoadInt64(&tw.expiredNum),
+		Tasks:      atomic.LoadInt64(&tw.taskNum),
+		Ticks:      atomic.LoadInt64(&tw.tickNum),
+		TickTime:   time.Duration(atomic.LoadInt64(&tw.tickTime)),
+		LastTick:   time.Duration(atomic.LoadInt64(&tw.lastTickCost)),
+		TickHist:   tw.tickHist.snapshot(),
+		Lateness:   tw.lateHist.snapshot(),
+		Position:   atomic.LoadInt64(&tw.position),
+		InFlight:   atomic.LoadInt64(&tw.inflightNum),
+		SlowJobs:   atomic.LoadInt64(&tw.slowNum),
+		Deferred:   atomic.LoadInt64(&tw.deferredNum),
+		BlackedOut: atomic.LoadInt64(&tw.blackedOutNum),
+		LockLost:   atomic.LoadInt64(&tw.lockLost),
+		Queues:     tw.QueueStats(),
+		Shedding:   tw.isShedding(),
+	}
+}
@@ -0,0 +1,44 @@
+package timewheel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWheelStats(t *testing.T) {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c))
+	tw.Start()
+	defer tw.Stop()
+	job := func(TaskData) {}
+	// add 10, fire 7, remove 2, expire 1
+	for i := 0; i < 7; i++ {
+		tw.AddTask(time.Second, 1, i, nil, job)
+	}
+	tw.AddTask(20*time.Second, 1, "r1", nil, job)
+	tw.AddTask(20*time.Second, 1, "r2", nil, job)
+	tw.AddTaskWith(20*time.Second, 1, "e", nil, job, TTL(5*time.Second))
+	settle(tw)
+	tw.RemoveTask("r1")
+	tw.RemoveTask("r2")
+	for i := 0; i < 8; i++ {
+		c.Tick(time.Second)
+	}
+	settle(tw)
+	st := tw.Stats()
+	if st.Added != 10 || st.Fired != 7 || st.Removed != 2 || st.Expired != 1 || st.Tasks != 0 || st.Ticks != 8 || st.Position != 8 {
+		t.Fatalf("%+v", st)
+	}
+
+	// the snapshot is a copy
+	tw.AddTask(time.Second, 1, "late", nil, job)
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	if st.Added != 10 || st.Fired != 7 || st.Ticks != 8 {
+		t.Fatalf("retained stats changed: %+v", st)
+	}
+	if now := tw.Stats(); now.Added != 11 || now.Fired != 8 || now.Ticks != 10 || now.Position != 0 {
+		t.Fatalf("%+v", now)
+	}
+}
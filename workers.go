@@ -0,0 +1,211 @@
+package timewheel
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// DropPolicy decide what happens to a run when the queue of the worker pool is full
+type DropPolicy int
+
+const (
+	// Block wait for room in the queue once the tick is handled, the wheel goroutine stops ticking meanwhile
+	// but serves the control calls, so a job may add, remove and update tasks while the queue is full
+	Block DropPolicy = iota
+	// DropNewest drop the run being dispatched
+	DropNewest
+	// DropOldest drop the run waiting longest in the queue to make room
+	DropOldest
+	// RunInline run the job in place of the wheel goroutine once the tick is handled, the wheel does not tick
+	// until the job returns. The job may add, remove and update tasks, the change takes effect before the next tick
+	RunInline
+)
+
+func (p DropPolicy) String() string {
+	switch p {
+	case Block:
+		return "block"
+	case DropNewest:
+		return "drop newest"
+	case DropOldest:
+		return "drop oldest"
+	case RunInline:
+		return "run inline"
+	}
+	return "unknown"
+}
+
+// WithWorkers run the jobs on n goroutines fed by a queue of the given size, policy decides what
+// happens to a run when the queue is full. A dropped run still counts towards the times of its task
+// and the task is rescheduled as usual. WithSequentialKeys takes precedence over the worker pool.
+// Default is a goroutine per run.
+func WithWorkers(n int, queue int, policy DropPolicy) Option {
+	return func(tw *TimeWheel) {
+		if n <= 0 {
+			return
+		}
+		if queue < 0 {
+			queue = 0
+		}
+		tw.workers = &workerPool{n: n, runs: make(chan *jobRun, queue), policy: policy}
+	}
+}
+
+// bounded pool of goroutines running the jobs
+type workerPool struct {
+	n      int
+	runs   chan *jobRun
+	policy DropPolicy
+
+	// counters, accessed atomically
+	dropped int64
+	blocked int64
+	inline  int64
+
+	// runs dropped or run inline, handled by the wheel goroutine once the current event is handled
+	overflow []*jobRun
+	// runs waiting for room in the queue under Block, queued once the current event is handled
+	pending []*jobRun
+}
+
+// start the workers, they exit once the queue is closed and drained
+func (tw *TimeWheel) startWorkers() {
+	for i := 0; i < tw.workers.n; i++ {
+		go func() {
+			for r := range tw.workers.runs {
+				tw.runJob(r)
+			}
+		}()
+	}
+}
+
+// queue the run according to the drop policy, called on the wheel goroutine
+func (tw *TimeWheel) submitRun(r *jobRun) {
+	p := tw.workers
+	if len(p.pending) > 0 {
+		// keep the order of the runs
+		p.pending = append(p.pending, r)
+		return
+	}
+	select {
+	case p.runs <- r:
+		return
+	default:
+	}
+	switch p.policy {
+	case DropNewest:
+		tw.dropRun(r)
+	case DropOldest:
+		for {
+			select {
+			case p.runs <- r:
+				return
+			default:
+			}
+			select {
+			case old := <-p.runs:
+				tw.dropRun(old)
+			default:
+			}
+		}
+	case RunInline:
+		atomic.AddInt64(&p.inline, 1)
+		tw.logger.Printf("timewheel: worker pool full, run inline, key: %v", r.task.key)
+		r.inline = true
+		p.overflow = append(p.overflow, r)
+	default:
+		atomic.AddInt64(&p.blocked, 1)
+		tw.logger.Printf("timewheel: worker pool full, tick blocked, key: %v", r.task.key)
+		// waiting here would hold the wheel goroutine in the middle of the slots, where it can not serve
+		// the calls of the jobs occupying the workers
+		p.pending = append(p.pending, r)
+	}
+}
+
+// queue the runs waiting under Block, the wheel goroutine serves the control calls meanwhile
+// like it does for an inline run, see runInline
+func (tw *TimeWheel) flushPending() {
+	p := tw.workers
+	for len(p.pending) > 0 {
+		select {
+		case p.runs <- p.pending[0]:
+			p.pending[0] = nil
+			p.pending = p.pending[1:]
+		case task := <-tw.addTaskChannel:
+			tw.addTask(task)
+		case req := <-tw.removeTaskChannel:
+			tw.drainAdds()
+			tw.reply(req.reply, func() error {
+				return tw.removeTask(req)
+			})
+		case req := <-tw.updateTaskChannel:
+			tw.drainAdds()
+			tw.reply(req.reply, func() error {
+				return tw.updateTask(req)
+			})
+		case fn := <-tw.execChannel:
+			tw.drainAdds()
+			fn()
+		case <-tw.stopChannel:
+			for _, r := range p.pending {
+				tw.dropRun(r)
+			}
+			p.pending = nil
+			return
+		}
+		if len(tw.finished) > 0 || len(tw.swept) > 0 {
+			tw.flushFinished()
+		}
+	}
+	p.pending = nil
+}
+
+// count the dropped run, its job is skipped but the task is finished as usual
+func (tw *TimeWheel) dropRun(r *jobRun) {
+	p := tw.workers
+	atomic.AddInt64(&p.dropped, 1)
+	tw.logger.Printf("timewheel: worker pool full, run dropped, key: %v, policy: %v", r.task.key, p.policy)
+	tw.reportError(ErrorRunDropped, r.task.key, fmt.Errorf("worker pool full, policy: %v", p.policy))
+	tw.publishInfo(EventDropped, r.task.key, r.info)
+	r.dropped = true
+	p.overflow = append(p.overflow, r)
+}
+
+// handle the runs dropped or run inline, the task of a final run is unregistered by now
+func (tw *TimeWheel) flushOverflow() {
+	p := tw.workers
+	runs := p.overflow
+	p.overflow = nil
+	for i, r := range runs {
+		runs[i] = nil
+		if r.inline {
+			tw.runInline(r)
+		} else {
+			go tw.runJob(r)
+		}
+	}
+}
+
+// WorkerStats counters of the worker pool
+type WorkerStats struct {
+	Workers int        // number of workers, 0 if the pool is not set
+	Policy  DropPolicy // policy applied when the queue is full
+	Dropped int64      // runs dropped by DropNewest or DropOldest
+	Blocked int64      // times the wheel goroutine waited for room under Block
+	Inline  int64      // runs run on the wheel goroutine under RunInline
+}
+
+// WorkerStats get the counters of the worker pool, cheap and safe to call from any goroutine
+func (tw *TimeWheel) WorkerStats() WorkerStats {
+	p := tw.workers
+	if p == nil {
+		return WorkerStats{}
+	}
+	return WorkerStats{
+		Workers: p.n,
+		Policy:  p.policy,
+		Dropped: atomic.LoadInt64(&p.dropped),
+		Blocked: atomic.LoadInt64(&p.blocked),
+		Inline:  atomic.LoadInt64(&p.inline),
+	}
+}
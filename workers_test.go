@@ -0,0 +1,124 @@
+package timewheel
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type policyResult struct {
+	stats   WorkerStats
+	ran     map[string]bool
+	dropped []interface{}
+}
+
+// saturate a single worker with a queue of 1: the worker is busy, a is queued and b, c, d and rec overflow
+func saturate(t *testing.T, policy DropPolicy) policyResult {
+	c := newFakeClock()
+	tw := New(time.Second, 10, WithClock(c), WithWorkers(1, 1, policy))
+	tw.Start()
+	defer tw.Stop()
+	events, cancel := tw.Subscribe(16)
+	defer cancel()
+	busy, release := make(chan struct{}), make(chan struct{})
+	tw.AddTask(time.Second, 1, "busy", nil, func(TaskData) {
+		close(busy)
+		<-release
+	})
+	var mu sync.Mutex
+	ran := make(map[string]bool)
+	for _, k := range []string{"a", "b", "c", "d"} {
+		k := k
+		tw.AddTask(2*time.Second, 1, k, nil, func(TaskData) {
+			mu.Lock()
+			ran[k] = true
+			mu.Unlock()
+		})
+	}
+	var recRuns int64
+	tw.AddTask(2*time.Second, -1, "rec", nil, func(TaskData) { atomic.AddInt64(&recRuns, 1) })
+	settle(tw)
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	<-busy
+	if policy == Block {
+		go c.Tick(time.Second)
+		deadline := time.Now().Add(5 * time.Second)
+		for tw.WorkerStats().Blocked == 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+	} else {
+		c.Tick(time.Second)
+		settle(tw)
+	}
+	close(release)
+	settle(tw)
+	// the recurring task is rescheduled even when its run was dropped
+	if !tw.HasTask("rec") {
+		t.Fatalf("%v: recurring task gone", policy)
+	}
+	before := atomic.LoadInt64(&recRuns)
+	c.Tick(time.Second)
+	c.Tick(time.Second)
+	settle(tw)
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt64(&recRuns) == before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if atomic.LoadInt64(&recRuns) == before {
+		t.Fatalf("%v: recurring task did not run again", policy)
+	}
+	for tw.QueueStats().InFlight > 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	res := policyResult{stats: tw.WorkerStats(), ran: ran}
+	for {
+		select {
+		case ev := <-events:
+			if ev.Type == EventDropped {
+				res.dropped = append(res.dropped, ev.Key)
+			}
+			continue
+		default:
+		}
+		break
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return res
+}
+
+func TestWorkerPolicies(t *testing.T) {
+	t.Run("block", func(t *testing.T) {
+		res := saturate(t, Block)
+		if res.stats.Blocked == 0 || res.stats.Dropped != 0 || len(res.ran) != 4 {
+			t.Fatalf("%+v ran %v", res.stats, res.ran)
+		}
+	})
+	t.Run("drop newest", func(t *testing.T) {
+		res := saturate(t, DropNewest)
+		if res.stats.Dropped != 4 || len(res.ran) != 1 || !res.ran["a"] {
+			t.Fatalf("%+v ran %v", res.stats, res.ran)
+		}
+		if len(res.dropped) != 4 || res.dropped[0] != "b" || res.dropped[3] != "rec" {
+			t.Fatalf("dropped events %v", res.dropped)
+		}
+	})
+	t.Run("drop oldest", func(t *testing.T) {
+		res := saturate(t, DropOldest)
+		if res.stats.Dropped != 4 || len(res.ran) != 0 {
+			t.Fatalf("%+v ran %v", res.stats, res.ran)
+		}
+		if len(res.dropped) != 4 || res.dropped[0] != "a" || res.dropped[3] != "d" {
+			t.Fatalf("dropped events %v", res.dropped)
+		}
+	})
+	t.Run("run inline", func(t *testing.T) {
+		res := saturate(t, RunInline)
+		if res.stats.Inline != 4 || res.stats.Dropped != 0 || len(res.ran) != 4 {
+			t.Fatalf("%+v ran %v", res.stats, res.ran)
+		}
+	})
+}